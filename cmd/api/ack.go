@@ -0,0 +1,216 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/constants"
+	"github.com/i4o-oss/watchtower/internal/data"
+	"gorm.io/gorm"
+)
+
+// ackLinkTTL is how long acknowledgement links sent with escalations stay valid
+const ackLinkTTL = 24 * time.Hour
+
+// ackSignature signs an incident acknowledgement link expiring at the given unix time
+func ackSignature(incidentID uuid.UUID, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(sessionSecret()))
+	fmt.Fprintf(mac, "ack:%s:%d", incidentID, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// validAckSignature reports whether the signature matches and the link has not expired
+func validAckSignature(incidentID uuid.UUID, expiresParam, signature string, now time.Time) bool {
+	expires, err := strconv.ParseInt(expiresParam, 10, 64)
+	if err != nil || now.Unix() > expires {
+		return false
+	}
+	return hmac.Equal([]byte(ackSignature(incidentID, expires)), []byte(signature))
+}
+
+// incidentAckURL builds a signed link that acknowledges the incident without logging in. The
+// link is absolute when a domain is configured in the settings
+func (app *Application) incidentAckURL(incidentID uuid.UUID) string {
+	expires := time.Now().Add(ackLinkTTL).Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", ackSignature(incidentID, expires))
+	path := fmt.Sprintf("/api/v1/incidents/%s/ack?%s", incidentID, query.Encode())
+
+	return app.baseURL() + path
+}
+
+// acknowledgeIncident acknowledges an incident, stopping its escalation, and records who did
+// it. It returns false if the incident was already acknowledged
+func (app *Application) acknowledgeIncident(incident *data.Incident, user *data.User) (bool, error) {
+	var userID *uuid.UUID
+	message := "Incident acknowledged via escalation link"
+	if user != nil {
+		userID = &user.ID
+		message = fmt.Sprintf("Incident acknowledged by %s", user.Email)
+	}
+
+	now := time.Now()
+	acknowledged, err := app.db.AcknowledgeIncident(incident.ID, userID, now)
+	if err != nil || !acknowledged {
+		return false, err
+	}
+
+	incident.AcknowledgedAt = &now
+	incident.AcknowledgedBy = userID
+	incident.NextEscalationAt = nil
+
+	timeline := &data.IncidentTimeline{
+		IncidentID: incident.ID,
+		UserID:     userID,
+		EventType:  "acknowledged",
+		Message:    message,
+	}
+	if err := app.db.CreateIncidentTimeline(timeline); err != nil {
+		app.logger.Error("Error creating incident timeline", "err", err.Error())
+		// Continue anyway, the incident was acknowledged
+	} else {
+		app.sseHub.BroadcastTimelineUpdate("timeline_created", timeline)
+	}
+
+	app.sseHub.BroadcastIncidentUpdate("incident_updated", incident)
+
+	return true, nil
+}
+
+// acknowledgeIncidentHandler handles POST /api/v1/admin/incidents/{id}/acknowledge
+func (app *Application) acknowledgeIncidentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidIncidentID)
+		return
+	}
+
+	incident, err := app.db.GetIncident(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			app.errorResponse(w, http.StatusNotFound, "Incident not found")
+			return
+		}
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting incident", err)
+		return
+	}
+
+	if incident.Status == "resolved" {
+		app.errorResponse(w, http.StatusConflict, "Incident is already resolved")
+		return
+	}
+
+	acknowledged, err := app.acknowledgeIncident(incident, app.getUserFromContext(r))
+	if err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error acknowledging incident", err)
+		return
+	}
+	if !acknowledged {
+		app.errorResponse(w, http.StatusConflict, "Incident is already acknowledged")
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, IncidentResponse{Incident: incident})
+}
+
+// ackPage is shown for signed acknowledgement links. Opening the link only asks for
+// confirmation so link previews and mail scanners cannot acknowledge an incident
+var ackPage = template.Must(template.New("ack").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Acknowledge incident</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Message}}</p>
+{{if .Confirm}}<form method="post">
+<input type="hidden" name="expires" value="{{.Expires}}">
+<input type="hidden" name="signature" value="{{.Signature}}">
+<button type="submit">Acknowledge</button>
+</form>{{end}}
+</body>
+</html>
+`))
+
+type ackPageData struct {
+	Title     string
+	Message   string
+	Confirm   bool
+	Expires   string
+	Signature string
+}
+
+// renderAckPage writes the acknowledgement page
+func (app *Application) renderAckPage(w http.ResponseWriter, status int, page ackPageData) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := ackPage.Execute(w, page); err != nil {
+		app.logger.Error("Error rendering acknowledgement page", "err", err.Error())
+	}
+}
+
+// incidentAckLink handles GET and POST /api/v1/incidents/{id}/ack, authenticated by the signed
+// link sent with escalation notifications. GET asks for confirmation, POST acknowledges
+func (app *Application) incidentAckLink(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.renderAckPage(w, http.StatusBadRequest, ackPageData{Title: "Invalid link", Message: "This acknowledgement link is not valid."})
+		return
+	}
+
+	expires := r.FormValue("expires")
+	signature := r.FormValue("signature")
+	if !validAckSignature(id, expires, signature, time.Now()) {
+		app.renderAckPage(w, http.StatusForbidden, ackPageData{Title: "Invalid link", Message: "This acknowledgement link is not valid or has expired."})
+		return
+	}
+
+	incident, err := app.db.GetIncident(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			app.renderAckPage(w, http.StatusNotFound, ackPageData{Title: "Incident not found", Message: "The incident no longer exists."})
+			return
+		}
+		app.logger.Error("Error getting incident", "err", err.Error())
+		app.renderAckPage(w, http.StatusInternalServerError, ackPageData{Title: "Something went wrong", Message: "The incident could not be loaded, please try again."})
+		return
+	}
+
+	switch {
+	case incident.Status == "resolved":
+		app.renderAckPage(w, http.StatusOK, ackPageData{Title: incident.Title, Message: "This incident has already been resolved."})
+		return
+	case incident.AcknowledgedAt != nil:
+		app.renderAckPage(w, http.StatusOK, ackPageData{Title: incident.Title, Message: "This incident has already been acknowledged."})
+		return
+	case r.Method != http.MethodPost:
+		app.renderAckPage(w, http.StatusOK, ackPageData{
+			Title:     incident.Title,
+			Message:   "Acknowledge this incident to stop further escalation.",
+			Confirm:   true,
+			Expires:   expires,
+			Signature: signature,
+		})
+		return
+	}
+
+	if _, err := app.acknowledgeIncident(incident, nil); err != nil {
+		app.logger.Error("Error acknowledging incident", "err", err.Error())
+		app.renderAckPage(w, http.StatusInternalServerError, ackPageData{Title: "Something went wrong", Message: "The incident could not be acknowledged, please try again."})
+		return
+	}
+
+	app.renderAckPage(w, http.StatusOK, ackPageData{Title: incident.Title, Message: "Incident acknowledged, escalation has stopped."})
+}
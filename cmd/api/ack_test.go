@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestValidAckSignature(t *testing.T) {
+	incidentID := uuid.New()
+	now := time.Now()
+	expires := now.Add(time.Hour).Unix()
+	expiresParam := strconv.FormatInt(expires, 10)
+	signature := ackSignature(incidentID, expires)
+
+	tests := []struct {
+		name       string
+		incidentID uuid.UUID
+		expires    string
+		signature  string
+		now        time.Time
+		expected   bool
+	}{
+		{"valid", incidentID, expiresParam, signature, now, true},
+		{"expired", incidentID, expiresParam, signature, now.Add(2 * time.Hour), false},
+		{"other incident", uuid.New(), expiresParam, signature, now, false},
+		{"extended expiry", incidentID, strconv.FormatInt(expires+3600, 10), signature, now, false},
+		{"missing signature", incidentID, expiresParam, "", now, false},
+		{"missing expiry", incidentID, "", signature, now, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validAckSignature(tt.incidentID, tt.expires, tt.signature, tt.now); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -12,25 +13,59 @@ import (
 	"github.com/google/uuid"
 	"github.com/i4o-oss/watchtower/internal/constants"
 	"github.com/i4o-oss/watchtower/internal/data"
+	"gorm.io/gorm"
 )
 
 // EndpointRequest represents the request body for endpoint operations
 type EndpointRequest struct {
-	Name                 string            `json:"name"`
-	Description          string            `json:"description"`
-	URL                  string            `json:"url"`
-	Method               string            `json:"method"`
-	Headers              map[string]string `json:"headers"`
-	Body                 string            `json:"body"`
-	ExpectedStatusCode   int               `json:"expected_status_code"`
-	TimeoutSeconds       int               `json:"timeout_seconds"`
-	CheckIntervalSeconds int               `json:"check_interval_seconds"`
-	Enabled              bool              `json:"enabled"`
+	Name                  string                   `json:"name"`
+	Description           string                   `json:"description"`
+	CheckType             string                   `json:"check_type"`
+	URL                   string                   `json:"url"`
+	Method                string                   `json:"method"`
+	Headers               map[string]string        `json:"headers"`
+	Body                  string                   `json:"body"`
+	ExpectedStatusCode    int                      `json:"expected_status_code"`
+	Assertions            []data.ResponseAssertion `json:"assertions"`
+	TCPSend               string                   `json:"tcp_send"`
+	TCPExpect             string                   `json:"tcp_expect"`
+	DNSRecordType         string                   `json:"dns_record_type"`
+	DNSResolver           string                   `json:"dns_resolver"`
+	DNSExpectedValues     []string                 `json:"dns_expected_values"`
+	CertExpiryWarningDays *int                     `json:"cert_expiry_warning_days"`
+	TLSSkipVerify         bool                     `json:"tls_skip_verify"`
+	HeartbeatGraceSeconds *int                     `json:"heartbeat_grace_seconds"`
+	RotateHeartbeatToken  bool                     `json:"rotate_heartbeat_token"`
+	TimeoutSeconds        int                      `json:"timeout_seconds"`
+	MaxRetries            *int                     `json:"max_retries"`
+	RetryDelayMs          *int                     `json:"retry_delay_ms"`
+	ConfirmRetries        *int                     `json:"confirm_retries"`
+	CheckIntervalSeconds  int                      `json:"check_interval_seconds"`
+	Enabled               bool                     `json:"enabled"`
+	Tags                  []string                 `json:"tags"`
+	ParentIDs             []uuid.UUID              `json:"parent_ids"`
 }
 
 // EndpointResponse represents the response for endpoint operations
 type EndpointResponse struct {
 	*data.Endpoint
+	HeartbeatURL string               `json:"heartbeat_url,omitempty"`
+	Certificate  *CertificateResponse `json:"certificate,omitempty"`
+}
+
+// newEndpointResponse wraps an endpoint for admin responses, exposing its heartbeat ping URL
+func newEndpointResponse(endpoint *data.Endpoint) EndpointResponse {
+	response := EndpointResponse{Endpoint: endpoint}
+	if endpoint.HeartbeatToken != "" {
+		response.HeartbeatURL = heartbeatPath(endpoint.HeartbeatToken)
+	}
+	return response
+}
+
+// CertificateResponse represents the latest TLS certificate seen for an endpoint
+type CertificateResponse struct {
+	*data.EndpointCertificate
+	DaysRemaining int `json:"days_remaining"`
 }
 
 // ListEndpointsResponse represents the response for listing endpoints
@@ -87,6 +122,14 @@ func (app *Application) createEndpoint(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A new endpoint has no dependents yet, so its parents cannot form a cycle
+	parentIDs, errors := app.validateEndpointParents(uuid.Nil, req.ParentIDs)
+	if len(errors) > 0 {
+		app.respondWithValidationErrors(w, errors)
+		return
+	}
+	req.ParentIDs = parentIDs
+
 	if req.Method == "" {
 		req.Method = "GET"
 	}
@@ -103,18 +146,58 @@ func (app *Application) createEndpoint(w http.ResponseWriter, r *http.Request) {
 		req.CheckIntervalSeconds = 300
 	}
 
+	if req.CertExpiryWarningDays == nil {
+		defaultWarningDays := 14
+		req.CertExpiryWarningDays = &defaultWarningDays
+	}
+
+	if req.HeartbeatGraceSeconds == nil {
+		defaultGraceSeconds := 60
+		req.HeartbeatGraceSeconds = &defaultGraceSeconds
+	}
+
+	if req.ConfirmRetries == nil {
+		defaultConfirmRetries := 0
+		req.ConfirmRetries = &defaultConfirmRetries
+	}
+
 	// Create endpoint
 	endpoint := &data.Endpoint{
-		Name:                 req.Name,
-		Description:          req.Description,
-		URL:                  req.URL,
-		Method:               req.Method,
-		Headers:              data.HTTPHeaders(req.Headers),
-		Body:                 req.Body,
-		ExpectedStatusCode:   req.ExpectedStatusCode,
-		TimeoutSeconds:       req.TimeoutSeconds,
-		CheckIntervalSeconds: req.CheckIntervalSeconds,
-		Enabled:              req.Enabled,
+		Name:                  req.Name,
+		Description:           req.Description,
+		CheckType:             req.CheckType,
+		URL:                   req.URL,
+		Method:                req.Method,
+		Headers:               data.HTTPHeaders(req.Headers),
+		Body:                  req.Body,
+		ExpectedStatusCode:    req.ExpectedStatusCode,
+		Assertions:            data.ResponseAssertions(req.Assertions),
+		TCPSend:               req.TCPSend,
+		TCPExpect:             req.TCPExpect,
+		DNSRecordType:         req.DNSRecordType,
+		DNSResolver:           req.DNSResolver,
+		DNSExpectedValues:     data.StringList(req.DNSExpectedValues),
+		CertExpiryWarningDays: *req.CertExpiryWarningDays,
+		TLSSkipVerify:         req.TLSSkipVerify,
+		HeartbeatGraceSeconds: *req.HeartbeatGraceSeconds,
+		TimeoutSeconds:        req.TimeoutSeconds,
+		MaxRetries:            req.MaxRetries,
+		RetryDelayMs:          req.RetryDelayMs,
+		ConfirmRetries:        *req.ConfirmRetries,
+		CheckIntervalSeconds:  req.CheckIntervalSeconds,
+		Enabled:               req.Enabled,
+		Tags:                  data.StringList(req.Tags),
+		ParentIDs:             data.UUIDList(req.ParentIDs),
+	}
+
+	// Heartbeat endpoints are pinged through a secret URL
+	if endpoint.CheckType == data.CheckTypeHeartbeat {
+		token, err := generateHeartbeatToken()
+		if err != nil {
+			app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error generating heartbeat token", err)
+			return
+		}
+		endpoint.HeartbeatToken = token
 	}
 
 	if err := app.db.CreateEndpoint(endpoint); err != nil {
@@ -136,7 +219,7 @@ func (app *Application) createEndpoint(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	app.writeJSON(w, http.StatusCreated, EndpointResponse{Endpoint: endpoint})
+	app.writeJSON(w, http.StatusCreated, newEndpointResponse(endpoint))
 }
 
 // getEndpoint handles GET /api/v1/admin/endpoints/{id}
@@ -154,7 +237,20 @@ func (app *Application) getEndpoint(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	app.writeJSON(w, http.StatusOK, EndpointResponse{Endpoint: endpoint})
+	response := newEndpointResponse(endpoint)
+
+	// Include the latest certificate for HTTPS endpoints
+	cert, err := app.db.GetEndpointCertificate(id)
+	if err == nil {
+		response.Certificate = &CertificateResponse{
+			EndpointCertificate: cert,
+			DaysRemaining:       cert.DaysRemaining(time.Now()),
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		app.logger.Error("Error getting endpoint certificate", "err", err.Error())
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
 }
 
 // updateEndpoint handles PUT /api/v1/admin/endpoints/{id}
@@ -179,31 +275,107 @@ func (app *Application) updateEndpoint(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Update fields
-	if req.Name != "" {
-		endpoint.Name = req.Name
+	// Fall back to existing values for fields omitted from the request
+	if req.Name == "" {
+		req.Name = endpoint.Name
 	}
-	endpoint.Description = req.Description
-	if req.URL != "" {
-		endpoint.URL = req.URL
+	if req.CheckType == "" {
+		req.CheckType = endpoint.GetCheckType()
 	}
-	if req.Method != "" {
-		endpoint.Method = req.Method
+	if req.URL == "" {
+		req.URL = endpoint.URL
 	}
-	if req.Headers != nil {
-		endpoint.Headers = data.HTTPHeaders(req.Headers)
+	if req.Method == "" {
+		req.Method = endpoint.Method
 	}
-	endpoint.Body = req.Body
-	if req.ExpectedStatusCode > 0 {
-		endpoint.ExpectedStatusCode = req.ExpectedStatusCode
+	if req.Headers == nil {
+		req.Headers = endpoint.Headers
+	}
+	if req.Assertions == nil {
+		req.Assertions = endpoint.Assertions
+	}
+	if req.ExpectedStatusCode == 0 {
+		req.ExpectedStatusCode = endpoint.ExpectedStatusCode
+	}
+	if req.TimeoutSeconds == 0 {
+		req.TimeoutSeconds = endpoint.TimeoutSeconds
+	}
+	if req.CheckIntervalSeconds == 0 {
+		req.CheckIntervalSeconds = endpoint.CheckIntervalSeconds
+	}
+	if req.CertExpiryWarningDays == nil {
+		req.CertExpiryWarningDays = &endpoint.CertExpiryWarningDays
+	}
+	if req.HeartbeatGraceSeconds == nil {
+		req.HeartbeatGraceSeconds = &endpoint.HeartbeatGraceSeconds
+	}
+	if req.MaxRetries == nil {
+		req.MaxRetries = endpoint.MaxRetries
 	}
-	if req.TimeoutSeconds > 0 {
-		endpoint.TimeoutSeconds = req.TimeoutSeconds
+	if req.RetryDelayMs == nil {
+		req.RetryDelayMs = endpoint.RetryDelayMs
 	}
-	if req.CheckIntervalSeconds > 0 {
-		endpoint.CheckIntervalSeconds = req.CheckIntervalSeconds
+	if req.ConfirmRetries == nil {
+		req.ConfirmRetries = &endpoint.ConfirmRetries
 	}
+	if req.Tags == nil {
+		req.Tags = endpoint.Tags
+	}
+	if req.ParentIDs == nil {
+		req.ParentIDs = endpoint.ParentIDs
+	}
+
+	// Validate request
+	if errors := validateEndpointRequest(&req); len(errors) > 0 {
+		app.respondWithValidationErrors(w, errors)
+		return
+	}
+
+	parentIDs, errors := app.validateEndpointParents(endpoint.ID, req.ParentIDs)
+	if len(errors) > 0 {
+		app.respondWithValidationErrors(w, errors)
+		return
+	}
+	req.ParentIDs = parentIDs
+
+	// Update fields
+	endpoint.Name = req.Name
+	endpoint.Description = req.Description
+	endpoint.CheckType = req.CheckType
+	endpoint.URL = req.URL
+	endpoint.Method = req.Method
+	endpoint.Headers = data.HTTPHeaders(req.Headers)
+	endpoint.Body = req.Body
+	endpoint.ExpectedStatusCode = req.ExpectedStatusCode
+	endpoint.Assertions = data.ResponseAssertions(req.Assertions)
+	endpoint.TCPSend = req.TCPSend
+	endpoint.TCPExpect = req.TCPExpect
+	endpoint.DNSRecordType = req.DNSRecordType
+	endpoint.DNSResolver = req.DNSResolver
+	endpoint.DNSExpectedValues = data.StringList(req.DNSExpectedValues)
+	endpoint.CertExpiryWarningDays = *req.CertExpiryWarningDays
+	endpoint.TLSSkipVerify = req.TLSSkipVerify
+	endpoint.HeartbeatGraceSeconds = *req.HeartbeatGraceSeconds
+	endpoint.TimeoutSeconds = req.TimeoutSeconds
+	endpoint.MaxRetries = req.MaxRetries
+	endpoint.RetryDelayMs = req.RetryDelayMs
+	endpoint.ConfirmRetries = *req.ConfirmRetries
+	endpoint.CheckIntervalSeconds = req.CheckIntervalSeconds
 	endpoint.Enabled = req.Enabled
+	endpoint.Tags = data.StringList(req.Tags)
+	endpoint.ParentIDs = data.UUIDList(req.ParentIDs)
+
+	// Issue a ping token when switching to a heartbeat, or a new one on rotation
+	if endpoint.CheckType != data.CheckTypeHeartbeat {
+		endpoint.HeartbeatToken = ""
+	} else if endpoint.HeartbeatToken == "" || req.RotateHeartbeatToken {
+		token, err := generateHeartbeatToken()
+		if err != nil {
+			app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error generating heartbeat token", err)
+			return
+		}
+		endpoint.HeartbeatToken = token
+	}
 
 	if err := app.db.UpdateEndpoint(endpoint); err != nil {
 		app.logger.Error("Error updating endpoint", "err", err.Error())
@@ -224,7 +396,7 @@ func (app *Application) updateEndpoint(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	app.writeJSON(w, http.StatusOK, EndpointResponse{Endpoint: endpoint})
+	app.writeJSON(w, http.StatusOK, newEndpointResponse(endpoint))
 }
 
 // deleteEndpoint handles DELETE /api/v1/admin/endpoints/{id}
@@ -501,6 +673,7 @@ func (app *Application) createIncident(w http.ResponseWriter, r *http.Request) {
 
 	// Broadcast incident creation event via SSE
 	app.sseHub.BroadcastIncidentUpdate("incident_created", incident)
+	app.notifyIncidentCreated(incident)
 
 	app.writeJSON(w, http.StatusCreated, IncidentResponse{Incident: incident})
 }
@@ -547,6 +720,8 @@ func (app *Application) updateIncident(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	previousStatus := incident.Status
+
 	// Update fields
 	if req.Title != "" {
 		incident.Title = req.Title
@@ -574,6 +749,7 @@ func (app *Application) updateIncident(w http.ResponseWriter, r *http.Request) {
 
 	// Broadcast incident update event via SSE
 	app.sseHub.BroadcastIncidentUpdate("incident_updated", incident)
+	app.notifyIncidentUpdated(incident, previousStatus)
 
 	app.writeJSON(w, http.StatusOK, IncidentResponse{Incident: incident})
 }
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/cache"
+	"github.com/i4o-oss/watchtower/internal/data"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	insertQuery    = regexp.MustCompile(`^INSERT INTO "(\w+)" \(([^)]*)\)`)
+	returningQuery = regexp.MustCompile(`RETURNING (.*)$`)
+	selectQuery    = regexp.MustCompile(`^SELECT .* FROM "(\w+)"`)
+	whereIDQuery   = regexp.MustCompile(`WHERE "\w+"\."id" = \$1`)
+)
+
+// memoryStore is a database/sql connector keeping inserted rows in memory. It understands the
+// inserts and primary key lookups GORM issues, every other query returns no rows
+type memoryStore struct {
+	mu     sync.Mutex
+	tables map[string][]map[string]driver.Value
+}
+
+func (s *memoryStore) Connect(context.Context) (driver.Conn, error) {
+	return &memoryConn{store: s}, nil
+}
+func (s *memoryStore) Driver() driver.Driver { return nil }
+
+type memoryConn struct {
+	store *memoryStore
+}
+
+func (c *memoryConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *memoryConn) Close() error                        { return nil }
+func (c *memoryConn) Begin() (driver.Tx, error)           { return c, nil }
+func (c *memoryConn) Commit() error                       { return nil }
+func (c *memoryConn) Rollback() error                     { return nil }
+
+func (c *memoryConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+func (c *memoryConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+
+	if match := insertQuery.FindStringSubmatch(query); match != nil {
+		row := map[string]driver.Value{"id": uuid.New().String()}
+		for i, column := range quotedColumns(match[2]) {
+			row[column] = args[i].Value
+		}
+		c.store.tables[match[1]] = append(c.store.tables[match[1]], row)
+
+		var returning []string
+		if match := returningQuery.FindStringSubmatch(query); match != nil {
+			returning = quotedColumns(match[1])
+		}
+		return newMemoryRows(returning, []map[string]driver.Value{row}), nil
+	}
+
+	if match := selectQuery.FindStringSubmatch(query); match != nil {
+		var rows []map[string]driver.Value
+		for _, row := range c.store.tables[match[1]] {
+			if whereIDQuery.MatchString(query) && row["id"] != args[0].Value {
+				continue
+			}
+			rows = append(rows, row)
+		}
+
+		var columns []string
+		for column := range firstRow(rows) {
+			columns = append(columns, column)
+		}
+		return newMemoryRows(columns, rows), nil
+	}
+
+	return newMemoryRows(nil, nil), nil
+}
+
+func quotedColumns(list string) []string {
+	var columns []string
+	for _, column := range strings.Split(list, ",") {
+		columns = append(columns, strings.Trim(strings.TrimSpace(column), `"`))
+	}
+	return columns
+}
+
+func firstRow(rows []map[string]driver.Value) map[string]driver.Value {
+	if len(rows) == 0 {
+		return nil
+	}
+	return rows[0]
+}
+
+type memoryRows struct {
+	columns []string
+	rows    []map[string]driver.Value
+}
+
+func newMemoryRows(columns []string, rows []map[string]driver.Value) *memoryRows {
+	return &memoryRows{columns: columns, rows: rows}
+}
+
+func (r *memoryRows) Columns() []string { return r.columns }
+func (r *memoryRows) Close() error      { return nil }
+
+func (r *memoryRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	for i, column := range r.columns {
+		dest[i] = r.rows[0][column]
+	}
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// newTestApplication returns an application backed by an in-memory database
+func newTestApplication(t *testing.T) *Application {
+	store := &memoryStore{tables: make(map[string][]map[string]driver.Value)}
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(store)}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+
+	return &Application{
+		logger: log.New(os.Stderr),
+		db:     data.NewCachedDB(&data.DB{DB: gormDB}, cache.NewNoOpCache()),
+		sseHub: NewSSEHub(),
+	}
+}
+
+func TestCreateAndGetEndpoint(t *testing.T) {
+	app := newTestApplication(t)
+
+	router := chi.NewRouter()
+	router.Post("/endpoints", app.createEndpoint)
+	router.Get("/endpoints/{id}", app.getEndpoint)
+
+	body := `{"name":"API","check_type":"http","url":"https://example.com/health","expected_status_code":200,"timeout_seconds":10,"check_interval_seconds":60,"enabled":true}`
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/endpoints", strings.NewReader(body)))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected %v, got %v: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+
+	var created data.Endpoint
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode created endpoint: %v", err)
+	}
+	if created.ID == uuid.Nil || created.Name != "API" {
+		t.Fatalf("Expected the created endpoint with an ID, got %+v", created)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/endpoints/"+created.ID.String(), nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var fetched EndpointResponse
+	if err := json.NewDecoder(rr.Body).Decode(&fetched); err != nil {
+		t.Fatalf("Failed to decode endpoint: %v", err)
+	}
+	if fetched.Endpoint == nil || fetched.ID != created.ID || fetched.URL != "https://example.com/health" {
+		t.Errorf("Expected endpoint %s, got %+v", created.ID, fetched.Endpoint)
+	}
+}
@@ -29,11 +29,17 @@ type AuthResponse struct {
 var store *sessions.CookieStore
 var sessionName string
 
-func initSessionStore() {
+// sessionSecret returns the server secret used to sign sessions and links
+func sessionSecret() string {
 	secretKey := os.Getenv("SESSION_SECRET")
 	if secretKey == "" {
 		secretKey = "your-secret-key-change-this-in-production"
 	}
+	return secretKey
+}
+
+func initSessionStore() {
+	secretKey := sessionSecret()
 
 	sessionName = os.Getenv("SESSION_NAME")
 	if sessionName == "" {
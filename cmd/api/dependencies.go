@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/constants"
+	"github.com/i4o-oss/watchtower/internal/dependency"
+	"github.com/i4o-oss/watchtower/internal/monitoring"
+)
+
+// maxEndpointParents limits how many endpoints a single endpoint can depend on
+const maxEndpointParents = 20
+
+// DependencyNode is an endpoint in the dependency graph
+type DependencyNode struct {
+	ID        uuid.UUID   `json:"id"`
+	Name      string      `json:"name"`
+	Enabled   bool        `json:"enabled"`
+	State     string      `json:"state"`
+	ParentIDs []uuid.UUID `json:"parent_ids"`
+}
+
+// DependencyEdge points from an endpoint to a parent it depends on
+type DependencyEdge struct {
+	ParentID uuid.UUID `json:"parent_id"`
+	ChildID  uuid.UUID `json:"child_id"`
+}
+
+// validateEndpointParents validates the parents an endpoint depends on and returns them
+// without duplicates. New endpoints pass uuid.Nil as their ID
+func (app *Application) validateEndpointParents(id uuid.UUID, parentIDs []uuid.UUID) ([]uuid.UUID, []string) {
+	var errors []string
+
+	parentIDs = uniqueUUIDs(parentIDs)
+	if len(parentIDs) > maxEndpointParents {
+		errors = append(errors, fmt.Sprintf("Endpoints can depend on no more than %d parents", maxEndpointParents))
+	}
+	for _, parentID := range parentIDs {
+		if parentID == id {
+			errors = append(errors, "Endpoints cannot depend on themselves")
+			continue
+		}
+		if _, err := app.db.GetEndpoint(parentID); err != nil {
+			errors = append(errors, fmt.Sprintf("Parent endpoint %s does not exist", parentID))
+		}
+	}
+	if len(errors) > 0 || len(parentIDs) == 0 || id == uuid.Nil {
+		return parentIDs, errors
+	}
+
+	endpoints, err := app.db.GetEndpoints()
+	if err != nil {
+		return parentIDs, []string{"Error checking endpoint dependencies"}
+	}
+
+	graph := dependency.NewGraph(endpoints)
+	graph.SetParents(id, parentIDs)
+	if cycle := graph.Cycle(id); cycle != nil {
+		names := make(map[uuid.UUID]string, len(endpoints))
+		for _, endpoint := range endpoints {
+			names[endpoint.ID] = endpoint.Name
+		}
+
+		chain := make([]string, 0, len(cycle))
+		for _, endpointID := range cycle {
+			chain = append(chain, names[endpointID])
+		}
+		errors = append(errors, fmt.Sprintf("Parents would create a dependency cycle: %s", strings.Join(chain, " -> ")))
+	}
+
+	return parentIDs, errors
+}
+
+// getEndpointDependencies handles GET /api/v1/admin/endpoints/dependencies
+func (app *Application) getEndpointDependencies(w http.ResponseWriter, r *http.Request) {
+	endpoints, err := app.db.GetEndpoints()
+	if err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting endpoints", err)
+		return
+	}
+
+	var states map[uuid.UUID]monitoring.EndpointState
+	if app.monitoringEngine != nil {
+		states = app.monitoringEngine.GetEndpointStates()
+	}
+
+	nodes := make([]DependencyNode, 0, len(endpoints))
+	edges := make([]DependencyEdge, 0)
+	for _, endpoint := range endpoints {
+		state, ok := states[endpoint.ID]
+		if !ok {
+			state = monitoring.EndpointStateUnknown
+		}
+
+		parentIDs := []uuid.UUID(endpoint.ParentIDs)
+		if parentIDs == nil {
+			parentIDs = []uuid.UUID{}
+		}
+
+		nodes = append(nodes, DependencyNode{
+			ID:        endpoint.ID,
+			Name:      endpoint.Name,
+			Enabled:   endpoint.Enabled,
+			State:     string(state),
+			ParentIDs: parentIDs,
+		})
+		for _, parentID := range parentIDs {
+			edges = append(edges, DependencyEdge{ParentID: parentID, ChildID: endpoint.ID})
+		}
+	}
+
+	app.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"nodes": nodes,
+		"edges": edges,
+	})
+}
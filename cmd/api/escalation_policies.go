@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/constants"
+	"github.com/i4o-oss/watchtower/internal/data"
+	"github.com/i4o-oss/watchtower/internal/security"
+	"gorm.io/gorm"
+)
+
+// EscalationPolicyRequest represents the API request for creating/updating escalation policies
+type EscalationPolicyRequest struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Enabled     bool                   `json:"enabled"`
+	Severities  []string               `json:"severities"`
+	Levels      []data.EscalationLevel `json:"levels"`
+}
+
+// validateEscalationPolicyRequest validates and normalizes an escalation policy request
+func (app *Application) validateEscalationPolicyRequest(req *EscalationPolicyRequest) []string {
+	var errors []string
+	sanitizer := security.NewSanitizer()
+
+	nameResult := sanitizer.SanitizeHTML(req.Name, "name")
+	errors = append(errors, nameResult.Errors...)
+	if nameResult.Value == "" {
+		errors = append(errors, "Name is required and cannot be empty")
+	}
+	if len(nameResult.Value) > 100 {
+		errors = append(errors, "Name must be no more than 100 characters")
+	}
+	req.Name = nameResult.Value
+
+	descriptionResult := sanitizer.SanitizeHTML(req.Description, "description")
+	errors = append(errors, descriptionResult.Errors...)
+	if len(descriptionResult.Value) > 500 {
+		errors = append(errors, "Description must be no more than 500 characters")
+	}
+	req.Description = descriptionResult.Value
+
+	severities := make([]string, 0, len(req.Severities))
+	for _, severity := range req.Severities {
+		severity = strings.ToLower(strings.TrimSpace(severity))
+		if !slices.Contains(notificationSeverities, severity) {
+			errors = append(errors, "Severities must be one of: "+strings.Join(notificationSeverities, ", "))
+			continue
+		}
+		if !slices.Contains(severities, severity) {
+			severities = append(severities, severity)
+		}
+	}
+	req.Severities = severities
+
+	if len(req.Levels) == 0 || len(req.Levels) > 10 {
+		errors = append(errors, "Levels must contain between 1 and 10 entries")
+	}
+	for i := range req.Levels {
+		level := &req.Levels[i]
+		if level.DelayMinutes < 0 || level.DelayMinutes > 1440 {
+			errors = append(errors, fmt.Sprintf("Level %d delay must be between 0 and 1440 minutes", i+1))
+		}
+
+		level.ChannelIDs = uniqueUUIDs(level.ChannelIDs)
+		if len(level.ChannelIDs) == 0 {
+			errors = append(errors, fmt.Sprintf("Level %d requires at least one channel", i+1))
+		}
+		for _, channelID := range level.ChannelIDs {
+			if _, err := app.db.GetNotificationChannel(channelID); err != nil {
+				errors = append(errors, fmt.Sprintf("Channel %s does not exist", channelID))
+			}
+		}
+	}
+
+	return errors
+}
+
+// applyEscalationPolicyRequest copies a validated request onto a policy
+func applyEscalationPolicyRequest(policy *data.EscalationPolicy, req *EscalationPolicyRequest) {
+	policy.Name = req.Name
+	policy.Description = req.Description
+	policy.Enabled = req.Enabled
+	policy.Severities = data.StringList(req.Severities)
+	policy.Levels = data.EscalationLevels(req.Levels)
+}
+
+// listEscalationPolicies handles GET /api/v1/admin/escalation-policies
+func (app *Application) listEscalationPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := app.db.GetEscalationPolicies()
+	if err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting escalation policies", err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"policies": policies,
+		"total":    len(policies),
+	})
+}
+
+// getEscalationPolicy handles GET /api/v1/admin/escalation-policies/{id}
+func (app *Application) getEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+
+	policy, err := app.db.GetEscalationPolicy(id)
+	if err != nil {
+		app.escalationPolicyLookupError(w, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, policy)
+}
+
+// createEscalationPolicy handles POST /api/v1/admin/escalation-policies
+func (app *Application) createEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	var req EscalationPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if errors := app.validateEscalationPolicyRequest(&req); len(errors) > 0 {
+		app.respondWithValidationErrors(w, errors)
+		return
+	}
+
+	if app.escalationPolicyNameTaken(w, req.Name, uuid.Nil) {
+		return
+	}
+
+	policy := &data.EscalationPolicy{}
+	applyEscalationPolicyRequest(policy, &req)
+
+	if err := app.db.CreateEscalationPolicy(policy); err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error creating escalation policy", err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusCreated, policy)
+}
+
+// updateEscalationPolicy handles PUT /api/v1/admin/escalation-policies/{id}. Incidents already
+// escalating follow the updated levels from their current level on
+func (app *Application) updateEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+
+	policy, err := app.db.GetEscalationPolicy(id)
+	if err != nil {
+		app.escalationPolicyLookupError(w, err)
+		return
+	}
+
+	var req EscalationPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if errors := app.validateEscalationPolicyRequest(&req); len(errors) > 0 {
+		app.respondWithValidationErrors(w, errors)
+		return
+	}
+
+	if app.escalationPolicyNameTaken(w, req.Name, policy.ID) {
+		return
+	}
+
+	applyEscalationPolicyRequest(policy, &req)
+
+	if err := app.db.UpdateEscalationPolicy(policy); err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error updating escalation policy", err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, policy)
+}
+
+// deleteEscalationPolicy handles DELETE /api/v1/admin/escalation-policies/{id}. Incidents using
+// the policy stop escalating
+func (app *Application) deleteEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+
+	if _, err := app.db.GetEscalationPolicy(id); err != nil {
+		app.escalationPolicyLookupError(w, err)
+		return
+	}
+
+	if err := app.db.DeleteEscalationPolicy(id); err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error deleting escalation policy", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// escalationPolicyLookupError responds to a failed escalation policy lookup
+func (app *Application) escalationPolicyLookupError(w http.ResponseWriter, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		app.errorResponse(w, http.StatusNotFound, "Escalation policy not found")
+		return
+	}
+	app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting escalation policy", err)
+}
+
+// escalationPolicyNameTaken responds with a conflict and returns true if another policy already
+// uses the name
+func (app *Application) escalationPolicyNameTaken(w http.ResponseWriter, name string, id uuid.UUID) bool {
+	existing, err := app.db.GetEscalationPolicyByName(name)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return false
+	case err != nil:
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting escalation policy", err)
+		return true
+	case existing.ID != id:
+		app.errorResponse(w, http.StatusConflict, fmt.Sprintf("An escalation policy named %q already exists", name))
+		return true
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/i4o-oss/watchtower/internal/constants"
+	"github.com/i4o-oss/watchtower/internal/monitoring"
+	"gorm.io/gorm"
+)
+
+// maxHeartbeatBodyBytes limits how much job output is read from a heartbeat ping
+const maxHeartbeatBodyBytes = 10 * 1024
+
+// HeartbeatResponse acknowledges a heartbeat ping
+type HeartbeatResponse struct {
+	Status string `json:"status"`
+	Signal string `json:"signal"`
+}
+
+// generateHeartbeatToken creates the secret token used in a heartbeat endpoint's ping URL
+func generateHeartbeatToken() (string, error) {
+	bytes := make([]byte, 24)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate heartbeat token: %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// heartbeatPath returns the ping path for a heartbeat token
+func heartbeatPath(token string) string {
+	return "/api/v1/heartbeat/" + token
+}
+
+// heartbeatPing handles GET/POST /api/v1/heartbeat/{token} along with the
+// /start, /fail and /{exit_code} variants
+func (app *Application) heartbeatPing(w http.ResponseWriter, r *http.Request) {
+	ping := monitoring.HeartbeatPing{
+		Signal:     monitoring.HeartbeatSuccess,
+		ReceivedAt: time.Now(),
+	}
+
+	switch signal := chi.URLParam(r, "signal"); signal {
+	case "":
+	case "start":
+		ping.Signal = monitoring.HeartbeatStart
+	case "fail":
+		ping.Signal = monitoring.HeartbeatFail
+	default:
+		exitCode, err := strconv.Atoi(signal)
+		if err != nil || exitCode < 0 || exitCode > 255 {
+			app.errorResponse(w, http.StatusBadRequest, "Heartbeat signal must be start, fail or an exit code between 0 and 255")
+			return
+		}
+		ping.ExitCode = &exitCode
+		if exitCode != 0 {
+			ping.Signal = monitoring.HeartbeatFail
+		}
+	}
+
+	endpoint, err := app.db.GetEndpointByHeartbeatToken(chi.URLParam(r, "token"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			app.errorResponse(w, http.StatusNotFound, "Heartbeat not found")
+			return
+		}
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting heartbeat endpoint", err)
+		return
+	}
+
+	// Jobs may post their output along with the ping
+	if r.Method == http.MethodPost {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxHeartbeatBodyBytes))
+		if err != nil {
+			app.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		ping.Body = body
+	}
+
+	if app.monitoringEngine == nil || !app.monitoringEngine.IsRunning() {
+		app.errorResponse(w, http.StatusServiceUnavailable, "Monitoring engine is not running")
+		return
+	}
+
+	if err := app.monitoringEngine.RecordHeartbeat(endpoint.ID, ping); err != nil {
+		if errors.Is(err, monitoring.ErrEndpointNotScheduled) {
+			app.errorResponse(w, http.StatusConflict, "Heartbeat monitor is disabled")
+			return
+		}
+		app.logErrorAndRespond(w, http.StatusServiceUnavailable, "Heartbeat could not be recorded", "Error recording heartbeat", err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, HeartbeatResponse{
+		Status: "ok",
+		Signal: string(ping.Signal),
+	})
+}
@@ -83,6 +83,20 @@ func parseUUIDParam(r *http.Request, paramName string) (uuid.UUID, error) {
 	return uuid.Parse(idStr)
 }
 
+// parseOptionalUUIDQuery extracts a UUID query parameter, returning nil if it is not set
+func parseOptionalUUIDQuery(r *http.Request, paramName string) (*uuid.UUID, error) {
+	value := r.URL.Query().Get(paramName)
+	if value == "" {
+		return nil, nil
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
 // logErrorAndRespond logs an error and sends an HTTP error response
 func (app *Application) logErrorAndRespond(w http.ResponseWriter, statusCode int, message string, logMsg string, err error) {
 	app.logger.Error(logMsg, "err", err.Error())
@@ -14,8 +14,11 @@ import (
 	"github.com/charmbracelet/log"
 	"github.com/i4o-oss/watchtower/internal/cache"
 	"github.com/i4o-oss/watchtower/internal/data"
+	"github.com/i4o-oss/watchtower/internal/maintenance"
 	"github.com/i4o-oss/watchtower/internal/monitoring"
 	"github.com/i4o-oss/watchtower/internal/notification"
+	"github.com/i4o-oss/watchtower/internal/notification/providers"
+	"github.com/i4o-oss/watchtower/internal/oncall"
 	"github.com/i4o-oss/watchtower/internal/security"
 	_ "github.com/joho/godotenv/autoload"
 )
@@ -53,6 +56,11 @@ type Application struct {
 	csrfProtection      *security.CSRFProtection
 	monitoringEngine    *monitoring.MonitoringEngine
 	notificationService *notification.Service
+	notifier            *notification.Dispatcher
+	outbox              *notification.Outbox
+	escalator           *notification.Escalator
+	onCall              *oncall.Resolver
+	maintenance         *maintenance.Checker
 	registrationLocked  bool
 }
 
@@ -89,6 +97,18 @@ func (app *Application) gracefulShutdown(apiServer *http.Server, done chan bool)
 		}
 	}
 
+	// Stop escalating, queue notifications that are still waiting for the dispatcher, then let the outbox finish
+	// the deliveries in flight. Pending deliveries are picked up again on the next start
+	if app.escalator != nil {
+		app.escalator.Stop()
+	}
+	if app.notifier != nil {
+		app.notifier.Stop()
+	}
+	if app.outbox != nil {
+		app.outbox.Stop()
+	}
+
 	// The context is used to inform the server it has 5 seconds to finish
 	// the request it is currently handling
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -257,8 +277,48 @@ func main() {
 	monitoringConfig := monitoring.DefaultEngineConfig()
 	monitoringEngine := monitoring.NewMonitoringEngine(monitoringConfig, rawDB, logger)
 
-	// Initialize notification service
+	// Failures during maintenance windows open no incidents and are left out of uptime
+	maintenanceChecker := maintenance.NewChecker(rawDB)
+	if err := maintenanceChecker.Reload(); err != nil {
+		logger.Error("failed to load maintenance windows", "err", err.Error())
+	}
+	monitoringEngine.UseMaintenance(maintenanceChecker)
+
+	// Initialize notification service, channels of these provider types are loaded from the database
 	notificationService := notification.NewService(nil) // Use default slog logger
+	for providerType, factory := range map[notification.ProviderType]notification.ProviderFactory{
+		notification.ProviderTypeEmail:     func() notification.NotificationProvider { return providers.NewEmailProvider(nil) },
+		notification.ProviderTypeSlack:     func() notification.NotificationProvider { return providers.NewSlackProvider(nil) },
+		notification.ProviderTypeDiscord:   func() notification.NotificationProvider { return providers.NewDiscordProvider(nil) },
+		notification.ProviderTypeWebhook:   func() notification.NotificationProvider { return providers.NewWebhookProvider(nil) },
+		notification.ProviderTypePagerDuty: func() notification.NotificationProvider { return providers.NewPagerDutyProvider(nil) },
+		notification.ProviderTypeTeams:     func() notification.NotificationProvider { return providers.NewTeamsProvider(nil) },
+		notification.ProviderTypeTelegram:  func() notification.NotificationProvider { return providers.NewTelegramProvider(nil) },
+		notification.ProviderTypeNtfy:      func() notification.NotificationProvider { return providers.NewNtfyProvider(nil) },
+		notification.ProviderTypeGotify:    func() notification.NotificationProvider { return providers.NewGotifyProvider(nil) },
+	} {
+		if err := notificationService.RegisterProviderFactory(providerType, factory); err != nil {
+			logger.Error("failed to register notification provider", "err", err.Error())
+		}
+	}
+
+	// Routes targeting an on-call schedule email whoever is on call
+	onCallResolver := oncall.NewResolver(db)
+	notificationService.SetOnCallResolver(onCallResolver.Email)
+
+	// Channel notifications are persisted in the outbox and delivered with retries
+	outbox := notification.NewOutbox(notificationService, rawDB, notification.DefaultOutboxConfig(), nil)
+	// Duplicates, notifications over a channel's rate limit and flapping endpoints are suppressed
+	outbox.UseThrottle(notification.NewThrottle(notification.DefaultThrottleConfig()))
+	notificationTrigger := notification.NewNotificationTrigger(notificationService, nil)
+	notificationTrigger.UseOutbox(outbox)
+
+	// Notifications are triggered in the background so callers never wait on the database or providers
+	notifier := notification.NewDispatcher(notificationTrigger, notification.DefaultDispatcherConfig(), nil)
+	notifier.Start()
+
+	// Unacknowledged incidents are escalated through their escalation policy's channels
+	escalator := notification.NewEscalator(rawDB, outbox, notification.DefaultEscalatorConfig(), nil)
 
 	app := &Application{
 		config:              config,
@@ -270,12 +330,32 @@ func main() {
 		csrfProtection:      csrfProtection,
 		monitoringEngine:    monitoringEngine,
 		notificationService: notificationService,
+		notifier:            notifier,
+		outbox:              outbox,
+		escalator:           escalator,
+		onCall:              onCallResolver,
+		maintenance:         maintenanceChecker,
 		registrationLocked:  registrationLocked,
 	}
 
+	// Load notification channels and routes before anything can trigger a notification
+	if err := app.reloadNotificationChannels(); err != nil {
+		logger.Error("failed to load notification channels", "err", err.Error())
+	}
+	if err := app.reloadNotificationRoutes(); err != nil {
+		logger.Error("failed to load notification routes", "err", err.Error())
+	}
+	outbox.Start()
+	escalator.UseAckLinks(app.incidentAckURL)
+	escalator.Start()
+
 	// Set monitoring result callback to broadcast via SSE
 	monitoringEngine.SetResultCallback(app.BroadcastMonitoringResult)
 
+	// Notify on endpoint state changes and incidents opened by the monitoring engine
+	monitoringEngine.OnStateTransition(app.notifyStateTransition)
+	monitoringEngine.OnIncident(app.notifyDetectedIncident)
+
 	// Start monitoring engine
 	if err := app.monitoringEngine.Start(); err != nil {
 		logger.Error("failed to start monitoring engine", "err", err.Error())
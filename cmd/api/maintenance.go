@@ -0,0 +1,260 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/constants"
+	"github.com/i4o-oss/watchtower/internal/data"
+	"github.com/i4o-oss/watchtower/internal/maintenance"
+	"github.com/i4o-oss/watchtower/internal/security"
+	"gorm.io/gorm"
+)
+
+// maintenanceRecurrences are the supported maintenance window recurrences
+var maintenanceRecurrences = []string{
+	data.MaintenanceRecurrenceNone,
+	data.MaintenanceRecurrenceDaily,
+	data.MaintenanceRecurrenceWeekly,
+	data.MaintenanceRecurrenceMonthly,
+}
+
+// MaintenanceWindowRequest represents the API request for creating/updating maintenance windows
+type MaintenanceWindowRequest struct {
+	Title           string      `json:"title"`
+	Description     string      `json:"description"`
+	StartTime       time.Time   `json:"start_time"`
+	EndTime         time.Time   `json:"end_time"`
+	Timezone        string      `json:"timezone"`
+	Recurrence      string      `json:"recurrence"`
+	RecurrenceUntil *time.Time  `json:"recurrence_until"`
+	EndpointIDs     []uuid.UUID `json:"endpoint_ids"` // every endpoint when empty
+}
+
+// MaintenanceWindowResponse is a maintenance window with its current or next occurrence
+type MaintenanceWindowResponse struct {
+	data.MaintenanceWindow
+	Active         bool                `json:"active"`
+	NextOccurrence *maintenance.Period `json:"next_occurrence,omitempty"`
+}
+
+// validateMaintenanceWindowRequest validates and normalizes a maintenance window request
+func (app *Application) validateMaintenanceWindowRequest(req *MaintenanceWindowRequest) []string {
+	var errors []string
+	sanitizer := security.NewSanitizer()
+
+	titleResult := sanitizer.SanitizeHTML(req.Title, "title")
+	errors = append(errors, titleResult.Errors...)
+	if titleResult.Value == "" {
+		errors = append(errors, "Title is required and cannot be empty")
+	}
+	if len(titleResult.Value) > 255 {
+		errors = append(errors, "Title must be no more than 255 characters")
+	}
+	req.Title = titleResult.Value
+
+	descriptionResult := sanitizer.SanitizeHTML(req.Description, "description")
+	errors = append(errors, descriptionResult.Errors...)
+	if len(descriptionResult.Value) > 1000 {
+		errors = append(errors, "Description must be no more than 1000 characters")
+	}
+	req.Description = descriptionResult.Value
+
+	req.Timezone = strings.TrimSpace(req.Timezone)
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+
+	req.Recurrence = strings.ToLower(strings.TrimSpace(req.Recurrence))
+	if !slices.Contains(maintenanceRecurrences, req.Recurrence) {
+		errors = append(errors, "Recurrence must be one of: daily, weekly, monthly, or empty for a one-off window")
+	}
+
+	if req.StartTime.IsZero() || req.EndTime.IsZero() {
+		errors = append(errors, "Start time and end time are required")
+	}
+
+	req.EndpointIDs = uniqueUUIDs(req.EndpointIDs)
+	for _, endpointID := range req.EndpointIDs {
+		if _, err := app.db.GetEndpoint(endpointID); err != nil {
+			errors = append(errors, fmt.Sprintf("Endpoint %s does not exist", endpointID))
+		}
+	}
+
+	if len(errors) == 0 {
+		window := &data.MaintenanceWindow{}
+		applyMaintenanceWindowRequest(window, req)
+		if err := maintenance.Validate(window); err != nil {
+			errors = append(errors, fmt.Sprintf("invalid maintenance window: %s", err.Error()))
+		}
+	}
+
+	return errors
+}
+
+// applyMaintenanceWindowRequest copies a validated request onto a window
+func applyMaintenanceWindowRequest(window *data.MaintenanceWindow, req *MaintenanceWindowRequest) {
+	window.Title = req.Title
+	window.Description = req.Description
+	window.StartTime = req.StartTime
+	window.EndTime = req.EndTime
+	window.Timezone = req.Timezone
+	window.Recurrence = req.Recurrence
+	window.RecurrenceUntil = req.RecurrenceUntil
+	window.EndpointIDs = data.UUIDList(req.EndpointIDs)
+}
+
+// maintenanceWindowResponse adds whether the window is in effect now and its next occurrence
+func maintenanceWindowResponse(window data.MaintenanceWindow, now time.Time) MaintenanceWindowResponse {
+	response := MaintenanceWindowResponse{MaintenanceWindow: window}
+
+	// Monthly windows recur at most every 31 days
+	periods, err := maintenance.Occurrences(&window, now, now.AddDate(0, 0, 32))
+	if err == nil && len(periods) > 0 {
+		response.Active = periods[0].Contains(now)
+		response.NextOccurrence = &periods[0]
+	}
+	return response
+}
+
+// reloadMaintenanceWindows refreshes the windows monitoring consults after they changed
+func (app *Application) reloadMaintenanceWindows() {
+	if err := app.maintenance.Reload(); err != nil {
+		app.logger.Error("Error reloading maintenance windows", "err", err.Error())
+	}
+}
+
+// listMaintenanceWindows handles GET /api/v1/admin/maintenance-windows
+func (app *Application) listMaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	windows, err := app.db.GetMaintenanceWindows()
+	if err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting maintenance windows", err)
+		return
+	}
+
+	now := time.Now()
+	responses := make([]MaintenanceWindowResponse, 0, len(windows))
+	for _, window := range windows {
+		responses = append(responses, maintenanceWindowResponse(window, now))
+	}
+
+	app.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"maintenance_windows": responses,
+		"total":               len(responses),
+	})
+}
+
+// getMaintenanceWindow handles GET /api/v1/admin/maintenance-windows/{id}
+func (app *Application) getMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+
+	window, err := app.db.GetMaintenanceWindow(id)
+	if err != nil {
+		app.maintenanceWindowLookupError(w, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, maintenanceWindowResponse(*window, time.Now()))
+}
+
+// createMaintenanceWindow handles POST /api/v1/admin/maintenance-windows
+func (app *Application) createMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	var req MaintenanceWindowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if errors := app.validateMaintenanceWindowRequest(&req); len(errors) > 0 {
+		app.respondWithValidationErrors(w, errors)
+		return
+	}
+
+	window := &data.MaintenanceWindow{}
+	applyMaintenanceWindowRequest(window, &req)
+
+	if err := app.db.CreateMaintenanceWindow(window); err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error creating maintenance window", err)
+		return
+	}
+	app.reloadMaintenanceWindows()
+
+	app.writeJSON(w, http.StatusCreated, maintenanceWindowResponse(*window, time.Now()))
+}
+
+// updateMaintenanceWindow handles PUT /api/v1/admin/maintenance-windows/{id}
+func (app *Application) updateMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+
+	window, err := app.db.GetMaintenanceWindow(id)
+	if err != nil {
+		app.maintenanceWindowLookupError(w, err)
+		return
+	}
+
+	var req MaintenanceWindowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if errors := app.validateMaintenanceWindowRequest(&req); len(errors) > 0 {
+		app.respondWithValidationErrors(w, errors)
+		return
+	}
+
+	applyMaintenanceWindowRequest(window, &req)
+
+	if err := app.db.UpdateMaintenanceWindow(window); err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error updating maintenance window", err)
+		return
+	}
+	app.reloadMaintenanceWindows()
+
+	app.writeJSON(w, http.StatusOK, maintenanceWindowResponse(*window, time.Now()))
+}
+
+// deleteMaintenanceWindow handles DELETE /api/v1/admin/maintenance-windows/{id}
+func (app *Application) deleteMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+
+	if _, err := app.db.GetMaintenanceWindow(id); err != nil {
+		app.maintenanceWindowLookupError(w, err)
+		return
+	}
+
+	if err := app.db.DeleteMaintenanceWindow(id); err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error deleting maintenance window", err)
+		return
+	}
+	app.reloadMaintenanceWindows()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// maintenanceWindowLookupError responds to a failed maintenance window lookup
+func (app *Application) maintenanceWindowLookupError(w http.ResponseWriter, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		app.errorResponse(w, http.StatusNotFound, "Maintenance window not found")
+		return
+	}
+	app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting maintenance window", err)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/i4o-oss/watchtower/internal/data"
+	"github.com/i4o-oss/watchtower/internal/monitoring"
+	"github.com/i4o-oss/watchtower/internal/notification"
+)
+
+// baseURL returns the configured domain as an absolute URL without a trailing slash, or an
+// empty string when no domain is set
+func (app *Application) baseURL() string {
+	settings, err := app.db.GetSettings()
+	if err != nil || settings.Domain == "" {
+		return ""
+	}
+
+	base := strings.TrimRight(settings.Domain, "/")
+	if !strings.Contains(base, "://") {
+		base = "https://" + base
+	}
+	return base
+}
+
+// deepLink returns the absolute URL of a page in the admin UI, or an empty string when no
+// domain is configured since a relative link is useless outside the app
+func (app *Application) deepLink(path string) string {
+	base := app.baseURL()
+	if base == "" {
+		return ""
+	}
+	return base + path
+}
+
+// notifyStateTransition sends endpoint down and recovery notifications for monitoring state
+// changes. Failures of an endpoint attributed to a parent it depends on are not notified since
+// the parent's incident already covers them. Maintenance needs no check here, failures during
+// a window never take an endpoint down while recoveries during one are still notified. The
+// link is built on a dispatcher worker since it needs the settings from the database
+func (app *Application) notifyStateTransition(transition monitoring.StateTransition) {
+	if transition.ParentID != nil {
+		return
+	}
+
+	endpoint := notification.EndpointInfo{ID: transition.EndpointID, Name: transition.EndpointName}
+	if job := transition.Result.Job.Endpoint; job != nil {
+		endpoint = notificationEndpoints([]data.Endpoint{*job})[0]
+	}
+
+	result := transition.Result
+	event := notification.EndpointEvent{
+		Endpoint: endpoint,
+		Check: notification.CheckInfo{
+			StatusCode:     result.StatusCode,
+			ResponseTimeMs: result.ResponseTimeMs,
+		},
+		IncidentID: transition.IncidentID,
+	}
+	if result.ErrorMessage != nil {
+		event.Check.Error = *result.ErrorMessage
+	}
+	link := fmt.Sprintf("/admin/endpoints/%s", endpoint.ID)
+
+	switch {
+	case transition.To == monitoring.EndpointStateDown:
+		app.notifier.Run("endpoint_down", func(ctx context.Context, trigger *notification.NotificationTrigger) error {
+			event.Link = app.deepLink(link)
+			return trigger.TriggerEndpointDown(ctx, event)
+		})
+
+	case transition.From == monitoring.EndpointStateDown:
+		downDuration := transition.At.Sub(transition.FromSince)
+		app.notifier.Run("endpoint_up", func(ctx context.Context, trigger *notification.NotificationTrigger) error {
+			event.Link = app.deepLink(link)
+			return trigger.TriggerEndpointUp(ctx, event, downDuration)
+		})
+	}
+}
+
+// notifyDetectedIncident sends notifications for incidents opened, resolved and merged by the
+// incident detector. Merged incidents get an update rather than a resolution since their
+// endpoint is still down. Looking up the incident's endpoints and escalation policy needs the
+// database, so it happens on a dispatcher worker instead of the detector's goroutine
+func (app *Application) notifyDetectedIncident(event monitoring.IncidentEvent) {
+	incident := event.Incident
+
+	switch event.Type {
+	case monitoring.IncidentEventCreated:
+		app.notifier.Run("incident_created", func(ctx context.Context, trigger *notification.NotificationTrigger) error {
+			app.assignEscalationPolicy(&incident)
+			return trigger.TriggerIncidentCreated(ctx, app.notificationIncident(&incident), incident.Description)
+		})
+	case monitoring.IncidentEventResolved:
+		app.notifier.Run("incident_resolved", func(ctx context.Context, trigger *notification.NotificationTrigger) error {
+			return trigger.TriggerIncidentResolved(ctx, app.notificationIncident(&incident), "Automatically resolved by monitoring system", incidentDuration(&incident))
+		})
+	case monitoring.IncidentEventMerged:
+		message := fmt.Sprintf("Merged into %s's incident, %s depends on it and is still down", event.ParentName, event.EndpointName)
+		app.notifier.Run("incident_updated", func(ctx context.Context, trigger *notification.NotificationTrigger) error {
+			return trigger.TriggerIncidentUpdated(ctx, app.notificationIncident(&incident), message)
+		})
+	}
+}
+
+// notifyIncidentCreated sends notifications for a new incident and starts escalating it if an
+// escalation policy applies
+func (app *Application) notifyIncidentCreated(incident *data.Incident) {
+	app.notifier.IncidentCreated(app.notificationIncident(incident), incident.Description)
+	app.assignEscalationPolicy(incident)
+}
+
+// assignEscalationPolicy starts escalating an incident if an escalation policy applies
+func (app *Application) assignEscalationPolicy(incident *data.Incident) {
+	if app.escalator == nil {
+		return
+	}
+	if _, err := app.escalator.Assign(incident); err != nil {
+		app.logger.Error("Error assigning escalation policy", "err", err.Error())
+	}
+}
+
+// notifyIncidentUpdated sends notifications for an incident changed by an admin, resolving
+// incidents get a resolution notification instead
+func (app *Application) notifyIncidentUpdated(incident *data.Incident, previousStatus string) {
+	if incident.Status == "resolved" && previousStatus != "resolved" {
+		app.notifyIncidentResolved(incident, "Resolved by an administrator")
+		return
+	}
+
+	message := "Incident details updated"
+	if incident.Status != previousStatus {
+		message = fmt.Sprintf("Status changed from %s to %s", previousStatus, incident.Status)
+	}
+	app.notifier.IncidentUpdated(app.notificationIncident(incident), message)
+}
+
+// notifyIncidentResolved sends notifications for a resolved incident
+func (app *Application) notifyIncidentResolved(incident *data.Incident, resolutionMessage string) {
+	app.notifier.IncidentResolved(app.notificationIncident(incident), resolutionMessage, incidentDuration(incident))
+}
+
+// incidentDuration returns how long an incident lasted, up to now for incidents still open
+func incidentDuration(incident *data.Incident) time.Duration {
+	endTime := time.Now()
+	if incident.EndTime != nil {
+		endTime = *incident.EndTime
+	}
+	return endTime.Sub(incident.StartTime).Round(time.Second)
+}
+
+// notificationEndpoints describes endpoints for notification routing
+func notificationEndpoints(endpoints []data.Endpoint) []notification.EndpointInfo {
+	infos := make([]notification.EndpointInfo, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		infos = append(infos, notification.EndpointInfo{
+			ID:   endpoint.ID,
+			Name: endpoint.Name,
+			URL:  endpoint.URL,
+			Tags: endpoint.Tags,
+		})
+	}
+	return infos
+}
+
+// notificationIncident describes an incident for notifications
+func (app *Application) notificationIncident(incident *data.Incident) notification.IncidentInfo {
+	return notification.IncidentInfo{
+		ID:        incident.ID,
+		Title:     incident.Title,
+		Severity:  incident.Severity,
+		Status:    incident.Status,
+		Endpoints: app.incidentNotificationEndpoints(incident),
+		Link:      app.deepLink(fmt.Sprintf("/admin/incidents/%s", incident.ID)),
+	}
+}
+
+// incidentNotificationEndpoints describes the endpoints affected by an incident for notification routing
+func (app *Application) incidentNotificationEndpoints(incident *data.Incident) []notification.EndpointInfo {
+	endpointIncidents, err := app.db.GetEndpointIncidents(incident.ID)
+	if err != nil {
+		app.logger.Error("Error getting incident endpoints", "err", err.Error())
+		return nil
+	}
+
+	endpoints := make([]data.Endpoint, 0, len(endpointIncidents))
+	for _, endpointIncident := range endpointIncidents {
+		if endpointIncident.Endpoint != nil {
+			endpoints = append(endpoints, *endpointIncident.Endpoint)
+		}
+	}
+	return notificationEndpoints(endpoints)
+}
@@ -0,0 +1,413 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/constants"
+	"github.com/i4o-oss/watchtower/internal/data"
+	"github.com/i4o-oss/watchtower/internal/notification"
+	"github.com/i4o-oss/watchtower/internal/security"
+	"gorm.io/gorm"
+)
+
+// notificationEventTypes are the event types routing rules can match
+var notificationEventTypes = []string{
+	string(notification.NotificationTypeEndpointDown),
+	string(notification.NotificationTypeEndpointUp),
+	string(notification.NotificationTypeIncidentCreated),
+	string(notification.NotificationTypeIncidentUpdated),
+	string(notification.NotificationTypeIncidentResolved),
+}
+
+// notificationSeverities are the severities routing rules can match
+var notificationSeverities = []string{"info", "low", "medium", "high", "critical"}
+
+// NotificationRouteRequest represents the API request for creating/updating notification routes
+type NotificationRouteRequest struct {
+	Name         string      `json:"name"`
+	Enabled      bool        `json:"enabled"`
+	EventTypes   []string    `json:"event_types"`
+	Severities   []string    `json:"severities"`
+	EndpointIDs  []uuid.UUID `json:"endpoint_ids"`
+	EndpointTags []string    `json:"endpoint_tags"`
+	ChannelIDs   []uuid.UUID `json:"channel_ids"`
+	// OnCallScheduleIDs send the route's email to whoever is on call instead of the channel's recipients
+	OnCallScheduleIDs []uuid.UUID `json:"oncall_schedule_ids"`
+}
+
+// RouteDryRunRequest describes a hypothetical event to route
+type RouteDryRunRequest struct {
+	Type        string      `json:"type"`
+	Severity    string      `json:"severity"`
+	EndpointIDs []uuid.UUID `json:"endpoint_ids"`
+}
+
+// RouteDryRunChannel is a channel that would receive the event and the routes selecting it
+type RouteDryRunChannel struct {
+	ID     uuid.UUID      `json:"id"`
+	Name   string         `json:"name"`
+	Type   string         `json:"type"`
+	Routes []RouteSummary `json:"routes"`
+	// Unrouted channels are not referred to by any route and receive every event
+	Unrouted bool `json:"unrouted"`
+	// Recipients are the on-call users an email channel would be sent to
+	Recipients []string `json:"recipients,omitempty"`
+}
+
+// RouteSummary identifies a notification route
+type RouteSummary struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// routingRule converts a stored route to a routing rule
+func routingRule(route *data.NotificationRoute) notification.RoutingRule {
+	eventTypes := make([]notification.NotificationType, 0, len(route.EventTypes))
+	for _, eventType := range route.EventTypes {
+		eventTypes = append(eventTypes, notification.NotificationType(eventType))
+	}
+
+	return notification.RoutingRule{
+		ID:                route.ID,
+		Name:              route.Name,
+		EventTypes:        eventTypes,
+		Severities:        route.Severities,
+		EndpointIDs:       route.EndpointIDs,
+		EndpointTags:      route.EndpointTags,
+		ChannelIDs:        route.ChannelIDs,
+		OnCallScheduleIDs: route.OnCallScheduleIDs,
+	}
+}
+
+// reloadNotificationRoutes replaces the notification service's routing rules with the enabled
+// routes from the database
+func (app *Application) reloadNotificationRoutes() error {
+	routes, err := app.db.GetNotificationRoutes()
+	if err != nil {
+		return err
+	}
+
+	rules := make([]notification.RoutingRule, 0, len(routes))
+	for i := range routes {
+		if routes[i].Enabled {
+			rules = append(rules, routingRule(&routes[i]))
+		}
+	}
+
+	app.notificationService.SetRoutingRules(rules)
+	return nil
+}
+
+// reloadAfterRouteChange reloads routes after a change, logging rather than failing the request
+func (app *Application) reloadAfterRouteChange() {
+	if err := app.reloadNotificationRoutes(); err != nil {
+		app.logger.Error("Error reloading notification routes", "err", err.Error())
+	}
+}
+
+// validateNotificationRouteRequest validates and normalizes a route request
+func (app *Application) validateNotificationRouteRequest(req *NotificationRouteRequest) []string {
+	var errors []string
+	sanitizer := security.NewSanitizer()
+
+	nameResult := sanitizer.SanitizeHTML(req.Name, "name")
+	errors = append(errors, nameResult.Errors...)
+	if nameResult.Value == "" {
+		errors = append(errors, "Name is required and cannot be empty")
+	}
+	if len(nameResult.Value) > 100 {
+		errors = append(errors, "Name must be no more than 100 characters")
+	}
+	req.Name = nameResult.Value
+
+	eventTypes := make([]string, 0, len(req.EventTypes))
+	for _, eventType := range req.EventTypes {
+		eventType = strings.ToLower(strings.TrimSpace(eventType))
+		if !slices.Contains(notificationEventTypes, eventType) {
+			errors = append(errors, "Event types must be one of: "+strings.Join(notificationEventTypes, ", "))
+			continue
+		}
+		if !slices.Contains(eventTypes, eventType) {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	req.EventTypes = eventTypes
+
+	severities := make([]string, 0, len(req.Severities))
+	for _, severity := range req.Severities {
+		severity = strings.ToLower(strings.TrimSpace(severity))
+		if !slices.Contains(notificationSeverities, severity) {
+			errors = append(errors, "Severities must be one of: "+strings.Join(notificationSeverities, ", "))
+			continue
+		}
+		if !slices.Contains(severities, severity) {
+			severities = append(severities, severity)
+		}
+	}
+	req.Severities = severities
+
+	tags, tagErrors := sanitizeTags(req.EndpointTags, sanitizer)
+	errors = append(errors, tagErrors...)
+	req.EndpointTags = tags
+
+	if len(req.EndpointIDs) > 100 {
+		errors = append(errors, "Endpoint IDs must contain no more than 100 entries")
+	}
+	req.EndpointIDs = uniqueUUIDs(req.EndpointIDs)
+	if endpoints, err := app.db.GetEndpointsByIDs(req.EndpointIDs); err != nil {
+		errors = append(errors, "Error checking endpoints")
+	} else if len(endpoints) != len(req.EndpointIDs) {
+		errors = append(errors, "Endpoint IDs must refer to existing endpoints")
+	}
+
+	req.ChannelIDs = uniqueUUIDs(req.ChannelIDs)
+	if len(req.ChannelIDs) == 0 {
+		errors = append(errors, "At least one channel is required")
+	}
+	for _, channelID := range req.ChannelIDs {
+		if _, err := app.db.GetNotificationChannel(channelID); err != nil {
+			errors = append(errors, fmt.Sprintf("Channel %s does not exist", channelID))
+		}
+	}
+
+	req.OnCallScheduleIDs = uniqueUUIDs(req.OnCallScheduleIDs)
+	for _, scheduleID := range req.OnCallScheduleIDs {
+		if _, err := app.db.GetOnCallSchedule(scheduleID); err != nil {
+			errors = append(errors, fmt.Sprintf("On-call schedule %s does not exist", scheduleID))
+		}
+	}
+
+	return errors
+}
+
+// uniqueUUIDs returns the IDs without duplicates, keeping their order
+func uniqueUUIDs(ids []uuid.UUID) []uuid.UUID {
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !slices.Contains(unique, id) {
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// applyNotificationRouteRequest copies a validated request onto a route
+func applyNotificationRouteRequest(route *data.NotificationRoute, req *NotificationRouteRequest) {
+	route.Name = req.Name
+	route.Enabled = req.Enabled
+	route.EventTypes = data.StringList(req.EventTypes)
+	route.Severities = data.StringList(req.Severities)
+	route.EndpointIDs = data.UUIDList(req.EndpointIDs)
+	route.EndpointTags = data.StringList(req.EndpointTags)
+	route.ChannelIDs = data.UUIDList(req.ChannelIDs)
+	route.OnCallScheduleIDs = data.UUIDList(req.OnCallScheduleIDs)
+}
+
+// listNotificationRoutes handles GET /api/v1/admin/notifications/routes
+func (app *Application) listNotificationRoutes(w http.ResponseWriter, r *http.Request) {
+	routes, err := app.db.GetNotificationRoutes()
+	if err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting notification routes", err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"routes": routes,
+		"total":  len(routes),
+	})
+}
+
+// getNotificationRoute handles GET /api/v1/admin/notifications/routes/{id}
+func (app *Application) getNotificationRoute(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+
+	route, err := app.db.GetNotificationRoute(id)
+	if err != nil {
+		app.notificationRouteLookupError(w, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, route)
+}
+
+// createNotificationRoute handles POST /api/v1/admin/notifications/routes
+func (app *Application) createNotificationRoute(w http.ResponseWriter, r *http.Request) {
+	var req NotificationRouteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if errors := app.validateNotificationRouteRequest(&req); len(errors) > 0 {
+		app.respondWithValidationErrors(w, errors)
+		return
+	}
+
+	if app.notificationRouteNameTaken(w, req.Name, uuid.Nil) {
+		return
+	}
+
+	route := &data.NotificationRoute{}
+	applyNotificationRouteRequest(route, &req)
+
+	if err := app.db.CreateNotificationRoute(route); err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error creating notification route", err)
+		return
+	}
+
+	app.reloadAfterRouteChange()
+
+	app.writeJSON(w, http.StatusCreated, route)
+}
+
+// updateNotificationRoute handles PUT /api/v1/admin/notifications/routes/{id}
+func (app *Application) updateNotificationRoute(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+
+	route, err := app.db.GetNotificationRoute(id)
+	if err != nil {
+		app.notificationRouteLookupError(w, err)
+		return
+	}
+
+	var req NotificationRouteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if errors := app.validateNotificationRouteRequest(&req); len(errors) > 0 {
+		app.respondWithValidationErrors(w, errors)
+		return
+	}
+
+	if app.notificationRouteNameTaken(w, req.Name, route.ID) {
+		return
+	}
+
+	applyNotificationRouteRequest(route, &req)
+
+	if err := app.db.UpdateNotificationRoute(route); err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error updating notification route", err)
+		return
+	}
+
+	app.reloadAfterRouteChange()
+
+	app.writeJSON(w, http.StatusOK, route)
+}
+
+// deleteNotificationRoute handles DELETE /api/v1/admin/notifications/routes/{id}
+func (app *Application) deleteNotificationRoute(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+
+	if _, err := app.db.GetNotificationRoute(id); err != nil {
+		app.notificationRouteLookupError(w, err)
+		return
+	}
+
+	if err := app.db.DeleteNotificationRoute(id); err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error deleting notification route", err)
+		return
+	}
+
+	app.reloadAfterRouteChange()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// dryRunNotificationRoutes handles POST /api/v1/admin/notifications/routes/dry-run, reporting
+// which channels would receive an event without sending anything
+func (app *Application) dryRunNotificationRoutes(w http.ResponseWriter, r *http.Request) {
+	var req RouteDryRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	req.Type = strings.ToLower(strings.TrimSpace(req.Type))
+	if !slices.Contains(notificationEventTypes, req.Type) {
+		app.errorResponse(w, http.StatusBadRequest, "Type must be one of: "+strings.Join(notificationEventTypes, ", "))
+		return
+	}
+
+	endpoints, err := app.db.GetEndpointsByIDs(uniqueUUIDs(req.EndpointIDs))
+	if err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting endpoints", err)
+		return
+	}
+
+	now := time.Now()
+	routes := app.notificationService.Route(notification.NotificationData{
+		Type:      notification.NotificationType(req.Type),
+		Severity:  strings.ToLower(strings.TrimSpace(req.Severity)),
+		Timestamp: now,
+		Endpoints: notificationEndpoints(endpoints),
+	})
+
+	channels := make([]RouteDryRunChannel, 0, len(routes))
+	for _, route := range routes {
+		channel := RouteDryRunChannel{
+			ID:         route.Channel.ID,
+			Name:       route.Channel.Name,
+			Type:       string(route.Channel.Type()),
+			Routes:     make([]RouteSummary, 0, len(route.Rules)),
+			Unrouted:   len(route.Rules) == 0,
+			Recipients: app.notificationService.Recipients(route, now),
+		}
+		for _, rule := range route.Rules {
+			channel.Routes = append(channel.Routes, RouteSummary{ID: rule.ID, Name: rule.Name})
+		}
+		channels = append(channels, channel)
+	}
+
+	app.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"channels": channels,
+		"total":    len(channels),
+	})
+}
+
+// notificationRouteLookupError responds to a failed route lookup
+func (app *Application) notificationRouteLookupError(w http.ResponseWriter, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		app.errorResponse(w, http.StatusNotFound, "Notification route not found")
+		return
+	}
+	app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting notification route", err)
+}
+
+// notificationRouteNameTaken responds with a conflict and returns true if another route already
+// uses the name
+func (app *Application) notificationRouteNameTaken(w http.ResponseWriter, name string, id uuid.UUID) bool {
+	existing, err := app.db.GetNotificationRouteByName(name)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return false
+	case err != nil:
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting notification route", err)
+		return true
+	case existing.ID != id:
+		app.errorResponse(w, http.StatusConflict, fmt.Sprintf("A route named %q already exists", name))
+		return true
+	}
+	return false
+}
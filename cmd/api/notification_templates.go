@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/i4o-oss/watchtower/internal/data"
+	"github.com/i4o-oss/watchtower/internal/notification"
+)
+
+// templateEventTypes are the event types channels can have message templates for
+var templateEventTypes = append(slices.Clone(notificationEventTypes),
+	string(notification.NotificationTypeIncidentEscalated),
+	string(notification.NotificationTypeEndpointFlapping),
+	string(notification.NotificationTypeEndpointStabilized),
+	string(notification.NotificationTypeDigest))
+
+// TemplatePreviewRequest represents the API request for previewing a message template
+type TemplatePreviewRequest struct {
+	EventType string `json:"event_type"`
+	// Type is the channel's provider type, used to check whether an html template applies
+	Type     string               `json:"type,omitempty"`
+	Template data.MessageTemplate `json:"template"`
+}
+
+// getNotificationTemplateReference handles GET /api/v1/admin/notifications/templates. It
+// documents the event types, variables and functions message templates can use
+func (app *Application) getNotificationTemplateReference(w http.ResponseWriter, r *http.Request) {
+	app.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"event_types": templateEventTypes,
+		"variables":   notification.TemplateVariables,
+		"functions":   notification.TemplateFunctions,
+	})
+}
+
+// previewNotificationTemplate handles POST /api/v1/admin/notifications/templates/preview. The
+// template is rendered against sample data of the event type, which is returned alongside so
+// clients can show what each variable holds
+func (app *Application) previewNotificationTemplate(w http.ResponseWriter, r *http.Request) {
+	var req TemplatePreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	req.EventType = strings.ToLower(strings.TrimSpace(req.EventType))
+	providerType := notification.ProviderType(req.Type)
+	if req.Type == "" {
+		// Without a channel type every part of the template is previewed
+		providerType = notification.ProviderTypeEmail
+	}
+
+	templates := data.MessageTemplates{req.EventType: req.Template}
+	if errors := validateChannelTemplates(providerType, templates); len(errors) > 0 {
+		app.respondWithValidationErrors(w, errors)
+		return
+	}
+
+	eventType := notification.NotificationType(req.EventType)
+	rendered, err := notification.PreviewTemplate(eventType, notification.MessageTemplate{
+		Title: req.Template.Title,
+		Body:  req.Template.Body,
+		HTML:  req.Template.HTML,
+	})
+	if err != nil {
+		app.respondWithValidationErrors(w, []string{err.Error()})
+		return
+	}
+
+	sample, _ := notification.SampleNotification(eventType)
+	app.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"event_type": req.EventType,
+		"rendered":   rendered,
+		"data":       notification.NewTemplateData(sample),
+	})
+}
@@ -2,22 +2,47 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"strconv"
+	"slices"
+	"strings"
 	"time"
 
-	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/constants"
+	"github.com/i4o-oss/watchtower/internal/data"
 	"github.com/i4o-oss/watchtower/internal/notification"
+	"github.com/i4o-oss/watchtower/internal/notification/providers"
+	"github.com/i4o-oss/watchtower/internal/security"
+	"gorm.io/gorm"
 )
 
+// maskedSettingValue replaces secret channel settings in responses. Sending it back on
+// update keeps the stored value
+const maskedSettingValue = "********"
+
+// secretSettingKeys are channel settings that are never returned by the API. A chat or
+// webhook URL embeds the credential allowed to post to it
+var secretSettingKeys = []string{"password", "secret", "token", "routing_key", "webhook_url"}
+
+// headersSettingKey is the webhook setting holding request headers, whose values often carry
+// an Authorization credential and are masked one by one
+const headersSettingKey = "headers"
+
 // NotificationChannelResponse represents the API response for notification channels
 type NotificationChannelResponse struct {
-	ID       int                    `json:"id"`
-	Type     string                 `json:"type"`
-	Name     string                 `json:"name"`
-	Enabled  bool                   `json:"enabled"`
-	Settings map[string]interface{} `json:"settings"`
+	ID         uuid.UUID              `json:"id"`
+	Type       string                 `json:"type"`
+	Name       string                 `json:"name"`
+	Enabled    bool                   `json:"enabled"`
+	Settings   map[string]interface{} `json:"settings"`
+	Templates  data.MessageTemplates  `json:"templates"`
+	RateLimit  *int                   `json:"rate_limit"`
+	QuietHours *data.QuietHours       `json:"quiet_hours"`
+	Loaded     bool                   `json:"loaded"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
 }
 
 // NotificationChannelRequest represents the API request for creating/updating notification channels
@@ -26,33 +51,273 @@ type NotificationChannelRequest struct {
 	Name     string                 `json:"name"`
 	Enabled  bool                   `json:"enabled"`
 	Settings map[string]interface{} `json:"settings"`
+	// Templates override the channel's messages by notification type, omitted on update keeps
+	// the stored templates
+	Templates data.MessageTemplates `json:"templates"`
+	// RateLimit is the notifications the channel receives per rate limit period, an hour by
+	// default. Null uses the default limit and 0 disables it
+	RateLimit *int `json:"rate_limit"`
+	// QuietHours holds back notifications below a severity during a daily window, optionally
+	// batching them into hourly or daily digests. Null sends every notification right away
+	QuietHours *data.QuietHours `json:"quiet_hours"`
+}
+
+// maxChannelRateLimit bounds a channel's rate limit override
+const maxChannelRateLimit = 10000
+
+// isSecretSetting returns whether a channel setting holds a credential
+func isSecretSetting(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range secretSettingKeys {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskChannelSettings returns a copy of the settings with secret values masked
+func maskChannelSettings(settings data.JSONMap) map[string]interface{} {
+	masked := make(map[string]interface{}, len(settings))
+	for key, value := range settings {
+		if isSecretSetting(key) && value != "" {
+			value = maskedSettingValue
+		}
+		if headers, ok := value.(map[string]interface{}); ok && key == headersSettingKey {
+			maskedHeaders := make(map[string]interface{}, len(headers))
+			for name, headerValue := range headers {
+				if headerValue != "" {
+					headerValue = maskedSettingValue
+				}
+				maskedHeaders[name] = headerValue
+			}
+			value = maskedHeaders
+		}
+		masked[key] = value
+	}
+	return masked
+}
+
+// restoreMaskedSettings replaces masked secrets sent back by clients with their stored value
+func restoreMaskedSettings(settings, stored data.JSONMap) {
+	for key, value := range settings {
+		if value == maskedSettingValue && isSecretSetting(key) {
+			settings[key] = stored[key]
+		}
+	}
+
+	headers, ok := settings[headersSettingKey].(map[string]interface{})
+	if !ok {
+		return
+	}
+	storedHeaders, _ := stored[headersSettingKey].(map[string]interface{})
+	for name, value := range headers {
+		if value != maskedSettingValue {
+			continue
+		}
+		if storedValue, ok := storedHeaders[name]; ok {
+			headers[name] = storedValue
+		} else {
+			delete(headers, name)
+		}
+	}
+}
+
+// notificationChannelResponse builds the API response for a stored channel
+func (app *Application) notificationChannelResponse(channel *data.NotificationChannel) NotificationChannelResponse {
+	_, loaded := app.notificationService.GetChannel(channel.ID)
+
+	return NotificationChannelResponse{
+		ID:         channel.ID,
+		Type:       channel.Type,
+		Name:       channel.Name,
+		Enabled:    channel.Enabled,
+		Settings:   maskChannelSettings(channel.Settings),
+		Templates:  channel.Templates,
+		RateLimit:  channel.RateLimit,
+		QuietHours: channel.QuietHours,
+		Loaded:     loaded,
+		CreatedAt:  channel.CreatedAt,
+		UpdatedAt:  channel.UpdatedAt,
+	}
+}
+
+// channelProviderConfig returns the provider configuration for a stored channel
+func channelProviderConfig(channel *data.NotificationChannel) notification.ProviderConfig {
+	return notification.ProviderConfig{
+		Type:     notification.ProviderType(channel.Type),
+		Enabled:  channel.Enabled,
+		Settings: channel.Settings,
+	}
+}
+
+// channelTemplates returns the message templates of a stored channel by notification type
+func channelTemplates(templates data.MessageTemplates) map[notification.NotificationType]notification.MessageTemplate {
+	converted := make(map[notification.NotificationType]notification.MessageTemplate, len(templates))
+	for eventType, template := range templates {
+		converted[notification.NotificationType(eventType)] = notification.MessageTemplate{
+			Title: template.Title,
+			Body:  template.Body,
+			HTML:  template.HTML,
+		}
+	}
+	return converted
+}
+
+// channelQuietHours returns the quiet hours of a stored channel, nil without any
+func channelQuietHours(quietHours *data.QuietHours) *notification.QuietHoursConfig {
+	if quietHours == nil {
+		return nil
+	}
+	return &notification.QuietHoursConfig{
+		Start:       quietHours.Start,
+		End:         quietHours.End,
+		Timezone:    quietHours.Timezone,
+		MinSeverity: quietHours.MinSeverity,
+		Digest:      quietHours.Digest,
+	}
+}
+
+// reloadNotificationChannels rebuilds the notification service's channels from the database
+func (app *Application) reloadNotificationChannels() error {
+	channels, err := app.db.GetNotificationChannels()
+	if err != nil {
+		return err
+	}
+
+	configs := make([]notification.ChannelConfig, 0, len(channels))
+	for i := range channels {
+		configs = append(configs, notification.ChannelConfig{
+			ID:         channels[i].ID,
+			Name:       channels[i].Name,
+			Config:     channelProviderConfig(&channels[i]),
+			Templates:  channelTemplates(channels[i].Templates),
+			RateLimit:  channels[i].RateLimit,
+			QuietHours: channelQuietHours(channels[i].QuietHours),
+		})
+	}
+
+	app.notificationService.LoadChannels(configs)
+	return nil
+}
+
+// validateNotificationChannelRequest validates and sanitizes a channel request, checking the
+// settings against the provider so broken channels are never stored
+func (app *Application) validateNotificationChannelRequest(req *NotificationChannelRequest) []string {
+	var errors []string
+	sanitizer := security.NewSanitizer()
+
+	nameResult := sanitizer.SanitizeHTML(req.Name, "name")
+	errors = append(errors, nameResult.Errors...)
+	if nameResult.Value == "" {
+		errors = append(errors, "Name is required and cannot be empty")
+	}
+	if len(nameResult.Value) > 100 {
+		errors = append(errors, "Name must be no more than 100 characters")
+	}
+	req.Name = nameResult.Value
+
+	providerType := notification.ProviderType(req.Type)
+	if !app.notificationService.SupportsProviderType(providerType) {
+		return append(errors, fmt.Sprintf("invalid provider type: %s", req.Type))
+	}
+
+	if req.Settings == nil {
+		req.Settings = map[string]interface{}{}
+	}
+
+	// Disabled providers skip validation in Configure, so always validate as enabled
+	_, err := app.notificationService.NewChannelProvider(notification.ProviderConfig{
+		Type:     providerType,
+		Enabled:  true,
+		Settings: req.Settings,
+	})
+	if err != nil {
+		errors = append(errors, fmt.Sprintf("invalid settings: %s", err.Error()))
+	}
+
+	if req.RateLimit != nil && (*req.RateLimit < 0 || *req.RateLimit > maxChannelRateLimit) {
+		errors = append(errors, fmt.Sprintf("Rate limit must be between 0 and %d", maxChannelRateLimit))
+	}
+
+	if req.QuietHours != nil {
+		if _, err := notification.NewQuietHours(*channelQuietHours(req.QuietHours)); err != nil {
+			errors = append(errors, fmt.Sprintf("invalid quiet hours: %s", err.Error()))
+		}
+	}
+
+	errors = append(errors, validateChannelTemplates(providerType, req.Templates)...)
+	if req.Templates == nil {
+		req.Templates = data.MessageTemplates{}
+	}
+
+	return errors
+}
+
+// validateChannelTemplates checks that templates are keyed by known event types and render
+// against sample data, so broken templates are never stored
+func validateChannelTemplates(providerType notification.ProviderType, templates data.MessageTemplates) []string {
+	var errors []string
+	for eventType, template := range templates {
+		if !slices.Contains(templateEventTypes, eventType) {
+			errors = append(errors, "Template event types must be one of: "+strings.Join(templateEventTypes, ", "))
+			continue
+		}
+		if template.HTML != "" && providerType != notification.ProviderTypeEmail {
+			errors = append(errors, fmt.Sprintf("%s template: html templates are only used by email channels", eventType))
+		}
+	}
+	if len(errors) > 0 {
+		return errors
+	}
+
+	if _, err := notification.CompileTemplates(channelTemplates(templates)); err != nil {
+		errors = append(errors, fmt.Sprintf("invalid templates: %s", err.Error()))
+	}
+	return errors
+}
+
+// reloadAfterChannelChange reloads channels after a change, logging rather than failing the request
+func (app *Application) reloadAfterChannelChange() {
+	if err := app.reloadNotificationChannels(); err != nil {
+		app.logger.Error("Error reloading notification channels", "err", err.Error())
+	}
 }
 
 // listNotificationChannels handles GET /api/v1/admin/notifications/channels
 func (app *Application) listNotificationChannels(w http.ResponseWriter, r *http.Request) {
-	// For now, return the configured providers from the notification service
-	providers := app.notificationService.GetProviders()
-
-	channels := make([]NotificationChannelResponse, 0, len(providers))
-	for i, provider := range providers {
-		channels = append(channels, NotificationChannelResponse{
-			ID:      i + 1,
-			Type:    string(provider.GetType()),
-			Name:    string(provider.GetType()),
-			Enabled: provider.IsEnabled(),
-			Settings: map[string]interface{}{
-				"configured": provider.IsEnabled(),
-			},
-		})
+	channels, err := app.db.GetNotificationChannels()
+	if err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting notification channels", err)
+		return
 	}
 
-	response := map[string]interface{}{
-		"channels": channels,
-		"total":    len(channels),
+	responses := make([]NotificationChannelResponse, 0, len(channels))
+	for i := range channels {
+		responses = append(responses, app.notificationChannelResponse(&channels[i]))
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	app.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"channels": responses,
+		"total":    len(responses),
+	})
+}
+
+// getNotificationChannel handles GET /api/v1/admin/notifications/channels/{id}
+func (app *Application) getNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+
+	channel, err := app.db.GetNotificationChannel(id)
+	if err != nil {
+		app.notificationChannelLookupError(w, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, app.notificationChannelResponse(channel))
 }
 
 // createNotificationChannel handles POST /api/v1/admin/notifications/channels
@@ -63,50 +328,46 @@ func (app *Application) createNotificationChannel(w http.ResponseWriter, r *http
 		return
 	}
 
-	// Validate provider type
-	providerType := notification.ProviderType(req.Type)
-	switch providerType {
-	case notification.ProviderTypeEmail, notification.ProviderTypeSlack,
-		notification.ProviderTypeDiscord, notification.ProviderTypeWebhook:
-		// Valid types
-	default:
-		app.errorResponse(w, http.StatusBadRequest, fmt.Sprintf("invalid provider type: %s", req.Type))
+	if errors := app.validateNotificationChannelRequest(&req); len(errors) > 0 {
+		app.respondWithValidationErrors(w, errors)
 		return
 	}
 
-	// Configure the provider
-	config := notification.ProviderConfig{
-		Type:     providerType,
-		Enabled:  req.Enabled,
-		Settings: req.Settings,
+	if app.notificationChannelNameTaken(w, req.Type, req.Name, uuid.Nil) {
+		return
 	}
 
-	err := app.notificationService.ConfigureProvider(providerType, config)
-	if err != nil {
-		app.errorResponse(w, http.StatusInternalServerError, err.Error())
-		return
+	channel := &data.NotificationChannel{
+		Name:       req.Name,
+		Type:       req.Type,
+		Enabled:    req.Enabled,
+		Settings:   req.Settings,
+		Templates:  req.Templates,
+		RateLimit:  req.RateLimit,
+		QuietHours: req.QuietHours,
 	}
 
-	// Return success response
-	response := NotificationChannelResponse{
-		ID:       1, // Simplified for now
-		Type:     req.Type,
-		Name:     req.Name,
-		Enabled:  req.Enabled,
-		Settings: req.Settings,
+	if err := app.db.CreateNotificationChannel(channel); err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error creating notification channel", err)
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(response)
+	app.reloadAfterChannelChange()
+
+	app.writeJSON(w, http.StatusCreated, app.notificationChannelResponse(channel))
 }
 
 // updateNotificationChannel handles PUT /api/v1/admin/notifications/channels/{id}
 func (app *Application) updateNotificationChannel(w http.ResponseWriter, r *http.Request) {
-	idStr := chi.URLParam(r, "id")
-	id, err := strconv.Atoi(idStr)
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+
+	channel, err := app.db.GetNotificationChannel(id)
 	if err != nil {
-		app.errorResponse(w, http.StatusBadRequest, "invalid channel ID")
+		app.notificationChannelLookupError(w, err)
 		return
 	}
 
@@ -116,60 +377,178 @@ func (app *Application) updateNotificationChannel(w http.ResponseWriter, r *http
 		return
 	}
 
-	// Validate provider type
-	providerType := notification.ProviderType(req.Type)
-	switch providerType {
-	case notification.ProviderTypeEmail, notification.ProviderTypeSlack,
-		notification.ProviderTypeDiscord, notification.ProviderTypeWebhook:
-		// Valid types
-	default:
-		app.errorResponse(w, http.StatusBadRequest, fmt.Sprintf("invalid provider type: %s", req.Type))
-		return
+	// Fall back to existing values for fields omitted from the request
+	if req.Type == "" {
+		req.Type = channel.Type
+	}
+	if req.Name == "" {
+		req.Name = channel.Name
+	}
+	if req.Settings == nil {
+		req.Settings = channel.Settings
+	}
+	if req.Templates == nil {
+		req.Templates = channel.Templates
 	}
 
-	// Configure the provider
-	config := notification.ProviderConfig{
-		Type:     providerType,
-		Enabled:  req.Enabled,
-		Settings: req.Settings,
+	// Masked secrets sent back by clients keep their stored value
+	restoreMaskedSettings(req.Settings, channel.Settings)
+
+	if errors := app.validateNotificationChannelRequest(&req); len(errors) > 0 {
+		app.respondWithValidationErrors(w, errors)
+		return
 	}
 
-	err = app.notificationService.ConfigureProvider(providerType, config)
-	if err != nil {
-		app.errorResponse(w, http.StatusInternalServerError, err.Error())
+	if app.notificationChannelNameTaken(w, req.Type, req.Name, channel.ID) {
 		return
 	}
 
-	// Return success response
-	response := NotificationChannelResponse{
-		ID:       id,
-		Type:     req.Type,
-		Name:     req.Name,
-		Enabled:  req.Enabled,
-		Settings: req.Settings,
+	channel.Name = req.Name
+	channel.Type = req.Type
+	channel.Enabled = req.Enabled
+	channel.Settings = req.Settings
+	channel.Templates = req.Templates
+	channel.RateLimit = req.RateLimit
+	channel.QuietHours = req.QuietHours
+
+	if err := app.db.UpdateNotificationChannel(channel); err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error updating notification channel", err)
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	app.reloadAfterChannelChange()
+
+	app.writeJSON(w, http.StatusOK, app.notificationChannelResponse(channel))
 }
 
 // deleteNotificationChannel handles DELETE /api/v1/admin/notifications/channels/{id}
 func (app *Application) deleteNotificationChannel(w http.ResponseWriter, r *http.Request) {
-	idStr := chi.URLParam(r, "id")
-	_, err := strconv.Atoi(idStr)
+	id, err := parseUUIDParam(r, "id")
 	if err != nil {
-		app.errorResponse(w, http.StatusBadRequest, "invalid channel ID")
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+
+	if _, err := app.db.GetNotificationChannel(id); err != nil {
+		app.notificationChannelLookupError(w, err)
 		return
 	}
 
-	// For now, just disable the provider
-	// In a full implementation, we'd remove the configuration from storage
+	if err := app.db.DeleteNotificationChannel(id); err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error deleting notification channel", err)
+		return
+	}
+
+	app.reloadAfterChannelChange()
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// TestNotificationRequest represents the request for testing notification delivery
+// defaultSecretGracePeriod is how long a rotated webhook secret keeps signing requests
+const defaultSecretGracePeriod = 24 * time.Hour
+
+// RotateSecretRequest represents the request for rotating a webhook channel's signing secret
+type RotateSecretRequest struct {
+	// GracePeriodHours the previous secret keeps signing requests, 0 drops it at once
+	GracePeriodHours *int `json:"grace_period_hours,omitempty"`
+}
+
+// rotateNotificationChannelSecret handles POST /api/v1/admin/notifications/channels/{id}/rotate-secret.
+// The new secret is only returned in this response
+func (app *Application) rotateNotificationChannelSecret(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+
+	channel, err := app.db.GetNotificationChannel(id)
+	if err != nil {
+		app.notificationChannelLookupError(w, err)
+		return
+	}
+
+	if notification.ProviderType(channel.Type) != notification.ProviderTypeWebhook {
+		app.errorResponse(w, http.StatusBadRequest, "Only webhook channels have a signing secret")
+		return
+	}
+
+	var req RotateSecretRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			app.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	gracePeriod := defaultSecretGracePeriod
+	if req.GracePeriodHours != nil {
+		if *req.GracePeriodHours < 0 || *req.GracePeriodHours > 720 {
+			app.respondWithValidationErrors(w, []string{"Grace period must be between 0 and 720 hours"})
+			return
+		}
+		gracePeriod = time.Duration(*req.GracePeriodHours) * time.Hour
+	}
+
+	if channel.Settings == nil {
+		channel.Settings = data.JSONMap{}
+	}
+	now := time.Now()
+	secret, err := providers.RotateWebhookSecret(channel.Settings, gracePeriod, now)
+	if err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error generating webhook secret", err)
+		return
+	}
+
+	if err := app.db.UpdateNotificationChannel(channel); err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error updating notification channel", err)
+		return
+	}
+
+	app.reloadAfterChannelChange()
+
+	response := map[string]interface{}{
+		"secret":  secret,
+		"channel": app.notificationChannelResponse(channel),
+	}
+	if _, rotating := channel.Settings["previous_secret"]; rotating {
+		response["previous_secret_expires_at"] = now.Add(gracePeriod).UTC()
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// notificationChannelLookupError responds to a failed channel lookup
+func (app *Application) notificationChannelLookupError(w http.ResponseWriter, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		app.errorResponse(w, http.StatusNotFound, "Notification channel not found")
+		return
+	}
+	app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting notification channel", err)
+}
+
+// notificationChannelNameTaken responds with a conflict and returns true if another channel of
+// the type already uses the name
+func (app *Application) notificationChannelNameTaken(w http.ResponseWriter, channelType, name string, id uuid.UUID) bool {
+	existing, err := app.db.GetNotificationChannelByName(channelType, name)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return false
+	case err != nil:
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting notification channel", err)
+		return true
+	case existing.ID != id:
+		app.errorResponse(w, http.StatusConflict, fmt.Sprintf("A %s channel named %q already exists", channelType, name))
+		return true
+	}
+	return false
+}
+
+// TestNotificationRequest represents the request for testing notification delivery. ChannelID
+// tests a single channel, ProviderType tests every channel of that type
 type TestNotificationRequest struct {
-	ProviderType string `json:"provider_type"`
+	ChannelID    *uuid.UUID `json:"channel_id,omitempty"`
+	ProviderType string     `json:"provider_type,omitempty"`
 }
 
 // testNotificationChannel handles POST /api/v1/admin/notifications/test
@@ -180,18 +559,46 @@ func (app *Application) testNotificationChannel(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	// Validate provider type
-	providerType := notification.ProviderType(req.ProviderType)
-	switch providerType {
-	case notification.ProviderTypeEmail, notification.ProviderTypeSlack,
-		notification.ProviderTypeDiscord, notification.ProviderTypeWebhook:
-		// Valid types
+	var channelIDs []uuid.UUID
+	switch {
+	case req.ChannelID != nil:
+		channelIDs = append(channelIDs, *req.ChannelID)
+	case req.ProviderType != "":
+		providerType := notification.ProviderType(req.ProviderType)
+		if !app.notificationService.SupportsProviderType(providerType) {
+			app.errorResponse(w, http.StatusBadRequest, fmt.Sprintf("invalid provider type: %s", req.ProviderType))
+			return
+		}
+		for _, channel := range app.notificationService.GetEnabledChannels() {
+			if channel.Type() == providerType {
+				channelIDs = append(channelIDs, channel.ID)
+			}
+		}
+		if len(channelIDs) == 0 {
+			app.errorResponse(w, http.StatusNotFound, fmt.Sprintf("No enabled %s channels configured", req.ProviderType))
+			return
+		}
 	default:
-		app.errorResponse(w, http.StatusBadRequest, fmt.Sprintf("invalid provider type: %s", req.ProviderType))
+		app.errorResponse(w, http.StatusBadRequest, "channel_id or provider_type is required")
+		return
+	}
+
+	app.sendTestNotification(w, r, channelIDs)
+}
+
+// testNotificationChannelByID handles POST /api/v1/admin/notifications/channels/{id}/test
+func (app *Application) testNotificationChannelByID(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
 		return
 	}
 
-	// Create test notification data
+	app.sendTestNotification(w, r, []uuid.UUID{id})
+}
+
+// sendTestNotification sends a test notification through the channels and reports the first failure
+func (app *Application) sendTestNotification(w http.ResponseWriter, r *http.Request, channelIDs []uuid.UUID) {
 	testData := notification.NotificationData{
 		Type:      notification.NotificationTypeEndpointDown,
 		Title:     "Test Notification",
@@ -199,23 +606,91 @@ func (app *Application) testNotificationChannel(w http.ResponseWriter, r *http.R
 		Timestamp: time.Now(),
 	}
 
-	// Send test notification to the specific provider
-	result := app.notificationService.SendNotification(r.Context(), providerType, testData)
+	for _, id := range channelIDs {
+		if _, exists := app.notificationService.GetChannel(id); !exists {
+			app.errorResponse(w, http.StatusNotFound, "Notification channel not found or not loaded")
+			return
+		}
 
-	if result.Success {
-		// Return success response
-		response := map[string]interface{}{
-			"success": true,
-			"message": "Test notification sent successfully",
+		result := app.notificationService.SendNotificationToChannel(r.Context(), id, testData)
+		if !result.Success {
+			errorMsg := "Unknown error"
+			if result.Error != nil {
+				errorMsg = result.Error.Error()
+			}
+			app.errorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Test notification failed: %s", errorMsg))
+			return
 		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
-	} else {
-		// Return error response
-		errorMsg := "Unknown error"
-		if result.Error != nil {
-			errorMsg = result.Error.Error()
+	}
+
+	app.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Test notification sent successfully",
+	})
+}
+
+// ListNotificationDeliveriesResponse represents the response for the delivery history
+type ListNotificationDeliveriesResponse struct {
+	Deliveries []data.NotificationDelivery `json:"deliveries"`
+	Total      int                         `json:"total"`
+	Page       int                         `json:"page"`
+	Limit      int                         `json:"limit"`
+}
+
+// listNotificationDeliveries handles GET /api/v1/admin/notifications/deliveries
+func (app *Application) listNotificationDeliveries(w http.ResponseWriter, r *http.Request) {
+	pagination := parsePaginationParams(r)
+
+	filter := data.NotificationDeliveryFilter{
+		Status:    r.URL.Query().Get("status"),
+		EventType: r.URL.Query().Get("event_type"),
+	}
+
+	channelID, err := parseOptionalUUIDQuery(r, "channel_id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, "Invalid channel_id")
+		return
+	}
+	filter.ChannelID = channelID
+
+	eventID, err := parseOptionalUUIDQuery(r, "event_id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, "Invalid event_id")
+		return
+	}
+	filter.EventID = eventID
+
+	deliveries, total, err := app.db.GetNotificationDeliveriesWithPagination(pagination.Page, pagination.Limit, filter)
+	if err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting notification deliveries", err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, ListNotificationDeliveriesResponse{
+		Deliveries: deliveries,
+		Total:      int(total),
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+	})
+}
+
+// getNotificationDelivery handles GET /api/v1/admin/notifications/deliveries/{id}
+func (app *Application) getNotificationDelivery(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+
+	delivery, err := app.db.GetNotificationDelivery(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			app.errorResponse(w, http.StatusNotFound, "Notification delivery not found")
+			return
 		}
-		app.errorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Test notification failed: %s", errorMsg))
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting notification delivery", err)
+		return
 	}
+
+	app.writeJSON(w, http.StatusOK, delivery)
 }
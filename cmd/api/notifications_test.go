@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+func TestMaskChannelSettings(t *testing.T) {
+	settings := data.JSONMap{
+		"webhook_url": "https://hooks.slack.com/services/T000/B000/XXXX",
+		"channel":     "#alerts",
+		"headers": map[string]interface{}{
+			"Authorization": "Bearer secret",
+			"X-Empty":       "",
+		},
+	}
+
+	masked := maskChannelSettings(settings)
+	if masked["webhook_url"] != maskedSettingValue {
+		t.Errorf("Expected webhook URL to be masked, got %v", masked["webhook_url"])
+	}
+	if masked["channel"] != "#alerts" {
+		t.Errorf("Expected channel to be returned, got %v", masked["channel"])
+	}
+
+	headers := masked["headers"].(map[string]interface{})
+	if headers["Authorization"] != maskedSettingValue || headers["X-Empty"] != "" {
+		t.Errorf("Expected header values to be masked, got %v", headers)
+	}
+	if settings["headers"].(map[string]interface{})["Authorization"] != "Bearer secret" {
+		t.Error("Expected stored headers to be left untouched")
+	}
+}
+
+func TestRestoreMaskedSettings(t *testing.T) {
+	stored := data.JSONMap{
+		"webhook_url": "https://example.com/hook?key=secret",
+		"headers": map[string]interface{}{
+			"Authorization": "Bearer secret",
+		},
+	}
+	settings := data.JSONMap{
+		"webhook_url": maskedSettingValue,
+		"headers": map[string]interface{}{
+			"Authorization": maskedSettingValue,
+			"X-Unknown":     maskedSettingValue,
+			"X-Team":        "ops",
+		},
+	}
+
+	restoreMaskedSettings(settings, stored)
+	if settings["webhook_url"] != "https://example.com/hook?key=secret" {
+		t.Errorf("Expected stored webhook URL to be kept, got %v", settings["webhook_url"])
+	}
+
+	headers := settings["headers"].(map[string]interface{})
+	if headers["Authorization"] != "Bearer secret" || headers["X-Team"] != "ops" {
+		t.Errorf("Expected stored header values to be kept, got %v", headers)
+	}
+	if _, ok := headers["X-Unknown"]; ok {
+		t.Error("Expected a masked header without a stored value to be dropped")
+	}
+}
@@ -0,0 +1,432 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/constants"
+	"github.com/i4o-oss/watchtower/internal/data"
+	"github.com/i4o-oss/watchtower/internal/oncall"
+	"github.com/i4o-oss/watchtower/internal/security"
+	"gorm.io/gorm"
+)
+
+// onCallRotationTypes are the supported rotation types
+var onCallRotationTypes = []string{data.OnCallRotationDaily, data.OnCallRotationWeekly}
+
+// OnCallScheduleRequest represents the API request for creating/updating on-call schedules
+type OnCallScheduleRequest struct {
+	Name           string      `json:"name"`
+	Description    string      `json:"description"`
+	Timezone       string      `json:"timezone"`
+	RotationType   string      `json:"rotation_type"`
+	HandoffTime    string      `json:"handoff_time"`
+	HandoffWeekday int         `json:"handoff_weekday"`
+	RotationStart  *time.Time  `json:"rotation_start"`
+	Participants   []uuid.UUID `json:"participants"`
+}
+
+// OnCallOverrideRequest represents the API request for creating on-call overrides
+type OnCallOverrideRequest struct {
+	UserID    uuid.UUID `json:"user_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Reason    string    `json:"reason"`
+}
+
+// OnCallResponse describes who is on call for a schedule at a time
+type OnCallResponse struct {
+	ScheduleID   uuid.UUID `json:"schedule_id"`
+	ScheduleName string    `json:"schedule_name"`
+	At           time.Time `json:"at"`
+	*oncall.OnCall
+}
+
+// validateOnCallScheduleRequest validates and normalizes a schedule request
+func (app *Application) validateOnCallScheduleRequest(req *OnCallScheduleRequest) []string {
+	var errors []string
+	sanitizer := security.NewSanitizer()
+
+	nameResult := sanitizer.SanitizeHTML(req.Name, "name")
+	errors = append(errors, nameResult.Errors...)
+	if nameResult.Value == "" {
+		errors = append(errors, "Name is required and cannot be empty")
+	}
+	if len(nameResult.Value) > 100 {
+		errors = append(errors, "Name must be no more than 100 characters")
+	}
+	req.Name = nameResult.Value
+
+	descriptionResult := sanitizer.SanitizeHTML(req.Description, "description")
+	errors = append(errors, descriptionResult.Errors...)
+	if len(descriptionResult.Value) > 500 {
+		errors = append(errors, "Description must be no more than 500 characters")
+	}
+	req.Description = descriptionResult.Value
+
+	req.Timezone = strings.TrimSpace(req.Timezone)
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(req.Timezone); err != nil {
+		errors = append(errors, fmt.Sprintf("Timezone %q is not a valid IANA timezone", req.Timezone))
+	}
+
+	req.RotationType = strings.ToLower(strings.TrimSpace(req.RotationType))
+	if req.RotationType == "" {
+		req.RotationType = data.OnCallRotationWeekly
+	}
+	if !slices.Contains(onCallRotationTypes, req.RotationType) {
+		errors = append(errors, "Rotation type must be one of: "+strings.Join(onCallRotationTypes, ", "))
+	}
+
+	req.HandoffTime = strings.TrimSpace(req.HandoffTime)
+	if req.HandoffTime == "" {
+		req.HandoffTime = "09:00"
+	}
+	if _, _, err := oncall.ParseHandoffTime(req.HandoffTime); err != nil {
+		errors = append(errors, "Handoff time must be formatted as HH:MM")
+	}
+
+	if req.HandoffWeekday < 0 || req.HandoffWeekday > 6 {
+		errors = append(errors, "Handoff weekday must be between 0 (Sunday) and 6 (Saturday)")
+	}
+
+	if req.RotationStart == nil {
+		now := time.Now()
+		req.RotationStart = &now
+	}
+
+	req.Participants = uniqueUUIDs(req.Participants)
+	if len(req.Participants) == 0 || len(req.Participants) > 50 {
+		errors = append(errors, "Participants must contain between 1 and 50 users")
+	}
+	for _, userID := range req.Participants {
+		if _, err := app.db.GetUserByID(userID); err != nil {
+			errors = append(errors, fmt.Sprintf("User %s does not exist", userID))
+		}
+	}
+
+	return errors
+}
+
+// applyOnCallScheduleRequest copies a validated request onto a schedule
+func applyOnCallScheduleRequest(schedule *data.OnCallSchedule, req *OnCallScheduleRequest) {
+	schedule.Name = req.Name
+	schedule.Description = req.Description
+	schedule.Timezone = req.Timezone
+	schedule.RotationType = req.RotationType
+	schedule.HandoffTime = req.HandoffTime
+	schedule.HandoffWeekday = req.HandoffWeekday
+	schedule.RotationStart = *req.RotationStart
+	schedule.Participants = data.UUIDList(req.Participants)
+}
+
+// listOnCallSchedules handles GET /api/v1/admin/oncall/schedules
+func (app *Application) listOnCallSchedules(w http.ResponseWriter, r *http.Request) {
+	schedules, err := app.db.GetOnCallSchedules()
+	if err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting on-call schedules", err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"schedules": schedules,
+		"total":     len(schedules),
+	})
+}
+
+// getOnCallSchedule handles GET /api/v1/admin/oncall/schedules/{id}
+func (app *Application) getOnCallSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+
+	schedule, err := app.db.GetOnCallSchedule(id)
+	if err != nil {
+		app.onCallScheduleLookupError(w, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, schedule)
+}
+
+// createOnCallSchedule handles POST /api/v1/admin/oncall/schedules
+func (app *Application) createOnCallSchedule(w http.ResponseWriter, r *http.Request) {
+	var req OnCallScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if errors := app.validateOnCallScheduleRequest(&req); len(errors) > 0 {
+		app.respondWithValidationErrors(w, errors)
+		return
+	}
+
+	if app.onCallScheduleNameTaken(w, req.Name, uuid.Nil) {
+		return
+	}
+
+	schedule := &data.OnCallSchedule{}
+	applyOnCallScheduleRequest(schedule, &req)
+
+	if err := app.db.CreateOnCallSchedule(schedule); err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error creating on-call schedule", err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusCreated, schedule)
+}
+
+// updateOnCallSchedule handles PUT /api/v1/admin/oncall/schedules/{id}
+func (app *Application) updateOnCallSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+
+	schedule, err := app.db.GetOnCallSchedule(id)
+	if err != nil {
+		app.onCallScheduleLookupError(w, err)
+		return
+	}
+
+	var req OnCallScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Keep the rotation anchored where it was unless a new start is given
+	if req.RotationStart == nil {
+		req.RotationStart = &schedule.RotationStart
+	}
+
+	if errors := app.validateOnCallScheduleRequest(&req); len(errors) > 0 {
+		app.respondWithValidationErrors(w, errors)
+		return
+	}
+
+	if app.onCallScheduleNameTaken(w, req.Name, schedule.ID) {
+		return
+	}
+
+	applyOnCallScheduleRequest(schedule, &req)
+
+	if err := app.db.UpdateOnCallSchedule(schedule); err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error updating on-call schedule", err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, schedule)
+}
+
+// deleteOnCallSchedule handles DELETE /api/v1/admin/oncall/schedules/{id}
+func (app *Application) deleteOnCallSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+
+	if _, err := app.db.GetOnCallSchedule(id); err != nil {
+		app.onCallScheduleLookupError(w, err)
+		return
+	}
+
+	if err := app.db.DeleteOnCallSchedule(id); err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error deleting on-call schedule", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getOnCall handles GET /api/v1/admin/oncall/schedules/{id}/on-call, answering who is on call
+// at the time given by the optional RFC 3339 "at" query parameter, now by default
+func (app *Application) getOnCall(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+
+	at := time.Now()
+	if value := r.URL.Query().Get("at"); value != "" {
+		at, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			app.errorResponse(w, http.StatusBadRequest, "Invalid at parameter, expected an RFC 3339 time")
+			return
+		}
+	}
+
+	schedule, err := app.db.GetOnCallSchedule(id)
+	if err != nil {
+		app.onCallScheduleLookupError(w, err)
+		return
+	}
+
+	onCall, err := app.onCall.OnCallAt(id, at)
+	if err != nil {
+		if errors.Is(err, oncall.ErrNoParticipants) {
+			app.errorResponse(w, http.StatusNotFound, "No one is on call for this schedule")
+			return
+		}
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error resolving on-call user", err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, OnCallResponse{
+		ScheduleID:   schedule.ID,
+		ScheduleName: schedule.Name,
+		At:           at,
+		OnCall:       onCall,
+	})
+}
+
+// listOnCallOverrides handles GET /api/v1/admin/oncall/schedules/{id}/overrides, listing
+// overrides that have not ended yet
+func (app *Application) listOnCallOverrides(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+
+	if _, err := app.db.GetOnCallSchedule(id); err != nil {
+		app.onCallScheduleLookupError(w, err)
+		return
+	}
+
+	overrides, err := app.db.GetOnCallOverrides(id, time.Now(), time.Now().AddDate(100, 0, 0))
+	if err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting on-call overrides", err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"overrides": overrides,
+		"total":     len(overrides),
+	})
+}
+
+// createOnCallOverride handles POST /api/v1/admin/oncall/schedules/{id}/overrides
+func (app *Application) createOnCallOverride(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+
+	if _, err := app.db.GetOnCallSchedule(id); err != nil {
+		app.onCallScheduleLookupError(w, err)
+		return
+	}
+
+	var req OnCallOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var errors []string
+	if _, err := app.db.GetUserByID(req.UserID); err != nil {
+		errors = append(errors, fmt.Sprintf("User %s does not exist", req.UserID))
+	}
+	if req.StartTime.IsZero() || req.EndTime.IsZero() {
+		errors = append(errors, "Start time and end time are required")
+	} else if !req.EndTime.After(req.StartTime) {
+		errors = append(errors, "End time must be after start time")
+	}
+	reasonResult := security.NewSanitizer().SanitizeHTML(req.Reason, "reason")
+	errors = append(errors, reasonResult.Errors...)
+	if len(reasonResult.Value) > 500 {
+		errors = append(errors, "Reason must be no more than 500 characters")
+	}
+	if len(errors) > 0 {
+		app.respondWithValidationErrors(w, errors)
+		return
+	}
+
+	override := &data.OnCallOverride{
+		ScheduleID: id,
+		UserID:     req.UserID,
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+		Reason:     reasonResult.Value,
+	}
+
+	if err := app.db.CreateOnCallOverride(override); err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error creating on-call override", err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusCreated, override)
+}
+
+// deleteOnCallOverride handles DELETE /api/v1/admin/oncall/schedules/{id}/overrides/{override_id}
+func (app *Application) deleteOnCallOverride(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUIDParam(r, "id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+	overrideID, err := parseUUIDParam(r, "override_id")
+	if err != nil {
+		app.errorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		return
+	}
+
+	override, err := app.db.GetOnCallOverride(overrideID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting on-call override", err)
+		return
+	}
+	if err != nil || override.ScheduleID != id {
+		app.errorResponse(w, http.StatusNotFound, "On-call override not found")
+		return
+	}
+
+	if err := app.db.DeleteOnCallOverride(overrideID); err != nil {
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error deleting on-call override", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// onCallScheduleLookupError responds to a failed schedule lookup
+func (app *Application) onCallScheduleLookupError(w http.ResponseWriter, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		app.errorResponse(w, http.StatusNotFound, "On-call schedule not found")
+		return
+	}
+	app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting on-call schedule", err)
+}
+
+// onCallScheduleNameTaken responds with a conflict and returns true if another schedule already
+// uses the name
+func (app *Application) onCallScheduleNameTaken(w http.ResponseWriter, name string, id uuid.UUID) bool {
+	existing, err := app.db.GetOnCallScheduleByName(name)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return false
+	case err != nil:
+		app.logErrorAndRespond(w, http.StatusInternalServerError, constants.ErrInternalServer, "Error getting on-call schedule", err)
+		return true
+	case existing.ID != id:
+		app.errorResponse(w, http.StatusConflict, fmt.Sprintf("An on-call schedule named %q already exists", name))
+		return true
+	}
+	return false
+}
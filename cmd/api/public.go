@@ -9,20 +9,26 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/data"
+	"github.com/i4o-oss/watchtower/internal/maintenance"
 )
 
+// maintenanceHorizon is how far ahead the status page lists scheduled maintenance
+const maintenanceHorizon = 7 * 24 * time.Hour
+
 // PublicStatusResponse represents the public status API response
 type PublicStatusResponse struct {
-	Services    []ServiceStatus `json:"services"`
-	Overall     OverallStatus   `json:"overall"`
-	LastUpdated time.Time       `json:"last_updated"`
+	Services    []ServiceStatus      `json:"services"`
+	Overall     OverallStatus        `json:"overall"`
+	Maintenance []MaintenanceSummary `json:"maintenance"`
+	LastUpdated time.Time            `json:"last_updated"`
 }
 
 // ServiceStatus represents the status of a single service
 type ServiceStatus struct {
 	ID           string    `json:"id"`
 	Name         string    `json:"name"`
-	Status       string    `json:"status"` // "operational", "degraded", "outage"
+	Status       string    `json:"status"` // "operational", "degraded", "outage", "maintenance"
 	UptimeToday  float64   `json:"uptime_today"`
 	Uptime30Day  float64   `json:"uptime_30_day"`
 	Uptime90Day  float64   `json:"uptime_90_day"`
@@ -65,6 +71,17 @@ type IncidentSummary struct {
 	Services    []string   `json:"affected_services"`
 }
 
+// MaintenanceSummary represents an active or upcoming maintenance window occurrence
+type MaintenanceSummary struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Status      string    `json:"status"` // "in_progress", "scheduled"
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	Services    []string  `json:"affected_services"`
+}
+
 // IncidentsResponse represents the incidents API response
 type IncidentsResponse struct {
 	Incidents   []IncidentSummary `json:"incidents"`
@@ -89,6 +106,7 @@ func (app *Application) getPublicStatus(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	now := time.Now()
 	services := make([]ServiceStatus, 0, len(endpoints))
 	var totalUptimeToday, totalUptime30Day, totalUptime90Day float64
 	var overallStatus = "operational"
@@ -108,6 +126,9 @@ func (app *Application) getPublicStatus(w http.ResponseWriter, r *http.Request)
 
 			if latestLog.Success {
 				service.Status = "operational"
+			} else if app.maintenance.InMaintenance(endpoint.ID, now) {
+				// Planned downtime is not an outage
+				service.Status = "maintenance"
 			} else {
 				service.Status = "outage"
 				overallStatus = "outage"
@@ -147,7 +168,8 @@ func (app *Application) getPublicStatus(w http.ResponseWriter, r *http.Request)
 	response := PublicStatusResponse{
 		Services:    services,
 		Overall:     overall,
-		LastUpdated: time.Now(),
+		Maintenance: publicMaintenance(app.maintenance.Occurrences(now, now.Add(maintenanceHorizon)), endpoints, now),
+		LastUpdated: now,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -271,6 +293,38 @@ func (app *Application) getPublicIncidents(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(response)
 }
 
+// publicMaintenance summarizes maintenance occurrences affecting the public endpoints
+func publicMaintenance(occurrences []maintenance.Occurrence, endpoints []data.Endpoint, now time.Time) []MaintenanceSummary {
+	summaries := make([]MaintenanceSummary, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		affectedServices := make([]string, 0)
+		for _, endpoint := range endpoints {
+			if maintenance.Covers(&occurrence.Window, endpoint.ID) {
+				affectedServices = append(affectedServices, endpoint.Name)
+			}
+		}
+		if len(affectedServices) == 0 {
+			continue
+		}
+
+		status := "scheduled"
+		if occurrence.Contains(now) {
+			status = "in_progress"
+		}
+
+		summaries = append(summaries, MaintenanceSummary{
+			ID:          occurrence.Window.ID.String(),
+			Title:       occurrence.Window.Title,
+			Description: occurrence.Window.Description,
+			Status:      status,
+			StartTime:   occurrence.Start,
+			EndTime:     occurrence.End,
+			Services:    affectedServices,
+		})
+	}
+	return summaries
+}
+
 // calculateUptime calculates uptime percentage for an endpoint over the specified number of days
 func (app *Application) calculateUptime(endpointID uuid.UUID, days int) float64 {
 	// Get monitoring logs for the specified period
@@ -285,7 +339,7 @@ func (app *Application) calculateUptime(endpointID uuid.UUID, days int) float64
 
 	var totalChecks, successfulChecks int
 	for _, log := range logs {
-		if log.Timestamp.After(cutoff) {
+		if log.Timestamp.After(cutoff) && !log.InMaintenance {
 			totalChecks++
 			if log.Success {
 				successfulChecks++
@@ -300,7 +354,8 @@ func (app *Application) calculateUptime(endpointID uuid.UUID, days int) float64
 	return (float64(successfulChecks) / float64(totalChecks)) * 100.0
 }
 
-// calculateUptimeForDate calculates uptime for a specific date
+// calculateUptimeForDate calculates uptime for a specific date, leaving out checks run during
+// maintenance windows
 func (app *Application) calculateUptimeForDate(endpointID uuid.UUID, date time.Time) float64 {
 	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 	endOfDay := startOfDay.Add(24 * time.Hour)
@@ -311,16 +366,20 @@ func (app *Application) calculateUptimeForDate(endpointID uuid.UUID, date time.T
 		return 100.0 // Default to operational if we can't get data
 	}
 
-	if len(logs) == 0 {
-		return 100.0
-	}
-
-	var successfulChecks int
+	var totalChecks, successfulChecks int
 	for _, log := range logs {
+		if log.InMaintenance {
+			continue
+		}
+		totalChecks++
 		if log.Success {
 			successfulChecks++
 		}
 	}
 
-	return (float64(successfulChecks) / float64(len(logs))) * 100.0
+	if totalChecks == 0 {
+		return 100.0
+	}
+
+	return (float64(successfulChecks) / float64(totalChecks)) * 100.0
 }
@@ -37,6 +37,22 @@ func (app *Application) routes() http.Handler {
 			r.Get("/auth/registration-status", app.registrationStatus)
 		})
 
+		// Heartbeat pings from monitored jobs, authenticated by the secret token in the URL (no CSRF)
+		r.Group(func(r chi.Router) {
+			r.Use(app.rateLimitMiddleware(PublicAPIRateLimit))
+			r.Get("/heartbeat/{token}", app.heartbeatPing)
+			r.Post("/heartbeat/{token}", app.heartbeatPing)
+			r.Get("/heartbeat/{token}/{signal}", app.heartbeatPing)
+			r.Post("/heartbeat/{token}/{signal}", app.heartbeatPing)
+		})
+
+		// Incident acknowledgement links sent with escalations, authenticated by their signature (no CSRF)
+		r.Group(func(r chi.Router) {
+			r.Use(app.rateLimitMiddleware(PublicAPIRateLimit))
+			r.Get("/incidents/{id}/ack", app.incidentAckLink)
+			r.Post("/incidents/{id}/ack", app.incidentAckLink)
+		})
+
 		// Real-time updates via Server-Sent Events (no additional rate limiting - handled by SSE)
 		r.Get("/events", app.handleSSE)
 
@@ -69,6 +85,7 @@ func (app *Application) routes() http.Handler {
 			r.Route("/endpoints", func(r chi.Router) {
 				r.Get("/", app.listEndpoints)
 				r.Post("/", app.createEndpoint)
+				r.Get("/dependencies", app.getEndpointDependencies)
 				r.Get("/{id}", app.getEndpoint)
 				r.Put("/{id}", app.updateEndpoint)
 				r.Delete("/{id}", app.deleteEndpoint)
@@ -89,6 +106,7 @@ func (app *Application) routes() http.Handler {
 				r.Get("/{id}", app.getIncident)
 				r.Put("/{id}", app.updateIncident)
 				r.Delete("/{id}", app.deleteIncident)
+				r.Post("/{id}/acknowledge", app.acknowledgeIncidentHandler)
 
 				// Incident-endpoint associations
 				r.Get("/{id}/endpoints", app.getIncidentEndpoints)
@@ -107,9 +125,59 @@ func (app *Application) routes() http.Handler {
 			r.Route("/notifications", func(r chi.Router) {
 				r.Get("/channels", app.listNotificationChannels)
 				r.Post("/channels", app.createNotificationChannel)
+				r.Get("/channels/{id}", app.getNotificationChannel)
 				r.Put("/channels/{id}", app.updateNotificationChannel)
 				r.Delete("/channels/{id}", app.deleteNotificationChannel)
+				r.Post("/channels/{id}/test", app.testNotificationChannelByID)
+				r.Post("/channels/{id}/rotate-secret", app.rotateNotificationChannelSecret)
 				r.Post("/test", app.testNotificationChannel)
+
+				// Message templates
+				r.Get("/templates", app.getNotificationTemplateReference)
+				r.Post("/templates/preview", app.previewNotificationTemplate)
+
+				// Routing rules
+				r.Get("/routes", app.listNotificationRoutes)
+				r.Post("/routes", app.createNotificationRoute)
+				r.Post("/routes/dry-run", app.dryRunNotificationRoutes)
+				r.Get("/routes/{id}", app.getNotificationRoute)
+				r.Put("/routes/{id}", app.updateNotificationRoute)
+				r.Delete("/routes/{id}", app.deleteNotificationRoute)
+
+				// Delivery history
+				r.Get("/deliveries", app.listNotificationDeliveries)
+				r.Get("/deliveries/{id}", app.getNotificationDelivery)
+			})
+
+			// Escalation policies
+			r.Route("/escalation-policies", func(r chi.Router) {
+				r.Get("/", app.listEscalationPolicies)
+				r.Post("/", app.createEscalationPolicy)
+				r.Get("/{id}", app.getEscalationPolicy)
+				r.Put("/{id}", app.updateEscalationPolicy)
+				r.Delete("/{id}", app.deleteEscalationPolicy)
+			})
+
+			// On-call schedules
+			r.Route("/oncall/schedules", func(r chi.Router) {
+				r.Get("/", app.listOnCallSchedules)
+				r.Post("/", app.createOnCallSchedule)
+				r.Get("/{id}", app.getOnCallSchedule)
+				r.Put("/{id}", app.updateOnCallSchedule)
+				r.Delete("/{id}", app.deleteOnCallSchedule)
+				r.Get("/{id}/on-call", app.getOnCall)
+				r.Get("/{id}/overrides", app.listOnCallOverrides)
+				r.Post("/{id}/overrides", app.createOnCallOverride)
+				r.Delete("/{id}/overrides/{override_id}", app.deleteOnCallOverride)
+			})
+
+			// Maintenance windows
+			r.Route("/maintenance-windows", func(r chi.Router) {
+				r.Get("/", app.listMaintenanceWindows)
+				r.Post("/", app.createMaintenanceWindow)
+				r.Get("/{id}", app.getMaintenanceWindow)
+				r.Put("/{id}", app.updateMaintenanceWindow)
+				r.Delete("/{id}", app.deleteMaintenanceWindow)
 			})
 
 			// Settings management
@@ -1,12 +1,21 @@
 package main
 
 import (
+	"fmt"
+	"net"
 	"net/http"
+	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/i4o-oss/watchtower/internal/data"
+	"github.com/i4o-oss/watchtower/internal/monitoring"
 	"github.com/i4o-oss/watchtower/internal/security"
 )
 
+// hostnamePattern matches DNS hostnames made of letters, digits, hyphens and underscores
+var hostnamePattern = regexp.MustCompile(`^([a-zA-Z0-9_]([a-zA-Z0-9_-]{0,61}[a-zA-Z0-9])?\.)*[a-zA-Z0-9_]([a-zA-Z0-9_-]{0,61}[a-zA-Z0-9])?$`)
+
 // validateEndpointRequest validates endpoint creation/update requests with enhanced security
 func validateEndpointRequest(req *EndpointRequest) []string {
 	var errors []string
@@ -33,6 +42,77 @@ func validateEndpointRequest(req *EndpointRequest) []string {
 		req.Description = descResult.Value
 	}
 
+	// Validate check type
+	req.CheckType = strings.ToLower(strings.TrimSpace(req.CheckType))
+	if req.CheckType == "" {
+		req.CheckType = data.CheckTypeHTTP
+	}
+
+	switch req.CheckType {
+	case data.CheckTypeHTTP:
+		errors = append(errors, validateHTTPCheckRequest(req, sanitizer)...)
+	case data.CheckTypeTCP:
+		errors = append(errors, validateTCPCheckRequest(req, sanitizer)...)
+	case data.CheckTypeDNS:
+		errors = append(errors, validateDNSCheckRequest(req, sanitizer)...)
+	case data.CheckTypeHeartbeat:
+		errors = append(errors, validateHeartbeatCheckRequest(req, sanitizer)...)
+	default:
+		errors = append(errors, "Check type must be one of: "+strings.Join(data.ValidCheckTypes, ", "))
+	}
+
+	// Validate timeout range
+	timeoutErrors := sanitizer.ValidateIntRange(req.TimeoutSeconds, "timeout", 1, 300)
+	errors = append(errors, timeoutErrors...)
+
+	// Validate check interval range
+	intervalErrors := sanitizer.ValidateIntRange(req.CheckIntervalSeconds, "check interval", 1, 86400)
+	errors = append(errors, intervalErrors...)
+
+	// Validate retry settings
+	if req.MaxRetries != nil {
+		errors = append(errors, sanitizer.ValidateIntRange(*req.MaxRetries, "max retries", 0, 10)...)
+	}
+	if req.RetryDelayMs != nil {
+		errors = append(errors, sanitizer.ValidateIntRange(*req.RetryDelayMs, "retry delay", 0, 60000)...)
+	}
+	if req.ConfirmRetries != nil {
+		errors = append(errors, sanitizer.ValidateIntRange(*req.ConfirmRetries, "confirm retries", 0, 10)...)
+	}
+
+	// Validate tags
+	tags, tagErrors := sanitizeTags(req.Tags, sanitizer)
+	errors = append(errors, tagErrors...)
+	req.Tags = tags
+
+	return errors
+}
+
+// sanitizeTags validates tags and returns them lowercased and without duplicates
+func sanitizeTags(tags []string, sanitizer *security.Sanitizer) ([]string, []string) {
+	var errors []string
+	if len(tags) > 20 {
+		errors = append(errors, "Tags must contain no more than 20 entries")
+	}
+
+	sanitized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tagResult := sanitizer.SanitizeAlphanumeric(strings.ToLower(strings.TrimSpace(tag)), "tag", "-_.:")
+		errors = append(errors, tagResult.Errors...)
+		if tagResult.Value == "" || slices.Contains(sanitized, tagResult.Value) {
+			continue
+		}
+		errors = append(errors, sanitizer.ValidateStringLength(tagResult.Value, "tag", 1, 50)...)
+		sanitized = append(sanitized, tagResult.Value)
+	}
+
+	return sanitized, errors
+}
+
+// validateHTTPCheckRequest validates the fields used by HTTP checks
+func validateHTTPCheckRequest(req *EndpointRequest, sanitizer *security.Sanitizer) []string {
+	var errors []string
+
 	// Validate and sanitize URL
 	urlResult := sanitizer.SanitizeURL(req.URL, "URL")
 	errors = append(errors, urlResult.Errors...)
@@ -68,13 +148,157 @@ func validateEndpointRequest(req *EndpointRequest) []string {
 	statusCodeErrors := sanitizer.ValidateIntRange(req.ExpectedStatusCode, "expected status code", 100, 599)
 	errors = append(errors, statusCodeErrors...)
 
-	// Validate timeout range
-	timeoutErrors := sanitizer.ValidateIntRange(req.TimeoutSeconds, "timeout", 1, 300)
-	errors = append(errors, timeoutErrors...)
+	// Validate certificate expiry warning threshold
+	if req.CertExpiryWarningDays != nil {
+		warningErrors := sanitizer.ValidateIntRange(*req.CertExpiryWarningDays, "certificate expiry warning days", 0, 365)
+		errors = append(errors, warningErrors...)
+	}
 
-	// Validate check interval range
-	intervalErrors := sanitizer.ValidateIntRange(req.CheckIntervalSeconds, "check interval", 1, 86400)
-	errors = append(errors, intervalErrors...)
+	// Validate response assertions
+	errors = append(errors, validateAssertions(req, sanitizer)...)
+
+	return errors
+}
+
+// validateAssertions normalizes and validates the response assertions of an HTTP check
+func validateAssertions(req *EndpointRequest, sanitizer *security.Sanitizer) []string {
+	var errors []string
+
+	if len(req.Assertions) > 20 {
+		errors = append(errors, "Assertions must contain no more than 20 entries")
+	}
+
+	for i := range req.Assertions {
+		assertion := &req.Assertions[i]
+		assertion.Type = strings.ToLower(strings.TrimSpace(assertion.Type))
+		assertion.Operator = strings.ToLower(strings.TrimSpace(assertion.Operator))
+		assertion.Path = strings.TrimSpace(assertion.Path)
+
+		// Body assertions only use the value
+		if assertion.Type != data.AssertionJSONPath && assertion.Type != data.AssertionHeader {
+			assertion.Path = ""
+			assertion.Operator = ""
+		}
+
+		field := fmt.Sprintf("Assertion %d", i+1)
+		errors = append(errors, sanitizer.ValidateStringLength(assertion.Path, field+" path", 0, 255)...)
+		errors = append(errors, sanitizer.ValidateStringLength(assertion.Value, field+" value", 0, 1024)...)
+
+		if err := monitoring.ValidateAssertion(*assertion); err != nil {
+			errors = append(errors, fmt.Sprintf("%s is invalid: %s", field, err.Error()))
+		}
+	}
+
+	return errors
+}
+
+// validateTCPCheckRequest validates the fields used by TCP port checks
+func validateTCPCheckRequest(req *EndpointRequest, sanitizer *security.Sanitizer) []string {
+	var errors []string
+
+	// Validate target address
+	addressResult := sanitizer.SanitizeString(strings.TrimSpace(req.URL), "URL")
+	errors = append(errors, addressResult.Errors...)
+	if addressResult.Value == "" {
+		errors = append(errors, "URL is required and cannot be empty")
+	} else if address, err := monitoring.ParseTCPAddress(addressResult.Value); err != nil {
+		errors = append(errors, "URL must be a host:port address for TCP checks")
+	} else {
+		req.URL = address
+	}
+
+	// Validate payload and expected banner
+	errors = append(errors, sanitizer.ValidateStringLength(req.TCPSend, "TCP send payload", 0, 1024)...)
+	errors = append(errors, sanitizer.ValidateStringLength(req.TCPExpect, "TCP expected response", 0, 1024)...)
+
+	// HTTP-only fields keep their defaults so the row satisfies table constraints
+	req.Method = "GET"
+	req.ExpectedStatusCode = 200
+	req.Assertions = nil
+
+	return errors
+}
+
+// validateDNSCheckRequest validates the fields used by DNS resolution checks
+func validateDNSCheckRequest(req *EndpointRequest, sanitizer *security.Sanitizer) []string {
+	var errors []string
+
+	// Validate hostname
+	hostResult := sanitizer.SanitizeString(strings.TrimSpace(req.URL), "URL")
+	errors = append(errors, hostResult.Errors...)
+	host := strings.TrimSuffix(hostResult.Value, ".")
+	if host == "" {
+		errors = append(errors, "URL is required and cannot be empty")
+	} else if !hostnamePattern.MatchString(host) {
+		errors = append(errors, "URL must be a hostname for DNS checks")
+	}
+	req.URL = strings.ToLower(host)
+
+	// Validate record type
+	req.DNSRecordType = strings.ToUpper(strings.TrimSpace(req.DNSRecordType))
+	if req.DNSRecordType == "" {
+		req.DNSRecordType = "A"
+	}
+	if !slices.Contains(data.ValidDNSRecordTypes, req.DNSRecordType) {
+		errors = append(errors, "DNS record type must be one of: "+strings.Join(data.ValidDNSRecordTypes, ", "))
+	}
+
+	// Validate resolver
+	req.DNSResolver = strings.TrimSpace(req.DNSResolver)
+	if req.DNSResolver != "" {
+		if address, err := monitoring.ParseResolverAddress(req.DNSResolver); err != nil {
+			errors = append(errors, "DNS resolver must be a host or host:port address")
+		} else {
+			req.DNSResolver = address
+		}
+	}
+
+	// Validate expected values
+	if len(req.DNSExpectedValues) > 20 {
+		errors = append(errors, "DNS expected values must contain no more than 20 entries")
+	}
+	expectedValues := make([]string, 0, len(req.DNSExpectedValues))
+	for _, value := range req.DNSExpectedValues {
+		valueResult := sanitizer.SanitizeString(strings.TrimSpace(value), "DNS expected value")
+		errors = append(errors, valueResult.Errors...)
+		if valueResult.Value == "" {
+			continue
+		}
+		errors = append(errors, sanitizer.ValidateStringLength(valueResult.Value, "DNS expected value", 1, 255)...)
+
+		if req.DNSRecordType == "A" || req.DNSRecordType == "AAAA" {
+			ip := net.ParseIP(valueResult.Value)
+			if ip == nil || (req.DNSRecordType == "A") != (ip.To4() != nil) {
+				errors = append(errors, fmt.Sprintf("DNS expected value %q is not a valid %s address", valueResult.Value, req.DNSRecordType))
+			}
+		}
+		expectedValues = append(expectedValues, valueResult.Value)
+	}
+	req.DNSExpectedValues = expectedValues
+
+	// HTTP-only fields keep their defaults so the row satisfies table constraints
+	req.Method = "GET"
+	req.ExpectedStatusCode = 200
+	req.Assertions = nil
+
+	return errors
+}
+
+// validateHeartbeatCheckRequest validates the fields used by heartbeat monitors
+func validateHeartbeatCheckRequest(req *EndpointRequest, sanitizer *security.Sanitizer) []string {
+	var errors []string
+
+	// Validate grace window
+	if req.HeartbeatGraceSeconds != nil {
+		graceErrors := sanitizer.ValidateIntRange(*req.HeartbeatGraceSeconds, "heartbeat grace period", 0, 86400)
+		errors = append(errors, graceErrors...)
+	}
+
+	// Heartbeats are pushed to Watchtower, so there is no target to probe
+	req.URL = ""
+	req.Method = "GET"
+	req.ExpectedStatusCode = 200
+	req.Assertions = nil
 
 	return errors
 }
@@ -0,0 +1,133 @@
+package data
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EscalationLevel notifies its channels once the previous level has gone unacknowledged for
+// DelayMinutes, the first level counts from the start of the incident
+type EscalationLevel struct {
+	DelayMinutes int         `json:"delay_minutes"`
+	ChannelIDs   []uuid.UUID `json:"channel_ids"`
+}
+
+// EscalationLevels represents a JSON array of escalation levels
+type EscalationLevels []EscalationLevel
+
+// Value implements the driver.Valuer interface for database storage
+func (l EscalationLevels) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	return json.Marshal(l)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (l *EscalationLevels) Scan(value interface{}) error {
+	if value == nil {
+		*l = make(EscalationLevels, 0)
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return errors.New("cannot scan non-string value into EscalationLevels")
+	}
+}
+
+// EscalationPolicy escalates unacknowledged incidents of the listed severities, an empty list
+// matches every severity
+type EscalationPolicy struct {
+	ID          uuid.UUID        `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Name        string           `json:"name" gorm:"not null"`
+	Description string           `json:"description"`
+	Enabled     bool             `json:"enabled" gorm:"not null"`
+	Severities  StringList       `json:"severities" gorm:"type:jsonb;not null;default:'[]'"`
+	Levels      EscalationLevels `json:"levels" gorm:"type:jsonb;not null;default:'[]'"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// TableName sets the table name to singular form
+func (EscalationPolicy) TableName() string {
+	return "escalation_policy"
+}
+
+// EscalationPolicy database operations
+func (db *DB) CreateEscalationPolicy(policy *EscalationPolicy) error {
+	return db.DB.Create(policy).Error
+}
+
+func (db *DB) GetEscalationPolicy(id uuid.UUID) (*EscalationPolicy, error) {
+	var policy EscalationPolicy
+	err := db.DB.First(&policy, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (db *DB) GetEscalationPolicies() ([]EscalationPolicy, error) {
+	var policies []EscalationPolicy
+	err := db.DB.Order("name ASC").Find(&policies).Error
+	return policies, err
+}
+
+// GetEscalationPolicyByName gets the policy with the given name
+func (db *DB) GetEscalationPolicyByName(name string) (*EscalationPolicy, error) {
+	var policy EscalationPolicy
+	err := db.DB.Where("name = ?", name).First(&policy).Error
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (db *DB) UpdateEscalationPolicy(policy *EscalationPolicy) error {
+	return db.DB.Save(policy).Error
+}
+
+func (db *DB) DeleteEscalationPolicy(id uuid.UUID) error {
+	return db.DB.Delete(&EscalationPolicy{}, id).Error
+}
+
+// GetIncidentsDueForEscalation gets unresolved, unacknowledged incidents whose next escalation
+// is due, with their endpoints preloaded
+func (db *DB) GetIncidentsDueForEscalation(now time.Time) ([]Incident, error) {
+	var incidents []Incident
+	err := db.DB.Where("status != ? AND acknowledged_at IS NULL AND next_escalation_at <= ?", "resolved", now).
+		Preload("EndpointIncidents.Endpoint").
+		Order("next_escalation_at ASC").
+		Find(&incidents).Error
+	return incidents, err
+}
+
+// UpdateIncidentEscalation saves only the escalation progress of an incident so concurrent
+// changes to the incident are kept
+func (db *DB) UpdateIncidentEscalation(incident *Incident) error {
+	return db.DB.Model(incident).
+		Select("escalation_policy_id", "escalation_level", "next_escalation_at").
+		Updates(incident).Error
+}
+
+// AcknowledgeIncident marks an unacknowledged incident as acknowledged and stops its
+// escalation. It returns false if the incident was already acknowledged
+func (db *DB) AcknowledgeIncident(id uuid.UUID, userID *uuid.UUID, at time.Time) (bool, error) {
+	result := db.DB.Model(&Incident{}).
+		Where("id = ? AND acknowledged_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"acknowledged_at":    at,
+			"acknowledged_by":    userID,
+			"next_escalation_at": nil,
+		})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,65 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Maintenance window recurrences
+const (
+	MaintenanceRecurrenceNone    = ""
+	MaintenanceRecurrenceDaily   = "daily"
+	MaintenanceRecurrenceWeekly  = "weekly"
+	MaintenanceRecurrenceMonthly = "monthly"
+)
+
+// MaintenanceWindow is planned downtime for some or all endpoints. Failures during it open no
+// incidents and send no notifications, and its checks are left out of uptime. Recurring windows
+// repeat at the same wall clock time in the window's timezone until RecurrenceUntil
+type MaintenanceWindow struct {
+	ID              uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Title           string     `json:"title" gorm:"not null"`
+	Description     string     `json:"description"`
+	StartTime       time.Time  `json:"start_time" gorm:"not null"` // first occurrence
+	EndTime         time.Time  `json:"end_time" gorm:"not null"`
+	Timezone        string     `json:"timezone" gorm:"not null;default:UTC"`
+	Recurrence      string     `json:"recurrence" gorm:"not null;default:''"`
+	RecurrenceUntil *time.Time `json:"recurrence_until"`                                     // no occurrence starts after it, forever when nil
+	EndpointIDs     UUIDList   `json:"endpoint_ids" gorm:"type:jsonb;not null;default:'[]'"` // every endpoint when empty
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// TableName sets the table name to singular form
+func (MaintenanceWindow) TableName() string {
+	return "maintenance_window"
+}
+
+// MaintenanceWindow database operations
+func (db *DB) CreateMaintenanceWindow(window *MaintenanceWindow) error {
+	return db.DB.Create(window).Error
+}
+
+func (db *DB) GetMaintenanceWindow(id uuid.UUID) (*MaintenanceWindow, error) {
+	var window MaintenanceWindow
+	err := db.DB.First(&window, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &window, nil
+}
+
+func (db *DB) GetMaintenanceWindows() ([]MaintenanceWindow, error) {
+	var windows []MaintenanceWindow
+	err := db.DB.Order("start_time DESC").Find(&windows).Error
+	return windows, err
+}
+
+func (db *DB) UpdateMaintenanceWindow(window *MaintenanceWindow) error {
+	return db.DB.Save(window).Error
+}
+
+func (db *DB) DeleteMaintenanceWindow(id uuid.UUID) error {
+	return db.DB.Delete(&MaintenanceWindow{}, id).Error
+}
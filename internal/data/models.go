@@ -4,11 +4,14 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"math"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type User struct {
@@ -110,21 +113,141 @@ func (h *HTTPHeaders) Scan(value interface{}) error {
 	}
 }
 
+// StringList represents a JSON array of strings
+type StringList []string
+
+// Value implements the driver.Valuer interface for database storage
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	return json.Marshal(l)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (l *StringList) Scan(value interface{}) error {
+	if value == nil {
+		*l = make(StringList, 0)
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return errors.New("cannot scan non-string value into StringList")
+	}
+}
+
+// Assertion types supported by response assertions
+const (
+	AssertionBodyContains    = "body_contains"
+	AssertionBodyNotContains = "body_not_contains"
+	AssertionBodyMatches     = "body_matches"
+	AssertionJSONPath        = "json_path"
+	AssertionHeader          = "header"
+)
+
+// ValidAssertionTypes lists the assertion types an endpoint can be configured with
+var ValidAssertionTypes = []string{AssertionBodyContains, AssertionBodyNotContains, AssertionBodyMatches, AssertionJSONPath, AssertionHeader}
+
+// Operators used by JSONPath and header assertions
+const (
+	AssertionOpExists             = "exists"
+	AssertionOpNotExists          = "not_exists"
+	AssertionOpEquals             = "equals"
+	AssertionOpNotEquals          = "not_equals"
+	AssertionOpContains           = "contains"
+	AssertionOpMatches            = "matches"
+	AssertionOpGreaterThan        = "greater_than"
+	AssertionOpGreaterThanOrEqual = "greater_than_or_equal"
+	AssertionOpLessThan           = "less_than"
+	AssertionOpLessThanOrEqual    = "less_than_or_equal"
+)
+
+// ResponseAssertion is a rule the response of an HTTP check must satisfy
+type ResponseAssertion struct {
+	Type     string `json:"type"`
+	Path     string `json:"path,omitempty"`     // JSONPath expression or header name
+	Operator string `json:"operator,omitempty"` // only used by json_path and header assertions
+	Value    string `json:"value,omitempty"`
+}
+
+// ResponseAssertions represents a JSON array of response assertions
+type ResponseAssertions []ResponseAssertion
+
+// Value implements the driver.Valuer interface for database storage
+func (a ResponseAssertions) Value() (driver.Value, error) {
+	if a == nil {
+		return "[]", nil
+	}
+	return json.Marshal(a)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (a *ResponseAssertions) Scan(value interface{}) error {
+	if value == nil {
+		*a = make(ResponseAssertions, 0)
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	default:
+		return errors.New("cannot scan non-string value into ResponseAssertions")
+	}
+}
+
+// Check types supported by endpoints
+const (
+	CheckTypeHTTP      = "http"
+	CheckTypeTCP       = "tcp"
+	CheckTypeDNS       = "dns"
+	CheckTypeHeartbeat = "heartbeat"
+)
+
+// ValidCheckTypes lists the check types an endpoint can be configured with
+var ValidCheckTypes = []string{CheckTypeHTTP, CheckTypeTCP, CheckTypeDNS, CheckTypeHeartbeat}
+
+// ValidDNSRecordTypes lists the record types a DNS check can query
+var ValidDNSRecordTypes = []string{"A", "AAAA", "CNAME", "MX", "TXT"}
+
 // Endpoint represents a monitoring target
 type Endpoint struct {
-	ID                   uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
-	Name                 string      `json:"name" gorm:"not null"`
-	Description          string      `json:"description"`
-	URL                  string      `json:"url" gorm:"not null"`
-	Method               string      `json:"method" gorm:"default:GET"`
-	Headers              HTTPHeaders `json:"headers" gorm:"type:jsonb;default:'{}'"`
-	Body                 string      `json:"body"`
-	ExpectedStatusCode   int         `json:"expected_status_code" gorm:"default:200"`
-	TimeoutSeconds       int         `json:"timeout_seconds" gorm:"default:30"`
-	CheckIntervalSeconds int         `json:"check_interval_seconds" gorm:"default:300"`
-	Enabled              bool        `json:"enabled" gorm:"default:true"`
-	CreatedAt            time.Time   `json:"created_at"`
-	UpdatedAt            time.Time   `json:"updated_at"`
+	ID                    uuid.UUID          `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Name                  string             `json:"name" gorm:"not null"`
+	Description           string             `json:"description"`
+	CheckType             string             `json:"check_type" gorm:"default:http"`
+	URL                   string             `json:"url" gorm:"not null"` // host:port for TCP checks, hostname for DNS checks, unused for heartbeats
+	Method                string             `json:"method" gorm:"default:GET"`
+	Headers               HTTPHeaders        `json:"headers" gorm:"type:jsonb;default:'{}'"`
+	Body                  string             `json:"body"`
+	ExpectedStatusCode    int                `json:"expected_status_code" gorm:"default:200"`
+	Assertions            ResponseAssertions `json:"assertions" gorm:"type:jsonb;default:'[]'"`
+	TCPSend               string             `json:"tcp_send"`
+	TCPExpect             string             `json:"tcp_expect"`
+	DNSRecordType         string             `json:"dns_record_type"`
+	DNSResolver           string             `json:"dns_resolver"`
+	DNSExpectedValues     StringList         `json:"dns_expected_values" gorm:"type:jsonb;default:'[]'"`
+	CertExpiryWarningDays int                `json:"cert_expiry_warning_days" gorm:"default:14"`
+	TLSSkipVerify         bool               `json:"tls_skip_verify"`
+	HeartbeatToken        string             `json:"-"` // secret part of the ping URL, only exposed to admins
+	HeartbeatGraceSeconds int                `json:"heartbeat_grace_seconds"`
+	TimeoutSeconds        int                `json:"timeout_seconds" gorm:"default:30"`
+	MaxRetries            *int               `json:"max_retries"`                               // transport-level retries, nil uses the engine default
+	RetryDelayMs          *int               `json:"retry_delay_ms"`                            // base delay between retries, nil uses the engine default
+	ConfirmRetries        int                `json:"confirm_retries"`                           // times a failed check is re-run before the failure is recorded
+	CheckIntervalSeconds  int                `json:"check_interval_seconds" gorm:"default:300"` // expected ping period for heartbeat checks
+	Enabled               bool               `json:"enabled" gorm:"default:true"`
+	Tags                  StringList         `json:"tags" gorm:"type:jsonb;default:'[]'"`       // used to route notifications
+	ParentIDs             UUIDList           `json:"parent_ids" gorm:"type:jsonb;default:'[]'"` // endpoints it depends on, failures while one is down are attributed to its incident
+	CreatedAt             time.Time          `json:"created_at"`
+	UpdatedAt             time.Time          `json:"updated_at"`
 }
 
 // TableName sets the table name to singular form
@@ -132,6 +255,14 @@ func (Endpoint) TableName() string {
 	return "endpoint"
 }
 
+// GetCheckType returns the endpoint's check type, treating an empty value as HTTP
+func (e *Endpoint) GetCheckType() string {
+	if e.CheckType == "" {
+		return CheckTypeHTTP
+	}
+	return e.CheckType
+}
+
 // MonitoringLog represents a monitoring check result
 type MonitoringLog struct {
 	ID                 uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
@@ -143,6 +274,7 @@ type MonitoringLog struct {
 	ErrorMessage       *string   `json:"error_message"`
 	Success            bool      `json:"success" gorm:"not null"`
 	ResponseBodySample *string   `json:"response_body_sample"`
+	InMaintenance      bool      `json:"in_maintenance" gorm:"not null;default:false"` // run during a maintenance window, left out of uptime
 	CreatedAt          time.Time `json:"created_at"`
 }
 
@@ -151,6 +283,34 @@ func (MonitoringLog) TableName() string {
 	return "monitoring_log"
 }
 
+// EndpointCertificate stores the TLS certificate last presented by an HTTPS endpoint
+type EndpointCertificate struct {
+	ID           uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	EndpointID   uuid.UUID  `json:"endpoint_id" gorm:"type:uuid;not null;uniqueIndex"`
+	Endpoint     *Endpoint  `json:"endpoint,omitempty" gorm:"foreignKey:EndpointID"`
+	Subject      string     `json:"subject"`
+	Issuer       string     `json:"issuer"`
+	SANs         StringList `json:"sans" gorm:"column:sans;type:jsonb;default:'[]'"`
+	SerialNumber string     `json:"serial_number"`
+	NotBefore    time.Time  `json:"not_before"`
+	NotAfter     time.Time  `json:"not_after"`
+	ChainValid   bool       `json:"chain_valid"`
+	ChainError   string     `json:"chain_error"`
+	CheckedAt    time.Time  `json:"checked_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName sets the table name to singular form
+func (EndpointCertificate) TableName() string {
+	return "endpoint_certificate"
+}
+
+// DaysRemaining returns the number of whole days until the certificate expires
+func (c *EndpointCertificate) DaysRemaining(now time.Time) int {
+	return int(math.Floor(c.NotAfter.Sub(now).Hours() / 24))
+}
+
 // Endpoint database operations
 func (db *DB) CreateEndpoint(endpoint *Endpoint) error {
 	return db.DB.Create(endpoint).Error
@@ -165,12 +325,32 @@ func (db *DB) GetEndpoint(id uuid.UUID) (*Endpoint, error) {
 	return &endpoint, nil
 }
 
+// GetEndpointByHeartbeatToken finds the heartbeat endpoint pinged through the given token
+func (db *DB) GetEndpointByHeartbeatToken(token string) (*Endpoint, error) {
+	var endpoint Endpoint
+	err := db.DB.Where("heartbeat_token = ? AND check_type = ?", token, CheckTypeHeartbeat).First(&endpoint).Error
+	if err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
 func (db *DB) GetEndpoints() ([]Endpoint, error) {
 	var endpoints []Endpoint
 	err := db.DB.Order("created_at DESC").Find(&endpoints).Error
 	return endpoints, err
 }
 
+// GetEndpointsByIDs gets the endpoints with the given IDs
+func (db *DB) GetEndpointsByIDs(ids []uuid.UUID) ([]Endpoint, error) {
+	var endpoints []Endpoint
+	if len(ids) == 0 {
+		return endpoints, nil
+	}
+	err := db.DB.Where("id IN ?", ids).Order("name ASC").Find(&endpoints).Error
+	return endpoints, err
+}
+
 // GetEndpointsWithPagination gets endpoints with pagination and filtering
 func (db *DB) GetEndpointsWithPagination(page, limit int, enabled *bool) ([]Endpoint, int64, error) {
 	var endpoints []Endpoint
@@ -205,8 +385,17 @@ func (db *DB) UpdateEndpoint(endpoint *Endpoint) error {
 	return db.DB.Save(endpoint).Error
 }
 
+// DeleteEndpoint deletes an endpoint and removes it from the parents of the endpoints depending on it
 func (db *DB) DeleteEndpoint(id uuid.UUID) error {
-	return db.DB.Delete(&Endpoint{}, id).Error
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&Endpoint{}).
+			Where("parent_ids @> jsonb_build_array(?::text)", id.String()).
+			Update("parent_ids", gorm.Expr("parent_ids - ?::text", id.String())).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&Endpoint{}, id).Error
+	})
 }
 
 // MonitoringLog database operations
@@ -231,6 +420,21 @@ func (db *DB) GetRecentMonitoringLogs(hours int) ([]MonitoringLog, error) {
 	return logs, err
 }
 
+// GetLatestMonitoringLogs gets up to perEndpoint of the most recent monitoring logs for
+// every endpoint, most recent first
+func (db *DB) GetLatestMonitoringLogs(perEndpoint int) ([]MonitoringLog, error) {
+	var logs []MonitoringLog
+	err := db.DB.Raw(`
+		SELECT id, endpoint_id, timestamp, status_code, response_time_ms, error_message, success, response_body_sample, in_maintenance, created_at
+		FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY endpoint_id ORDER BY timestamp DESC) AS row_num
+			FROM monitoring_log
+		) ranked
+		WHERE row_num <= ?
+		ORDER BY endpoint_id, timestamp DESC`, perEndpoint).Scan(&logs).Error
+	return logs, err
+}
+
 // GetMonitoringLogsWithPagination gets monitoring logs with proper pagination
 func (db *DB) GetMonitoringLogsWithPagination(page, limit, hours int, endpointID *uuid.UUID, success *bool) ([]MonitoringLog, int64, error) {
 	var logs []MonitoringLog
@@ -274,7 +478,8 @@ func (db *DB) GetMonitoringLogsByDateRange(endpointID uuid.UUID, startDate, endD
 	return logs, err
 }
 
-// GetUptimeStats calculates uptime statistics for an endpoint over a period
+// GetUptimeStats calculates uptime statistics for an endpoint over a period, leaving out checks
+// run during maintenance windows
 func (db *DB) GetUptimeStats(endpointID uuid.UUID, days int) (float64, error) {
 	cutoff := time.Now().AddDate(0, 0, -days)
 
@@ -282,7 +487,7 @@ func (db *DB) GetUptimeStats(endpointID uuid.UUID, days int) (float64, error) {
 
 	// Count total checks
 	err := db.DB.Model(&MonitoringLog{}).
-		Where("endpoint_id = ? AND timestamp > ?", endpointID, cutoff).
+		Where("endpoint_id = ? AND timestamp > ? AND in_maintenance = false", endpointID, cutoff).
 		Count(&total).Error
 	if err != nil {
 		return 0, err
@@ -290,7 +495,7 @@ func (db *DB) GetUptimeStats(endpointID uuid.UUID, days int) (float64, error) {
 
 	// Count successful checks
 	err = db.DB.Model(&MonitoringLog{}).
-		Where("endpoint_id = ? AND timestamp > ? AND success = true", endpointID, cutoff).
+		Where("endpoint_id = ? AND timestamp > ? AND in_maintenance = false AND success = true", endpointID, cutoff).
 		Count(&successful).Error
 	if err != nil {
 		return 0, err
@@ -310,7 +515,7 @@ func (db *DB) GetLatestMonitoringStatus() (map[uuid.UUID]MonitoringLog, error) {
 	// Get the latest log for each endpoint
 	err := db.DB.Raw(`
 		SELECT DISTINCT ON (endpoint_id) endpoint_id, id, timestamp, status_code, 
-		       response_time_ms, error_message, success, response_body_sample, created_at
+		       response_time_ms, error_message, success, response_body_sample, in_maintenance, created_at
 		FROM monitoring_log
 		ORDER BY endpoint_id, timestamp DESC
 	`).Scan(&logs).Error
@@ -327,6 +532,36 @@ func (db *DB) GetLatestMonitoringStatus() (map[uuid.UUID]MonitoringLog, error) {
 	return result, nil
 }
 
+// EndpointCertificate database operations
+
+// UpsertEndpointCertificate stores the latest certificate seen for an endpoint
+func (db *DB) UpsertEndpointCertificate(cert *EndpointCertificate) error {
+	return db.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "endpoint_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"subject", "issuer", "sans", "serial_number", "not_before", "not_after",
+			"chain_valid", "chain_error", "checked_at", "updated_at",
+		}),
+	}).Create(cert).Error
+}
+
+// GetEndpointCertificate gets the latest certificate recorded for an endpoint
+func (db *DB) GetEndpointCertificate(endpointID uuid.UUID) (*EndpointCertificate, error) {
+	var cert EndpointCertificate
+	err := db.DB.Where("endpoint_id = ?", endpointID).First(&cert).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// GetEndpointCertificates gets the latest certificates for all endpoints with their endpoint details
+func (db *DB) GetEndpointCertificates() ([]EndpointCertificate, error) {
+	var certs []EndpointCertificate
+	err := db.DB.Preload("Endpoint").Order("not_after ASC").Find(&certs).Error
+	return certs, err
+}
+
 // Incident represents a system incident
 type Incident struct {
 	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
@@ -337,8 +572,16 @@ type Incident struct {
 	StartTime   time.Time  `json:"start_time" gorm:"default:now()"`
 	EndTime     *time.Time `json:"end_time"`
 	CreatedBy   *uuid.UUID `json:"created_by" gorm:"type:uuid"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	AutoCreated bool       `json:"auto_created" gorm:"not null;default:false"`
+	AutoReason  string     `json:"auto_reason,omitempty" gorm:"type:varchar(32);not null;default:''"`
+	// AcknowledgedAt is set once someone has taken the incident, which stops escalation
+	AcknowledgedAt     *time.Time `json:"acknowledged_at"`
+	AcknowledgedBy     *uuid.UUID `json:"acknowledged_by" gorm:"type:uuid"`
+	EscalationPolicyID *uuid.UUID `json:"escalation_policy_id" gorm:"type:uuid"`
+	EscalationLevel    int        `json:"escalation_level" gorm:"not null;default:0"` // escalation levels notified so far
+	NextEscalationAt   *time.Time `json:"next_escalation_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
 
 	// Relationships
 	Creator           *User              `json:"creator,omitempty" gorm:"foreignKey:CreatedBy"`
@@ -350,6 +593,12 @@ func (Incident) TableName() string {
 	return "incident"
 }
 
+// Reasons recorded on incidents opened by the incident detector
+const (
+	IncidentAutoReasonEndpointDown      = "endpoint_down"
+	IncidentAutoReasonCertificateExpiry = "certificate_expiry"
+)
+
 // EndpointIncident represents the junction table linking incidents to endpoints
 type EndpointIncident struct {
 	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
@@ -357,7 +606,9 @@ type EndpointIncident struct {
 	IncidentID    uuid.UUID  `json:"incident_id" gorm:"type:uuid;not null"`
 	AffectedStart time.Time  `json:"affected_start" gorm:"default:now()"`
 	AffectedEnd   *time.Time `json:"affected_end"`
-	CreatedAt     time.Time  `json:"created_at"`
+	// ParentEndpointID is set when the endpoint is affected through a down parent it depends on
+	ParentEndpointID *uuid.UUID `json:"parent_endpoint_id" gorm:"type:uuid"`
+	CreatedAt        time.Time  `json:"created_at"`
 
 	// Relationships
 	Endpoint *Endpoint `json:"endpoint,omitempty" gorm:"foreignKey:EndpointID"`
@@ -446,6 +697,18 @@ func (db *DB) GetEndpointIncidents(incidentID uuid.UUID) ([]EndpointIncident, er
 	return endpointIncidents, err
 }
 
+// GetOpenAutoEndpointIncidents gets the unresolved endpoint associations of incidents
+// opened by the incident detector, with the incident preloaded
+func (db *DB) GetOpenAutoEndpointIncidents() ([]EndpointIncident, error) {
+	var endpointIncidents []EndpointIncident
+	err := db.DB.Joins("JOIN incident ON incident.id = endpoint_incident.incident_id").
+		Where("incident.auto_created = ? AND incident.status != ? AND endpoint_incident.affected_end IS NULL", true, "resolved").
+		Preload("Incident").
+		Order("endpoint_incident.affected_start ASC").
+		Find(&endpointIncidents).Error
+	return endpointIncidents, err
+}
+
 func (db *DB) GetIncidentsByEndpoint(endpointID uuid.UUID) ([]Incident, error) {
 	var incidents []Incident
 	err := db.DB.Joins("JOIN endpoint_incident ON incident.id = endpoint_incident.incident_id").
@@ -487,6 +750,8 @@ func (IncidentTimeline) TableName() string {
 var ValidEventTypes = []string{
 	"created",
 	"update",
+	"acknowledged",
+	"escalated",
 }
 
 // Validate validates the incident timeline entry
@@ -508,7 +773,7 @@ func (it *IncidentTimeline) Validate() error {
 		}
 	}
 	if !validType {
-		return errors.New("invalid event_type: must be 'created' or 'update'")
+		return errors.New("invalid event_type: must be one of " + strings.Join(ValidEventTypes, ", "))
 	}
 
 	// Message is required for updates
@@ -0,0 +1,411 @@
+package data
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JSONMap represents a JSON object with arbitrary values
+type JSONMap map[string]interface{}
+
+// Value implements the driver.Valuer interface for database storage
+func (m JSONMap) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	return json.Marshal(m)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (m *JSONMap) Scan(value interface{}) error {
+	if value == nil {
+		*m = make(JSONMap)
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, m)
+	case string:
+		return json.Unmarshal([]byte(v), m)
+	default:
+		return errors.New("cannot scan non-string value into JSONMap")
+	}
+}
+
+// UUIDList represents a JSON array of UUIDs
+type UUIDList []uuid.UUID
+
+// Value implements the driver.Valuer interface for database storage
+func (l UUIDList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	return json.Marshal(l)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (l *UUIDList) Scan(value interface{}) error {
+	if value == nil {
+		*l = make(UUIDList, 0)
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return errors.New("cannot scan non-string value into UUIDList")
+	}
+}
+
+// MessageTemplate overrides the title and message of one notification type on a channel. Title
+// and Body are text/template sources, HTML is an html/template source used by email channels
+type MessageTemplate struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+	HTML  string `json:"html,omitempty"`
+}
+
+// MessageTemplates represents a JSON object of message templates keyed by notification type
+type MessageTemplates map[string]MessageTemplate
+
+// Value implements the driver.Valuer interface for database storage
+func (t MessageTemplates) Value() (driver.Value, error) {
+	if t == nil {
+		return "{}", nil
+	}
+	return json.Marshal(t)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (t *MessageTemplates) Scan(value interface{}) error {
+	if value == nil {
+		*t = make(MessageTemplates)
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	default:
+		return errors.New("cannot scan non-string value into MessageTemplates")
+	}
+}
+
+// QuietHours holds back a channel's notifications below a severity between Start and End, times
+// of day in Timezone. Held notifications are dropped or, with a Digest of hourly or daily, sent
+// together once the quiet window ends. A digest also batches notifications below the severity
+// outside quiet hours, in which case Start and End may be empty
+type QuietHours struct {
+	Start       string `json:"start,omitempty"`        // HH:MM
+	End         string `json:"end,omitempty"`          // HH:MM, before Start for windows spanning midnight
+	Timezone    string `json:"timezone,omitempty"`     // IANA name, UTC when empty
+	MinSeverity string `json:"min_severity,omitempty"` // notifications at or above it are never held
+	Digest      string `json:"digest,omitempty"`       // empty, hourly or daily
+}
+
+// Value implements the driver.Valuer interface for database storage
+func (q QuietHours) Value() (driver.Value, error) {
+	return json.Marshal(q)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (q *QuietHours) Scan(value interface{}) error {
+	if value == nil {
+		*q = QuietHours{}
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, q)
+	case string:
+		return json.Unmarshal([]byte(v), q)
+	default:
+		return errors.New("cannot scan non-string value into QuietHours")
+	}
+}
+
+// NotificationChannel is a named, configured notification provider instance
+type NotificationChannel struct {
+	ID         uuid.UUID        `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Name       string           `json:"name" gorm:"not null"`
+	Type       string           `json:"type" gorm:"type:varchar(32);not null"`
+	Enabled    bool             `json:"enabled" gorm:"not null"`
+	Settings   JSONMap          `json:"settings" gorm:"type:jsonb;not null;default:'{}'"`
+	Templates  MessageTemplates `json:"templates" gorm:"type:jsonb;not null;default:'{}'"`
+	RateLimit  *int             `json:"rate_limit"` // notifications per rate limit period, nil uses the default and 0 disables the limit
+	QuietHours *QuietHours      `json:"quiet_hours" gorm:"type:jsonb"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+}
+
+// TableName sets the table name to singular form
+func (NotificationChannel) TableName() string {
+	return "notification_channel"
+}
+
+// NotificationChannel database operations
+func (db *DB) CreateNotificationChannel(channel *NotificationChannel) error {
+	return db.DB.Create(channel).Error
+}
+
+func (db *DB) GetNotificationChannel(id uuid.UUID) (*NotificationChannel, error) {
+	var channel NotificationChannel
+	err := db.DB.First(&channel, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &channel, nil
+}
+
+func (db *DB) GetNotificationChannels() ([]NotificationChannel, error) {
+	var channels []NotificationChannel
+	err := db.DB.Order("type ASC, name ASC").Find(&channels).Error
+	return channels, err
+}
+
+// GetNotificationChannelByName gets the channel of the given type with the given name
+func (db *DB) GetNotificationChannelByName(channelType, name string) (*NotificationChannel, error) {
+	var channel NotificationChannel
+	err := db.DB.Where("type = ? AND name = ?", channelType, name).First(&channel).Error
+	if err != nil {
+		return nil, err
+	}
+	return &channel, nil
+}
+
+func (db *DB) UpdateNotificationChannel(channel *NotificationChannel) error {
+	return db.DB.Save(channel).Error
+}
+
+func (db *DB) DeleteNotificationChannel(id uuid.UUID) error {
+	return db.DB.Delete(&NotificationChannel{}, id).Error
+}
+
+// NotificationRoute sends events matching all of its non-empty criteria to its channels
+type NotificationRoute struct {
+	ID           uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Name         string     `json:"name" gorm:"not null"`
+	Enabled      bool       `json:"enabled" gorm:"not null"`
+	EventTypes   StringList `json:"event_types" gorm:"type:jsonb;not null;default:'[]'"`
+	Severities   StringList `json:"severities" gorm:"type:jsonb;not null;default:'[]'"`
+	EndpointIDs  UUIDList   `json:"endpoint_ids" gorm:"type:jsonb;not null;default:'[]'"`
+	EndpointTags StringList `json:"endpoint_tags" gorm:"type:jsonb;not null;default:'[]'"`
+	ChannelIDs   UUIDList   `json:"channel_ids" gorm:"type:jsonb;not null;default:'[]'"`
+	// OnCallScheduleIDs send the route's email to whoever is on call instead of the channel's recipients
+	OnCallScheduleIDs UUIDList  `json:"oncall_schedule_ids" gorm:"column:oncall_schedule_ids;type:jsonb;not null;default:'[]'"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// TableName sets the table name to singular form
+func (NotificationRoute) TableName() string {
+	return "notification_route"
+}
+
+// NotificationRoute database operations
+func (db *DB) CreateNotificationRoute(route *NotificationRoute) error {
+	return db.DB.Create(route).Error
+}
+
+func (db *DB) GetNotificationRoute(id uuid.UUID) (*NotificationRoute, error) {
+	var route NotificationRoute
+	err := db.DB.First(&route, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &route, nil
+}
+
+func (db *DB) GetNotificationRoutes() ([]NotificationRoute, error) {
+	var routes []NotificationRoute
+	err := db.DB.Order("name ASC").Find(&routes).Error
+	return routes, err
+}
+
+// GetNotificationRouteByName gets the route with the given name
+func (db *DB) GetNotificationRouteByName(name string) (*NotificationRoute, error) {
+	var route NotificationRoute
+	err := db.DB.Where("name = ?", name).First(&route).Error
+	if err != nil {
+		return nil, err
+	}
+	return &route, nil
+}
+
+func (db *DB) UpdateNotificationRoute(route *NotificationRoute) error {
+	return db.DB.Save(route).Error
+}
+
+func (db *DB) DeleteNotificationRoute(id uuid.UUID) error {
+	return db.DB.Delete(&NotificationRoute{}, id).Error
+}
+
+// Notification delivery statuses
+const (
+	NotificationDeliveryPending   = "pending"
+	NotificationDeliveryDelivered = "delivered"
+	NotificationDeliveryFailed    = "failed"
+	// NotificationDeliverySuppressed deliveries were never sent, SuppressedReason says why
+	NotificationDeliverySuppressed = "suppressed"
+	// NotificationDeliveryHeld deliveries wait for their channel's next digest
+	NotificationDeliveryHeld = "held"
+)
+
+// DeliveryAttempt records a single attempt to deliver a notification
+type DeliveryAttempt struct {
+	Attempt     int       `json:"attempt"`
+	AttemptedAt time.Time `json:"attempted_at"`
+	Success     bool      `json:"success"`
+	Error       string    `json:"error,omitempty"`
+	Response    string    `json:"response,omitempty"`
+	DurationMs  int64     `json:"duration_ms"`
+}
+
+// DeliveryAttempts represents a JSON array of delivery attempts
+type DeliveryAttempts []DeliveryAttempt
+
+// Value implements the driver.Valuer interface for database storage
+func (a DeliveryAttempts) Value() (driver.Value, error) {
+	if a == nil {
+		return "[]", nil
+	}
+	return json.Marshal(a)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (a *DeliveryAttempts) Scan(value interface{}) error {
+	if value == nil {
+		*a = make(DeliveryAttempts, 0)
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	default:
+		return errors.New("cannot scan non-string value into DeliveryAttempts")
+	}
+}
+
+// NotificationDelivery is an outbox entry for one event sent through one channel. Channel
+// details are copied so the history survives the channel being deleted
+type NotificationDelivery struct {
+	ID               uuid.UUID        `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	EventID          uuid.UUID        `json:"event_id" gorm:"type:uuid;not null"` // shared by the deliveries of one event
+	EventType        string           `json:"event_type" gorm:"not null"`
+	Title            string           `json:"title"`
+	Severity         string           `json:"severity"`
+	ChannelID        uuid.UUID        `json:"channel_id" gorm:"type:uuid;not null"`
+	ChannelName      string           `json:"channel_name"`
+	ChannelType      string           `json:"channel_type"`
+	Payload          JSONMap          `json:"payload" gorm:"type:jsonb;not null;default:'{}'"`
+	Status           string           `json:"status" gorm:"not null;default:pending"`
+	Attempts         int              `json:"attempts" gorm:"not null"`
+	MaxAttempts      int              `json:"max_attempts" gorm:"not null"`
+	NextAttemptAt    time.Time        `json:"next_attempt_at" gorm:"not null"`
+	LastError        string           `json:"last_error"`
+	ProviderResponse string           `json:"provider_response"`
+	SuppressedReason string           `json:"suppressed_reason,omitempty"`
+	AttemptLog       DeliveryAttempts `json:"attempt_log" gorm:"type:jsonb;not null;default:'[]'"`
+	DeliveredAt      *time.Time       `json:"delivered_at"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+}
+
+// TableName sets the table name to singular form
+func (NotificationDelivery) TableName() string {
+	return "notification_delivery"
+}
+
+// NotificationDeliveryFilter narrows the delivery history, zero values match everything
+type NotificationDeliveryFilter struct {
+	Status    string
+	EventType string
+	ChannelID *uuid.UUID
+	EventID   *uuid.UUID
+}
+
+// NotificationDelivery database operations
+func (db *DB) CreateNotificationDeliveries(deliveries []NotificationDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return db.DB.Create(&deliveries).Error
+}
+
+func (db *DB) GetNotificationDelivery(id uuid.UUID) (*NotificationDelivery, error) {
+	var delivery NotificationDelivery
+	err := db.DB.First(&delivery, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// GetDueNotificationDeliveries gets pending deliveries whose next attempt is due, oldest first
+func (db *DB) GetDueNotificationDeliveries(now time.Time, limit int) ([]NotificationDelivery, error) {
+	var deliveries []NotificationDelivery
+	err := db.DB.Where("status = ? AND next_attempt_at <= ?", NotificationDeliveryPending, now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// GetHeldNotificationDeliveries gets the deliveries held for digests, oldest first
+func (db *DB) GetHeldNotificationDeliveries() ([]NotificationDelivery, error) {
+	var deliveries []NotificationDelivery
+	err := db.DB.Where("status = ?", NotificationDeliveryHeld).
+		Order("created_at ASC").
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (db *DB) UpdateNotificationDelivery(delivery *NotificationDelivery) error {
+	return db.DB.Save(delivery).Error
+}
+
+// GetNotificationDeliveriesWithPagination gets the delivery history, newest first
+func (db *DB) GetNotificationDeliveriesWithPagination(page, limit int, filter NotificationDeliveryFilter) ([]NotificationDelivery, int64, error) {
+	var deliveries []NotificationDelivery
+	var total int64
+
+	query := db.DB.Model(&NotificationDelivery{})
+
+	// Apply filters
+	if filter.Status != "" && filter.Status != "all" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.EventType != "" {
+		query = query.Where("event_type = ?", filter.EventType)
+	}
+	if filter.ChannelID != nil {
+		query = query.Where("channel_id = ?", *filter.ChannelID)
+	}
+	if filter.EventID != nil {
+		query = query.Where("event_id = ?", *filter.EventID)
+	}
+
+	// Get total count
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&deliveries).Error
+
+	return deliveries, total, err
+}
@@ -0,0 +1,120 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// On-call rotation types
+const (
+	OnCallRotationDaily  = "daily"
+	OnCallRotationWeekly = "weekly"
+)
+
+// OnCallSchedule rotates its participants in order, handing off daily or weekly at the handoff
+// time in the schedule's timezone. The first participant's shift is the one containing
+// RotationStart
+type OnCallSchedule struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Name           string    `json:"name" gorm:"not null"`
+	Description    string    `json:"description"`
+	Timezone       string    `json:"timezone" gorm:"not null;default:UTC"`
+	RotationType   string    `json:"rotation_type" gorm:"not null;default:weekly"`
+	HandoffTime    string    `json:"handoff_time" gorm:"not null;default:09:00"` // HH:MM
+	HandoffWeekday int       `json:"handoff_weekday" gorm:"not null;default:1"`  // 0 is Sunday, weekly rotations only
+	RotationStart  time.Time `json:"rotation_start" gorm:"not null;default:now()"`
+	Participants   UUIDList  `json:"participants" gorm:"type:jsonb;not null;default:'[]'"` // user IDs in rotation order
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName sets the table name to singular form
+func (OnCallSchedule) TableName() string {
+	return "oncall_schedule"
+}
+
+// OnCallOverride puts a user on call for a schedule in place of the rotation
+type OnCallOverride struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	ScheduleID uuid.UUID `json:"schedule_id" gorm:"type:uuid;not null"`
+	UserID     uuid.UUID `json:"user_id" gorm:"type:uuid;not null"`
+	StartTime  time.Time `json:"start_time" gorm:"not null"`
+	EndTime    time.Time `json:"end_time" gorm:"not null"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	// Relationships
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// TableName sets the table name to singular form
+func (OnCallOverride) TableName() string {
+	return "oncall_override"
+}
+
+// OnCallSchedule database operations
+func (db *DB) CreateOnCallSchedule(schedule *OnCallSchedule) error {
+	return db.DB.Create(schedule).Error
+}
+
+func (db *DB) GetOnCallSchedule(id uuid.UUID) (*OnCallSchedule, error) {
+	var schedule OnCallSchedule
+	err := db.DB.First(&schedule, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (db *DB) GetOnCallSchedules() ([]OnCallSchedule, error) {
+	var schedules []OnCallSchedule
+	err := db.DB.Order("name ASC").Find(&schedules).Error
+	return schedules, err
+}
+
+// GetOnCallScheduleByName gets the schedule with the given name
+func (db *DB) GetOnCallScheduleByName(name string) (*OnCallSchedule, error) {
+	var schedule OnCallSchedule
+	err := db.DB.Where("name = ?", name).First(&schedule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (db *DB) UpdateOnCallSchedule(schedule *OnCallSchedule) error {
+	return db.DB.Save(schedule).Error
+}
+
+func (db *DB) DeleteOnCallSchedule(id uuid.UUID) error {
+	return db.DB.Delete(&OnCallSchedule{}, id).Error
+}
+
+// OnCallOverride database operations
+func (db *DB) CreateOnCallOverride(override *OnCallOverride) error {
+	return db.DB.Create(override).Error
+}
+
+func (db *DB) GetOnCallOverride(id uuid.UUID) (*OnCallOverride, error) {
+	var override OnCallOverride
+	err := db.DB.Preload("User").First(&override, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+// GetOnCallOverrides gets a schedule's overrides overlapping the period, ordered by start time
+func (db *DB) GetOnCallOverrides(scheduleID uuid.UUID, from, to time.Time) ([]OnCallOverride, error) {
+	var overrides []OnCallOverride
+	err := db.DB.Where("schedule_id = ? AND start_time < ? AND end_time > ?", scheduleID, to, from).
+		Preload("User").
+		Order("start_time ASC").
+		Find(&overrides).Error
+	return overrides, err
+}
+
+func (db *DB) DeleteOnCallOverride(id uuid.UUID) error {
+	return db.DB.Delete(&OnCallOverride{}, id).Error
+}
@@ -0,0 +1,68 @@
+package dependency
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+// Graph is the dependency graph of endpoints. Each endpoint points to the parents it depends on
+type Graph struct {
+	parents map[uuid.UUID][]uuid.UUID
+}
+
+// NewGraph builds the graph of the endpoints' parents
+func NewGraph(endpoints []data.Endpoint) *Graph {
+	graph := &Graph{parents: make(map[uuid.UUID][]uuid.UUID, len(endpoints))}
+	for _, endpoint := range endpoints {
+		graph.parents[endpoint.ID] = slices.Clone(endpoint.ParentIDs)
+	}
+	return graph
+}
+
+// SetParents replaces the parents of an endpoint, adding it to the graph when it is new
+func (g *Graph) SetParents(id uuid.UUID, parents []uuid.UUID) {
+	g.parents[id] = slices.Clone(parents)
+}
+
+// Parents returns the endpoints the endpoint depends on directly
+func (g *Graph) Parents(id uuid.UUID) []uuid.UUID {
+	return g.parents[id]
+}
+
+// Children returns the endpoints depending directly on the endpoint, in no particular order
+func (g *Graph) Children(id uuid.UUID) []uuid.UUID {
+	var children []uuid.UUID
+	for child, parents := range g.parents {
+		if slices.Contains(parents, id) {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// Cycle returns a chain of dependencies leading from the endpoint back to itself, nil when
+// there is none. The endpoint appears at both ends of the chain
+func (g *Graph) Cycle(id uuid.UUID) []uuid.UUID {
+	visited := make(map[uuid.UUID]bool)
+
+	var walk func(current uuid.UUID, path []uuid.UUID) []uuid.UUID
+	walk = func(current uuid.UUID, path []uuid.UUID) []uuid.UUID {
+		for _, parent := range g.parents[current] {
+			if parent == id {
+				return append(slices.Clone(path), parent)
+			}
+			if visited[parent] {
+				continue
+			}
+			visited[parent] = true
+			if cycle := walk(parent, append(path, parent)); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+
+	return walk(id, []uuid.UUID{id})
+}
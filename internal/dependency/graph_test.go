@@ -0,0 +1,77 @@
+package dependency
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+func TestGraphCycle(t *testing.T) {
+	gateway, auth, api, web := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	graph := NewGraph([]data.Endpoint{
+		{ID: gateway},
+		{ID: auth, ParentIDs: data.UUIDList{gateway}},
+		{ID: api, ParentIDs: data.UUIDList{gateway, auth}},
+		{ID: web, ParentIDs: data.UUIDList{api}},
+	})
+
+	for _, id := range []uuid.UUID{gateway, auth, api, web} {
+		if cycle := graph.Cycle(id); cycle != nil {
+			t.Errorf("Expected no cycle, got %v", cycle)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		id       uuid.UUID
+		parents  []uuid.UUID
+		expected []uuid.UUID
+	}{
+		{"itself", gateway, []uuid.UUID{gateway}, []uuid.UUID{gateway, gateway}},
+		{"direct", gateway, []uuid.UUID{auth}, []uuid.UUID{gateway, auth, gateway}},
+		{"transitive", gateway, []uuid.UUID{web}, []uuid.UUID{gateway, web, api, gateway}},
+		{"shared parent is no cycle", web, []uuid.UUID{api, gateway}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := NewGraph([]data.Endpoint{
+				{ID: gateway},
+				{ID: auth, ParentIDs: data.UUIDList{gateway}},
+				{ID: api, ParentIDs: data.UUIDList{gateway, auth}},
+				{ID: web, ParentIDs: data.UUIDList{api}},
+			})
+			graph.SetParents(tt.id, tt.parents)
+
+			cycle := graph.Cycle(tt.id)
+			if tt.expected == nil {
+				if cycle != nil {
+					t.Errorf("Expected no cycle, got %v", cycle)
+				}
+				return
+			}
+			if cycle[0] != tt.id || cycle[len(cycle)-1] != tt.id || len(cycle) != len(tt.expected) {
+				t.Errorf("Expected cycle %v, got %v", tt.expected, cycle)
+			}
+		})
+	}
+}
+
+func TestGraphChildren(t *testing.T) {
+	gateway, api, web := uuid.New(), uuid.New(), uuid.New()
+	graph := NewGraph([]data.Endpoint{
+		{ID: gateway},
+		{ID: api, ParentIDs: data.UUIDList{gateway}},
+		{ID: web, ParentIDs: data.UUIDList{gateway, api}},
+	})
+
+	children := graph.Children(gateway)
+	if len(children) != 2 || !slices.Contains(children, api) || !slices.Contains(children, web) {
+		t.Errorf("Expected the API and website to depend on the gateway, got %v", children)
+	}
+	if children := graph.Children(web); len(children) != 0 {
+		t.Errorf("Expected nothing to depend on the website, got %v", children)
+	}
+}
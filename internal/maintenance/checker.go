@@ -0,0 +1,89 @@
+package maintenance
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+// Store loads maintenance windows
+type Store interface {
+	GetMaintenanceWindows() ([]data.MaintenanceWindow, error)
+}
+
+// Occurrence is a period during which a maintenance window is in effect
+type Occurrence struct {
+	Period
+	Window data.MaintenanceWindow `json:"window"`
+}
+
+// Checker answers whether endpoints are in maintenance. It keeps the windows in memory and
+// must be reloaded whenever they change
+type Checker struct {
+	store   Store
+	mu      sync.RWMutex
+	windows []data.MaintenanceWindow
+}
+
+// NewChecker creates a new checker reading from the store. It has no windows until reloaded
+func NewChecker(store Store) *Checker {
+	return &Checker{store: store}
+}
+
+// Reload loads the current windows from the store
+func (c *Checker) Reload() error {
+	windows, err := c.store.GetMaintenanceWindows()
+	if err != nil {
+		return fmt.Errorf("failed to load maintenance windows: %w", err)
+	}
+
+	c.mu.Lock()
+	c.windows = windows
+	c.mu.Unlock()
+	return nil
+}
+
+// InMaintenance returns whether a window covering the endpoint is in effect at the time
+func (c *Checker) InMaintenance(endpointID uuid.UUID, at time.Time) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for i := range c.windows {
+		window := &c.windows[i]
+		if !Covers(window, endpointID) {
+			continue
+		}
+		// Windows are validated when saved, one that fails here never applies
+		if period, err := ActivePeriod(window, at); err == nil && period != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Occurrences returns the occurrences of every window overlapping the period from to to,
+// ordered by start
+func (c *Checker) Occurrences(from, to time.Time) []Occurrence {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var occurrences []Occurrence
+	for _, window := range c.windows {
+		periods, err := Occurrences(&window, from, to)
+		if err != nil {
+			continue
+		}
+		for _, period := range periods {
+			occurrences = append(occurrences, Occurrence{Period: period, Window: window})
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].Start.Before(occurrences[j].Start)
+	})
+	return occurrences
+}
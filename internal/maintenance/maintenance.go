@@ -0,0 +1,158 @@
+package maintenance
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+// Period is one occurrence of a maintenance window
+type Period struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Contains returns whether the time falls within the period
+func (p Period) Contains(at time.Time) bool {
+	return !at.Before(p.Start) && at.Before(p.End)
+}
+
+// interval returns the longest a recurring window may last so its occurrences do not overlap
+func interval(recurrence string) time.Duration {
+	switch recurrence {
+	case data.MaintenanceRecurrenceDaily:
+		return 24 * time.Hour
+	case data.MaintenanceRecurrenceWeekly:
+		return 7 * 24 * time.Hour
+	case data.MaintenanceRecurrenceMonthly:
+		return 28 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// Validate checks that the window's period, timezone and recurrence make sense
+func Validate(window *data.MaintenanceWindow) error {
+	if !window.EndTime.After(window.StartTime) {
+		return fmt.Errorf("end time must be after start time")
+	}
+	if _, err := time.LoadLocation(window.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone: %s", window.Timezone)
+	}
+
+	if window.Recurrence == data.MaintenanceRecurrenceNone {
+		if window.RecurrenceUntil != nil {
+			return fmt.Errorf("recurrence until requires a recurrence")
+		}
+		return nil
+	}
+
+	limit := interval(window.Recurrence)
+	if limit == 0 {
+		return fmt.Errorf("recurrence must be daily, weekly, monthly or empty")
+	}
+	if window.EndTime.Sub(window.StartTime) > limit {
+		return fmt.Errorf("a %s window cannot last longer than %s", window.Recurrence, limit)
+	}
+	if window.RecurrenceUntil != nil && window.RecurrenceUntil.Before(window.StartTime) {
+		return fmt.Errorf("recurrence until must not be before the start time")
+	}
+	return nil
+}
+
+// Covers returns whether the window applies to the endpoint, windows without endpoints apply
+// to every endpoint
+func Covers(window *data.MaintenanceWindow, endpointID uuid.UUID) bool {
+	return len(window.EndpointIDs) == 0 || slices.Contains(window.EndpointIDs, endpointID)
+}
+
+// Occurrences returns the window's occurrences overlapping the period from to to, in order.
+// Recurring windows repeat in calendar days or months of the window's timezone, so they keep
+// their wall clock time across DST changes
+func Occurrences(window *data.MaintenanceWindow, from, to time.Time) ([]Period, error) {
+	duration := window.EndTime.Sub(window.StartTime)
+	if window.Recurrence == data.MaintenanceRecurrenceNone {
+		if window.StartTime.Before(to) && window.EndTime.After(from) {
+			return []Period{{Start: window.StartTime, End: window.EndTime}}, nil
+		}
+		return nil, nil
+	}
+
+	location, err := time.LoadLocation(window.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", window.Timezone, err)
+	}
+	limit := interval(window.Recurrence)
+	if limit == 0 {
+		return nil, fmt.Errorf("unknown recurrence %q", window.Recurrence)
+	}
+
+	first := window.StartTime.In(location)
+
+	// Skip the occurrences that ended long before the period, DST changes move occurrences
+	// by an hour at most so starting one early is enough
+	n := 0
+	if skip := from.Sub(first) - duration; skip > 0 {
+		switch window.Recurrence {
+		case data.MaintenanceRecurrenceMonthly:
+			n = monthsBetween(first, from.In(location)) - 2
+		default:
+			n = int(skip/limit) - 1
+		}
+		n = max(n, 0)
+	}
+
+	var periods []Period
+	for ; ; n++ {
+		start := occurrence(first, window.Recurrence, n)
+		if !start.Before(to) || (window.RecurrenceUntil != nil && start.After(*window.RecurrenceUntil)) {
+			break
+		}
+		if end := start.Add(duration); end.After(from) {
+			periods = append(periods, Period{Start: start, End: end})
+		}
+	}
+	return periods, nil
+}
+
+// ActivePeriod returns the window's occurrence containing the time, nil when there is none
+func ActivePeriod(window *data.MaintenanceWindow, at time.Time) (*Period, error) {
+	periods, err := Occurrences(window, at, at.Add(time.Nanosecond))
+	if err != nil || len(periods) == 0 {
+		return nil, err
+	}
+	return &periods[0], nil
+}
+
+// occurrence returns the start of the nth occurrence of a recurring window. Monthly windows
+// starting on a day some months lack start on the last day of those months
+func occurrence(first time.Time, recurrence string, n int) time.Time {
+	switch recurrence {
+	case data.MaintenanceRecurrenceDaily:
+		return addDays(first, n)
+	case data.MaintenanceRecurrenceWeekly:
+		return addDays(first, 7*n)
+	default:
+		month := time.Date(first.Year(), first.Month()+time.Month(n), 1, 0, 0, 0, 0, first.Location())
+		day := min(first.Day(), daysIn(month))
+		return time.Date(month.Year(), month.Month(), day, first.Hour(), first.Minute(), first.Second(), 0, first.Location())
+	}
+}
+
+// addDays moves a time by calendar days, keeping its wall clock time
+func addDays(t time.Time, days int) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()+days, t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+}
+
+// daysIn returns the number of days in the month of the time
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+}
+
+// monthsBetween counts the calendar months from a to b
+func monthsBetween(a, b time.Time) int {
+	return (b.Year()-a.Year())*12 + int(b.Month()) - int(a.Month())
+}
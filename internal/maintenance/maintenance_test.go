@@ -0,0 +1,164 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+type memoryStore struct {
+	windows []data.MaintenanceWindow
+}
+
+func (s *memoryStore) GetMaintenanceWindows() ([]data.MaintenanceWindow, error) {
+	return s.windows, nil
+}
+
+func TestOccurrencesWeeklyAcrossDST(t *testing.T) {
+	location, _ := time.LoadLocation("Europe/Amsterdam")
+	window := &data.MaintenanceWindow{
+		StartTime:  time.Date(2025, 10, 18, 2, 0, 0, 0, location), // Saturday
+		EndTime:    time.Date(2025, 10, 18, 4, 0, 0, 0, location),
+		Timezone:   "Europe/Amsterdam",
+		Recurrence: data.MaintenanceRecurrenceWeekly,
+	}
+
+	periods, err := Occurrences(window, time.Date(2025, 10, 20, 0, 0, 0, 0, location), time.Date(2025, 11, 10, 0, 0, 0, 0, location))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []time.Time{
+		time.Date(2025, 10, 25, 2, 0, 0, 0, location),
+		time.Date(2025, 11, 1, 2, 0, 0, 0, location), // after the DST change, still 02:00 local
+		time.Date(2025, 11, 8, 2, 0, 0, 0, location),
+	}
+	if len(periods) != len(expected) {
+		t.Fatalf("Expected %d occurrences, got %v", len(expected), periods)
+	}
+	for i, start := range expected {
+		if !periods[i].Start.Equal(start) || periods[i].End.Sub(periods[i].Start) != 2*time.Hour {
+			t.Errorf("Expected occurrence %d from %v for 2h, got %v to %v", i, start, periods[i].Start, periods[i].End)
+		}
+	}
+}
+
+func TestOccurrencesDailySpanningMidnight(t *testing.T) {
+	until := time.Date(2025, 9, 5, 0, 0, 0, 0, time.UTC)
+	window := &data.MaintenanceWindow{
+		StartTime:       time.Date(2025, 9, 1, 23, 0, 0, 0, time.UTC),
+		EndTime:         time.Date(2025, 9, 2, 1, 0, 0, 0, time.UTC),
+		Timezone:        "UTC",
+		Recurrence:      data.MaintenanceRecurrenceDaily,
+		RecurrenceUntil: &until,
+	}
+
+	tests := []struct {
+		name   string
+		at     time.Time
+		active bool
+	}{
+		{"before the first occurrence", time.Date(2025, 9, 1, 22, 59, 0, 0, time.UTC), false},
+		{"first occurrence", time.Date(2025, 9, 1, 23, 0, 0, 0, time.UTC), true},
+		{"after midnight", time.Date(2025, 9, 3, 0, 30, 0, 0, time.UTC), true},
+		{"at the end", time.Date(2025, 9, 3, 1, 0, 0, 0, time.UTC), false},
+		{"last occurrence", time.Date(2025, 9, 5, 0, 30, 0, 0, time.UTC), true},
+		{"after recurrence until", time.Date(2025, 9, 5, 23, 30, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			period, err := ActivePeriod(window, tt.at)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if (period != nil) != tt.active {
+				t.Errorf("Expected active %v, got %+v", tt.active, period)
+			}
+		})
+	}
+}
+
+func TestOccurrencesMonthlyOnLastDays(t *testing.T) {
+	window := &data.MaintenanceWindow{
+		StartTime:  time.Date(2025, 1, 31, 22, 0, 0, 0, time.UTC),
+		EndTime:    time.Date(2025, 1, 31, 23, 0, 0, 0, time.UTC),
+		Timezone:   "UTC",
+		Recurrence: data.MaintenanceRecurrenceMonthly,
+	}
+
+	periods, err := Occurrences(window, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []int{28, 31, 30} // February, March, April
+	if len(periods) != len(expected) {
+		t.Fatalf("Expected %d occurrences, got %v", len(expected), periods)
+	}
+	for i, day := range expected {
+		if periods[i].Start.Day() != day || periods[i].Start.Hour() != 22 {
+			t.Errorf("Expected occurrence %d on day %d at 22:00, got %v", i, day, periods[i].Start)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	start := time.Date(2025, 9, 1, 2, 0, 0, 0, time.UTC)
+	before := start.Add(-time.Hour)
+
+	tests := []struct {
+		name   string
+		window data.MaintenanceWindow
+		valid  bool
+	}{
+		{"one-off", data.MaintenanceWindow{StartTime: start, EndTime: start.Add(time.Hour), Timezone: "UTC"}, true},
+		{"end before start", data.MaintenanceWindow{StartTime: start, EndTime: start, Timezone: "UTC"}, false},
+		{"unknown timezone", data.MaintenanceWindow{StartTime: start, EndTime: start.Add(time.Hour), Timezone: "Mars/Olympus"}, false},
+		{"unknown recurrence", data.MaintenanceWindow{StartTime: start, EndTime: start.Add(time.Hour), Timezone: "UTC", Recurrence: "yearly"}, false},
+		{"longer than the recurrence", data.MaintenanceWindow{StartTime: start, EndTime: start.Add(25 * time.Hour), Timezone: "UTC", Recurrence: data.MaintenanceRecurrenceDaily}, false},
+		{"until before start", data.MaintenanceWindow{StartTime: start, EndTime: start.Add(time.Hour), Timezone: "UTC", Recurrence: data.MaintenanceRecurrenceDaily, RecurrenceUntil: &before}, false},
+		{"until without recurrence", data.MaintenanceWindow{StartTime: start, EndTime: start.Add(time.Hour), Timezone: "UTC", RecurrenceUntil: &before}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(&tt.window); (err == nil) != tt.valid {
+				t.Errorf("Expected valid %v, got %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestCheckerInMaintenance(t *testing.T) {
+	api, web := uuid.New(), uuid.New()
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	checker := NewChecker(&memoryStore{windows: []data.MaintenanceWindow{
+		{Title: "API deploy", StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour), Timezone: "UTC", EndpointIDs: data.UUIDList{api}},
+		{Title: "Database upgrade", StartTime: now.Add(24 * time.Hour), EndTime: now.Add(26 * time.Hour), Timezone: "UTC"},
+	}})
+	if checker.InMaintenance(api, now) {
+		t.Fatal("Expected no maintenance before the first reload")
+	}
+	if err := checker.Reload(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !checker.InMaintenance(api, now) {
+		t.Error("Expected the API to be in maintenance")
+	}
+	if checker.InMaintenance(web, now) {
+		t.Error("Expected the website not to be covered by the API deploy")
+	}
+	if !checker.InMaintenance(web, now.Add(25*time.Hour)) {
+		t.Error("Expected a window without endpoints to cover every endpoint")
+	}
+
+	occurrences := checker.Occurrences(now, now.Add(7*24*time.Hour))
+	if len(occurrences) != 2 || occurrences[0].Window.Title != "API deploy" || occurrences[1].Window.Title != "Database upgrade" {
+		t.Errorf("Expected the active and upcoming windows in order, got %+v", occurrences)
+	}
+}
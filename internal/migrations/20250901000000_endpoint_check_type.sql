@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Add check type so endpoints can be monitored with protocols other than HTTP
+ALTER TABLE "endpoint" ADD COLUMN IF NOT EXISTS check_type VARCHAR(20) NOT NULL DEFAULT 'http';
+
+-- Optional payload to send and banner to expect for TCP checks
+ALTER TABLE "endpoint" ADD COLUMN IF NOT EXISTS tcp_send TEXT DEFAULT '';
+ALTER TABLE "endpoint" ADD COLUMN IF NOT EXISTS tcp_expect TEXT DEFAULT '';
+
+-- Add constraint for valid check types
+ALTER TABLE "endpoint" ADD CONSTRAINT chk_endpoint_valid_check_type
+CHECK (check_type IN ('http', 'tcp'));
+
+CREATE INDEX IF NOT EXISTS idx_endpoint_check_type ON "endpoint"(check_type);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_endpoint_check_type;
+ALTER TABLE "endpoint" DROP CONSTRAINT IF EXISTS chk_endpoint_valid_check_type;
+ALTER TABLE "endpoint" DROP COLUMN IF EXISTS tcp_expect;
+ALTER TABLE "endpoint" DROP COLUMN IF EXISTS tcp_send;
+ALTER TABLE "endpoint" DROP COLUMN IF EXISTS check_type;
+-- +goose StatementEnd
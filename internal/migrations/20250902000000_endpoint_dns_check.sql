@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- DNS checks resolve the endpoint URL as a hostname against an optional resolver
+ALTER TABLE "endpoint" ADD COLUMN IF NOT EXISTS dns_record_type VARCHAR(10) DEFAULT '';
+ALTER TABLE "endpoint" ADD COLUMN IF NOT EXISTS dns_resolver VARCHAR(255) DEFAULT '';
+ALTER TABLE "endpoint" ADD COLUMN IF NOT EXISTS dns_expected_values JSONB DEFAULT '[]';
+
+-- Allow the dns check type
+ALTER TABLE "endpoint" DROP CONSTRAINT IF EXISTS chk_endpoint_valid_check_type;
+ALTER TABLE "endpoint" ADD CONSTRAINT chk_endpoint_valid_check_type
+CHECK (check_type IN ('http', 'tcp', 'dns'));
+
+-- Add constraint for valid DNS record types
+ALTER TABLE "endpoint" ADD CONSTRAINT chk_endpoint_valid_dns_record_type
+CHECK (dns_record_type IN ('', 'A', 'AAAA', 'CNAME', 'MX', 'TXT'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "endpoint" DROP CONSTRAINT IF EXISTS chk_endpoint_valid_dns_record_type;
+ALTER TABLE "endpoint" DROP CONSTRAINT IF EXISTS chk_endpoint_valid_check_type;
+ALTER TABLE "endpoint" ADD CONSTRAINT chk_endpoint_valid_check_type
+CHECK (check_type IN ('http', 'tcp'));
+ALTER TABLE "endpoint" DROP COLUMN IF EXISTS dns_expected_values;
+ALTER TABLE "endpoint" DROP COLUMN IF EXISTS dns_resolver;
+ALTER TABLE "endpoint" DROP COLUMN IF EXISTS dns_record_type;
+-- +goose StatementEnd
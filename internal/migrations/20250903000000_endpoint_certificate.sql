@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Per-endpoint TLS settings
+ALTER TABLE "endpoint" ADD COLUMN IF NOT EXISTS cert_expiry_warning_days INTEGER DEFAULT 14;
+ALTER TABLE "endpoint" ADD COLUMN IF NOT EXISTS tls_skip_verify BOOLEAN DEFAULT false;
+
+ALTER TABLE "endpoint" ADD CONSTRAINT chk_endpoint_cert_expiry_warning_days
+CHECK (cert_expiry_warning_days >= 0 AND cert_expiry_warning_days <= 365);
+
+-- Create endpoint_certificate table storing the last certificate seen for each HTTPS endpoint
+CREATE TABLE IF NOT EXISTS "endpoint_certificate" (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    endpoint_id UUID NOT NULL UNIQUE REFERENCES "endpoint"(id) ON DELETE CASCADE,
+    subject TEXT DEFAULT '',
+    issuer TEXT DEFAULT '',
+    sans JSONB DEFAULT '[]',
+    serial_number VARCHAR(128) DEFAULT '',
+    not_before TIMESTAMP WITH TIME ZONE NOT NULL,
+    not_after TIMESTAMP WITH TIME ZONE NOT NULL,
+    chain_valid BOOLEAN NOT NULL DEFAULT false,
+    chain_error TEXT DEFAULT '',
+    checked_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_endpoint_certificate_not_after ON "endpoint_certificate"(not_after);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "endpoint_certificate";
+ALTER TABLE "endpoint" DROP CONSTRAINT IF EXISTS chk_endpoint_cert_expiry_warning_days;
+ALTER TABLE "endpoint" DROP COLUMN IF EXISTS tls_skip_verify;
+ALTER TABLE "endpoint" DROP COLUMN IF EXISTS cert_expiry_warning_days;
+-- +goose StatementEnd
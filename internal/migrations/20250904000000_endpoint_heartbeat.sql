@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Heartbeat monitors are pinged by the monitored job through a secret URL instead of being probed
+ALTER TABLE "endpoint" ADD COLUMN IF NOT EXISTS heartbeat_token VARCHAR(64) DEFAULT '';
+ALTER TABLE "endpoint" ADD COLUMN IF NOT EXISTS heartbeat_grace_seconds INTEGER DEFAULT 60;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_endpoint_heartbeat_token ON "endpoint"(heartbeat_token) WHERE heartbeat_token <> '';
+
+-- Allow the heartbeat check type
+ALTER TABLE "endpoint" DROP CONSTRAINT IF EXISTS chk_endpoint_valid_check_type;
+ALTER TABLE "endpoint" ADD CONSTRAINT chk_endpoint_valid_check_type
+CHECK (check_type IN ('http', 'tcp', 'dns', 'heartbeat'));
+
+ALTER TABLE "endpoint" ADD CONSTRAINT chk_endpoint_heartbeat_grace_seconds
+CHECK (heartbeat_grace_seconds >= 0 AND heartbeat_grace_seconds <= 86400);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "endpoint" DROP CONSTRAINT IF EXISTS chk_endpoint_heartbeat_grace_seconds;
+ALTER TABLE "endpoint" DROP CONSTRAINT IF EXISTS chk_endpoint_valid_check_type;
+ALTER TABLE "endpoint" ADD CONSTRAINT chk_endpoint_valid_check_type
+CHECK (check_type IN ('http', 'tcp', 'dns'));
+DROP INDEX IF EXISTS idx_endpoint_heartbeat_token;
+ALTER TABLE "endpoint" DROP COLUMN IF EXISTS heartbeat_grace_seconds;
+ALTER TABLE "endpoint" DROP COLUMN IF EXISTS heartbeat_token;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Response assertions evaluated against the body and headers of HTTP checks
+ALTER TABLE "endpoint" ADD COLUMN IF NOT EXISTS assertions JSONB DEFAULT '[]';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "endpoint" DROP COLUMN IF EXISTS assertions;
+-- +goose StatementEnd
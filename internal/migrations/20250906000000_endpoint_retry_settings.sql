@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Per-endpoint retry settings, NULL falls back to the engine defaults
+ALTER TABLE "endpoint" ADD COLUMN IF NOT EXISTS max_retries INTEGER;
+ALTER TABLE "endpoint" ADD COLUMN IF NOT EXISTS retry_delay_ms INTEGER;
+ALTER TABLE "endpoint" ADD COLUMN IF NOT EXISTS confirm_retries INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE "endpoint" ADD CONSTRAINT chk_endpoint_max_retries
+CHECK (max_retries IS NULL OR (max_retries >= 0 AND max_retries <= 10));
+
+ALTER TABLE "endpoint" ADD CONSTRAINT chk_endpoint_retry_delay_ms
+CHECK (retry_delay_ms IS NULL OR (retry_delay_ms >= 0 AND retry_delay_ms <= 60000));
+
+ALTER TABLE "endpoint" ADD CONSTRAINT chk_endpoint_confirm_retries
+CHECK (confirm_retries >= 0 AND confirm_retries <= 10);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "endpoint" DROP CONSTRAINT IF EXISTS chk_endpoint_confirm_retries;
+ALTER TABLE "endpoint" DROP CONSTRAINT IF EXISTS chk_endpoint_retry_delay_ms;
+ALTER TABLE "endpoint" DROP CONSTRAINT IF EXISTS chk_endpoint_max_retries;
+ALTER TABLE "endpoint" DROP COLUMN IF EXISTS confirm_retries;
+ALTER TABLE "endpoint" DROP COLUMN IF EXISTS retry_delay_ms;
+ALTER TABLE "endpoint" DROP COLUMN IF EXISTS max_retries;
+-- +goose StatementEnd
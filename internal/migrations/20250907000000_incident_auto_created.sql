@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Incidents opened by the incident detector, so it can reclaim them after a restart
+ALTER TABLE "incident" ADD COLUMN IF NOT EXISTS auto_created BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE "incident" ADD COLUMN IF NOT EXISTS auto_reason VARCHAR(32) NOT NULL DEFAULT '';
+
+ALTER TABLE "incident" ADD CONSTRAINT chk_incident_auto_reason
+CHECK (auto_reason IN ('', 'endpoint_down', 'certificate_expiry'));
+
+CREATE INDEX IF NOT EXISTS idx_incident_auto_created_open ON "incident"(auto_created, status) WHERE auto_created = TRUE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_incident_auto_created_open;
+ALTER TABLE "incident" DROP CONSTRAINT IF EXISTS chk_incident_auto_reason;
+ALTER TABLE "incident" DROP COLUMN IF EXISTS auto_reason;
+ALTER TABLE "incident" DROP COLUMN IF EXISTS auto_created;
+-- +goose StatementEnd
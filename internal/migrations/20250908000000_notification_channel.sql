@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Create notification_channel table, each row is a named, configured instance of a provider
+CREATE TABLE IF NOT EXISTS "notification_channel" (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    settings JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT uq_notification_channel_type_name UNIQUE (type, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_channel_enabled ON "notification_channel"(enabled);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "notification_channel";
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Tags group endpoints for notification routing
+ALTER TABLE "endpoint" ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]';
+
+CREATE INDEX IF NOT EXISTS idx_endpoint_tags ON "endpoint" USING GIN (tags);
+
+-- Create notification_route table, each row sends matching events to a list of channels.
+-- Empty match lists match every event
+CREATE TABLE IF NOT EXISTS "notification_route" (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    event_types JSONB NOT NULL DEFAULT '[]',
+    severities JSONB NOT NULL DEFAULT '[]',
+    endpoint_ids JSONB NOT NULL DEFAULT '[]',
+    endpoint_tags JSONB NOT NULL DEFAULT '[]',
+    channel_ids JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT uq_notification_route_name UNIQUE (name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_route_enabled ON "notification_route"(enabled);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "notification_route";
+
+DROP INDEX IF EXISTS idx_endpoint_tags;
+
+ALTER TABLE "endpoint" DROP COLUMN IF EXISTS tags;
+-- +goose StatementEnd
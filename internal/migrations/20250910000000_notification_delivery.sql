@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Create notification_delivery table, an outbox holding one row per event and channel until
+-- the notification is delivered or its attempts are exhausted
+CREATE TABLE IF NOT EXISTS "notification_delivery" (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    event_id UUID NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    title VARCHAR(500) NOT NULL DEFAULT '',
+    severity VARCHAR(20) NOT NULL DEFAULT '',
+    channel_id UUID NOT NULL,
+    channel_name VARCHAR(255) NOT NULL DEFAULT '',
+    channel_type VARCHAR(32) NOT NULL DEFAULT '',
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 1,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_error TEXT NOT NULL DEFAULT '',
+    provider_response TEXT NOT NULL DEFAULT '',
+    attempt_log JSONB NOT NULL DEFAULT '[]',
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT chk_notification_delivery_status CHECK (status IN ('pending', 'delivered', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_delivery_due ON "notification_delivery"(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_notification_delivery_event_id ON "notification_delivery"(event_id);
+CREATE INDEX IF NOT EXISTS idx_notification_delivery_channel_id ON "notification_delivery"(channel_id);
+CREATE INDEX IF NOT EXISTS idx_notification_delivery_created_at ON "notification_delivery"(created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "notification_delivery";
+-- +goose StatementEnd
@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Create escalation_policy table, levels are notified in order while an incident stays unacknowledged
+CREATE TABLE IF NOT EXISTS "escalation_policy" (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    severities JSONB NOT NULL DEFAULT '[]',
+    levels JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT uq_escalation_policy_name UNIQUE (name)
+);
+
+-- Acknowledgement and escalation progress of incidents
+ALTER TABLE "incident" ADD COLUMN IF NOT EXISTS acknowledged_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE "incident" ADD COLUMN IF NOT EXISTS acknowledged_by UUID REFERENCES "user"(id) ON DELETE SET NULL;
+ALTER TABLE "incident" ADD COLUMN IF NOT EXISTS escalation_policy_id UUID REFERENCES "escalation_policy"(id) ON DELETE SET NULL;
+ALTER TABLE "incident" ADD COLUMN IF NOT EXISTS escalation_level INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "incident" ADD COLUMN IF NOT EXISTS next_escalation_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_incident_next_escalation_at ON "incident"(next_escalation_at) WHERE next_escalation_at IS NOT NULL;
+
+-- Allow acknowledgement and escalation timeline entries
+ALTER TABLE "incident_timeline" DROP CONSTRAINT IF EXISTS incident_timeline_event_type_check;
+ALTER TABLE "incident_timeline" ADD CONSTRAINT chk_incident_timeline_event_type CHECK (event_type IN ('status_change', 'update', 'comment', 'endpoint_associated', 'endpoint_removed', 'created', 'resolved', 'acknowledged', 'escalated'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM "incident_timeline" WHERE event_type IN ('acknowledged', 'escalated');
+ALTER TABLE "incident_timeline" DROP CONSTRAINT IF EXISTS chk_incident_timeline_event_type;
+ALTER TABLE "incident_timeline" ADD CONSTRAINT incident_timeline_event_type_check CHECK (event_type IN ('status_change', 'update', 'comment', 'endpoint_associated', 'endpoint_removed', 'created', 'resolved'));
+
+DROP INDEX IF EXISTS idx_incident_next_escalation_at;
+
+ALTER TABLE "incident" DROP COLUMN IF EXISTS next_escalation_at;
+ALTER TABLE "incident" DROP COLUMN IF EXISTS escalation_level;
+ALTER TABLE "incident" DROP COLUMN IF EXISTS escalation_policy_id;
+ALTER TABLE "incident" DROP COLUMN IF EXISTS acknowledged_by;
+ALTER TABLE "incident" DROP COLUMN IF EXISTS acknowledged_at;
+
+DROP TABLE IF EXISTS "escalation_policy";
+-- +goose StatementEnd
@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Create oncall_schedule table, participants take turns in order at every handoff
+CREATE TABLE IF NOT EXISTS "oncall_schedule" (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    rotation_type VARCHAR(16) NOT NULL DEFAULT 'weekly',
+    handoff_time VARCHAR(5) NOT NULL DEFAULT '09:00',
+    handoff_weekday INTEGER NOT NULL DEFAULT 1,
+    rotation_start TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    participants JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT uq_oncall_schedule_name UNIQUE (name),
+    CONSTRAINT chk_oncall_schedule_rotation_type CHECK (rotation_type IN ('daily', 'weekly')),
+    CONSTRAINT chk_oncall_schedule_handoff_weekday CHECK (handoff_weekday BETWEEN 0 AND 6)
+);
+
+-- Create oncall_override table, an override puts someone else on call for a period
+CREATE TABLE IF NOT EXISTS "oncall_override" (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    schedule_id UUID NOT NULL REFERENCES "oncall_schedule"(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT chk_oncall_override_period CHECK (end_time > start_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_oncall_override_schedule_id_start_time ON "oncall_override"(schedule_id, start_time);
+
+-- Routes can send email to whoever is on call for a schedule
+ALTER TABLE "notification_route" ADD COLUMN IF NOT EXISTS oncall_schedule_ids JSONB NOT NULL DEFAULT '[]';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "notification_route" DROP COLUMN IF EXISTS oncall_schedule_ids;
+DROP INDEX IF EXISTS idx_oncall_override_schedule_id_start_time;
+DROP TABLE IF EXISTS "oncall_override";
+DROP TABLE IF EXISTS "oncall_schedule";
+-- +goose StatementEnd
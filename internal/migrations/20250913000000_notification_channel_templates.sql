@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Per notification type message templates of a channel, keyed by notification type
+ALTER TABLE "notification_channel" ADD COLUMN IF NOT EXISTS templates JSONB NOT NULL DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "notification_channel" DROP COLUMN IF EXISTS templates;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Notifications suppressed as duplicates, over a channel's rate limit or while an endpoint is
+-- flapping are kept in the delivery history with the reason
+ALTER TABLE "notification_delivery" DROP CONSTRAINT IF EXISTS chk_notification_delivery_status;
+ALTER TABLE "notification_delivery" ADD CONSTRAINT chk_notification_delivery_status CHECK (status IN ('pending', 'delivered', 'failed', 'suppressed'));
+ALTER TABLE "notification_delivery" ADD COLUMN IF NOT EXISTS suppressed_reason TEXT NOT NULL DEFAULT '';
+
+-- Notifications a channel may receive per rate limit period, NULL uses the default and 0 disables the limit
+ALTER TABLE "notification_channel" ADD COLUMN IF NOT EXISTS rate_limit INTEGER;
+ALTER TABLE "notification_channel" ADD CONSTRAINT chk_notification_channel_rate_limit CHECK (rate_limit IS NULL OR rate_limit >= 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "notification_channel" DROP CONSTRAINT IF EXISTS chk_notification_channel_rate_limit;
+ALTER TABLE "notification_channel" DROP COLUMN IF EXISTS rate_limit;
+
+DELETE FROM "notification_delivery" WHERE status = 'suppressed';
+ALTER TABLE "notification_delivery" DROP COLUMN IF EXISTS suppressed_reason;
+ALTER TABLE "notification_delivery" DROP CONSTRAINT IF EXISTS chk_notification_delivery_status;
+ALTER TABLE "notification_delivery" ADD CONSTRAINT chk_notification_delivery_status CHECK (status IN ('pending', 'delivered', 'failed'));
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Quiet hours and digest settings of a channel, NULL sends every notification right away
+ALTER TABLE "notification_channel" ADD COLUMN IF NOT EXISTS quiet_hours JSONB;
+
+-- Notifications held for a channel's next digest
+ALTER TABLE "notification_delivery" DROP CONSTRAINT IF EXISTS chk_notification_delivery_status;
+ALTER TABLE "notification_delivery" ADD CONSTRAINT chk_notification_delivery_status CHECK (status IN ('pending', 'delivered', 'failed', 'suppressed', 'held'));
+
+CREATE INDEX IF NOT EXISTS idx_notification_delivery_held ON "notification_delivery"(channel_id, created_at) WHERE status = 'held';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_notification_delivery_held;
+
+UPDATE "notification_delivery" SET status = 'suppressed', suppressed_reason = 'quiet_hours' WHERE status = 'held';
+ALTER TABLE "notification_delivery" DROP CONSTRAINT IF EXISTS chk_notification_delivery_status;
+ALTER TABLE "notification_delivery" ADD CONSTRAINT chk_notification_delivery_status CHECK (status IN ('pending', 'delivered', 'failed', 'suppressed'));
+
+ALTER TABLE "notification_channel" DROP COLUMN IF EXISTS quiet_hours;
+-- +goose StatementEnd
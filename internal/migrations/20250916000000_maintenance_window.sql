@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Create maintenance_window table, planned downtime that opens no incidents and sends no
+-- notifications. Recurring windows repeat from their first occurrence until recurrence_until
+CREATE TABLE IF NOT EXISTS "maintenance_window" (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    recurrence VARCHAR(16) NOT NULL DEFAULT '',
+    recurrence_until TIMESTAMP WITH TIME ZONE,
+    endpoint_ids JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT chk_maintenance_window_period CHECK (end_time > start_time),
+    CONSTRAINT chk_maintenance_window_recurrence CHECK (recurrence IN ('', 'daily', 'weekly', 'monthly'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_window_start_time ON "maintenance_window"(start_time);
+
+-- Checks run during a maintenance window are kept but left out of uptime
+ALTER TABLE "monitoring_log" ADD COLUMN IF NOT EXISTS in_maintenance BOOLEAN NOT NULL DEFAULT false;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "monitoring_log" DROP COLUMN IF EXISTS in_maintenance;
+DROP INDEX IF EXISTS idx_maintenance_window_start_time;
+DROP TABLE IF EXISTS "maintenance_window";
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Endpoints an endpoint depends on, its failures while one of them is down are attributed to that incident
+ALTER TABLE "endpoint" ADD COLUMN IF NOT EXISTS parent_ids JSONB NOT NULL DEFAULT '[]';
+
+-- Set on associations of endpoints affected through a down parent rather than failing on their own
+ALTER TABLE "endpoint_incident" ADD COLUMN IF NOT EXISTS parent_endpoint_id UUID REFERENCES "endpoint"(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "endpoint_incident" DROP COLUMN IF EXISTS parent_endpoint_id;
+ALTER TABLE "endpoint" DROP COLUMN IF EXISTS parent_ids;
+-- +goose StatementEnd
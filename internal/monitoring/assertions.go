@@ -0,0 +1,424 @@
+package monitoring
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+// assertionOperators lists the operators each assertion type accepts
+var assertionOperators = map[string][]string{
+	data.AssertionJSONPath: {
+		data.AssertionOpExists, data.AssertionOpNotExists,
+		data.AssertionOpEquals, data.AssertionOpNotEquals,
+		data.AssertionOpContains, data.AssertionOpMatches,
+		data.AssertionOpGreaterThan, data.AssertionOpGreaterThanOrEqual,
+		data.AssertionOpLessThan, data.AssertionOpLessThanOrEqual,
+	},
+	data.AssertionHeader: {
+		data.AssertionOpExists, data.AssertionOpNotExists,
+		data.AssertionOpEquals, data.AssertionOpNotEquals,
+		data.AssertionOpContains, data.AssertionOpMatches,
+	},
+}
+
+// maxAssertionActualLength limits how much of an actual value is quoted in errors
+const maxAssertionActualLength = 100
+
+// ValidateAssertion checks that an assertion is well formed: a known type, an
+// operator the type accepts, a parseable path, a compilable regex and a numeric
+// value for comparisons
+func ValidateAssertion(assertion data.ResponseAssertion) error {
+	if !slices.Contains(data.ValidAssertionTypes, assertion.Type) {
+		return fmt.Errorf("type must be one of: %s", strings.Join(data.ValidAssertionTypes, ", "))
+	}
+
+	switch assertion.Type {
+	case data.AssertionBodyContains, data.AssertionBodyNotContains:
+		if assertion.Value == "" {
+			return fmt.Errorf("value is required")
+		}
+		return nil
+	case data.AssertionBodyMatches:
+		if _, err := regexp.Compile(assertion.Value); err != nil {
+			return fmt.Errorf("value is not a valid regular expression: %w", err)
+		}
+		return nil
+	case data.AssertionJSONPath:
+		if _, err := parseJSONPath(assertion.Path); err != nil {
+			return err
+		}
+	case data.AssertionHeader:
+		if strings.TrimSpace(assertion.Path) == "" {
+			return fmt.Errorf("header name is required")
+		}
+	}
+
+	operators := assertionOperators[assertion.Type]
+	if !slices.Contains(operators, assertion.Operator) {
+		return fmt.Errorf("operator must be one of: %s", strings.Join(operators, ", "))
+	}
+
+	switch assertion.Operator {
+	case data.AssertionOpMatches:
+		if _, err := regexp.Compile(assertion.Value); err != nil {
+			return fmt.Errorf("value is not a valid regular expression: %w", err)
+		}
+	case data.AssertionOpGreaterThan, data.AssertionOpGreaterThanOrEqual,
+		data.AssertionOpLessThan, data.AssertionOpLessThanOrEqual:
+		if _, err := strconv.ParseFloat(assertion.Value, 64); err != nil {
+			return fmt.Errorf("value must be a number for %s", assertion.Operator)
+		}
+	}
+
+	return nil
+}
+
+// validateAssertions evaluates the endpoint's response assertions, recording a
+// validation error for every assertion that does not hold
+func (v *ResponseValidator) validateAssertions(endpoint *data.Endpoint, response *HTTPResponse, result *ValidationResult) bool {
+	valid := true
+
+	for _, assertion := range endpoint.Assertions {
+		if err := evaluateAssertion(assertion, response); err != nil {
+			result.Errors = append(result.Errors, *err)
+			valid = false
+		}
+	}
+
+	return valid
+}
+
+// evaluateAssertion checks a single assertion against the response
+func evaluateAssertion(assertion data.ResponseAssertion, response *HTTPResponse) *ValidationError {
+	switch assertion.Type {
+	case data.AssertionBodyContains:
+		if !strings.Contains(response.Body, assertion.Value) {
+			return assertionError("response_body", "contains "+strconv.Quote(assertion.Value), "not found",
+				fmt.Sprintf("Assertion failed: body does not contain %q", assertion.Value))
+		}
+	case data.AssertionBodyNotContains:
+		if strings.Contains(response.Body, assertion.Value) {
+			return assertionError("response_body", "does not contain "+strconv.Quote(assertion.Value), "found",
+				fmt.Sprintf("Assertion failed: body contains %q", assertion.Value))
+		}
+	case data.AssertionBodyMatches:
+		re, err := regexp.Compile(assertion.Value)
+		if err != nil {
+			return assertionError("response_body", "valid regular expression", assertion.Value,
+				fmt.Sprintf("Assertion failed: invalid regular expression %q", assertion.Value))
+		}
+		if !re.MatchString(response.Body) {
+			return assertionError("response_body", "matches "+assertion.Value, "no match",
+				fmt.Sprintf("Assertion failed: body does not match /%s/", assertion.Value))
+		}
+	case data.AssertionJSONPath:
+		return evaluateJSONPathAssertion(assertion, response.Body)
+	case data.AssertionHeader:
+		return evaluateHeaderAssertion(assertion, response.Headers)
+	default:
+		return assertionError("assertions", strings.Join(data.ValidAssertionTypes, ", "), assertion.Type,
+			fmt.Sprintf("Assertion failed: unknown assertion type %q", assertion.Type))
+	}
+
+	return nil
+}
+
+// evaluateJSONPathAssertion resolves the path in the JSON body. The path must match
+// at least one value (unless asserting it does not exist) and every matched value
+// must satisfy the operator.
+func evaluateJSONPathAssertion(assertion data.ResponseAssertion, body string) *ValidationError {
+	field := "json:" + assertion.Path
+
+	path, err := parseJSONPath(assertion.Path)
+	if err != nil {
+		return assertionError(field, "valid JSONPath", assertion.Path,
+			fmt.Sprintf("Assertion failed: %s", err.Error()))
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return assertionError(field, "JSON body", "invalid JSON",
+			fmt.Sprintf("Assertion failed: %s cannot be evaluated, body is not valid JSON", assertion.Path))
+	}
+
+	matches := path.evaluate(document)
+
+	switch assertion.Operator {
+	case data.AssertionOpExists:
+		if len(matches) == 0 {
+			return assertionError(field, "exists", "missing",
+				fmt.Sprintf("Assertion failed: %s does not exist", assertion.Path))
+		}
+		return nil
+	case data.AssertionOpNotExists:
+		if len(matches) > 0 {
+			return assertionError(field, "does not exist", truncateActual(formatJSONValue(matches[0])),
+				fmt.Sprintf("Assertion failed: %s exists", assertion.Path))
+		}
+		return nil
+	}
+
+	if len(matches) == 0 {
+		return assertionError(field, describeExpectation(assertion), "missing",
+			fmt.Sprintf("Assertion failed: %s does not exist", assertion.Path))
+	}
+
+	for _, match := range matches {
+		actual := formatJSONValue(match)
+		if !compareAssertionValue(assertion.Operator, actual, assertion.Value, isJSONNumber(match)) {
+			return assertionError(field, describeExpectation(assertion), truncateActual(actual),
+				fmt.Sprintf("Assertion failed: %s %s, got %s", assertion.Path, describeExpectation(assertion), truncateActual(actual)))
+		}
+	}
+
+	return nil
+}
+
+// evaluateHeaderAssertion checks a response header against the assertion
+func evaluateHeaderAssertion(assertion data.ResponseAssertion, headers map[string]string) *ValidationError {
+	name := http.CanonicalHeaderKey(strings.TrimSpace(assertion.Path))
+	field := "header:" + name
+
+	actual, exists := headers[name]
+
+	switch assertion.Operator {
+	case data.AssertionOpExists:
+		if !exists {
+			return assertionError(field, "present", "missing",
+				fmt.Sprintf("Assertion failed: header %s is missing", name))
+		}
+		return nil
+	case data.AssertionOpNotExists:
+		if exists {
+			return assertionError(field, "absent", truncateActual(actual),
+				fmt.Sprintf("Assertion failed: header %s is present", name))
+		}
+		return nil
+	}
+
+	if !exists {
+		return assertionError(field, describeExpectation(assertion), "missing",
+			fmt.Sprintf("Assertion failed: header %s is missing", name))
+	}
+
+	if !compareAssertionValue(assertion.Operator, actual, assertion.Value, false) {
+		return assertionError(field, describeExpectation(assertion), truncateActual(actual),
+			fmt.Sprintf("Assertion failed: header %s %s, got %q", name, describeExpectation(assertion), truncateActual(actual)))
+	}
+
+	return nil
+}
+
+// compareAssertionValue applies an operator to an actual value. Equality is numeric
+// when the actual value is a JSON number and the expected value parses as one.
+func compareAssertionValue(operator, actual, expected string, numeric bool) bool {
+	switch operator {
+	case data.AssertionOpEquals, data.AssertionOpNotEquals:
+		equal := actual == expected
+		if numeric {
+			a, errA := strconv.ParseFloat(actual, 64)
+			e, errE := strconv.ParseFloat(expected, 64)
+			if errA == nil && errE == nil {
+				equal = a == e
+			}
+		}
+		return equal == (operator == data.AssertionOpEquals)
+	case data.AssertionOpContains:
+		return strings.Contains(actual, expected)
+	case data.AssertionOpMatches:
+		re, err := regexp.Compile(expected)
+		return err == nil && re.MatchString(actual)
+	case data.AssertionOpGreaterThan, data.AssertionOpGreaterThanOrEqual,
+		data.AssertionOpLessThan, data.AssertionOpLessThanOrEqual:
+		a, errA := strconv.ParseFloat(actual, 64)
+		e, errE := strconv.ParseFloat(expected, 64)
+		if errA != nil || errE != nil {
+			return false
+		}
+		switch operator {
+		case data.AssertionOpGreaterThan:
+			return a > e
+		case data.AssertionOpGreaterThanOrEqual:
+			return a >= e
+		case data.AssertionOpLessThan:
+			return a < e
+		default:
+			return a <= e
+		}
+	}
+
+	return false
+}
+
+// describeExpectation renders an assertion's operator and value for error messages
+func describeExpectation(assertion data.ResponseAssertion) string {
+	operator := strings.ReplaceAll(assertion.Operator, "_", " ")
+	if assertion.Operator == data.AssertionOpMatches {
+		return fmt.Sprintf("%s /%s/", operator, assertion.Value)
+	}
+	return fmt.Sprintf("%s %q", operator, assertion.Value)
+}
+
+func assertionError(field, expected, actual, description string) *ValidationError {
+	return &ValidationError{
+		Type:        "assertion_failed",
+		Field:       field,
+		Expected:    expected,
+		Actual:      actual,
+		Description: description,
+	}
+}
+
+func truncateActual(value string) string {
+	if len(value) > maxAssertionActualLength {
+		return value[:maxAssertionActualLength] + "..."
+	}
+	return value
+}
+
+// formatJSONValue renders a decoded JSON value the way users write expected values:
+// strings unquoted, numbers as written, and objects or arrays as compact JSON
+func formatJSONValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return "null"
+	default:
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(v); err != nil {
+			return fmt.Sprint(v)
+		}
+		return strings.TrimSpace(buf.String())
+	}
+}
+
+func isJSONNumber(value any) bool {
+	_, ok := value.(json.Number)
+	return ok
+}
+
+// jsonPath is a parsed JSONPath expression. The supported subset covers the root
+// ($), child names (.name or ['name']), array indexes ([0], [-1]) and wildcards
+// (.* or [*]).
+type jsonPath []jsonPathSegment
+
+type jsonPathSegment struct {
+	name     string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parseJSONPath parses a JSONPath expression into segments
+func parseJSONPath(expression string) (jsonPath, error) {
+	expression = strings.TrimSpace(expression)
+	if !strings.HasPrefix(expression, "$") {
+		return nil, fmt.Errorf("JSONPath %q must start with $", expression)
+	}
+
+	var path jsonPath
+	rest := expression[1:]
+
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			if name == "" {
+				return nil, fmt.Errorf("JSONPath %q has an empty name", expression)
+			}
+			if name == "*" {
+				path = append(path, jsonPathSegment{wildcard: true})
+			} else {
+				path = append(path, jsonPathSegment{name: name})
+			}
+			rest = rest[end:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("JSONPath %q has an unclosed bracket", expression)
+			}
+			selector := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+
+			switch {
+			case selector == "*":
+				path = append(path, jsonPathSegment{wildcard: true})
+			case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+				path = append(path, jsonPathSegment{name: selector[1 : len(selector)-1]})
+			default:
+				index, err := strconv.Atoi(selector)
+				if err != nil {
+					return nil, fmt.Errorf("JSONPath %q has an invalid selector [%s]", expression, selector)
+				}
+				path = append(path, jsonPathSegment{index: index, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("JSONPath %q has an unexpected character %q", expression, rest[0])
+		}
+	}
+
+	return path, nil
+}
+
+// evaluate returns every value in the document matched by the path
+func (p jsonPath) evaluate(document any) []any {
+	nodes := []any{document}
+
+	for _, segment := range p {
+		var next []any
+		for _, node := range nodes {
+			switch value := node.(type) {
+			case map[string]any:
+				if segment.wildcard {
+					keys := make([]string, 0, len(value))
+					for key := range value {
+						keys = append(keys, key)
+					}
+					slices.Sort(keys)
+					for _, key := range keys {
+						next = append(next, value[key])
+					}
+				} else if child, ok := value[segment.name]; ok && !segment.isIndex {
+					next = append(next, child)
+				}
+			case []any:
+				if segment.wildcard {
+					next = append(next, value...)
+				} else if segment.isIndex {
+					index := segment.index
+					if index < 0 {
+						index += len(value)
+					}
+					if index >= 0 && index < len(value) {
+						next = append(next, value[index])
+					}
+				}
+			}
+		}
+		nodes = next
+	}
+
+	return nodes
+}
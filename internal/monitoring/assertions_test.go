@@ -0,0 +1,141 @@
+package monitoring
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+const assertionTestBody = `{"status":"ok","version":"1.4.2","checks":{"db":{"latency_ms":12},"cache":{"latency_ms":3}},"items":[{"id":1,"healthy":true},{"id":2,"healthy":true}],"queue":{"depth":42}}`
+
+func assertionTestResponse() *HTTPResponse {
+	return &HTTPResponse{
+		StatusCode: 200,
+		Body:       assertionTestBody,
+		Headers: map[string]string{
+			"Content-Type":  "application/json; charset=utf-8",
+			"Cache-Control": "no-store",
+		},
+	}
+}
+
+func TestEvaluateAssertion(t *testing.T) {
+	tests := []struct {
+		name      string
+		assertion data.ResponseAssertion
+		pass      bool
+	}{
+		{"body contains", data.ResponseAssertion{Type: data.AssertionBodyContains, Value: `"status":"ok"`}, true},
+		{"body contains missing", data.ResponseAssertion{Type: data.AssertionBodyContains, Value: "maintenance"}, false},
+		{"body not contains", data.ResponseAssertion{Type: data.AssertionBodyNotContains, Value: "maintenance"}, true},
+		{"body not contains present", data.ResponseAssertion{Type: data.AssertionBodyNotContains, Value: "healthy"}, false},
+		{"body matches", data.ResponseAssertion{Type: data.AssertionBodyMatches, Value: `"version":"1\.\d+\.\d+"`}, true},
+		{"body does not match", data.ResponseAssertion{Type: data.AssertionBodyMatches, Value: `"version":"2\.`}, false},
+		{"json equals", data.ResponseAssertion{Type: data.AssertionJSONPath, Path: "$.status", Operator: data.AssertionOpEquals, Value: "ok"}, true},
+		{"json equals mismatch", data.ResponseAssertion{Type: data.AssertionJSONPath, Path: "$.status", Operator: data.AssertionOpEquals, Value: "degraded"}, false},
+		{"json numeric equals", data.ResponseAssertion{Type: data.AssertionJSONPath, Path: "$.queue.depth", Operator: data.AssertionOpEquals, Value: "42.0"}, true},
+		{"json not equals", data.ResponseAssertion{Type: data.AssertionJSONPath, Path: "$['status']", Operator: data.AssertionOpNotEquals, Value: "down"}, true},
+		{"json exists", data.ResponseAssertion{Type: data.AssertionJSONPath, Path: "$.checks.db", Operator: data.AssertionOpExists}, true},
+		{"json exists missing", data.ResponseAssertion{Type: data.AssertionJSONPath, Path: "$.checks.search", Operator: data.AssertionOpExists}, false},
+		{"json not exists", data.ResponseAssertion{Type: data.AssertionJSONPath, Path: "$.error", Operator: data.AssertionOpNotExists}, true},
+		{"json less than", data.ResponseAssertion{Type: data.AssertionJSONPath, Path: "$.queue.depth", Operator: data.AssertionOpLessThan, Value: "100"}, true},
+		{"json greater than", data.ResponseAssertion{Type: data.AssertionJSONPath, Path: "$.queue.depth", Operator: data.AssertionOpGreaterThan, Value: "100"}, false},
+		{"json wildcard all match", data.ResponseAssertion{Type: data.AssertionJSONPath, Path: "$.items[*].healthy", Operator: data.AssertionOpEquals, Value: "true"}, true},
+		{"json wildcard object", data.ResponseAssertion{Type: data.AssertionJSONPath, Path: "$.checks.*.latency_ms", Operator: data.AssertionOpLessThanOrEqual, Value: "12"}, true},
+		{"json negative index", data.ResponseAssertion{Type: data.AssertionJSONPath, Path: "$.items[-1].id", Operator: data.AssertionOpEquals, Value: "2"}, true},
+		{"json comparison on missing path", data.ResponseAssertion{Type: data.AssertionJSONPath, Path: "$.items[5].id", Operator: data.AssertionOpEquals, Value: "5"}, false},
+		{"json matches", data.ResponseAssertion{Type: data.AssertionJSONPath, Path: "$.version", Operator: data.AssertionOpMatches, Value: `^1\.`}, true},
+		{"header equals", data.ResponseAssertion{Type: data.AssertionHeader, Path: "cache-control", Operator: data.AssertionOpEquals, Value: "no-store"}, true},
+		{"header contains", data.ResponseAssertion{Type: data.AssertionHeader, Path: "Content-Type", Operator: data.AssertionOpContains, Value: "application/json"}, true},
+		{"header matches", data.ResponseAssertion{Type: data.AssertionHeader, Path: "Content-Type", Operator: data.AssertionOpMatches, Value: `^text/`}, false},
+		{"header missing", data.ResponseAssertion{Type: data.AssertionHeader, Path: "X-Request-Id", Operator: data.AssertionOpExists}, false},
+		{"header not exists", data.ResponseAssertion{Type: data.AssertionHeader, Path: "Server", Operator: data.AssertionOpNotExists}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := evaluateAssertion(tt.assertion, assertionTestResponse())
+			if tt.pass {
+				if err != nil {
+					t.Fatalf("Expected assertion to pass, got: %s", err.Description)
+				}
+			} else {
+				if err == nil {
+					t.Fatal("Expected assertion to fail")
+				}
+				assertEqual(t, "assertion_failed", err.Type)
+			}
+		})
+	}
+}
+
+func TestEvaluateAssertion_InvalidJSONBody(t *testing.T) {
+	response := &HTTPResponse{StatusCode: 200, Body: "<html>ok</html>"}
+	assertion := data.ResponseAssertion{Type: data.AssertionJSONPath, Path: "$.status", Operator: data.AssertionOpExists}
+
+	err := evaluateAssertion(assertion, response)
+	assertTrue(t, err != nil)
+	assertEqual(t, "json:$.status", err.Field)
+}
+
+func TestValidateAssertion(t *testing.T) {
+	tests := []struct {
+		name      string
+		assertion data.ResponseAssertion
+		valid     bool
+	}{
+		{"body contains", data.ResponseAssertion{Type: data.AssertionBodyContains, Value: "ok"}, true},
+		{"body contains without value", data.ResponseAssertion{Type: data.AssertionBodyContains}, false},
+		{"invalid regex", data.ResponseAssertion{Type: data.AssertionBodyMatches, Value: "("}, false},
+		{"unknown type", data.ResponseAssertion{Type: "xpath", Value: "/"}, false},
+		{"json path", data.ResponseAssertion{Type: data.AssertionJSONPath, Path: "$.data[0]['name']", Operator: data.AssertionOpExists}, true},
+		{"json path without root", data.ResponseAssertion{Type: data.AssertionJSONPath, Path: "data.name", Operator: data.AssertionOpExists}, false},
+		{"json path unclosed bracket", data.ResponseAssertion{Type: data.AssertionJSONPath, Path: "$.data[0", Operator: data.AssertionOpExists}, false},
+		{"json path missing operator", data.ResponseAssertion{Type: data.AssertionJSONPath, Path: "$.status"}, false},
+		{"comparison needs number", data.ResponseAssertion{Type: data.AssertionJSONPath, Path: "$.depth", Operator: data.AssertionOpLessThan, Value: "many"}, false},
+		{"header", data.ResponseAssertion{Type: data.AssertionHeader, Path: "Content-Type", Operator: data.AssertionOpEquals, Value: "text/plain"}, true},
+		{"header without name", data.ResponseAssertion{Type: data.AssertionHeader, Operator: data.AssertionOpExists}, false},
+		{"header comparison", data.ResponseAssertion{Type: data.AssertionHeader, Path: "Age", Operator: data.AssertionOpGreaterThan, Value: "1"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAssertion(tt.assertion)
+			if tt.valid {
+				assertNoError(t, err)
+			} else {
+				assertError(t, err)
+			}
+		})
+	}
+}
+
+func TestWorkerPool_ExecuteJob_AssertionFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"degraded"}`))
+	}))
+	defer server.Close()
+
+	wp := NewWorkerPool(WorkerPoolConfig{WorkerCount: 1, JobQueueSize: 1, ResultChanSize: 1}, log.New(io.Discard), NewMockDB(), nil)
+	endpoint := &data.Endpoint{
+		URL:                server.URL,
+		Method:             "GET",
+		ExpectedStatusCode: 200,
+		TimeoutSeconds:     5,
+		Assertions: data.ResponseAssertions{
+			{Type: data.AssertionJSONPath, Path: "$.status", Operator: data.AssertionOpEquals, Value: "ok"},
+		},
+	}
+
+	result := wp.executeJob(Job{Endpoint: endpoint, Timestamp: time.Now()})
+	assertTrue(t, !result.Success)
+	assertTrue(t, result.ErrorMessage != nil)
+	assertTrue(t, strings.Contains(*result.ErrorMessage, `Assertion failed: $.status equals "ok", got degraded`))
+}
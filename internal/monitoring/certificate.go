@@ -0,0 +1,89 @@
+package monitoring
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+// TLSCertificateInfo describes the leaf certificate presented by an HTTPS endpoint
+type TLSCertificateInfo struct {
+	Subject      string
+	Issuer       string
+	SANs         []string
+	SerialNumber string
+	NotBefore    time.Time
+	NotAfter     time.Time
+	ChainValid   bool
+	ChainError   string
+}
+
+// toModel converts the certificate details into the stored representation
+func (c *TLSCertificateInfo) toModel(endpointID uuid.UUID, checkedAt time.Time) *data.EndpointCertificate {
+	return &data.EndpointCertificate{
+		EndpointID:   endpointID,
+		Subject:      c.Subject,
+		Issuer:       c.Issuer,
+		SANs:         data.StringList(c.SANs),
+		SerialNumber: c.SerialNumber,
+		NotBefore:    c.NotBefore,
+		NotAfter:     c.NotAfter,
+		ChainValid:   c.ChainValid,
+		ChainError:   c.ChainError,
+		CheckedAt:    checkedAt,
+	}
+}
+
+// inspectCertificate extracts the leaf certificate details from a TLS connection and
+// verifies its chain against the given roots, independently of whether the transport
+// skipped verification
+func inspectCertificate(state *tls.ConnectionState, host string, roots *x509.CertPool) *TLSCertificateInfo {
+	if len(state.PeerCertificates) == 0 {
+		return nil
+	}
+
+	leaf := state.PeerCertificates[0]
+
+	sans := make([]string, 0, len(leaf.DNSNames)+len(leaf.IPAddresses))
+	sans = append(sans, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+
+	info := &TLSCertificateInfo{
+		Subject:      leaf.Subject.String(),
+		Issuer:       leaf.Issuer.String(),
+		SANs:         sans,
+		SerialNumber: leaf.SerialNumber.String(),
+		NotBefore:    leaf.NotBefore,
+		NotAfter:     leaf.NotAfter,
+	}
+
+	// The handshake already verified the chain unless verification was skipped
+	if len(state.VerifiedChains) > 0 {
+		info.ChainValid = true
+		return info
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		DNSName:       host,
+	}
+
+	if _, err := leaf.Verify(opts); err != nil {
+		info.ChainError = err.Error()
+	} else {
+		info.ChainValid = true
+	}
+
+	return info
+}
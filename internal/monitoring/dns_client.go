@@ -0,0 +1,227 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+// DNSResponse represents the result of a DNS resolution check
+type DNSResponse struct {
+	RecordType string
+	Resolver   string
+	Records    []string
+	BodySample *string
+}
+
+// DNSClient handles DNS resolution checks for monitoring
+type DNSClient struct {
+	config DNSClientConfig
+}
+
+// DNSClientConfig holds configuration for the DNS client
+type DNSClientConfig struct {
+	Timeout time.Duration
+}
+
+// NewDNSClient creates a new DNS client with the specified configuration
+func NewDNSClient(config DNSClientConfig) *DNSClient {
+	// Set defaults if not specified
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	return &DNSClient{
+		config: config,
+	}
+}
+
+// ExecuteCheck resolves the endpoint's hostname and verifies the answer contains
+// every expected value
+func (c *DNSClient) ExecuteCheck(endpoint *data.Endpoint) (*DNSResponse, error) {
+	host := strings.TrimSuffix(strings.TrimSpace(endpoint.URL), ".")
+	if host == "" {
+		return nil, fmt.Errorf("hostname is required")
+	}
+
+	recordType := strings.ToUpper(endpoint.DNSRecordType)
+	if recordType == "" {
+		recordType = "A"
+	}
+
+	timeout := c.config.Timeout
+	if endpoint.TimeoutSeconds > 0 {
+		timeout = time.Duration(endpoint.TimeoutSeconds) * time.Second
+	}
+
+	// Create lookup context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	resolver, resolverAddress, err := newResolver(endpoint.DNSResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := lookupRecords(ctx, resolver, host+".", recordType)
+	if err != nil {
+		return nil, fmt.Errorf("%s lookup for %s failed: %w", recordType, host, err)
+	}
+
+	response := &DNSResponse{
+		RecordType: recordType,
+		Resolver:   resolverAddress,
+		Records:    records,
+	}
+	if len(records) > 0 {
+		sample := truncateSample([]byte(strings.Join(records, ", ")))
+		response.BodySample = &sample
+	}
+
+	if len(records) == 0 {
+		return response, fmt.Errorf("%s lookup for %s returned no records", recordType, host)
+	}
+
+	var missing []string
+	for _, expected := range endpoint.DNSExpectedValues {
+		if !containsRecord(recordType, records, expected) {
+			missing = append(missing, expected)
+		}
+	}
+	if len(missing) > 0 {
+		return response, fmt.Errorf("%s records for %s missing expected values: %s", recordType, host, strings.Join(missing, ", "))
+	}
+
+	return response, nil
+}
+
+// newResolver returns a resolver that queries the given address, or the system resolver when empty
+func newResolver(address string) (*net.Resolver, string, error) {
+	if address == "" {
+		return net.DefaultResolver, "system", nil
+	}
+
+	address, err := ParseResolverAddress(address)
+	if err != nil {
+		return nil, "", err
+	}
+
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, address)
+		},
+	}
+
+	return resolver, address, nil
+}
+
+// lookupRecords queries the records of the given type and formats them as strings
+func lookupRecords(ctx context.Context, resolver *net.Resolver, host, recordType string) ([]string, error) {
+	var records []string
+
+	switch recordType {
+	case "A", "AAAA":
+		network := "ip4"
+		if recordType == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := resolver.LookupIP(ctx, network, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			records = append(records, ip.String())
+		}
+	case "CNAME":
+		cname, err := resolver.LookupCNAME(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, cname)
+	case "MX":
+		mxs, err := resolver.LookupMX(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range mxs {
+			records = append(records, fmt.Sprintf("%d %s", mx.Pref, mx.Host))
+		}
+	case "TXT":
+		txts, err := resolver.LookupTXT(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, txts...)
+	default:
+		return nil, fmt.Errorf("unsupported record type %q", recordType)
+	}
+
+	return records, nil
+}
+
+// containsRecord reports whether an expected value matches one of the resolved records.
+// Names are compared case-insensitively without the trailing dot, and MX values may be
+// given either as "preference host" or just the host.
+func containsRecord(recordType string, records []string, expected string) bool {
+	expected = strings.TrimSpace(expected)
+
+	for _, record := range records {
+		switch recordType {
+		case "A", "AAAA":
+			if ip := net.ParseIP(expected); ip != nil && ip.Equal(net.ParseIP(record)) {
+				return true
+			}
+		case "TXT":
+			if record == expected {
+				return true
+			}
+		case "MX":
+			if equalDNSNames(record, expected) {
+				return true
+			}
+			if _, host, ok := strings.Cut(record, " "); ok && equalDNSNames(host, expected) {
+				return true
+			}
+		default:
+			if equalDNSNames(record, expected) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// equalDNSNames compares two domain names case-insensitively, ignoring the trailing dot
+func equalDNSNames(a, b string) bool {
+	return strings.EqualFold(strings.TrimSuffix(a, "."), strings.TrimSuffix(b, "."))
+}
+
+// ParseResolverAddress normalizes a resolver address, defaulting to port 53
+func ParseResolverAddress(address string) (string, error) {
+	address = strings.TrimSpace(address)
+
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		// No port given, treat the whole value as the host
+		host = strings.Trim(address, "[]")
+		portStr = "53"
+	}
+	if host == "" {
+		return "", fmt.Errorf("invalid resolver address %q: missing host", address)
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return "", fmt.Errorf("invalid resolver address %q: port must be between 1 and 65535", address)
+	}
+
+	return net.JoinHostPort(host, portStr), nil
+}
@@ -0,0 +1,301 @@
+package monitoring
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+// DNS record types used by the test server
+const (
+	dnsTypeA     = 1
+	dnsTypeCNAME = 5
+	dnsTypeMX    = 15
+	dnsTypeTXT   = 16
+	dnsTypeAAAA  = 28
+)
+
+// testDNSRecord is a resource record served by the test DNS server
+type testDNSRecord struct {
+	Name  string
+	Type  uint16
+	RData []byte
+}
+
+// startDNSServer starts an in-process UDP DNS server answering from the given records
+func startDNSServer(t *testing.T, records []testDNSRecord) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start DNS server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if response := buildDNSResponse(buf[:n], records); response != nil {
+				conn.WriteTo(response, addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+// buildDNSResponse answers a single-question query, following CNAMEs for address lookups
+func buildDNSResponse(query []byte, records []testDNSRecord) []byte {
+	if len(query) < 12 {
+		return nil
+	}
+
+	// Parse the question name
+	offset := 12
+	var labels []string
+	for offset < len(query) && query[offset] != 0 {
+		length := int(query[offset])
+		if offset+1+length > len(query) {
+			return nil
+		}
+		labels = append(labels, string(query[offset+1:offset+1+length]))
+		offset += 1 + length
+	}
+	offset++ // terminating zero
+	if offset+4 > len(query) {
+		return nil
+	}
+	qtype := binary.BigEndian.Uint16(query[offset:])
+	questionEnd := offset + 4
+	name := strings.ToLower(strings.Join(labels, "."))
+
+	var answers []testDNSRecord
+	for {
+		var cname string
+		for _, record := range records {
+			if record.Name != name {
+				continue
+			}
+			if record.Type == qtype {
+				answers = append(answers, record)
+			} else if record.Type == dnsTypeCNAME && (qtype == dnsTypeA || qtype == dnsTypeAAAA) {
+				answers = append(answers, record)
+				cname = decodeTestDNSName(record.RData)
+			}
+		}
+		if cname == "" {
+			break
+		}
+		name = cname
+	}
+
+	rcode := uint16(0)
+	if len(answers) == 0 {
+		rcode = 3 // NXDOMAIN
+	}
+
+	response := make([]byte, 12, 512)
+	copy(response, query[:2])
+	binary.BigEndian.PutUint16(response[2:], 0x8180|rcode)
+	binary.BigEndian.PutUint16(response[4:], 1)
+	binary.BigEndian.PutUint16(response[6:], uint16(len(answers)))
+	response = append(response, query[12:questionEnd]...)
+
+	for _, answer := range answers {
+		response = append(response, encodeTestDNSName(answer.Name)...)
+		response = binary.BigEndian.AppendUint16(response, answer.Type)
+		response = binary.BigEndian.AppendUint16(response, 1) // IN
+		response = binary.BigEndian.AppendUint32(response, 60)
+		response = binary.BigEndian.AppendUint16(response, uint16(len(answer.RData)))
+		response = append(response, answer.RData...)
+	}
+
+	return response
+}
+
+func encodeTestDNSName(name string) []byte {
+	var encoded []byte
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		encoded = append(encoded, byte(len(label)))
+		encoded = append(encoded, label...)
+	}
+	return append(encoded, 0)
+}
+
+func decodeTestDNSName(encoded []byte) string {
+	var labels []string
+	for i := 0; i < len(encoded) && encoded[i] != 0; i += 1 + int(encoded[i]) {
+		labels = append(labels, string(encoded[i+1:i+1+int(encoded[i])]))
+	}
+	return strings.Join(labels, ".")
+}
+
+func testARecord(name, ip string) testDNSRecord {
+	return testDNSRecord{Name: name, Type: dnsTypeA, RData: net.ParseIP(ip).To4()}
+}
+
+func testAAAARecord(name, ip string) testDNSRecord {
+	return testDNSRecord{Name: name, Type: dnsTypeAAAA, RData: net.ParseIP(ip).To16()}
+}
+
+func testCNAMERecord(name, target string) testDNSRecord {
+	return testDNSRecord{Name: name, Type: dnsTypeCNAME, RData: encodeTestDNSName(target)}
+}
+
+func testMXRecord(name string, pref uint16, host string) testDNSRecord {
+	rdata := binary.BigEndian.AppendUint16(nil, pref)
+	return testDNSRecord{Name: name, Type: dnsTypeMX, RData: append(rdata, encodeTestDNSName(host)...)}
+}
+
+func testTXTRecord(name, text string) testDNSRecord {
+	return testDNSRecord{Name: name, Type: dnsTypeTXT, RData: append([]byte{byte(len(text))}, text...)}
+}
+
+func newTestDNSServer(t *testing.T) string {
+	return startDNSServer(t, []testDNSRecord{
+		testARecord("app.watchtower.test", "192.0.2.10"),
+		testARecord("app.watchtower.test", "192.0.2.11"),
+		testAAAARecord("app.watchtower.test", "2001:db8::10"),
+		testCNAMERecord("www.watchtower.test", "app.watchtower.test"),
+		testMXRecord("watchtower.test", 10, "mx1.watchtower.test"),
+		testMXRecord("watchtower.test", 20, "mx2.watchtower.test"),
+		testTXTRecord("watchtower.test", "v=spf1 include:_spf.watchtower.test ~all"),
+	})
+}
+
+func TestNewDNSClient(t *testing.T) {
+	client := NewDNSClient(DNSClientConfig{})
+
+	assertTrue(t, client != nil)
+	assertEqual(t, 30*time.Second, client.config.Timeout)
+}
+
+func TestDNSClient_ExecuteCheck_Records(t *testing.T) {
+	resolver := newTestDNSServer(t)
+	client := NewDNSClient(DNSClientConfig{})
+
+	tests := []struct {
+		name       string
+		host       string
+		recordType string
+		expected   []string
+	}{
+		{"A", "app.watchtower.test", "A", []string{"192.0.2.10", "192.0.2.11"}},
+		{"AAAA", "app.watchtower.test", "AAAA", []string{"2001:db8::10"}},
+		{"CNAME", "www.watchtower.test", "CNAME", []string{"app.watchtower.test"}},
+		{"MX", "watchtower.test", "MX", []string{"mx1.watchtower.test.", "20 mx2.watchtower.test"}},
+		{"TXT", "watchtower.test", "TXT", []string{"v=spf1 include:_spf.watchtower.test ~all"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &data.Endpoint{
+				CheckType:         data.CheckTypeDNS,
+				URL:               tt.host,
+				DNSRecordType:     tt.recordType,
+				DNSResolver:       resolver,
+				DNSExpectedValues: tt.expected,
+				TimeoutSeconds:    5,
+			}
+
+			response, err := client.ExecuteCheck(endpoint)
+			assertNoError(t, err)
+			if response == nil {
+				t.Fatal("Expected response, got nil")
+			}
+			assertEqual(t, resolver, response.Resolver)
+			assertTrue(t, response.BodySample != nil)
+		})
+	}
+}
+
+func TestDNSClient_ExecuteCheck_MissingExpectedValue(t *testing.T) {
+	resolver := newTestDNSServer(t)
+	client := NewDNSClient(DNSClientConfig{})
+
+	endpoint := &data.Endpoint{
+		CheckType:         data.CheckTypeDNS,
+		URL:               "app.watchtower.test",
+		DNSRecordType:     "A",
+		DNSResolver:       resolver,
+		DNSExpectedValues: data.StringList{"192.0.2.10", "198.51.100.1"},
+		TimeoutSeconds:    5,
+	}
+
+	response, err := client.ExecuteCheck(endpoint)
+	assertError(t, err)
+	assertTrue(t, strings.Contains(err.Error(), "198.51.100.1"))
+	assertTrue(t, response != nil && response.BodySample != nil)
+	assertTrue(t, strings.Contains(*response.BodySample, "192.0.2.10"))
+}
+
+func TestDNSClient_ExecuteCheck_NXDomain(t *testing.T) {
+	resolver := newTestDNSServer(t)
+	client := NewDNSClient(DNSClientConfig{})
+
+	endpoint := &data.Endpoint{
+		CheckType:      data.CheckTypeDNS,
+		URL:            "missing.watchtower.test",
+		DNSRecordType:  "A",
+		DNSResolver:    resolver,
+		TimeoutSeconds: 5,
+	}
+
+	_, err := client.ExecuteCheck(endpoint)
+	assertError(t, err)
+}
+
+func TestParseResolverAddress(t *testing.T) {
+	tests := []struct {
+		address  string
+		expected string
+		valid    bool
+	}{
+		{"1.1.1.1", "1.1.1.1:53", true},
+		{"1.1.1.1:5353", "1.1.1.1:5353", true},
+		{"2606:4700:4700::1111", "[2606:4700:4700::1111]:53", true},
+		{"[2606:4700:4700::1111]:53", "[2606:4700:4700::1111]:53", true},
+		{"ns1.example.com", "ns1.example.com:53", true},
+		{"1.1.1.1:0", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		address, err := ParseResolverAddress(tt.address)
+		if tt.valid {
+			assertNoError(t, err)
+			assertEqual(t, tt.expected, address)
+		} else {
+			assertError(t, err)
+		}
+	}
+}
+
+func TestWorkerPool_ExecuteJob_DNS(t *testing.T) {
+	resolver := newTestDNSServer(t)
+
+	wp := NewWorkerPool(WorkerPoolConfig{WorkerCount: 1, JobQueueSize: 1, ResultChanSize: 1}, log.New(io.Discard), NewMockDB(), nil)
+	endpoint := &data.Endpoint{
+		CheckType:         data.CheckTypeDNS,
+		URL:               "app.watchtower.test",
+		DNSRecordType:     "A",
+		DNSResolver:       resolver,
+		DNSExpectedValues: data.StringList{"192.0.2.11"},
+		TimeoutSeconds:    5,
+	}
+
+	result := wp.executeJob(Job{Endpoint: endpoint, Timestamp: time.Now()})
+	assertTrue(t, result.Success)
+	assertTrue(t, result.ResponseSample != nil)
+	assertEqual(t, "192.0.2.10, 192.0.2.11", *result.ResponseSample)
+}
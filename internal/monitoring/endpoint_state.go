@@ -0,0 +1,146 @@
+package monitoring
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+// EndpointState is the health state the incident detector derives from an endpoint's results
+type EndpointState string
+
+const (
+	// EndpointStateUnknown is the state before the first success or enough failures have been seen
+	EndpointStateUnknown EndpointState = "unknown"
+	// EndpointStateUp means the endpoint is considered healthy
+	EndpointStateUp EndpointState = "up"
+	// EndpointStateDown means the endpoint crossed the consecutive failure threshold
+	EndpointStateDown EndpointState = "down"
+)
+
+// StateTransition describes an endpoint moving from one state to another
+type StateTransition struct {
+	EndpointID   uuid.UUID
+	EndpointName string
+	From         EndpointState
+	To           EndpointState
+	// FromSince is when the endpoint entered the From state, zero for unknown
+	FromSince time.Time
+	// Result is the check result that caused the transition
+	Result Result
+	// ConsecutiveFailures and ConsecutiveSuccesses are the streaks at the time of the transition
+	ConsecutiveFailures  int
+	ConsecutiveSuccesses int
+	// IncidentID is the incident opened or resolved by the transition, if any
+	IncidentID *uuid.UUID
+	// ParentID is set when the endpoint depends on a down parent, IncidentID is then the
+	// parent's incident the endpoint was added to or removed from
+	ParentID *uuid.UUID
+	At       time.Time
+}
+
+// TransitionListener is notified of every endpoint state transition. Listeners are called
+// from the detector's goroutine and must not block
+type TransitionListener func(transition StateTransition)
+
+// FailureTracker tracks failure patterns for an endpoint
+type FailureTracker struct {
+	EndpointID          uuid.UUID
+	State               EndpointState
+	StateSince          time.Time
+	ConsecutiveFailures int
+	ConsecutiveSuccess  int
+	FailingSince        time.Time // first failure of the current failure streak
+	LastFailureTime     time.Time
+	LastSuccessTime     time.Time
+	RecentResults       []Result // results within the failure window, used to grade severity
+}
+
+// newFailureTracker returns a tracker for an endpoint whose state is not yet known
+func newFailureTracker(endpointID uuid.UUID) *FailureTracker {
+	return &FailureTracker{
+		EndpointID: endpointID,
+		State:      EndpointStateUnknown,
+	}
+}
+
+// record applies a result to the tracker and returns the new state and whether it changed.
+// An endpoint goes down once failureThreshold consecutive failures are seen and comes back
+// up after recoveryThreshold consecutive successes; an unknown endpoint is up on its first success.
+// Failures during maintenance are ignored so planned downtime never takes an endpoint down,
+// successes still count and may resolve an outage that started before the window
+func (t *FailureTracker) record(result Result, failureThreshold, recoveryThreshold int, window time.Duration) (EndpointState, bool) {
+	if result.InMaintenance && !result.Success {
+		return t.State, false
+	}
+
+	failureThreshold = max(failureThreshold, 1)
+	recoveryThreshold = max(recoveryThreshold, 1)
+
+	t.RecentResults = append(t.RecentResults, result)
+	cutoff := result.ExecutedAt.Add(-window)
+	for len(t.RecentResults) > 1 && t.RecentResults[0].ExecutedAt.Before(cutoff) {
+		t.RecentResults = t.RecentResults[1:]
+	}
+
+	next := t.State
+	if result.Success {
+		t.ConsecutiveSuccess++
+		t.ConsecutiveFailures = 0
+		t.LastSuccessTime = result.ExecutedAt
+
+		switch {
+		case t.State == EndpointStateUnknown:
+			next = EndpointStateUp
+		case t.State == EndpointStateDown && t.ConsecutiveSuccess >= recoveryThreshold:
+			next = EndpointStateUp
+		}
+	} else {
+		if t.ConsecutiveFailures == 0 {
+			t.FailingSince = result.ExecutedAt
+		}
+		t.ConsecutiveFailures++
+		t.ConsecutiveSuccess = 0
+		t.LastFailureTime = result.ExecutedAt
+
+		if t.State != EndpointStateDown && t.ConsecutiveFailures >= failureThreshold {
+			next = EndpointStateDown
+		}
+	}
+
+	if next == t.State {
+		return t.State, false
+	}
+
+	t.State = next
+	t.StateSince = result.ExecutedAt
+	return next, true
+}
+
+// IncidentEventType identifies what happened to an incident managed by the detector
+type IncidentEventType string
+
+const (
+	IncidentEventCreated  IncidentEventType = "created"
+	IncidentEventResolved IncidentEventType = "resolved"
+	// IncidentEventMerged closes the incident of an endpoint moved into the incident of a
+	// parent it depends on. The endpoint is still down, its outage continues in that incident
+	IncidentEventMerged IncidentEventType = "merged"
+)
+
+// IncidentEvent describes an incident the detector opened, resolved or merged
+type IncidentEvent struct {
+	Type         IncidentEventType
+	Incident     data.Incident
+	EndpointID   uuid.UUID
+	EndpointName string
+	// MergedInto and ParentName are the incident a merged incident moved into and the name of
+	// the parent endpoint it belongs to
+	MergedInto *uuid.UUID
+	ParentName string
+}
+
+// IncidentListener is notified of incidents opened, resolved and merged by the detector.
+// Listeners are called from the detector's goroutine and must not block
+type IncidentListener func(event IncidentEvent)
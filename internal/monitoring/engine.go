@@ -16,18 +16,21 @@ import (
 
 // MonitoringEngine is the main monitoring system that coordinates all components
 type MonitoringEngine struct {
-	workerPool       *WorkerPool
-	scheduler        *Scheduler
-	incidentDetector *IncidentDetector
-	db               *data.DB
-	logger           *log.Logger
-	config           EngineConfig
-	ctx              context.Context
-	cancel           context.CancelFunc
-	wg               sync.WaitGroup
-	isRunning        bool
-	mu               sync.RWMutex
-	resultCallback   ResultCallback
+	workerPool          *WorkerPool
+	scheduler           *Scheduler
+	incidentDetector    *IncidentDetector
+	db                  *data.DB
+	logger              *log.Logger
+	config              EngineConfig
+	ctx                 context.Context
+	cancel              context.CancelFunc
+	wg                  sync.WaitGroup
+	isRunning           bool
+	mu                  sync.RWMutex
+	resultCallback      ResultCallback
+	transitionListeners []TransitionListener
+	incidentListeners   []IncidentListener
+	maintenance         MaintenanceChecker
 }
 
 // EngineConfig holds configuration for the monitoring engine
@@ -63,7 +66,6 @@ func DefaultEngineConfig() EngineConfig {
 			RetryDelay:         1 * time.Second,
 			MaxRetryDelay:      10 * time.Second,
 			ConnectTimeout:     10 * time.Second,
-			ResponseTimeout:    30 * time.Second,
 			MaxResponseBodyKB:  64,
 			InsecureSkipVerify: false,
 			FollowRedirects:    true,
@@ -93,6 +95,37 @@ func (e *MonitoringEngine) SetResultCallback(callback ResultCallback) {
 	e.resultCallback = callback
 }
 
+// OnStateTransition registers a listener for endpoint up/down transitions
+func (e *MonitoringEngine) OnStateTransition(listener TransitionListener) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.transitionListeners = append(e.transitionListeners, listener)
+	if e.incidentDetector != nil {
+		e.incidentDetector.OnTransition(listener)
+	}
+}
+
+// OnIncident registers a listener for incidents opened and resolved by the incident detector
+func (e *MonitoringEngine) OnIncident(listener IncidentListener) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.incidentListeners = append(e.incidentListeners, listener)
+	if e.incidentDetector != nil {
+		e.incidentDetector.OnIncident(listener)
+	}
+}
+
+// UseMaintenance sets the checker deciding which endpoints are in a maintenance window. Failures
+// during maintenance open no incidents and the checks are marked so uptime can leave them out.
+// It must be called before Start
+func (e *MonitoringEngine) UseMaintenance(checker MaintenanceChecker) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.maintenance = checker
+}
+
 // Start starts the monitoring engine
 func (e *MonitoringEngine) Start() error {
 	e.mu.Lock()
@@ -104,14 +137,31 @@ func (e *MonitoringEngine) Start() error {
 
 	e.logger.Info("starting monitoring engine")
 
-	// Create worker pool
-	e.workerPool = NewWorkerPool(e.config.WorkerPoolConfig, e.logger, e.db, e.resultCallback)
+	// Create worker pool, using the engine's HTTP settings as endpoint defaults
+	workerPoolConfig := e.config.WorkerPoolConfig
+	workerPoolConfig.HTTPClientConfig = e.config.HTTPClientConfig
+	e.workerPool = NewWorkerPool(workerPoolConfig, e.logger, e.db, e.resultCallback)
+	if e.maintenance != nil {
+		e.workerPool.UseMaintenance(e.maintenance)
+	}
 
 	// Create scheduler with the database as endpoint provider
 	e.scheduler = NewScheduler(e.config.SchedulerConfig, e.workerPool, e.db, e.logger)
 
 	// Create incident detector
 	e.incidentDetector = NewIncidentDetector(e.config.IncidentDetectorConfig, e.db, e.logger)
+	for _, listener := range e.transitionListeners {
+		e.incidentDetector.OnTransition(listener)
+	}
+	for _, listener := range e.incidentListeners {
+		e.incidentDetector.OnIncident(listener)
+	}
+
+	// Stream results to the scheduler and incident detector as they are stored
+	e.workerPool.AddResultHandler(func(result Result) {
+		e.scheduler.OnJobResult(result.Job.EndpointID, result.Success)
+		e.incidentDetector.HandleResult(result)
+	})
 
 	// Start worker pool
 	e.workerPool.Start()
@@ -122,6 +172,9 @@ func (e *MonitoringEngine) Start() error {
 		return fmt.Errorf("failed to start scheduler: %w", err)
 	}
 
+	// Pick up incidents and failure streaks from before the last restart
+	e.restoreState()
+
 	// Start incident detector
 	if err := e.incidentDetector.Start(); err != nil {
 		e.scheduler.Stop()
@@ -129,10 +182,6 @@ func (e *MonitoringEngine) Start() error {
 		return fmt.Errorf("failed to start incident detector: %w", err)
 	}
 
-	// Start job result monitoring
-	e.wg.Add(1)
-	go e.resultMonitor()
-
 	// Start health monitoring
 	e.wg.Add(1)
 	go e.healthMonitor()
@@ -215,57 +264,38 @@ func (e *MonitoringEngine) GetStatus() EngineStatus {
 	return status
 }
 
-// resultMonitor monitors job results and updates scheduler state
-func (e *MonitoringEngine) resultMonitor() {
-	defer e.wg.Done()
-
-	e.logger.Debug("starting result monitor")
-
-	// This would typically listen to worker pool results
-	// For now, we'll implement a simple monitoring loop
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
+// GetEndpointStates returns the current state of every endpoint the incident detector tracks,
+// nil while the engine has not been started
+func (e *MonitoringEngine) GetEndpointStates() map[uuid.UUID]EndpointState {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
 
-	for {
-		select {
-		case <-ticker.C:
-			// Check for recent monitoring results and update scheduler
-			e.updateSchedulerFromResults()
-		case <-e.ctx.Done():
-			e.logger.Debug("result monitor stopping")
-			return
-		}
+	if e.incidentDetector == nil {
+		return nil
 	}
+	return e.incidentDetector.GetEndpointStates()
 }
 
-// updateSchedulerFromResults checks recent monitoring results and updates scheduler
-func (e *MonitoringEngine) updateSchedulerFromResults() {
-	// Get recent monitoring logs (last 10 minutes)
-	logs, err := e.db.GetRecentMonitoringLogs(10) // 10 minutes
+// restoreState rebuilds scheduler and incident detector state from the database so a restart
+// neither duplicates open auto-created incidents nor leaves them unresolved
+func (e *MonitoringEngine) restoreState() {
+	openIncidents, err := e.db.GetOpenAutoEndpointIncidents()
 	if err != nil {
-		e.logger.Error("failed to get recent monitoring logs", "error", err)
+		e.logger.Error("failed to load open incidents, detector state not restored", "error", err)
 		return
 	}
 
-	// Track results per endpoint
-	endpointResults := make(map[string][]bool)
-	for _, log := range logs {
-		endpointID := log.EndpointID.String()
-		endpointResults[endpointID] = append(endpointResults[endpointID], log.Success)
+	// Enough history to reconstruct every threshold the detector and scheduler track
+	detectorConfig := e.config.IncidentDetectorConfig
+	perEndpoint := max(detectorConfig.ConsecutiveFailures, detectorConfig.RecoveryThreshold, e.config.SchedulerConfig.MaxFailures, 1)
+	logs, err := e.db.GetLatestMonitoringLogs(perEndpoint)
+	if err != nil {
+		e.logger.Error("failed to load latest monitoring logs, detector state not restored", "error", err)
+		return
 	}
 
-	// Update scheduler with results
-	for endpointIDStr, results := range endpointResults {
-		if len(results) > 0 {
-			// Use the most recent result
-			lastResult := results[len(results)-1]
-
-			// Parse endpoint ID
-			if endpointID, err := parseUUID(endpointIDStr); err == nil {
-				e.scheduler.OnJobResult(endpointID, lastResult)
-			}
-		}
-	}
+	e.scheduler.RestoreState(logs)
+	e.incidentDetector.Restore(openIncidents, logs)
 }
 
 // healthMonitor monitors the health of the monitoring engine components
@@ -361,6 +391,15 @@ func (e *MonitoringEngine) UpdateEndpoint(endpoint *data.Endpoint) error {
 	return nil
 }
 
+// RecordHeartbeat records a ping received for a heartbeat endpoint
+func (e *MonitoringEngine) RecordHeartbeat(endpointID uuid.UUID, ping HeartbeatPing) error {
+	if !e.IsRunning() {
+		return fmt.Errorf("monitoring engine is not running")
+	}
+
+	return e.scheduler.RecordHeartbeat(endpointID, ping)
+}
+
 // RunWithGracefulShutdown runs the monitoring engine with signal handling for graceful shutdown
 func (e *MonitoringEngine) RunWithGracefulShutdown() error {
 	// Set up signal handling
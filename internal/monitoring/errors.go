@@ -20,4 +20,10 @@ var (
 
 	// ErrMaxRetriesExceeded is returned when maximum retry attempts are exceeded
 	ErrMaxRetriesExceeded = errors.New("maximum retry attempts exceeded")
+
+	// ErrResultQueueFull is returned when the result queue is full and cannot accept more results
+	ErrResultQueueFull = errors.New("result queue is full")
+
+	// ErrEndpointNotScheduled is returned when a heartbeat is received for an endpoint that is not being monitored
+	ErrEndpointNotScheduled = errors.New("endpoint is not scheduled")
 )
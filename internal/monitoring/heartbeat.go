@@ -0,0 +1,141 @@
+package monitoring
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HeartbeatSignal identifies what a heartbeat ping reports
+type HeartbeatSignal string
+
+const (
+	// HeartbeatStart marks the beginning of a job run
+	HeartbeatStart HeartbeatSignal = "start"
+	// HeartbeatSuccess reports a successfully completed run
+	HeartbeatSuccess HeartbeatSignal = "success"
+	// HeartbeatFail reports a failed run
+	HeartbeatFail HeartbeatSignal = "fail"
+)
+
+// HeartbeatPing is a ping received from a job monitored by a heartbeat endpoint
+type HeartbeatPing struct {
+	Signal     HeartbeatSignal
+	ExitCode   *int   // exit code reported by the job, if any
+	Body       []byte // optional output sent along with the ping
+	ReceivedAt time.Time
+}
+
+// RecordHeartbeat applies a ping to a heartbeat endpoint's schedule. Start pings only
+// open a run, giving it at least the grace window to finish; success and fail pings
+// are stored as monitoring results and push the deadline out by another period.
+func (s *Scheduler) RecordHeartbeat(endpointID uuid.UUID, ping HeartbeatPing) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scheduled, exists := s.endpoints[endpointID]
+	if !exists || !scheduled.IsActive {
+		return ErrEndpointNotScheduled
+	}
+
+	if ping.ReceivedAt.IsZero() {
+		ping.ReceivedAt = time.Now()
+	}
+
+	if ping.Signal == HeartbeatStart {
+		scheduled.StartedAt = &ping.ReceivedAt
+		grace := time.Duration(scheduled.Endpoint.HeartbeatGraceSeconds) * time.Second
+		if runDeadline := ping.ReceivedAt.Add(grace); runDeadline.After(scheduled.NextRun) {
+			scheduled.NextRun = runDeadline
+		}
+
+		s.logger.Debug("heartbeat run started", "endpoint_id", endpointID, "next_run", scheduled.NextRun)
+		return nil
+	}
+
+	result := Result{
+		Job: Job{
+			ID:         uuid.New(),
+			EndpointID: endpointID,
+			Endpoint:   scheduled.Endpoint,
+			Timestamp:  ping.ReceivedAt,
+		},
+		Success:    ping.Signal == HeartbeatSuccess,
+		ExecutedAt: ping.ReceivedAt,
+	}
+
+	// Report the job duration when the run was opened with a start ping
+	if scheduled.StartedAt != nil {
+		durationMs := int(ping.ReceivedAt.Sub(*scheduled.StartedAt).Milliseconds())
+		result.ResponseTimeMs = &durationMs
+	}
+
+	if len(ping.Body) > 0 {
+		sample := truncateSample(ping.Body)
+		result.ResponseSample = &sample
+	}
+
+	if !result.Success {
+		errMsg := "job reported failure"
+		if ping.ExitCode != nil {
+			errMsg = fmt.Sprintf("job exited with code %d", *ping.ExitCode)
+		}
+		result.ErrorMessage = &errMsg
+	}
+
+	if err := s.workerPool.SubmitResult(result); err != nil {
+		return fmt.Errorf("failed to record heartbeat: %w", err)
+	}
+
+	scheduled.LastRun = &ping.ReceivedAt
+	scheduled.NextRun = scheduled.dueAfter(ping.ReceivedAt)
+	scheduled.StartedAt = nil
+
+	s.logger.Debug("heartbeat received",
+		"endpoint_id", endpointID,
+		"signal", ping.Signal,
+		"next_run", scheduled.NextRun)
+
+	return nil
+}
+
+// reportMissedHeartbeat records a failed result for a heartbeat endpoint whose
+// deadline passed without a ping, then waits another period before reporting again
+func (s *Scheduler) reportMissedHeartbeat(endpointID uuid.UUID, scheduled *ScheduledEndpoint, now time.Time) {
+	window := scheduled.Interval + time.Duration(scheduled.Endpoint.HeartbeatGraceSeconds)*time.Second
+
+	var errMsg string
+	switch {
+	case scheduled.StartedAt != nil:
+		errMsg = fmt.Sprintf("job started at %s did not complete", scheduled.StartedAt.UTC().Format(time.RFC3339))
+	case scheduled.LastRun != nil:
+		errMsg = fmt.Sprintf("no heartbeat received within %s, last ping at %s", window, scheduled.LastRun.UTC().Format(time.RFC3339))
+	default:
+		errMsg = fmt.Sprintf("no heartbeat received within %s", window)
+	}
+
+	result := Result{
+		Job: Job{
+			ID:         uuid.New(),
+			EndpointID: endpointID,
+			Endpoint:   scheduled.Endpoint,
+			Timestamp:  now,
+		},
+		Success:      false,
+		ErrorMessage: &errMsg,
+		ExecutedAt:   now,
+	}
+
+	if err := s.workerPool.SubmitResult(result); err != nil {
+		s.logger.Error("failed to submit missed heartbeat", "endpoint_id", endpointID, "error", err)
+		// Don't update NextRun so the miss is reported on the next tick
+		return
+	}
+
+	scheduled.NextRun = now.Add(scheduled.Interval)
+
+	s.logger.Debug("heartbeat missed",
+		"endpoint_id", endpointID,
+		"next_run", scheduled.NextRun)
+}
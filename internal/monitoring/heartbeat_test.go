@@ -0,0 +1,135 @@
+package monitoring
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+// newHeartbeatScheduler returns a scheduler with a single heartbeat endpoint and a
+// worker pool whose results can be read directly from its result channel
+func newHeartbeatScheduler(t *testing.T) (*Scheduler, *WorkerPool, *data.Endpoint) {
+	t.Helper()
+
+	logger := log.New(io.Discard)
+	wp := NewWorkerPool(WorkerPoolConfig{WorkerCount: 1, JobQueueSize: 1, ResultChanSize: 4}, logger, NewMockDB(), nil)
+	scheduler := NewScheduler(SchedulerConfig{}, wp, nil, logger)
+
+	endpoint := &data.Endpoint{
+		ID:                    uuid.New(),
+		Name:                  "nightly-backup",
+		CheckType:             data.CheckTypeHeartbeat,
+		HeartbeatToken:        "token",
+		HeartbeatGraceSeconds: 60,
+		CheckIntervalSeconds:  3600,
+		Enabled:               true,
+	}
+	scheduler.AddEndpoint(endpoint)
+
+	return scheduler, wp, endpoint
+}
+
+func receiveResult(t *testing.T, wp *WorkerPool) Result {
+	t.Helper()
+
+	select {
+	case result := <-wp.resultChan:
+		return result
+	default:
+		t.Fatal("Expected a result to be submitted")
+		return Result{}
+	}
+}
+
+func TestScheduler_AddHeartbeatEndpoint_IncludesGrace(t *testing.T) {
+	before := time.Now()
+	scheduler, _, endpoint := newHeartbeatScheduler(t)
+
+	nextRun := scheduler.endpoints[endpoint.ID].NextRun
+	assertTrue(t, !nextRun.Before(before.Add(time.Hour+time.Minute)))
+}
+
+func TestScheduler_RecordHeartbeat_Success(t *testing.T) {
+	scheduler, wp, endpoint := newHeartbeatScheduler(t)
+
+	started := time.Now()
+	assertNoError(t, scheduler.RecordHeartbeat(endpoint.ID, HeartbeatPing{Signal: HeartbeatStart, ReceivedAt: started}))
+	assertEqual(t, 0, len(wp.resultChan))
+
+	finished := started.Add(90 * time.Second)
+	err := scheduler.RecordHeartbeat(endpoint.ID, HeartbeatPing{
+		Signal:     HeartbeatSuccess,
+		Body:       []byte("backup completed"),
+		ReceivedAt: finished,
+	})
+	assertNoError(t, err)
+
+	result := receiveResult(t, wp)
+	assertTrue(t, result.Success)
+	assertEqual(t, endpoint.ID, result.Job.EndpointID)
+	assertTrue(t, result.ResponseTimeMs != nil)
+	assertEqual(t, 90000, *result.ResponseTimeMs)
+	assertTrue(t, result.ResponseSample != nil)
+	assertEqual(t, "backup completed", *result.ResponseSample)
+
+	scheduled := scheduler.endpoints[endpoint.ID]
+	assertTrue(t, scheduled.StartedAt == nil)
+	assertEqual(t, finished.Add(time.Hour+time.Minute), scheduled.NextRun)
+}
+
+func TestScheduler_RecordHeartbeat_ExitCode(t *testing.T) {
+	scheduler, wp, endpoint := newHeartbeatScheduler(t)
+
+	exitCode := 3
+	err := scheduler.RecordHeartbeat(endpoint.ID, HeartbeatPing{Signal: HeartbeatFail, ExitCode: &exitCode})
+	assertNoError(t, err)
+
+	result := receiveResult(t, wp)
+	assertTrue(t, !result.Success)
+	assertTrue(t, result.ResponseTimeMs == nil)
+	assertTrue(t, result.ErrorMessage != nil)
+	assertEqual(t, "job exited with code 3", *result.ErrorMessage)
+}
+
+func TestScheduler_RecordHeartbeat_StartExtendsDeadline(t *testing.T) {
+	scheduler, _, endpoint := newHeartbeatScheduler(t)
+
+	scheduled := scheduler.endpoints[endpoint.ID]
+	started := time.Now()
+	scheduled.NextRun = started.Add(time.Second)
+
+	assertNoError(t, scheduler.RecordHeartbeat(endpoint.ID, HeartbeatPing{Signal: HeartbeatStart, ReceivedAt: started}))
+	assertEqual(t, started.Add(time.Minute), scheduled.NextRun)
+}
+
+func TestScheduler_RecordHeartbeat_NotScheduled(t *testing.T) {
+	scheduler, _, _ := newHeartbeatScheduler(t)
+
+	err := scheduler.RecordHeartbeat(uuid.New(), HeartbeatPing{Signal: HeartbeatSuccess})
+	assertTrue(t, errors.Is(err, ErrEndpointNotScheduled))
+}
+
+func TestScheduler_ProcessPendingJobs_MissedHeartbeat(t *testing.T) {
+	scheduler, wp, endpoint := newHeartbeatScheduler(t)
+
+	scheduled := scheduler.endpoints[endpoint.ID]
+	scheduled.NextRun = time.Now().Add(-time.Second)
+
+	before := time.Now()
+	scheduler.processPendingJobs()
+
+	result := receiveResult(t, wp)
+	assertTrue(t, !result.Success)
+	assertTrue(t, result.ErrorMessage != nil)
+	assertTrue(t, strings.Contains(*result.ErrorMessage, "no heartbeat received within 1h1m0s"))
+
+	// No probe is submitted and the next miss is reported a period later
+	assertEqual(t, 0, len(wp.jobQueue))
+	assertTrue(t, !scheduled.NextRun.Before(before.Add(time.Hour)))
+}
@@ -3,6 +3,8 @@ package monitoring
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -15,29 +17,32 @@ import (
 
 // HTTPResponse represents the response from an HTTP request
 type HTTPResponse struct {
-	StatusCode int
-	BodySample *string
-	Headers    map[string]string
+	StatusCode  int
+	Body        string // body read up to MaxResponseBodyKB, used for assertions
+	BodySample  *string
+	Headers     map[string]string
+	Certificate *TLSCertificateInfo
 }
 
 // HTTPClient handles HTTP requests for monitoring
 type HTTPClient struct {
-	client *http.Client
-	config HTTPClientConfig
+	client         *http.Client
+	insecureClient *http.Client // used for endpoints that opt out of certificate verification
+	config         HTTPClientConfig
 }
 
 // HTTPClientConfig holds configuration for the HTTP client
 type HTTPClientConfig struct {
-	Timeout            time.Duration
-	MaxRetries         int
-	RetryDelay         time.Duration
+	Timeout            time.Duration // request timeout for endpoints without their own
+	MaxRetries         int           // default retry count, endpoints may override it
+	RetryDelay         time.Duration // default base retry delay, endpoints may override it
 	MaxRetryDelay      time.Duration
 	ConnectTimeout     time.Duration
-	ResponseTimeout    time.Duration
 	MaxResponseBodyKB  int
 	InsecureSkipVerify bool
 	FollowRedirects    bool
 	MaxRedirects       int
+	RootCAs            *x509.CertPool // nil uses the system roots
 }
 
 // NewHTTPClient creates a new HTTP client with the specified configuration
@@ -49,9 +54,6 @@ func NewHTTPClient(config HTTPClientConfig) *HTTPClient {
 	if config.ConnectTimeout == 0 {
 		config.ConnectTimeout = 10 * time.Second
 	}
-	if config.ResponseTimeout == 0 {
-		config.ResponseTimeout = 30 * time.Second
-	}
 	if config.MaxRetries == 0 {
 		config.MaxRetries = 3
 	}
@@ -68,20 +70,29 @@ func NewHTTPClient(config HTTPClientConfig) *HTTPClient {
 		config.MaxRedirects = 10
 	}
 
+	return &HTTPClient{
+		client:         newMonitoringHTTPClient(config, config.InsecureSkipVerify),
+		insecureClient: newMonitoringHTTPClient(config, true),
+		config:         config,
+	}
+}
+
+// newMonitoringHTTPClient builds an http.Client with the configured timeouts, TLS settings and redirect policy
+func newMonitoringHTTPClient(config HTTPClientConfig, insecureSkipVerify bool) *http.Client {
 	// Create custom transport with timeouts and TLS settings
 	transport := &http.Transport{
 		DialContext: (&net.Dialer{
 			Timeout:   config.ConnectTimeout,
 			KeepAlive: 30 * time.Second,
 		}).DialContext,
-		ResponseHeaderTimeout: config.ResponseTimeout,
-		TLSHandshakeTimeout:   10 * time.Second,
-		IdleConnTimeout:       90 * time.Second,
-		MaxIdleConns:          100,
-		MaxIdleConnsPerHost:   10,
-		DisableKeepAlives:     false,
+		TLSHandshakeTimeout: 10 * time.Second,
+		IdleConnTimeout:     90 * time.Second,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		DisableKeepAlives:   false,
 		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: config.InsecureSkipVerify,
+			InsecureSkipVerify: insecureSkipVerify,
+			RootCAs:            config.RootCAs,
 		},
 	}
 
@@ -96,26 +107,27 @@ func NewHTTPClient(config HTTPClientConfig) *HTTPClient {
 		return nil
 	}
 
-	client := &http.Client{
+	// Requests are bounded by the endpoint's timeout through their context rather
+	// than a client-wide timeout, so endpoints can wait longer than the default
+	return &http.Client{
 		Transport:     transport,
-		Timeout:       config.Timeout,
 		CheckRedirect: checkRedirect,
 	}
-
-	return &HTTPClient{
-		client: client,
-		config: config,
-	}
 }
 
-// ExecuteRequest executes an HTTP request for the given endpoint with retry logic
+// ExecuteRequest executes an HTTP request for the given endpoint with retry logic. A request
+// failing on an untrusted or expired certificate still returns a response carrying it
 func (c *HTTPClient) ExecuteRequest(endpoint *data.Endpoint) (*HTTPResponse, error) {
+	var lastResponse *HTTPResponse
 	var lastErr error
 
-	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
+	maxRetries := c.maxRetries(endpoint)
+	retryDelay := c.retryDelay(endpoint)
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
 		if attempt > 0 {
 			// Calculate backoff delay with exponential backoff
-			delay := c.config.RetryDelay * time.Duration(1<<uint(attempt-1))
+			delay := retryDelay * time.Duration(1<<uint(attempt-1))
 			if delay > c.config.MaxRetryDelay {
 				delay = c.config.MaxRetryDelay
 			}
@@ -127,6 +139,7 @@ func (c *HTTPClient) ExecuteRequest(endpoint *data.Endpoint) (*HTTPResponse, err
 			return response, nil
 		}
 
+		lastResponse = response
 		lastErr = err
 
 		// Don't retry for certain types of errors (4xx client errors)
@@ -135,13 +148,37 @@ func (c *HTTPClient) ExecuteRequest(endpoint *data.Endpoint) (*HTTPResponse, err
 		}
 	}
 
-	return nil, fmt.Errorf("request failed after %d attempts: %w", c.config.MaxRetries+1, lastErr)
+	return lastResponse, fmt.Errorf("request failed after %d attempts: %w", maxRetries+1, lastErr)
+}
+
+// maxRetries returns the endpoint's retry count, falling back to the client default
+func (c *HTTPClient) maxRetries(endpoint *data.Endpoint) int {
+	if endpoint.MaxRetries != nil {
+		return *endpoint.MaxRetries
+	}
+	return c.config.MaxRetries
+}
+
+// retryDelay returns the endpoint's base retry delay, falling back to the client default
+func (c *HTTPClient) retryDelay(endpoint *data.Endpoint) time.Duration {
+	if endpoint.RetryDelayMs != nil {
+		return time.Duration(*endpoint.RetryDelayMs) * time.Millisecond
+	}
+	return c.config.RetryDelay
+}
+
+// requestTimeout returns the endpoint's timeout, falling back to the client default
+func (c *HTTPClient) requestTimeout(endpoint *data.Endpoint) time.Duration {
+	if endpoint.TimeoutSeconds > 0 {
+		return time.Duration(endpoint.TimeoutSeconds) * time.Second
+	}
+	return c.config.Timeout
 }
 
 // executeRequestOnce performs a single HTTP request attempt
 func (c *HTTPClient) executeRequestOnce(endpoint *data.Endpoint) (*HTTPResponse, error) {
 	// Create request context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(endpoint.TimeoutSeconds)*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), c.requestTimeout(endpoint))
 	defer cancel()
 
 	// Prepare request body
@@ -172,12 +209,30 @@ func (c *HTTPClient) executeRequestOnce(endpoint *data.Endpoint) (*HTTPResponse,
 	}
 
 	// Execute request
-	resp, err := c.client.Do(req)
+	client := c.client
+	if endpoint.TLSSkipVerify {
+		client = c.insecureClient
+	}
+	resp, err := client.Do(req)
 	if err != nil {
+		// A certificate failing verification aborts the handshake, record it anyway so
+		// expired and untrusted certificates show up without skipping verification
+		var certErr *tls.CertificateVerificationError
+		if errors.As(err, &certErr) {
+			state := &tls.ConnectionState{PeerCertificates: certErr.UnverifiedCertificates}
+			certificate := inspectCertificate(state, req.URL.Hostname(), c.config.RootCAs)
+			return &HTTPResponse{Certificate: certificate}, fmt.Errorf("request failed: %w", err)
+		}
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	// Record the certificate presented during the TLS handshake
+	var certificate *TLSCertificateInfo
+	if resp.TLS != nil {
+		certificate = inspectCertificate(resp.TLS, resp.Request.URL.Hostname(), c.config.RootCAs)
+	}
+
 	// Extract response headers first
 	headers := make(map[string]string)
 	for key, values := range resp.Header {
@@ -197,14 +252,8 @@ func (c *HTTPClient) executeRequestOnce(endpoint *data.Endpoint) (*HTTPResponse,
 	// Prepare response body sample (truncate if too long)
 	var bodySample *string
 	if len(bodyBytes) > 0 {
-		sampleSize := 500 // Store first 500 characters as sample
-		if len(bodyBytes) > sampleSize {
-			sample := string(bodyBytes[:sampleSize]) + "..."
-			bodySample = &sample
-		} else {
-			sample := string(bodyBytes)
-			bodySample = &sample
-		}
+		sample := truncateSample(bodyBytes)
+		bodySample = &sample
 	}
 
 	// Check for HTTP error status codes and return as error for retry logic
@@ -213,9 +262,11 @@ func (c *HTTPClient) executeRequestOnce(endpoint *data.Endpoint) (*HTTPResponse,
 	}
 
 	return &HTTPResponse{
-		StatusCode: resp.StatusCode,
-		BodySample: bodySample,
-		Headers:    headers,
+		StatusCode:  resp.StatusCode,
+		Body:        string(bodyBytes),
+		BodySample:  bodySample,
+		Headers:     headers,
+		Certificate: certificate,
 	}, nil
 }
 
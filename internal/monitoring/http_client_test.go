@@ -1,6 +1,7 @@
 package monitoring
 
 import (
+	"crypto/x509"
 	"fmt"
 	"io"
 	"net/http"
@@ -49,7 +50,6 @@ func TestNewHTTPClient(t *testing.T) {
 	assertTrue(t, client != nil)
 	assertEqual(t, 30*time.Second, client.config.Timeout)
 	assertEqual(t, 10*time.Second, client.config.ConnectTimeout)
-	assertEqual(t, 30*time.Second, client.config.ResponseTimeout)
 	assertEqual(t, 3, client.config.MaxRetries)
 	assertEqual(t, 1*time.Second, client.config.RetryDelay)
 	assertEqual(t, 10*time.Second, client.config.MaxRetryDelay)
@@ -63,7 +63,6 @@ func TestNewHTTPClient(t *testing.T) {
 		RetryDelay:         500 * time.Millisecond,
 		MaxRetryDelay:      30 * time.Second,
 		ConnectTimeout:     5 * time.Second,
-		ResponseTimeout:    10 * time.Second,
 		MaxResponseBodyKB:  128,
 		InsecureSkipVerify: true,
 		FollowRedirects:    false,
@@ -492,3 +491,147 @@ func TestHTTPClient_ConcurrentRequests(t *testing.T) {
 
 	assertEqual(t, numRequests, requestCount)
 }
+
+func TestHTTPClient_ExecuteRequest_RecordsCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	client := NewHTTPClient(HTTPClientConfig{RootCAs: roots})
+	endpoint := &data.Endpoint{
+		URL:            server.URL,
+		Method:         "GET",
+		TimeoutSeconds: 5,
+	}
+
+	response, err := client.ExecuteRequest(endpoint)
+	assertNoError(t, err)
+	if response.Certificate == nil {
+		t.Fatal("Expected certificate to be recorded")
+	}
+
+	cert := response.Certificate
+	assertTrue(t, cert.ChainValid)
+	assertEqual(t, "", cert.ChainError)
+	assertEqual(t, server.Certificate().NotAfter, cert.NotAfter)
+	assertTrue(t, strings.Contains(strings.Join(cert.SANs, ","), "127.0.0.1"))
+}
+
+func TestHTTPClient_ExecuteRequest_TLSSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(HTTPClientConfig{MaxRetries: 1, RetryDelay: 10 * time.Millisecond})
+
+	// The test server's certificate is not trusted by the system roots
+	strictEndpoint := &data.Endpoint{
+		URL:            server.URL,
+		Method:         "GET",
+		TimeoutSeconds: 5,
+	}
+	response, err := client.ExecuteRequest(strictEndpoint)
+	assertError(t, err)
+
+	// The rejected certificate is still recorded
+	if response == nil || response.Certificate == nil {
+		t.Fatal("Expected certificate to be recorded for a failed handshake")
+	}
+	assertTrue(t, !response.Certificate.ChainValid)
+	assertTrue(t, response.Certificate.ChainError != "")
+	assertEqual(t, server.Certificate().NotAfter, response.Certificate.NotAfter)
+
+	// Skipping verification lets the check pass while still recording the invalid chain
+	insecureEndpoint := &data.Endpoint{
+		URL:            server.URL,
+		Method:         "GET",
+		TimeoutSeconds: 5,
+		TLSSkipVerify:  true,
+	}
+	response, err = client.ExecuteRequest(insecureEndpoint)
+	assertNoError(t, err)
+	if response.Certificate == nil {
+		t.Fatal("Expected certificate to be recorded")
+	}
+	assertTrue(t, !response.Certificate.ChainValid)
+	assertTrue(t, response.Certificate.ChainError != "")
+}
+
+func TestHTTPClient_ExecuteRequest_NoCertificateForHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(HTTPClientConfig{})
+	endpoint := &data.Endpoint{
+		URL:            server.URL,
+		Method:         "GET",
+		TimeoutSeconds: 5,
+	}
+
+	response, err := client.ExecuteRequest(endpoint)
+	assertNoError(t, err)
+	assertTrue(t, response.Certificate == nil)
+}
+
+func TestHTTPClient_ExecuteRequest_EndpointRetryOverride(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(HTTPClientConfig{
+		MaxRetries: 3,
+		RetryDelay: 1 * time.Second,
+	})
+
+	maxRetries := 1
+	retryDelayMs := 10
+	endpoint := &data.Endpoint{
+		URL:            server.URL,
+		Method:         "GET",
+		TimeoutSeconds: 5,
+		MaxRetries:     &maxRetries,
+		RetryDelayMs:   &retryDelayMs,
+	}
+
+	start := time.Now()
+	_, err := client.ExecuteRequest(endpoint)
+	elapsed := time.Since(start)
+
+	assertError(t, err)
+	assertTrue(t, strings.Contains(err.Error(), "after 2 attempts"))
+	assertEqual(t, 2, attempts)
+	assertTrue(t, elapsed < 500*time.Millisecond)
+}
+
+func TestHTTPClient_ExecuteRequest_EndpointTimeoutExceedsDefault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(1500 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// The client default is shorter than the endpoint's own timeout
+	client := NewHTTPClient(HTTPClientConfig{
+		Timeout: 1 * time.Second,
+	})
+
+	endpoint := &data.Endpoint{
+		URL:            server.URL,
+		Method:         "GET",
+		TimeoutSeconds: 3,
+	}
+
+	response, err := client.ExecuteRequest(endpoint)
+	assertNoError(t, err)
+	assertEqual(t, http.StatusOK, response.StatusCode)
+}
@@ -3,6 +3,7 @@ package monitoring
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -11,32 +12,60 @@ import (
 	"github.com/i4o-oss/watchtower/internal/data"
 )
 
-// IncidentDetector monitors endpoint health and automatically creates incidents
+// IncidentDB defines the database operations needed by the incident detector
+type IncidentDB interface {
+	GetEndpoint(id uuid.UUID) (*data.Endpoint, error)
+	GetEndpointCertificates() ([]data.EndpointCertificate, error)
+	CreateIncident(incident *data.Incident) error
+	GetIncident(id uuid.UUID) (*data.Incident, error)
+	UpdateIncident(incident *data.Incident) error
+	CreateEndpointIncident(endpointIncident *data.EndpointIncident) error
+	GetEndpointIncidents(incidentID uuid.UUID) ([]data.EndpointIncident, error)
+	UpdateEndpointIncident(endpointIncident *data.EndpointIncident) error
+	CreateIncidentTimeline(timeline *data.IncidentTimeline) error
+}
+
+// IncidentDetector consumes monitoring results, tracks each endpoint's up/down state
+// and automatically opens and resolves incidents on state transitions
 type IncidentDetector struct {
-	db               *data.DB
-	logger           *log.Logger
-	config           IncidentDetectorConfig
-	ctx              context.Context
-	cancel           context.CancelFunc
-	wg               sync.WaitGroup
-	endpointFailures map[uuid.UUID]*FailureTracker
-	activeIncidents  map[uuid.UUID]uuid.UUID // endpoint_id -> incident_id
-	mu               sync.RWMutex
-	isRunning        bool
+	db                  IncidentDB
+	logger              *log.Logger
+	config              IncidentDetectorConfig
+	ctx                 context.Context
+	cancel              context.CancelFunc
+	wg                  sync.WaitGroup
+	results             chan Result
+	endpointFailures    map[uuid.UUID]*FailureTracker
+	activeIncidents     map[uuid.UUID]uuid.UUID         // endpoint_id -> incident_id
+	parentIncidents     map[uuid.UUID]parentAttribution // endpoint_id -> incident of the down parent its failures are attributed to
+	certIncidents       map[uuid.UUID]uuid.UUID         // endpoint_id -> certificate expiry incident_id
+	transitionListeners []TransitionListener
+	incidentListeners   []IncidentListener
+	pendingEvents       []IncidentEvent // incident events waiting for the lock to be released
+	mu                  sync.RWMutex
+	isRunning           bool
+}
+
+// parentAttribution links a down endpoint to the incident of the down parent it depends on
+type parentAttribution struct {
+	ParentID   uuid.UUID
+	IncidentID uuid.UUID
 }
 
 // IncidentDetectorConfig holds configuration for incident detection
 type IncidentDetectorConfig struct {
-	// CheckInterval is how often to check for incidents
+	// CheckInterval is how often to check certificates for upcoming expiry
 	CheckInterval time.Duration
 	// ConsecutiveFailures is the number of consecutive failures before creating an incident
 	ConsecutiveFailures int
-	// FailureWindow is the time window to consider for failure counting
+	// FailureWindow is the window of recent results used to grade incident severity
 	FailureWindow time.Duration
 	// RecoveryThreshold is the number of consecutive successes needed to resolve an incident
 	RecoveryThreshold int
 	// AutoResolve whether to automatically resolve incidents when endpoints recover
 	AutoResolve bool
+	// ResultBufferSize is the number of results that can be queued for the detector
+	ResultBufferSize int
 	// SeverityThresholds define response time thresholds for different severities
 	SeverityThresholds SeverityThresholds
 }
@@ -49,16 +78,6 @@ type SeverityThresholds struct {
 	// Below medium = low
 }
 
-// FailureTracker tracks failure patterns for an endpoint
-type FailureTracker struct {
-	EndpointID          uuid.UUID
-	ConsecutiveFailures int
-	ConsecutiveSuccess  int
-	LastFailureTime     time.Time
-	LastSuccessTime     time.Time
-	FailureHistory      []time.Time
-}
-
 // DefaultIncidentDetectorConfig returns a default configuration
 func DefaultIncidentDetectorConfig() IncidentDetectorConfig {
 	return IncidentDetectorConfig{
@@ -67,6 +86,7 @@ func DefaultIncidentDetectorConfig() IncidentDetectorConfig {
 		FailureWindow:       10 * time.Minute,
 		RecoveryThreshold:   2,
 		AutoResolve:         true,
+		ResultBufferSize:    100,
 		SeverityThresholds: SeverityThresholds{
 			CriticalResponseTimeMs: 10000, // 10s
 			HighResponseTimeMs:     5000,  // 5s
@@ -76,7 +96,7 @@ func DefaultIncidentDetectorConfig() IncidentDetectorConfig {
 }
 
 // NewIncidentDetector creates a new incident detector
-func NewIncidentDetector(config IncidentDetectorConfig, db *data.DB, logger *log.Logger) *IncidentDetector {
+func NewIncidentDetector(config IncidentDetectorConfig, db IncidentDB, logger *log.Logger) *IncidentDetector {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &IncidentDetector{
@@ -85,8 +105,138 @@ func NewIncidentDetector(config IncidentDetectorConfig, db *data.DB, logger *log
 		config:           config,
 		ctx:              ctx,
 		cancel:           cancel,
+		results:          make(chan Result, config.ResultBufferSize),
 		endpointFailures: make(map[uuid.UUID]*FailureTracker),
 		activeIncidents:  make(map[uuid.UUID]uuid.UUID),
+		parentIncidents:  make(map[uuid.UUID]parentAttribution),
+		certIncidents:    make(map[uuid.UUID]uuid.UUID),
+	}
+}
+
+// Restore rebuilds the detector's state after a restart from the unresolved associations of
+// auto-created incidents and each endpoint's latest monitoring logs, most recent first. It must
+// be called before Start
+func (id *IncidentDetector) Restore(openIncidents []data.EndpointIncident, logs []data.MonitoringLog) {
+	defer id.flushIncidentEvents()
+
+	id.mu.Lock()
+	defer id.mu.Unlock()
+
+	for _, ei := range openIncidents {
+		if ei.Incident == nil {
+			continue
+		}
+		switch {
+		case ei.Incident.AutoReason == data.IncidentAutoReasonCertificateExpiry:
+			id.certIncidents[ei.EndpointID] = ei.IncidentID
+		case ei.ParentEndpointID != nil:
+			id.parentIncidents[ei.EndpointID] = parentAttribution{ParentID: *ei.ParentEndpointID, IncidentID: ei.IncidentID}
+		default:
+			id.activeIncidents[ei.EndpointID] = ei.IncidentID
+		}
+	}
+
+	endpointLogs := make(map[uuid.UUID][]data.MonitoringLog)
+	for _, log := range logs {
+		endpointLogs[log.EndpointID] = append(endpointLogs[log.EndpointID], log)
+	}
+
+	// Endpoints with an open incident start down, then the logs are replayed oldest first
+	for endpointID := range id.activeIncidents {
+		tracker := newFailureTracker(endpointID)
+		tracker.State = EndpointStateDown
+		id.endpointFailures[endpointID] = tracker
+	}
+	for endpointID := range id.parentIncidents {
+		tracker := newFailureTracker(endpointID)
+		tracker.State = EndpointStateDown
+		id.endpointFailures[endpointID] = tracker
+	}
+
+	for endpointID, logs := range endpointLogs {
+		tracker, exists := id.endpointFailures[endpointID]
+		if !exists {
+			tracker = newFailureTracker(endpointID)
+			id.endpointFailures[endpointID] = tracker
+		}
+
+		for i := len(logs) - 1; i >= 0; i-- {
+			tracker.record(resultFromLog(logs[i]), id.config.ConsecutiveFailures, id.config.RecoveryThreshold, id.config.FailureWindow)
+		}
+	}
+
+	// Resolve incidents whose endpoint recovered before the restart, dependent endpoints first
+	// so only those still down get an incident of their own when their parent's is resolved
+	for endpointID := range id.parentIncidents {
+		if id.endpointFailures[endpointID].State == EndpointStateUp {
+			id.detachFromParentIncident(endpointID)
+		}
+	}
+	for endpointID := range id.activeIncidents {
+		tracker := id.endpointFailures[endpointID]
+		if tracker.State == EndpointStateUp {
+			id.resolveIncidentIfNeeded(endpointID, tracker)
+		}
+	}
+
+	id.logger.Info("restored incident detector state",
+		"tracked_endpoints", len(id.endpointFailures),
+		"active_incidents", len(id.activeIncidents),
+		"dependent_endpoints", len(id.parentIncidents),
+		"certificate_incidents", len(id.certIncidents))
+}
+
+// resultFromLog converts a stored monitoring log back into a result for state replay
+func resultFromLog(log data.MonitoringLog) Result {
+	return Result{
+		Job:            Job{ID: log.ID, EndpointID: log.EndpointID, Timestamp: log.Timestamp},
+		Success:        log.Success,
+		StatusCode:     log.StatusCode,
+		ResponseTimeMs: log.ResponseTimeMs,
+		ErrorMessage:   log.ErrorMessage,
+		ResponseSample: log.ResponseBodySample,
+		ExecutedAt:     log.Timestamp,
+		InMaintenance:  log.InMaintenance,
+	}
+}
+
+// OnTransition registers a listener that is called for every endpoint state transition
+func (id *IncidentDetector) OnTransition(listener TransitionListener) {
+	id.mu.Lock()
+	defer id.mu.Unlock()
+	id.transitionListeners = append(id.transitionListeners, listener)
+}
+
+// OnIncident registers a listener that is called for every incident the detector opens or resolves
+func (id *IncidentDetector) OnIncident(listener IncidentListener) {
+	id.mu.Lock()
+	defer id.mu.Unlock()
+	id.incidentListeners = append(id.incidentListeners, listener)
+}
+
+// queueIncidentEvent records an incident event to be delivered once the lock is released.
+// Callers must hold the lock
+func (id *IncidentDetector) queueIncidentEvent(eventType IncidentEventType, incident *data.Incident, endpointID uuid.UUID, endpointName string) {
+	id.pendingEvents = append(id.pendingEvents, IncidentEvent{
+		Type:         eventType,
+		Incident:     *incident,
+		EndpointID:   endpointID,
+		EndpointName: endpointName,
+	})
+}
+
+// flushIncidentEvents delivers queued incident events to the listeners
+func (id *IncidentDetector) flushIncidentEvents() {
+	id.mu.Lock()
+	events := id.pendingEvents
+	id.pendingEvents = nil
+	listeners := id.incidentListeners
+	id.mu.Unlock()
+
+	for _, event := range events {
+		for _, listener := range listeners {
+			listener(event)
+		}
 	}
 }
 
@@ -112,9 +262,8 @@ func (id *IncidentDetector) Start() error {
 // Stop gracefully stops the incident detector
 func (id *IncidentDetector) Stop() error {
 	id.mu.Lock()
-	defer id.mu.Unlock()
-
 	if !id.isRunning {
+		id.mu.Unlock()
 		return nil
 	}
 
@@ -122,14 +271,26 @@ func (id *IncidentDetector) Stop() error {
 
 	// Signal shutdown
 	id.cancel()
+	id.mu.Unlock()
 
-	// Wait for goroutines to finish
+	// Wait for the detection loop, which takes the lock while processing results
 	id.wg.Wait()
 
+	id.mu.Lock()
 	id.isRunning = false
+	id.mu.Unlock()
 	return nil
 }
 
+// HandleResult queues a monitoring result for the detector, blocking while the queue is full
+// so no result is skipped by the state machine
+func (id *IncidentDetector) HandleResult(result Result) {
+	select {
+	case id.results <- result:
+	case <-id.ctx.Done():
+	}
+}
+
 // detectionLoop is the main detection loop
 func (id *IncidentDetector) detectionLoop() {
 	defer id.wg.Done()
@@ -139,8 +300,10 @@ func (id *IncidentDetector) detectionLoop() {
 
 	for {
 		select {
+		case result := <-id.results:
+			id.processResult(result)
 		case <-ticker.C:
-			id.performDetection()
+			id.performCertificateDetection()
 		case <-id.ctx.Done():
 			id.logger.Debug("incident detector stopping")
 			return
@@ -148,119 +311,121 @@ func (id *IncidentDetector) detectionLoop() {
 	}
 }
 
-// performDetection checks for incidents and recoveries
-func (id *IncidentDetector) performDetection() {
-	// Get recent monitoring logs
-	logs, err := id.db.GetRecentMonitoringLogs(int(id.config.FailureWindow.Hours()))
-	if err != nil {
-		id.logger.Error("failed to get recent monitoring logs", "error", err)
-		return
-	}
+// processResult advances the endpoint's state machine and opens or resolves its incident
+// when the result crosses a threshold
+func (id *IncidentDetector) processResult(result Result) {
+	endpointID := result.Job.EndpointID
 
-	// Group logs by endpoint
-	endpointLogs := make(map[uuid.UUID][]data.MonitoringLog)
-	for _, log := range logs {
-		endpointLogs[log.EndpointID] = append(endpointLogs[log.EndpointID], log)
-	}
-
-	// Process each endpoint
-	for endpointID, logs := range endpointLogs {
-		id.processEndpointLogs(endpointID, logs)
-	}
-}
-
-// processEndpointLogs processes logs for a specific endpoint
-func (id *IncidentDetector) processEndpointLogs(endpointID uuid.UUID, logs []data.MonitoringLog) {
 	id.mu.Lock()
-	defer id.mu.Unlock()
 
-	// Get or create failure tracker
 	tracker, exists := id.endpointFailures[endpointID]
 	if !exists {
-		tracker = &FailureTracker{
-			EndpointID: endpointID,
-		}
+		tracker = newFailureTracker(endpointID)
 		id.endpointFailures[endpointID] = tracker
 	}
 
-	// Sort logs by timestamp (most recent first)
-	if len(logs) == 0 {
+	from, fromSince := tracker.State, tracker.StateSince
+	to, changed := tracker.record(result, id.config.ConsecutiveFailures, id.config.RecoveryThreshold, id.config.FailureWindow)
+	if !changed {
+		id.mu.Unlock()
 		return
 	}
 
-	// Process logs in reverse chronological order to find current state
-	for i := len(logs) - 1; i >= 0; i-- {
-		log := logs[i]
+	endpoint := result.Job.Endpoint
+	if endpoint == nil {
+		var err error
+		if endpoint, err = id.db.GetEndpoint(endpointID); err != nil {
+			id.logger.Error("failed to get endpoint for state transition", "endpoint_id", endpointID, "error", err)
+			endpoint = &data.Endpoint{ID: endpointID}
+		}
+	}
+
+	transition := StateTransition{
+		EndpointID:           endpointID,
+		EndpointName:         endpoint.Name,
+		From:                 from,
+		To:                   to,
+		FromSince:            fromSince,
+		Result:               result,
+		ConsecutiveFailures:  tracker.ConsecutiveFailures,
+		ConsecutiveSuccesses: tracker.ConsecutiveSuccess,
+		At:                   result.ExecutedAt,
+	}
 
-		if log.Success {
-			if tracker.ConsecutiveFailures > 0 {
-				// Reset failure count on success
-				tracker.ConsecutiveFailures = 0
-				tracker.LastSuccessTime = log.Timestamp
+	switch {
+	case to == EndpointStateDown:
+		// Failures of an endpoint whose parent is down are part of the parent's incident,
+		// unless it cannot be added to it
+		if parent, ok := id.downParent(endpoint); ok {
+			if transition.IncidentID = id.attachToParentIncident(endpoint, tracker, parent); transition.IncidentID != nil {
+				transition.ParentID = &parent.ParentID
 			}
-			tracker.ConsecutiveSuccess++
+		}
+		if transition.IncidentID == nil {
+			transition.IncidentID = id.createIncidentIfNeeded(endpoint, tracker)
+		}
+		// Endpoints depending on it may have noticed the outage first
+		if transition.IncidentID != nil {
+			id.adoptDownChildren(endpoint, *transition.IncidentID)
+		}
+	case from == EndpointStateDown:
+		if parent, ok := id.parentIncidents[endpointID]; ok {
+			id.detachFromParentIncident(endpointID)
+			transition.IncidentID = &parent.IncidentID
+			transition.ParentID = &parent.ParentID
 		} else {
-			if tracker.ConsecutiveSuccess > 0 {
-				// Reset success count on failure
-				tracker.ConsecutiveSuccess = 0
-				tracker.LastFailureTime = log.Timestamp
-			}
-			tracker.ConsecutiveFailures++
-			tracker.FailureHistory = append(tracker.FailureHistory, log.Timestamp)
+			transition.IncidentID = id.resolveIncidentIfNeeded(endpointID, tracker)
 		}
 	}
 
-	// Clean old failures from history
-	cutoff := time.Now().Add(-id.config.FailureWindow)
-	var recentFailures []time.Time
-	for _, failureTime := range tracker.FailureHistory {
-		if failureTime.After(cutoff) {
-			recentFailures = append(recentFailures, failureTime)
-		}
-	}
-	tracker.FailureHistory = recentFailures
+	id.logger.Info("endpoint state changed",
+		"endpoint_id", endpointID,
+		"endpoint_name", endpoint.Name,
+		"from", from,
+		"to", to)
 
-	// Check if we should create an incident
-	if tracker.ConsecutiveFailures >= id.config.ConsecutiveFailures {
-		id.createIncidentIfNeeded(endpointID, tracker, logs)
-	}
+	listeners := id.transitionListeners
+	id.mu.Unlock()
 
-	// Check if we should resolve an incident
-	if tracker.ConsecutiveSuccess >= id.config.RecoveryThreshold {
-		id.resolveIncidentIfNeeded(endpointID, tracker)
+	for _, listener := range listeners {
+		listener(transition)
 	}
+
+	id.flushIncidentEvents()
 }
 
-// createIncidentIfNeeded creates an incident if one doesn't already exist
-func (id *IncidentDetector) createIncidentIfNeeded(endpointID uuid.UUID, tracker *FailureTracker, logs []data.MonitoringLog) {
-	// Check if there's already an active incident for this endpoint
-	if _, exists := id.activeIncidents[endpointID]; exists {
-		return
-	}
+// createIncidentIfNeeded creates an incident if one doesn't already exist and returns its ID
+func (id *IncidentDetector) createIncidentIfNeeded(endpoint *data.Endpoint, tracker *FailureTracker) *uuid.UUID {
+	endpointID := endpoint.ID
 
-	// Get endpoint details
-	endpoint, err := id.db.GetEndpoint(endpointID)
-	if err != nil {
-		id.logger.Error("failed to get endpoint for incident creation", "endpoint_id", endpointID, "error", err)
-		return
+	// Check if there's already an active incident for this endpoint, it may have been
+	// resolved manually since
+	if incidentID, exists := id.activeIncidents[endpointID]; exists {
+		incident, err := id.db.GetIncident(incidentID)
+		if err != nil || incident.Status != "resolved" {
+			return &incidentID
+		}
+		delete(id.activeIncidents, endpointID)
 	}
 
 	// Determine severity based on response time and failure pattern
-	severity := id.determineSeverity(logs)
+	severity := id.determineSeverity(tracker.RecentResults)
 
 	// Create incident
 	incident := &data.Incident{
 		Title: fmt.Sprintf("Endpoint %s is failing", endpoint.Name),
 		Description: fmt.Sprintf("Endpoint %s (%s) has failed %d consecutive times. Last failure: %s",
 			endpoint.Name, endpoint.URL, tracker.ConsecutiveFailures, tracker.LastFailureTime.Format(time.RFC3339)),
-		Severity:  severity,
-		Status:    "investigating",
-		StartTime: tracker.LastFailureTime,
+		Severity:    severity,
+		Status:      "investigating",
+		StartTime:   tracker.FailingSince,
+		AutoCreated: true,
+		AutoReason:  data.IncidentAutoReasonEndpointDown,
 	}
 
 	if err := id.db.CreateIncident(incident); err != nil {
 		id.logger.Error("failed to create incident", "endpoint_id", endpointID, "error", err)
-		return
+		return nil
 	}
 
 	// Create timeline entry for automatic incident creation
@@ -279,7 +444,7 @@ func (id *IncidentDetector) createIncidentIfNeeded(endpointID uuid.UUID, tracker
 	endpointIncident := &data.EndpointIncident{
 		EndpointID:    endpointID,
 		IncidentID:    incident.ID,
-		AffectedStart: tracker.LastFailureTime,
+		AffectedStart: tracker.FailingSince,
 	}
 
 	if err := id.db.CreateEndpointIncident(endpointIncident); err != nil {
@@ -300,6 +465,7 @@ func (id *IncidentDetector) createIncidentIfNeeded(endpointID uuid.UUID, tracker
 
 	// Track the active incident
 	id.activeIncidents[endpointID] = incident.ID
+	id.queueIncidentEvent(IncidentEventCreated, incident, endpointID, endpoint.Name)
 
 	id.logger.Info("automatic incident created",
 		"incident_id", incident.ID,
@@ -307,31 +473,34 @@ func (id *IncidentDetector) createIncidentIfNeeded(endpointID uuid.UUID, tracker
 		"endpoint_name", endpoint.Name,
 		"severity", severity,
 		"consecutive_failures", tracker.ConsecutiveFailures)
+
+	return &incident.ID
 }
 
-// resolveIncidentIfNeeded resolves an incident if auto-resolve is enabled
-func (id *IncidentDetector) resolveIncidentIfNeeded(endpointID uuid.UUID, tracker *FailureTracker) {
+// resolveIncidentIfNeeded resolves the endpoint's incident if auto-resolve is enabled and
+// returns the ID of the resolved incident
+func (id *IncidentDetector) resolveIncidentIfNeeded(endpointID uuid.UUID, tracker *FailureTracker) *uuid.UUID {
 	if !id.config.AutoResolve {
-		return
+		return nil
 	}
 
 	// Check if there's an active incident for this endpoint
 	incidentID, exists := id.activeIncidents[endpointID]
 	if !exists {
-		return
+		return nil
 	}
 
 	// Get the incident
 	incident, err := id.db.GetIncident(incidentID)
 	if err != nil {
 		id.logger.Error("failed to get incident for resolution", "incident_id", incidentID, "error", err)
-		return
+		return nil
 	}
 
 	// Only resolve if the incident is not already resolved
 	if incident.Status == "resolved" {
 		delete(id.activeIncidents, endpointID)
-		return
+		return nil
 	}
 
 	// Update incident status to resolved
@@ -341,7 +510,7 @@ func (id *IncidentDetector) resolveIncidentIfNeeded(endpointID uuid.UUID, tracke
 
 	if err := id.db.UpdateIncident(incident); err != nil {
 		id.logger.Error("failed to resolve incident", "incident_id", incidentID, "error", err)
-		return
+		return nil
 	}
 
 	// Create timeline entry for automatic resolution
@@ -373,16 +542,412 @@ func (id *IncidentDetector) resolveIncidentIfNeeded(endpointID uuid.UUID, tracke
 
 	// Remove from active incidents
 	delete(id.activeIncidents, endpointID)
+	id.queueIncidentEvent(IncidentEventResolved, incident, endpointID, endpointName(incident, endpointID))
+
+	// Endpoints still down once their parent recovered fail on their own
+	id.handOverDependents(incidentID, endpointID, nil)
+	for _, parent := range id.parentIncidents {
+		if parent.IncidentID == incidentID {
+			id.handOverDependents(incidentID, parent.ParentID, nil)
+		}
+	}
 
 	id.logger.Info("automatic incident resolved",
 		"incident_id", incidentID,
 		"endpoint_id", endpointID,
 		"consecutive_successes", tracker.ConsecutiveSuccess)
+
+	return &incidentID
+}
+
+// handOverDependents moves the endpoints attributed to a resolved incident through the parent
+// to the parent's new incident. Without one, those still down get an incident of their own
+// and their dependents follow it
+func (id *IncidentDetector) handOverDependents(incidentID, parentID uuid.UUID, parentIncidentID *uuid.UUID) {
+	for childID, attribution := range id.parentIncidents {
+		if attribution.IncidentID != incidentID || attribution.ParentID != parentID {
+			continue
+		}
+		id.detachFromParentIncident(childID)
+
+		tracker := id.endpointFailures[childID]
+		var childIncidentID *uuid.UUID
+		if tracker != nil && tracker.State == EndpointStateDown {
+			if child, err := id.db.GetEndpoint(childID); err != nil {
+				id.logger.Error("failed to get dependent endpoint", "endpoint_id", childID, "error", err)
+			} else {
+				if parentIncidentID != nil {
+					childIncidentID = id.attachToParentIncident(child, tracker, parentAttribution{ParentID: parentID, IncidentID: *parentIncidentID})
+				}
+				if childIncidentID == nil {
+					childIncidentID = id.createIncidentIfNeeded(child, tracker)
+				}
+			}
+		}
+		id.handOverDependents(incidentID, childID, childIncidentID)
+	}
+}
+
+// downParent returns the incident of a down parent the endpoint depends on. A parent that is
+// itself attributed to its own parent's incident passes that incident on
+func (id *IncidentDetector) downParent(endpoint *data.Endpoint) (parentAttribution, bool) {
+	// An endpoint that already has its own incident keeps it
+	if _, exists := id.activeIncidents[endpoint.ID]; exists {
+		return parentAttribution{}, false
+	}
+
+	for _, parentID := range endpoint.ParentIDs {
+		tracker, exists := id.endpointFailures[parentID]
+		if !exists || tracker.State != EndpointStateDown {
+			continue
+		}
+		if incidentID, exists := id.activeIncidents[parentID]; exists {
+			return parentAttribution{ParentID: parentID, IncidentID: incidentID}, true
+		}
+		if grandparent, exists := id.parentIncidents[parentID]; exists {
+			return parentAttribution{ParentID: parentID, IncidentID: grandparent.IncidentID}, true
+		}
+	}
+	return parentAttribution{}, false
+}
+
+// attachToParentIncident adds a down endpoint to the incident of the parent it depends on
+// instead of opening an incident of its own, and returns the parent's incident ID. An endpoint
+// that already recovered during the incident is affected again. Returns nil when the endpoint
+// could not be added
+func (id *IncidentDetector) attachToParentIncident(endpoint *data.Endpoint, tracker *FailureTracker, parent parentAttribution) *uuid.UUID {
+	endpointIncidents, err := id.db.GetEndpointIncidents(parent.IncidentID)
+	if err != nil {
+		id.logger.Error("failed to get endpoint incidents", "incident_id", parent.IncidentID, "error", err)
+		return nil
+	}
+
+	var endpointIncident *data.EndpointIncident
+	for i := range endpointIncidents {
+		if endpointIncidents[i].EndpointID == endpoint.ID {
+			endpointIncident = &endpointIncidents[i]
+			break
+		}
+	}
+
+	if endpointIncident != nil {
+		endpointIncident.AffectedEnd = nil
+		endpointIncident.ParentEndpointID = &parent.ParentID
+		if err := id.db.UpdateEndpointIncident(endpointIncident); err != nil {
+			id.logger.Error("failed to reopen endpoint incident", "incident_id", parent.IncidentID, "endpoint_id", endpoint.ID, "error", err)
+			return nil
+		}
+	} else {
+		endpointIncident = &data.EndpointIncident{
+			EndpointID:       endpoint.ID,
+			IncidentID:       parent.IncidentID,
+			AffectedStart:    tracker.FailingSince,
+			ParentEndpointID: &parent.ParentID,
+		}
+		if err := id.db.CreateEndpointIncident(endpointIncident); err != nil {
+			id.logger.Error("failed to create endpoint incident", "incident_id", parent.IncidentID, "endpoint_id", endpoint.ID, "error", err)
+			return nil
+		}
+	}
+
+	parentName := parent.ParentID.String()
+	if parentEndpoint, err := id.db.GetEndpoint(parent.ParentID); err == nil {
+		parentName = parentEndpoint.Name
+	}
+	timeline := &data.IncidentTimeline{
+		IncidentID: parent.IncidentID,
+		UserID:     nil, // System-generated
+		EventType:  "update",
+		Message:    fmt.Sprintf("Endpoint '%s' automatically associated, it depends on '%s'", endpoint.Name, parentName),
+	}
+	if err := id.db.CreateIncidentTimeline(timeline); err != nil {
+		id.logger.Error("failed to create endpoint association timeline", "incident_id", parent.IncidentID, "error", err)
+	}
+
+	id.parentIncidents[endpoint.ID] = parent
+
+	id.logger.Info("endpoint failure attributed to parent incident",
+		"incident_id", parent.IncidentID,
+		"endpoint_id", endpoint.ID,
+		"endpoint_name", endpoint.Name,
+		"parent_id", parent.ParentID)
+
+	return &parent.IncidentID
+}
+
+// adoptDownChildren moves endpoints that went down before a parent they depend on into the
+// parent's incident. Their own incidents are resolved as part of it
+func (id *IncidentDetector) adoptDownChildren(parent *data.Endpoint, incidentID uuid.UUID) {
+	for childID, tracker := range id.endpointFailures {
+		childIncidentID, exists := id.activeIncidents[childID]
+		if childID == parent.ID || !exists || childIncidentID == incidentID || tracker.State != EndpointStateDown {
+			continue
+		}
+
+		child, err := id.db.GetEndpoint(childID)
+		if err != nil {
+			id.logger.Error("failed to get endpoint", "endpoint_id", childID, "error", err)
+			continue
+		}
+		if !slices.Contains(child.ParentIDs, parent.ID) {
+			continue
+		}
+
+		// The child keeps its own incident when it cannot be added to the parent's
+		if id.attachToParentIncident(child, tracker, parentAttribution{ParentID: parent.ID, IncidentID: incidentID}) == nil {
+			continue
+		}
+		id.resolveMergedIncident(child, childIncidentID, parent.Name, incidentID)
+
+		// Endpoints attributed to the child's incident follow it
+		for grandchildID, attribution := range id.parentIncidents {
+			if attribution.IncidentID != childIncidentID {
+				continue
+			}
+			id.detachFromParentIncident(grandchildID)
+			if grandchild, err := id.db.GetEndpoint(grandchildID); err != nil {
+				id.logger.Error("failed to get dependent endpoint", "endpoint_id", grandchildID, "error", err)
+			} else {
+				id.attachToParentIncident(grandchild, id.endpointFailures[grandchildID], parentAttribution{ParentID: attribution.ParentID, IncidentID: incidentID})
+			}
+		}
+	}
+}
+
+// resolveMergedIncident resolves the incident of an endpoint moved into the incident of a
+// parent it depends on
+func (id *IncidentDetector) resolveMergedIncident(endpoint *data.Endpoint, incidentID uuid.UUID, parentName string, mergedInto uuid.UUID) {
+	delete(id.activeIncidents, endpoint.ID)
+
+	incident, err := id.db.GetIncident(incidentID)
+	if err != nil {
+		id.logger.Error("failed to get incident for resolution", "incident_id", incidentID, "error", err)
+		return
+	}
+	if incident.Status == "resolved" {
+		return
+	}
+
+	now := time.Now()
+	incident.Status = "resolved"
+	incident.EndTime = &now
+	if err := id.db.UpdateIncident(incident); err != nil {
+		id.logger.Error("failed to resolve incident", "incident_id", incidentID, "error", err)
+		return
+	}
+
+	timeline := &data.IncidentTimeline{
+		IncidentID: incidentID,
+		UserID:     nil, // System-generated
+		EventType:  "update",
+		Message:    fmt.Sprintf("Incident automatically resolved, '%s' depends on '%s' which is down and tracked in its incident", endpoint.Name, parentName),
+	}
+	if err := id.db.CreateIncidentTimeline(timeline); err != nil {
+		id.logger.Error("failed to create resolution timeline", "incident_id", incidentID, "error", err)
+	}
+
+	if endpointIncidents, err := id.db.GetEndpointIncidents(incidentID); err == nil {
+		for _, ei := range endpointIncidents {
+			if ei.EndpointID == endpoint.ID && ei.AffectedEnd == nil {
+				ei.AffectedEnd = &now
+				if err := id.db.UpdateEndpointIncident(&ei); err != nil {
+					id.logger.Error("failed to update endpoint incident end time",
+						"endpoint_incident_id", ei.ID, "error", err)
+				}
+				break
+			}
+		}
+	}
+
+	id.pendingEvents = append(id.pendingEvents, IncidentEvent{
+		Type:         IncidentEventMerged,
+		Incident:     *incident,
+		EndpointID:   endpoint.ID,
+		EndpointName: endpoint.Name,
+		MergedInto:   &mergedInto,
+		ParentName:   parentName,
+	})
+
+	id.logger.Info("incident merged into parent incident",
+		"incident_id", incidentID,
+		"endpoint_id", endpoint.ID,
+		"endpoint_name", endpoint.Name)
+}
+
+// detachFromParentIncident ends a dependent endpoint's association with its parent's incident
+func (id *IncidentDetector) detachFromParentIncident(endpointID uuid.UUID) {
+	parent := id.parentIncidents[endpointID]
+	delete(id.parentIncidents, endpointID)
+
+	endpointIncidents, err := id.db.GetEndpointIncidents(parent.IncidentID)
+	if err != nil {
+		id.logger.Error("failed to get endpoint incidents", "incident_id", parent.IncidentID, "error", err)
+		return
+	}
+
+	now := time.Now()
+	for _, ei := range endpointIncidents {
+		if ei.EndpointID == endpointID && ei.AffectedEnd == nil {
+			ei.AffectedEnd = &now
+			if err := id.db.UpdateEndpointIncident(&ei); err != nil {
+				id.logger.Error("failed to update endpoint incident end time",
+					"endpoint_incident_id", ei.ID, "error", err)
+			}
+			break
+		}
+	}
+}
+
+// performCertificateDetection raises warning incidents for certificates nearing expiry
+// and resolves them once the certificate has been renewed
+func (id *IncidentDetector) performCertificateDetection() {
+	certs, err := id.db.GetEndpointCertificates()
+	if err != nil {
+		id.logger.Error("failed to get endpoint certificates", "error", err)
+		return
+	}
+
+	defer id.flushIncidentEvents()
+
+	id.mu.Lock()
+	defer id.mu.Unlock()
+
+	now := time.Now()
+	for i := range certs {
+		cert := &certs[i]
+		if cert.Endpoint == nil {
+			continue
+		}
+
+		warningDays := cert.Endpoint.CertExpiryWarningDays
+		daysRemaining := cert.DaysRemaining(now)
+		_, hasIncident := id.certIncidents[cert.EndpointID]
+
+		if cert.Endpoint.Enabled && warningDays > 0 && daysRemaining <= warningDays {
+			if !hasIncident {
+				id.createCertificateIncident(cert, daysRemaining)
+			}
+		} else if hasIncident {
+			id.resolveCertificateIncident(cert)
+		}
+	}
 }
 
-// determineSeverity determines incident severity based on monitoring logs
-func (id *IncidentDetector) determineSeverity(logs []data.MonitoringLog) string {
-	if len(logs) == 0 {
+// createCertificateIncident opens a warning incident for an expiring certificate
+func (id *IncidentDetector) createCertificateIncident(cert *data.EndpointCertificate, daysRemaining int) {
+	endpoint := cert.Endpoint
+
+	title := fmt.Sprintf("TLS certificate for %s expires in %d days", endpoint.Name, daysRemaining)
+	severity := "medium"
+	if daysRemaining < 0 {
+		title = fmt.Sprintf("TLS certificate for %s has expired", endpoint.Name)
+		severity = "critical"
+	} else if daysRemaining <= 3 {
+		severity = "high"
+	}
+
+	incident := &data.Incident{
+		Title: title,
+		Description: fmt.Sprintf("The certificate presented by %s (%s) issued by %s expires on %s.",
+			endpoint.Name, endpoint.URL, cert.Issuer, cert.NotAfter.Format(time.RFC3339)),
+		Severity:    severity,
+		Status:      "open",
+		StartTime:   time.Now(),
+		AutoCreated: true,
+		AutoReason:  data.IncidentAutoReasonCertificateExpiry,
+	}
+
+	if err := id.db.CreateIncident(incident); err != nil {
+		id.logger.Error("failed to create certificate incident", "endpoint_id", endpoint.ID, "error", err)
+		return
+	}
+
+	timeline := &data.IncidentTimeline{
+		IncidentID: incident.ID,
+		UserID:     nil, // System-generated
+		EventType:  "created",
+		Message:    fmt.Sprintf("Incident automatically created: certificate expires in %d days", daysRemaining),
+	}
+	if err := id.db.CreateIncidentTimeline(timeline); err != nil {
+		id.logger.Error("failed to create incident timeline", "incident_id", incident.ID, "error", err)
+	}
+
+	endpointIncident := &data.EndpointIncident{
+		EndpointID:    endpoint.ID,
+		IncidentID:    incident.ID,
+		AffectedStart: incident.StartTime,
+	}
+	if err := id.db.CreateEndpointIncident(endpointIncident); err != nil {
+		id.logger.Error("failed to create endpoint incident", "incident_id", incident.ID, "endpoint_id", endpoint.ID, "error", err)
+	}
+
+	id.certIncidents[endpoint.ID] = incident.ID
+	id.queueIncidentEvent(IncidentEventCreated, incident, endpoint.ID, endpoint.Name)
+
+	id.logger.Info("certificate expiry incident created",
+		"incident_id", incident.ID,
+		"endpoint_id", endpoint.ID,
+		"endpoint_name", endpoint.Name,
+		"days_remaining", daysRemaining)
+}
+
+// resolveCertificateIncident resolves the expiry incident once a renewed certificate is seen
+func (id *IncidentDetector) resolveCertificateIncident(cert *data.EndpointCertificate) {
+	incidentID := id.certIncidents[cert.EndpointID]
+
+	incident, err := id.db.GetIncident(incidentID)
+	if err != nil {
+		id.logger.Error("failed to get certificate incident for resolution", "incident_id", incidentID, "error", err)
+		return
+	}
+
+	if incident.Status != "resolved" {
+		now := time.Now()
+		incident.Status = "resolved"
+		incident.EndTime = &now
+
+		if err := id.db.UpdateIncident(incident); err != nil {
+			id.logger.Error("failed to resolve certificate incident", "incident_id", incidentID, "error", err)
+			return
+		}
+
+		timeline := &data.IncidentTimeline{
+			IncidentID: incident.ID,
+			UserID:     nil, // System-generated
+			EventType:  "update",
+			Message:    fmt.Sprintf("Incident automatically resolved: certificate now valid until %s", cert.NotAfter.Format(time.RFC3339)),
+		}
+		if err := id.db.CreateIncidentTimeline(timeline); err != nil {
+			id.logger.Error("failed to create resolution timeline", "incident_id", incidentID, "error", err)
+		}
+
+		endpointIncidents, err := id.db.GetEndpointIncidents(incidentID)
+		if err == nil {
+			for _, ei := range endpointIncidents {
+				if ei.EndpointID == cert.EndpointID && ei.AffectedEnd == nil {
+					ei.AffectedEnd = &now
+					if updateErr := id.db.UpdateEndpointIncident(&ei); updateErr != nil {
+						id.logger.Error("failed to update endpoint incident end time",
+							"endpoint_incident_id", ei.ID, "error", updateErr)
+					}
+					break
+				}
+			}
+		}
+
+		id.queueIncidentEvent(IncidentEventResolved, incident, cert.EndpointID, cert.Endpoint.Name)
+	}
+
+	delete(id.certIncidents, cert.EndpointID)
+
+	id.logger.Info("certificate expiry incident resolved",
+		"incident_id", incidentID,
+		"endpoint_id", cert.EndpointID)
+}
+
+// determineSeverity determines incident severity based on recent results
+func (id *IncidentDetector) determineSeverity(results []Result) string {
+	if len(results) == 0 {
 		return "medium"
 	}
 
@@ -391,16 +956,16 @@ func (id *IncidentDetector) determineSeverity(logs []data.MonitoringLog) string
 	var responseTimeCount int
 	var hasTimeouts bool
 
-	for _, log := range logs {
-		if log.ResponseTimeMs != nil {
-			avgResponseTime += float64(*log.ResponseTimeMs)
+	for _, result := range results {
+		if result.ResponseTimeMs != nil {
+			avgResponseTime += float64(*result.ResponseTimeMs)
 			responseTimeCount++
 		}
 
 		// Check for timeouts or connection errors
-		if log.ErrorMessage != nil &&
-			(containsIgnoreCase(*log.ErrorMessage, "timeout") ||
-				containsIgnoreCase(*log.ErrorMessage, "connection")) {
+		if result.ErrorMessage != nil &&
+			(containsIgnoreCase(*result.ErrorMessage, "timeout") ||
+				containsIgnoreCase(*result.ErrorMessage, "connection")) {
 			hasTimeouts = true
 		}
 	}
@@ -426,6 +991,16 @@ func (id *IncidentDetector) determineSeverity(logs []data.MonitoringLog) string
 	return "low"
 }
 
+// endpointName returns the name of the endpoint from the incident's preloaded associations
+func endpointName(incident *data.Incident, endpointID uuid.UUID) string {
+	for _, ei := range incident.EndpointIncidents {
+		if ei.EndpointID == endpointID && ei.Endpoint != nil {
+			return ei.Endpoint.Name
+		}
+	}
+	return ""
+}
+
 // containsIgnoreCase checks if a string contains a substring (case-insensitive)
 func containsIgnoreCase(s, substr string) bool {
 	return len(s) >= len(substr) &&
@@ -454,29 +1029,54 @@ func (id *IncidentDetector) GetActiveIncidents() map[uuid.UUID]uuid.UUID {
 	return result
 }
 
+// GetEndpointState returns the current state of an endpoint
+func (id *IncidentDetector) GetEndpointState(endpointID uuid.UUID) EndpointState {
+	id.mu.RLock()
+	defer id.mu.RUnlock()
+
+	if tracker, exists := id.endpointFailures[endpointID]; exists {
+		return tracker.State
+	}
+	return EndpointStateUnknown
+}
+
+// GetEndpointStates returns the current state of every tracked endpoint
+func (id *IncidentDetector) GetEndpointStates() map[uuid.UUID]EndpointState {
+	id.mu.RLock()
+	defer id.mu.RUnlock()
+
+	result := make(map[uuid.UUID]EndpointState, len(id.endpointFailures))
+	for endpointID, tracker := range id.endpointFailures {
+		result[endpointID] = tracker.State
+	}
+	return result
+}
+
 // GetStats returns statistics about the incident detector
 func (id *IncidentDetector) GetStats() IncidentDetectorStats {
 	id.mu.RLock()
 	defer id.mu.RUnlock()
 
 	return IncidentDetectorStats{
-		IsRunning:           id.isRunning,
-		ActiveIncidents:     len(id.activeIncidents),
-		TrackedEndpoints:    len(id.endpointFailures),
-		CheckInterval:       id.config.CheckInterval,
-		ConsecutiveFailures: id.config.ConsecutiveFailures,
-		RecoveryThreshold:   id.config.RecoveryThreshold,
-		AutoResolve:         id.config.AutoResolve,
+		IsRunning:            id.isRunning,
+		ActiveIncidents:      len(id.activeIncidents),
+		CertificateIncidents: len(id.certIncidents),
+		TrackedEndpoints:     len(id.endpointFailures),
+		CheckInterval:        id.config.CheckInterval,
+		ConsecutiveFailures:  id.config.ConsecutiveFailures,
+		RecoveryThreshold:    id.config.RecoveryThreshold,
+		AutoResolve:          id.config.AutoResolve,
 	}
 }
 
 // IncidentDetectorStats represents statistics about the incident detector
 type IncidentDetectorStats struct {
-	IsRunning           bool          `json:"is_running"`
-	ActiveIncidents     int           `json:"active_incidents"`
-	TrackedEndpoints    int           `json:"tracked_endpoints"`
-	CheckInterval       time.Duration `json:"check_interval"`
-	ConsecutiveFailures int           `json:"consecutive_failures"`
-	RecoveryThreshold   int           `json:"recovery_threshold"`
-	AutoResolve         bool          `json:"auto_resolve"`
+	IsRunning            bool          `json:"is_running"`
+	ActiveIncidents      int           `json:"active_incidents"`
+	CertificateIncidents int           `json:"certificate_incidents"`
+	TrackedEndpoints     int           `json:"tracked_endpoints"`
+	CheckInterval        time.Duration `json:"check_interval"`
+	ConsecutiveFailures  int           `json:"consecutive_failures"`
+	RecoveryThreshold    int           `json:"recovery_threshold"`
+	AutoResolve          bool          `json:"auto_resolve"`
 }
@@ -0,0 +1,554 @@
+package monitoring
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+func newTestIncidentDetector(db *MockDB) *IncidentDetector {
+	config := DefaultIncidentDetectorConfig()
+	config.CheckInterval = time.Hour
+	return NewIncidentDetector(config, db, log.New(io.Discard))
+}
+
+func checkResult(endpoint *data.Endpoint, success bool, at time.Time) Result {
+	result := Result{
+		Job:        Job{ID: uuid.New(), EndpointID: endpoint.ID, Endpoint: endpoint, Timestamp: at},
+		Success:    success,
+		ExecutedAt: at,
+	}
+	if !success {
+		errMsg := "unexpected status code: 503"
+		result.ErrorMessage = &errMsg
+	}
+	return result
+}
+
+func TestFailureTracker_Record(t *testing.T) {
+	start := time.Now()
+	tests := []struct {
+		name     string
+		results  []bool
+		expected []EndpointState
+	}{
+		{"first success is up", []bool{true}, []EndpointState{EndpointStateUp}},
+		{"failures below threshold stay unknown", []bool{false, false}, []EndpointState{EndpointStateUnknown, EndpointStateUnknown}},
+		{"down after threshold", []bool{true, false, false, false}, []EndpointState{EndpointStateUp, EndpointStateUp, EndpointStateUp, EndpointStateDown}},
+		{"success resets failure streak", []bool{true, false, false, true, false, false}, []EndpointState{EndpointStateUp, EndpointStateUp, EndpointStateUp, EndpointStateUp, EndpointStateUp, EndpointStateUp}},
+		{"recovery needs threshold", []bool{false, false, false, true, false, true, true}, []EndpointState{EndpointStateUnknown, EndpointStateUnknown, EndpointStateDown, EndpointStateDown, EndpointStateDown, EndpointStateDown, EndpointStateUp}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newFailureTracker(uuid.New())
+			endpoint := &data.Endpoint{ID: tracker.EndpointID}
+			for i, success := range tt.results {
+				state, _ := tracker.record(checkResult(endpoint, success, start.Add(time.Duration(i)*time.Minute)), 3, 2, 10*time.Minute)
+				assertEqual(t, tt.expected[i], state)
+			}
+		})
+	}
+}
+
+func TestFailureTracker_RecentResultsWindow(t *testing.T) {
+	tracker := newFailureTracker(uuid.New())
+	endpoint := &data.Endpoint{ID: tracker.EndpointID}
+	start := time.Now()
+
+	for i := 0; i < 20; i++ {
+		tracker.record(checkResult(endpoint, false, start.Add(time.Duration(i)*time.Minute)), 3, 2, 5*time.Minute)
+	}
+
+	assertEqual(t, 6, len(tracker.RecentResults))
+	assertEqual(t, 20, tracker.ConsecutiveFailures)
+	assertEqual(t, start, tracker.FailingSince)
+}
+
+func TestIncidentDetector_ProcessResult_OpensAndResolvesIncident(t *testing.T) {
+	db := NewMockDB()
+	detector := newTestIncidentDetector(db)
+	endpoint := &data.Endpoint{ID: uuid.New(), Name: "api", URL: "https://api.example.com"}
+
+	var transitions []StateTransition
+	detector.OnTransition(func(transition StateTransition) {
+		transitions = append(transitions, transition)
+	})
+
+	start := time.Now()
+	detector.processResult(checkResult(endpoint, true, start))
+	detector.processResult(checkResult(endpoint, false, start.Add(time.Minute)))
+	detector.processResult(checkResult(endpoint, false, start.Add(2*time.Minute)))
+	assertEqual(t, 0, len(db.incidents))
+
+	// The third consecutive failure opens the incident
+	detector.processResult(checkResult(endpoint, false, start.Add(3*time.Minute)))
+	assertEqual(t, 1, len(db.incidents))
+	assertEqual(t, EndpointStateDown, detector.GetEndpointState(endpoint.ID))
+
+	incidentID, exists := detector.GetActiveIncidents()[endpoint.ID]
+	assertTrue(t, exists)
+	incident := db.incidents[incidentID]
+	assertEqual(t, "investigating", incident.Status)
+	assertTrue(t, incident.AutoCreated)
+	assertEqual(t, data.IncidentAutoReasonEndpointDown, incident.AutoReason)
+	assertEqual(t, start.Add(time.Minute), incident.StartTime)
+	assertEqual(t, 1, len(db.endpointIncidents))
+
+	// Further failures do not open another incident
+	detector.processResult(checkResult(endpoint, false, start.Add(4*time.Minute)))
+	assertEqual(t, 1, len(db.incidents))
+
+	// The second consecutive success resolves it
+	detector.processResult(checkResult(endpoint, true, start.Add(5*time.Minute)))
+	assertEqual(t, "investigating", db.incidents[incidentID].Status)
+	detector.processResult(checkResult(endpoint, true, start.Add(6*time.Minute)))
+	assertEqual(t, "resolved", db.incidents[incidentID].Status)
+	assertTrue(t, db.endpointIncidents[0].AffectedEnd != nil)
+	assertEqual(t, 0, len(detector.GetActiveIncidents()))
+
+	assertEqual(t, 3, len(transitions))
+	assertEqual(t, EndpointStateUnknown, transitions[0].From)
+	assertEqual(t, EndpointStateUp, transitions[0].To)
+	assertTrue(t, transitions[0].IncidentID == nil)
+
+	assertEqual(t, EndpointStateUp, transitions[1].From)
+	assertEqual(t, EndpointStateDown, transitions[1].To)
+	assertEqual(t, "api", transitions[1].EndpointName)
+	assertEqual(t, 3, transitions[1].ConsecutiveFailures)
+	assertTrue(t, transitions[1].IncidentID != nil)
+	assertEqual(t, incidentID, *transitions[1].IncidentID)
+
+	assertEqual(t, EndpointStateDown, transitions[2].From)
+	assertEqual(t, EndpointStateUp, transitions[2].To)
+	assertEqual(t, 2, transitions[2].ConsecutiveSuccesses)
+	assertTrue(t, transitions[2].IncidentID != nil)
+	assertEqual(t, incidentID, *transitions[2].IncidentID)
+}
+
+func TestIncidentDetector_ProcessResult_NoAutoResolve(t *testing.T) {
+	db := NewMockDB()
+	detector := newTestIncidentDetector(db)
+	detector.config.AutoResolve = false
+	endpoint := &data.Endpoint{ID: uuid.New(), Name: "api"}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		detector.processResult(checkResult(endpoint, false, start.Add(time.Duration(i)*time.Minute)))
+	}
+	for i := 3; i < 6; i++ {
+		detector.processResult(checkResult(endpoint, true, start.Add(time.Duration(i)*time.Minute)))
+	}
+
+	assertEqual(t, EndpointStateUp, detector.GetEndpointState(endpoint.ID))
+	for _, incident := range db.incidents {
+		assertEqual(t, "investigating", incident.Status)
+	}
+	assertEqual(t, 1, len(detector.GetActiveIncidents()))
+}
+
+func TestIncidentDetector_ProcessResult_AttributesToParentIncident(t *testing.T) {
+	db := NewMockDB()
+	detector := newTestIncidentDetector(db)
+	gateway := &data.Endpoint{ID: uuid.New(), Name: "gateway"}
+	api := &data.Endpoint{ID: uuid.New(), Name: "api", ParentIDs: data.UUIDList{gateway.ID}}
+	db.endpoints = append(db.endpoints, *gateway, *api)
+
+	var transitions []StateTransition
+	detector.OnTransition(func(transition StateTransition) {
+		transitions = append(transitions, transition)
+	})
+	fail := func(endpoint *data.Endpoint, at time.Time) {
+		for i := 0; i < 3; i++ {
+			detector.processResult(checkResult(endpoint, false, at.Add(time.Duration(i)*time.Second)))
+		}
+	}
+
+	start := time.Now()
+	fail(gateway, start)
+	gatewayIncident := detector.GetActiveIncidents()[gateway.ID]
+
+	// The API going down while the gateway is down joins the gateway's incident
+	fail(api, start.Add(time.Minute))
+	assertEqual(t, 1, len(db.incidents))
+	assertEqual(t, EndpointStateDown, detector.GetEndpointState(api.ID))
+	assertEqual(t, 2, len(db.endpointIncidents))
+	assertEqual(t, api.ID, db.endpointIncidents[1].EndpointID)
+	assertEqual(t, gatewayIncident, db.endpointIncidents[1].IncidentID)
+	assertEqual(t, gateway.ID, *db.endpointIncidents[1].ParentEndpointID)
+
+	down := transitions[len(transitions)-1]
+	assertEqual(t, api.ID, down.EndpointID)
+	assertEqual(t, gateway.ID, *down.ParentID)
+	assertEqual(t, gatewayIncident, *down.IncidentID)
+
+	// Its recovery ends the association without resolving the gateway's incident
+	detector.processResult(checkResult(api, true, start.Add(2*time.Minute)))
+	detector.processResult(checkResult(api, true, start.Add(2*time.Minute+time.Second)))
+	assertTrue(t, db.endpointIncidents[1].AffectedEnd != nil)
+	assertEqual(t, "investigating", db.incidents[gatewayIncident].Status)
+	assertTrue(t, transitions[len(transitions)-1].ParentID != nil)
+
+	// Once the gateway recovers an API still down gets an incident of its own
+	fail(api, start.Add(3*time.Minute))
+	assertEqual(t, 1, len(db.incidents))
+	detector.processResult(checkResult(gateway, true, start.Add(4*time.Minute)))
+	detector.processResult(checkResult(gateway, true, start.Add(4*time.Minute+time.Second)))
+	assertEqual(t, "resolved", db.incidents[gatewayIncident].Status)
+	assertEqual(t, 2, len(db.incidents))
+
+	apiIncident, exists := detector.GetActiveIncidents()[api.ID]
+	assertTrue(t, exists)
+	assertTrue(t, apiIncident != gatewayIncident)
+}
+
+func TestIncidentDetector_ProcessResult_ReattachesToParentIncident(t *testing.T) {
+	db := NewMockDB()
+	detector := newTestIncidentDetector(db)
+	gateway := &data.Endpoint{ID: uuid.New(), Name: "gateway"}
+	api := &data.Endpoint{ID: uuid.New(), Name: "api", ParentIDs: data.UUIDList{gateway.ID}}
+	db.endpoints = append(db.endpoints, *gateway, *api)
+
+	var transitions []StateTransition
+	detector.OnTransition(func(transition StateTransition) {
+		transitions = append(transitions, transition)
+	})
+	fail := func(endpoint *data.Endpoint, at time.Time) {
+		for i := 0; i < 3; i++ {
+			detector.processResult(checkResult(endpoint, false, at.Add(time.Duration(i)*time.Second)))
+		}
+	}
+
+	start := time.Now()
+	fail(gateway, start)
+	gatewayIncident := detector.GetActiveIncidents()[gateway.ID]
+
+	// The API fails, recovers and fails again while the gateway stays down
+	fail(api, start.Add(time.Minute))
+	detector.processResult(checkResult(api, true, start.Add(2*time.Minute)))
+	detector.processResult(checkResult(api, true, start.Add(2*time.Minute+time.Second)))
+	fail(api, start.Add(3*time.Minute))
+
+	// Its association with the gateway's incident is reopened rather than duplicated
+	assertEqual(t, 1, len(db.incidents))
+	assertEqual(t, 2, len(db.endpointIncidents))
+	assertEqual(t, api.ID, db.endpointIncidents[1].EndpointID)
+	assertTrue(t, db.endpointIncidents[1].AffectedEnd == nil)
+
+	down := transitions[len(transitions)-1]
+	assertEqual(t, EndpointStateDown, down.To)
+	assertEqual(t, gatewayIncident, *down.IncidentID)
+	assertEqual(t, gateway.ID, *down.ParentID)
+
+	// It is still tracked in the gateway's incident, so its recovery ends the association again
+	detector.processResult(checkResult(api, true, start.Add(4*time.Minute)))
+	detector.processResult(checkResult(api, true, start.Add(4*time.Minute+time.Second)))
+	assertTrue(t, db.endpointIncidents[1].AffectedEnd != nil)
+	assertEqual(t, "investigating", db.incidents[gatewayIncident].Status)
+}
+
+func TestIncidentDetector_ProcessResult_AdoptsChildrenDownFirst(t *testing.T) {
+	db := NewMockDB()
+	detector := newTestIncidentDetector(db)
+	gateway := &data.Endpoint{ID: uuid.New(), Name: "gateway"}
+	api := &data.Endpoint{ID: uuid.New(), Name: "api", ParentIDs: data.UUIDList{gateway.ID}}
+	web := &data.Endpoint{ID: uuid.New(), Name: "web", ParentIDs: data.UUIDList{api.ID}}
+	db.endpoints = append(db.endpoints, *gateway, *api, *web)
+
+	var events []IncidentEvent
+	detector.OnIncident(func(event IncidentEvent) {
+		events = append(events, event)
+	})
+	fail := func(endpoint *data.Endpoint, at time.Time) {
+		for i := 0; i < 3; i++ {
+			detector.processResult(checkResult(endpoint, false, at.Add(time.Duration(i)*time.Second)))
+		}
+	}
+
+	// The API notices the outage before the gateway and opens its own incident, which the
+	// website is attributed to
+	start := time.Now()
+	fail(api, start)
+	fail(web, start.Add(10*time.Second))
+	apiIncident := detector.GetActiveIncidents()[api.ID]
+	assertEqual(t, 1, len(db.incidents))
+
+	// Once the gateway goes down its incident takes over
+	fail(gateway, start.Add(time.Minute))
+	gatewayIncident, exists := detector.GetActiveIncidents()[gateway.ID]
+	assertTrue(t, exists)
+	assertEqual(t, "resolved", db.incidents[apiIncident].Status)
+	merged := events[len(events)-1]
+	assertEqual(t, IncidentEventMerged, merged.Type)
+	assertEqual(t, apiIncident, merged.Incident.ID)
+	assertEqual(t, gatewayIncident, *merged.MergedInto)
+	assertEqual(t, "gateway", merged.ParentName)
+	_, exists = detector.GetActiveIncidents()[api.ID]
+	assertTrue(t, !exists)
+
+	associations := make(map[uuid.UUID]data.EndpointIncident)
+	for _, ei := range db.endpointIncidents {
+		if ei.IncidentID == gatewayIncident {
+			associations[ei.EndpointID] = ei
+		} else {
+			assertTrue(t, ei.AffectedEnd != nil)
+		}
+	}
+	assertEqual(t, 3, len(associations))
+	assertEqual(t, gateway.ID, *associations[api.ID].ParentEndpointID)
+	assertEqual(t, api.ID, *associations[web.ID].ParentEndpointID)
+
+	// Once the gateway recovers the API fails on its own and the website follows it
+	detector.processResult(checkResult(gateway, true, start.Add(2*time.Minute)))
+	detector.processResult(checkResult(gateway, true, start.Add(2*time.Minute+time.Second)))
+	assertEqual(t, "resolved", db.incidents[gatewayIncident].Status)
+	assertEqual(t, 3, len(db.incidents))
+
+	apiIncident, exists = detector.GetActiveIncidents()[api.ID]
+	assertTrue(t, exists)
+	_, exists = detector.GetActiveIncidents()[web.ID]
+	assertTrue(t, !exists)
+	last := db.endpointIncidents[len(db.endpointIncidents)-1]
+	assertEqual(t, web.ID, last.EndpointID)
+	assertEqual(t, apiIncident, last.IncidentID)
+}
+
+func TestIncidentDetector_ProcessResult_IgnoresMaintenanceFailures(t *testing.T) {
+	db := NewMockDB()
+	detector := newTestIncidentDetector(db)
+	endpoint := &data.Endpoint{ID: uuid.New(), Name: "api"}
+
+	var transitions []StateTransition
+	detector.OnTransition(func(transition StateTransition) {
+		transitions = append(transitions, transition)
+	})
+
+	inMaintenance := func(success bool, at time.Time) Result {
+		result := checkResult(endpoint, success, at)
+		result.InMaintenance = true
+		return result
+	}
+
+	start := time.Now()
+	detector.processResult(checkResult(endpoint, true, start))
+	detector.processResult(checkResult(endpoint, false, start.Add(time.Minute)))
+
+	// Failures during maintenance open no incident and change no state
+	for i := 2; i < 8; i++ {
+		detector.processResult(inMaintenance(false, start.Add(time.Duration(i)*time.Minute)))
+	}
+	assertEqual(t, 0, len(db.incidents))
+	assertEqual(t, EndpointStateUp, detector.GetEndpointState(endpoint.ID))
+	assertEqual(t, 1, len(transitions))
+
+	// After the window the streak continues from the failure before it
+	detector.processResult(checkResult(endpoint, false, start.Add(8*time.Minute)))
+	assertEqual(t, 0, len(db.incidents))
+	detector.processResult(checkResult(endpoint, false, start.Add(9*time.Minute)))
+	assertEqual(t, 1, len(db.incidents))
+	assertEqual(t, EndpointStateDown, detector.GetEndpointState(endpoint.ID))
+
+	// Successes during maintenance still resolve an outage from before the window
+	detector.processResult(inMaintenance(true, start.Add(10*time.Minute)))
+	detector.processResult(inMaintenance(true, start.Add(11*time.Minute)))
+	assertEqual(t, EndpointStateUp, detector.GetEndpointState(endpoint.ID))
+	assertEqual(t, 0, len(detector.GetActiveIncidents()))
+}
+
+// openAutoIncident stores an unresolved auto-created incident for the endpoint and returns
+// its endpoint association as loaded at startup
+func openAutoIncident(db *MockDB, endpointID uuid.UUID, reason string) data.EndpointIncident {
+	incident := &data.Incident{Title: "down", Status: "investigating", AutoCreated: true, AutoReason: reason}
+	db.CreateIncident(incident)
+
+	ei := &data.EndpointIncident{EndpointID: endpointID, IncidentID: incident.ID, AffectedStart: time.Now()}
+	db.CreateEndpointIncident(ei)
+	ei.Incident = incident
+	return *ei
+}
+
+// monitoringLogs returns logs for the endpoint, most recent first, one minute apart
+func monitoringLogs(endpointID uuid.UUID, successes ...bool) []data.MonitoringLog {
+	now := time.Now()
+	logs := make([]data.MonitoringLog, len(successes))
+	for i, success := range successes {
+		logs[i] = data.MonitoringLog{ID: uuid.New(), EndpointID: endpointID, Success: success, Timestamp: now.Add(-time.Duration(i) * time.Minute)}
+	}
+	return logs
+}
+
+func TestIncidentDetector_Restore_DoesNotDuplicateIncident(t *testing.T) {
+	db := NewMockDB()
+	detector := newTestIncidentDetector(db)
+	endpoint := &data.Endpoint{ID: uuid.New(), Name: "api"}
+
+	ei := openAutoIncident(db, endpoint.ID, data.IncidentAutoReasonEndpointDown)
+	detector.Restore([]data.EndpointIncident{ei}, monitoringLogs(endpoint.ID, false, false, false))
+
+	assertEqual(t, EndpointStateDown, detector.GetEndpointState(endpoint.ID))
+	assertEqual(t, ei.IncidentID, detector.GetActiveIncidents()[endpoint.ID])
+
+	// Another failure after the restart keeps the existing incident
+	detector.processResult(checkResult(endpoint, false, time.Now()))
+	assertEqual(t, 1, len(db.incidents))
+
+	// Recovery resolves the incident opened before the restart
+	detector.processResult(checkResult(endpoint, true, time.Now()))
+	detector.processResult(checkResult(endpoint, true, time.Now()))
+	assertEqual(t, "resolved", db.incidents[ei.IncidentID].Status)
+}
+
+func TestIncidentDetector_Restore_ResolvesRecoveredEndpoint(t *testing.T) {
+	db := NewMockDB()
+	detector := newTestIncidentDetector(db)
+	endpointID := uuid.New()
+
+	ei := openAutoIncident(db, endpointID, data.IncidentAutoReasonEndpointDown)
+	detector.Restore([]data.EndpointIncident{ei}, monitoringLogs(endpointID, true, true, false))
+
+	assertEqual(t, EndpointStateUp, detector.GetEndpointState(endpointID))
+	assertEqual(t, "resolved", db.incidents[ei.IncidentID].Status)
+	assertEqual(t, 0, len(detector.GetActiveIncidents()))
+}
+
+func TestIncidentDetector_Restore_FailureStreak(t *testing.T) {
+	db := NewMockDB()
+	detector := newTestIncidentDetector(db)
+	endpoint := &data.Endpoint{ID: uuid.New(), Name: "api"}
+
+	certEndpointID := uuid.New()
+	certIncident := openAutoIncident(db, certEndpointID, data.IncidentAutoReasonCertificateExpiry)
+	detector.Restore([]data.EndpointIncident{certIncident}, monitoringLogs(endpoint.ID, false, false, true))
+
+	assertEqual(t, EndpointStateUp, detector.GetEndpointState(endpoint.ID))
+	assertEqual(t, 1, detector.GetStats().CertificateIncidents)
+	assertEqual(t, 0, len(detector.GetActiveIncidents()))
+
+	// The two failures before the restart count towards the threshold
+	detector.processResult(checkResult(endpoint, false, time.Now()))
+	assertEqual(t, EndpointStateDown, detector.GetEndpointState(endpoint.ID))
+	assertEqual(t, 2, len(db.incidents))
+}
+
+func TestIncidentDetector_HandleResult_Stream(t *testing.T) {
+	db := NewMockDB()
+	detector := newTestIncidentDetector(db)
+	endpoint := &data.Endpoint{ID: uuid.New(), Name: "api"}
+
+	transitions := make(chan StateTransition, 1)
+	detector.OnTransition(func(transition StateTransition) {
+		transitions <- transition
+	})
+
+	assertNoError(t, detector.Start())
+	defer detector.Stop()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		detector.HandleResult(checkResult(endpoint, false, start.Add(time.Duration(i)*time.Second)))
+	}
+
+	select {
+	case transition := <-transitions:
+		assertEqual(t, EndpointStateDown, transition.To)
+		assertTrue(t, transition.IncidentID != nil)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a state transition")
+	}
+}
+
+func TestWorkerPool_ResultHandlers(t *testing.T) {
+	db := NewMockDB()
+	wp := NewWorkerPool(WorkerPoolConfig{WorkerCount: 1, JobQueueSize: 1, ResultChanSize: 1}, log.New(io.Discard), db, nil)
+
+	received := make(chan Result, 1)
+	wp.AddResultHandler(func(result Result) {
+		received <- result
+	})
+
+	wp.Start()
+	defer wp.Stop()
+
+	endpoint := &data.Endpoint{ID: uuid.New(), Name: "api"}
+	assertNoError(t, wp.SubmitResult(checkResult(endpoint, false, time.Now())))
+
+	select {
+	case result := <-received:
+		assertEqual(t, endpoint.ID, result.Job.EndpointID)
+		assertTrue(t, !result.Success)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the result handler to be called")
+	}
+}
+
+type fixedMaintenance map[uuid.UUID]bool
+
+func (m fixedMaintenance) InMaintenance(endpointID uuid.UUID, at time.Time) bool {
+	return m[endpointID]
+}
+
+func TestWorkerPool_MarksMaintenanceResults(t *testing.T) {
+	db := NewMockDB()
+	wp := NewWorkerPool(WorkerPoolConfig{WorkerCount: 1, JobQueueSize: 1, ResultChanSize: 2}, log.New(io.Discard), db, nil)
+
+	api, web := &data.Endpoint{ID: uuid.New(), Name: "api"}, &data.Endpoint{ID: uuid.New(), Name: "web"}
+	wp.UseMaintenance(fixedMaintenance{api.ID: true})
+
+	received := make(chan Result, 2)
+	wp.AddResultHandler(func(result Result) {
+		received <- result
+	})
+
+	wp.Start()
+	defer wp.Stop()
+
+	assertNoError(t, wp.SubmitResult(checkResult(api, false, time.Now())))
+	assertNoError(t, wp.SubmitResult(checkResult(web, false, time.Now())))
+
+	for i := 0; i < 2; i++ {
+		select {
+		case result := <-received:
+			assertEqual(t, result.Job.EndpointID == api.ID, result.InMaintenance)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected the result handler to be called")
+		}
+	}
+
+	logs := db.monitoringLogs
+	assertEqual(t, 2, len(logs))
+	for _, log := range logs {
+		assertEqual(t, log.EndpointID == api.ID, log.InMaintenance)
+	}
+}
+
+func TestIncidentDetector_IncidentEvents(t *testing.T) {
+	db := NewMockDB()
+	detector := newTestIncidentDetector(db)
+	endpoint := &data.Endpoint{ID: uuid.New(), Name: "api"}
+
+	var events []IncidentEvent
+	detector.OnIncident(func(event IncidentEvent) {
+		events = append(events, event)
+	})
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		detector.processResult(checkResult(endpoint, false, start.Add(time.Duration(i)*time.Minute)))
+	}
+	assertEqual(t, 1, len(events))
+	assertEqual(t, IncidentEventCreated, events[0].Type)
+	assertEqual(t, endpoint.ID, events[0].EndpointID)
+	assertEqual(t, "api", events[0].EndpointName)
+	assertTrue(t, events[0].Incident.AutoCreated)
+
+	detector.processResult(checkResult(endpoint, true, start.Add(4*time.Minute)))
+	detector.processResult(checkResult(endpoint, true, start.Add(5*time.Minute)))
+	assertEqual(t, 2, len(events))
+	assertEqual(t, IncidentEventResolved, events[1].Type)
+	assertEqual(t, events[0].Incident.ID, events[1].Incident.ID)
+	assertEqual(t, "resolved", events[1].Incident.Status)
+}
@@ -19,6 +19,17 @@ type ScheduledEndpoint struct {
 	LastRun      *time.Time
 	IsActive     bool
 	FailureCount int
+	StartedAt    *time.Time // start ping of the heartbeat run in progress
+}
+
+// dueAfter returns when the endpoint is next due counting from t. Heartbeat
+// endpoints get their grace window on top of the expected period.
+func (se *ScheduledEndpoint) dueAfter(t time.Time) time.Time {
+	next := t.Add(se.Interval)
+	if se.Endpoint.GetCheckType() == data.CheckTypeHeartbeat {
+		next = next.Add(time.Duration(se.Endpoint.HeartbeatGraceSeconds) * time.Second)
+	}
+	return next
 }
 
 // Scheduler manages monitoring intervals for different endpoints
@@ -115,10 +126,10 @@ func (s *Scheduler) LoadEndpoints() error {
 			scheduled = &ScheduledEndpoint{
 				Endpoint:     &endpoint,
 				Interval:     time.Duration(endpoint.CheckIntervalSeconds) * time.Second,
-				NextRun:      time.Now().Add(time.Duration(endpoint.CheckIntervalSeconds) * time.Second),
 				IsActive:     endpoint.Enabled,
 				FailureCount: 0,
 			}
+			scheduled.NextRun = scheduled.dueAfter(time.Now())
 			s.endpoints[endpoint.ID] = scheduled
 			s.logger.Debug("added new endpoint to schedule", "endpoint_id", endpoint.ID, "interval", scheduled.Interval)
 		} else {
@@ -128,7 +139,7 @@ func (s *Scheduler) LoadEndpoints() error {
 			if scheduled.Interval != newInterval {
 				scheduled.Interval = newInterval
 				// Reschedule if interval changed
-				scheduled.NextRun = time.Now().Add(newInterval)
+				scheduled.NextRun = scheduled.dueAfter(time.Now())
 				s.logger.Debug("updated endpoint interval", "endpoint_id", endpoint.ID, "new_interval", newInterval)
 			}
 		}
@@ -199,6 +210,12 @@ func (s *Scheduler) processPendingJobs() {
 		}
 
 		if now.After(scheduled.NextRun) || now.Equal(scheduled.NextRun) {
+			// Heartbeat endpoints are overdue rather than due for a check
+			if scheduled.Endpoint.GetCheckType() == data.CheckTypeHeartbeat {
+				s.reportMissedHeartbeat(endpointID, scheduled, now)
+				continue
+			}
+
 			// Time to schedule this job
 			job := Job{
 				ID:         uuid.New(),
@@ -239,10 +256,10 @@ func (s *Scheduler) AddEndpoint(endpoint *data.Endpoint) {
 	scheduled := &ScheduledEndpoint{
 		Endpoint:     endpoint,
 		Interval:     time.Duration(endpoint.CheckIntervalSeconds) * time.Second,
-		NextRun:      time.Now().Add(time.Duration(endpoint.CheckIntervalSeconds) * time.Second),
 		IsActive:     endpoint.Enabled,
 		FailureCount: 0,
 	}
+	scheduled.NextRun = scheduled.dueAfter(time.Now())
 
 	s.endpoints[endpoint.ID] = scheduled
 	s.logger.Info("added endpoint to schedule", "endpoint_id", endpoint.ID, "interval", scheduled.Interval)
@@ -267,22 +284,24 @@ func (s *Scheduler) UpdateEndpoint(endpoint *data.Endpoint) {
 		// If it doesn't exist, add it
 		s.mu.Unlock()
 		s.AddEndpoint(endpoint)
+		s.mu.Lock() // re-acquire for the deferred unlock
 		return
 	}
 
 	// Update the endpoint
+	previous := scheduled.Endpoint
 	scheduled.Endpoint = endpoint
 	scheduled.IsActive = endpoint.Enabled
 
-	// Update interval if it changed
+	// Update interval if it changed, or the grace window of a heartbeat
 	newInterval := time.Duration(endpoint.CheckIntervalSeconds) * time.Second
-	if scheduled.Interval != newInterval {
+	if scheduled.Interval != newInterval || previous.HeartbeatGraceSeconds != endpoint.HeartbeatGraceSeconds {
 		scheduled.Interval = newInterval
 		// Reschedule next run
 		if scheduled.LastRun != nil {
-			scheduled.NextRun = scheduled.LastRun.Add(newInterval)
+			scheduled.NextRun = scheduled.dueAfter(*scheduled.LastRun)
 		} else {
-			scheduled.NextRun = time.Now().Add(newInterval)
+			scheduled.NextRun = scheduled.dueAfter(time.Now())
 		}
 	}
 
@@ -372,3 +391,39 @@ func (s *Scheduler) OnJobResult(endpointID uuid.UUID, success bool) {
 			"failure_count", scheduled.FailureCount)
 	}
 }
+
+// RestoreState resumes each scheduled endpoint from its latest monitoring logs, most recent
+// first, after a restart: the next run follows from the newest log and the failure count is
+// the run of failures at the head of the logs
+func (s *Scheduler) RestoreState(logs []data.MonitoringLog) {
+	endpointLogs := make(map[uuid.UUID][]data.MonitoringLog)
+	for _, log := range logs {
+		endpointLogs[log.EndpointID] = append(endpointLogs[log.EndpointID], log)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	restored := 0
+	for endpointID, logs := range endpointLogs {
+		scheduled, exists := s.endpoints[endpointID]
+		if !exists {
+			continue
+		}
+
+		lastRun := logs[0].Timestamp
+		scheduled.LastRun = &lastRun
+		scheduled.NextRun = scheduled.dueAfter(lastRun)
+
+		scheduled.FailureCount = 0
+		for _, log := range logs {
+			if log.Success {
+				break
+			}
+			scheduled.FailureCount++
+		}
+		restored++
+	}
+
+	s.logger.Info("restored scheduler state", "endpoints", restored)
+}
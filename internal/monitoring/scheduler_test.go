@@ -0,0 +1,37 @@
+package monitoring
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+func TestScheduler_RestoreState(t *testing.T) {
+	logger := log.New(io.Discard)
+	wp := NewWorkerPool(WorkerPoolConfig{WorkerCount: 1, JobQueueSize: 1, ResultChanSize: 1}, logger, NewMockDB(), nil)
+	scheduler := NewScheduler(SchedulerConfig{}, wp, nil, logger)
+
+	endpoint := &data.Endpoint{ID: uuid.New(), CheckIntervalSeconds: 300, Enabled: true}
+	scheduler.AddEndpoint(endpoint)
+	idle := &data.Endpoint{ID: uuid.New(), CheckIntervalSeconds: 300, Enabled: true}
+	scheduler.AddEndpoint(idle)
+	idleNextRun := scheduler.endpoints[idle.ID].NextRun
+
+	logs := monitoringLogs(endpoint.ID, false, false, true, false)
+	logs = append(logs, monitoringLogs(uuid.New(), false)...)
+	scheduler.RestoreState(logs)
+
+	scheduled := scheduler.endpoints[endpoint.ID]
+	assertEqual(t, 2, scheduled.FailureCount)
+	assertTrue(t, scheduled.LastRun != nil)
+	assertEqual(t, logs[0].Timestamp, *scheduled.LastRun)
+	assertEqual(t, logs[0].Timestamp.Add(5*time.Minute), scheduled.NextRun)
+
+	// Endpoints without logs keep their schedule
+	assertEqual(t, idleNextRun, scheduler.endpoints[idle.ID].NextRun)
+	assertTrue(t, scheduler.endpoints[idle.ID].LastRun == nil)
+}
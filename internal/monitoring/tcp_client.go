@@ -0,0 +1,155 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+// TCPResponse represents the result of a TCP port check
+type TCPResponse struct {
+	Address     string
+	ConnectTime time.Duration
+	BodySample  *string
+}
+
+// TCPClient handles TCP port checks for monitoring
+type TCPClient struct {
+	config TCPClientConfig
+}
+
+// TCPClientConfig holds configuration for the TCP client
+type TCPClientConfig struct {
+	Timeout        time.Duration
+	MaxBannerBytes int
+}
+
+// NewTCPClient creates a new TCP client with the specified configuration
+func NewTCPClient(config TCPClientConfig) *TCPClient {
+	// Set defaults if not specified
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+	if config.MaxBannerBytes == 0 {
+		config.MaxBannerBytes = 4096
+	}
+
+	return &TCPClient{
+		config: config,
+	}
+}
+
+// ExecuteCheck connects to the endpoint's host:port, optionally writes the configured
+// payload and waits for the expected banner
+func (c *TCPClient) ExecuteCheck(endpoint *data.Endpoint) (*TCPResponse, error) {
+	address, err := ParseTCPAddress(endpoint.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := c.config.Timeout
+	if endpoint.TimeoutSeconds > 0 {
+		timeout = time.Duration(endpoint.TimeoutSeconds) * time.Second
+	}
+
+	// Create connection context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("connection failed: %w", err)
+	}
+	defer conn.Close()
+
+	response := &TCPResponse{
+		Address:     address,
+		ConnectTime: time.Since(start),
+	}
+
+	// The remaining exchange shares the same deadline as the connection attempt
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, fmt.Errorf("failed to set connection deadline: %w", err)
+		}
+	}
+
+	if endpoint.TCPSend != "" {
+		if _, err := conn.Write([]byte(endpoint.TCPSend)); err != nil {
+			return nil, fmt.Errorf("failed to send payload: %w", err)
+		}
+	}
+
+	if endpoint.TCPExpect == "" {
+		return response, nil
+	}
+
+	banner, err := c.readUntil(conn, []byte(endpoint.TCPExpect))
+	if len(banner) > 0 {
+		sample := truncateSample(banner)
+		response.BodySample = &sample
+	}
+	if err != nil {
+		return response, fmt.Errorf("expected response %q not received: %w", endpoint.TCPExpect, err)
+	}
+
+	return response, nil
+}
+
+// readUntil reads from the connection until the expected bytes are seen, the
+// banner limit is reached or the deadline expires
+func (c *TCPClient) readUntil(conn net.Conn, expected []byte) ([]byte, error) {
+	buf := make([]byte, 0, 512)
+	chunk := make([]byte, 512)
+
+	for len(buf) < c.config.MaxBannerBytes {
+		n, err := conn.Read(chunk)
+		buf = append(buf, chunk[:n]...)
+		if bytes.Contains(buf, expected) {
+			return buf, nil
+		}
+		if err != nil {
+			return buf, err
+		}
+	}
+
+	return buf, errors.New("response limit reached")
+}
+
+// ParseTCPAddress normalizes a TCP check target, accepting either host:port or tcp://host:port
+func ParseTCPAddress(target string) (string, error) {
+	address := strings.TrimPrefix(strings.TrimSpace(target), "tcp://")
+
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid TCP address %q: %w", target, err)
+	}
+	if host == "" {
+		return "", fmt.Errorf("invalid TCP address %q: missing host", target)
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return "", fmt.Errorf("invalid TCP address %q: port must be between 1 and 65535", target)
+	}
+
+	return net.JoinHostPort(host, portStr), nil
+}
+
+// truncateSample converts response bytes to a sample suitable for storage
+func truncateSample(b []byte) string {
+	sampleSize := 500 // Store first 500 characters as sample
+	if len(b) > sampleSize {
+		return string(b[:sampleSize]) + "..."
+	}
+	return string(b)
+}
@@ -0,0 +1,192 @@
+package monitoring
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+// startTCPServer starts a local TCP server that runs handler for every accepted connection
+func startTCPServer(t *testing.T, handler func(conn net.Conn)) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start TCP server: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handler(conn)
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestNewTCPClient(t *testing.T) {
+	client := NewTCPClient(TCPClientConfig{})
+
+	assertTrue(t, client != nil)
+	assertEqual(t, 30*time.Second, client.config.Timeout)
+	assertEqual(t, 4096, client.config.MaxBannerBytes)
+}
+
+func TestTCPClient_ExecuteCheck_Connect(t *testing.T) {
+	address := startTCPServer(t, func(conn net.Conn) {})
+
+	client := NewTCPClient(TCPClientConfig{})
+	endpoint := &data.Endpoint{
+		CheckType:      data.CheckTypeTCP,
+		URL:            address,
+		TimeoutSeconds: 5,
+	}
+
+	response, err := client.ExecuteCheck(endpoint)
+	assertNoError(t, err)
+	assertEqual(t, address, response.Address)
+	assertTrue(t, response.BodySample == nil)
+}
+
+func TestTCPClient_ExecuteCheck_ExpectBanner(t *testing.T) {
+	address := startTCPServer(t, func(conn net.Conn) {
+		conn.Write([]byte("220 mail.example.com ESMTP ready\r\n"))
+	})
+
+	client := NewTCPClient(TCPClientConfig{})
+	endpoint := &data.Endpoint{
+		CheckType:      data.CheckTypeTCP,
+		URL:            "tcp://" + address,
+		TCPExpect:      "220",
+		TimeoutSeconds: 5,
+	}
+
+	response, err := client.ExecuteCheck(endpoint)
+	assertNoError(t, err)
+	assertTrue(t, response.BodySample != nil)
+	assertTrue(t, strings.HasPrefix(*response.BodySample, "220 mail.example.com"))
+}
+
+func TestTCPClient_ExecuteCheck_SendAndExpect(t *testing.T) {
+	address := startTCPServer(t, func(conn net.Conn) {
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return
+		}
+		if line == "PING\r\n" {
+			conn.Write([]byte("+PONG\r\n"))
+		}
+	})
+
+	client := NewTCPClient(TCPClientConfig{})
+	endpoint := &data.Endpoint{
+		CheckType:      data.CheckTypeTCP,
+		URL:            address,
+		TCPSend:        "PING\r\n",
+		TCPExpect:      "+PONG",
+		TimeoutSeconds: 5,
+	}
+
+	_, err := client.ExecuteCheck(endpoint)
+	assertNoError(t, err)
+}
+
+func TestTCPClient_ExecuteCheck_UnexpectedBanner(t *testing.T) {
+	address := startTCPServer(t, func(conn net.Conn) {
+		conn.Write([]byte("-ERR unknown command\r\n"))
+	})
+
+	client := NewTCPClient(TCPClientConfig{})
+	endpoint := &data.Endpoint{
+		CheckType:      data.CheckTypeTCP,
+		URL:            address,
+		TCPExpect:      "+PONG",
+		TimeoutSeconds: 1,
+	}
+
+	response, err := client.ExecuteCheck(endpoint)
+	assertError(t, err)
+	assertTrue(t, response != nil && response.BodySample != nil)
+	assertEqual(t, "-ERR unknown command\r\n", *response.BodySample)
+}
+
+func TestTCPClient_ExecuteCheck_ConnectionRefused(t *testing.T) {
+	// Grab a free port and close the listener so nothing is accepting on it
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to reserve port: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	client := NewTCPClient(TCPClientConfig{})
+	endpoint := &data.Endpoint{
+		CheckType:      data.CheckTypeTCP,
+		URL:            address,
+		TimeoutSeconds: 2,
+	}
+
+	_, err = client.ExecuteCheck(endpoint)
+	assertError(t, err)
+}
+
+func TestParseTCPAddress(t *testing.T) {
+	tests := []struct {
+		target   string
+		expected string
+		valid    bool
+	}{
+		{"db.example.com:5432", "db.example.com:5432", true},
+		{"tcp://10.0.0.1:6379", "10.0.0.1:6379", true},
+		{"[::1]:22", "[::1]:22", true},
+		{"db.example.com", "", false},
+		{":5432", "", false},
+		{"db.example.com:0", "", false},
+		{"db.example.com:70000", "", false},
+		{"db.example.com:ssh", "", false},
+	}
+
+	for _, tt := range tests {
+		address, err := ParseTCPAddress(tt.target)
+		if tt.valid {
+			assertNoError(t, err)
+			assertEqual(t, tt.expected, address)
+		} else {
+			assertError(t, err)
+		}
+	}
+}
+
+func TestWorkerPool_ExecuteJob_TCP(t *testing.T) {
+	address := startTCPServer(t, func(conn net.Conn) {
+		conn.Write([]byte("SSH-2.0-OpenSSH_9.6\r\n"))
+	})
+
+	wp := NewWorkerPool(WorkerPoolConfig{WorkerCount: 1, JobQueueSize: 1, ResultChanSize: 1}, log.New(io.Discard), NewMockDB(), nil)
+	endpoint := &data.Endpoint{
+		CheckType:      data.CheckTypeTCP,
+		URL:            address,
+		TCPExpect:      "SSH-2.0",
+		TimeoutSeconds: 5,
+	}
+
+	result := wp.executeJob(Job{Endpoint: endpoint, Timestamp: time.Now()})
+	assertTrue(t, result.Success)
+	assertTrue(t, result.StatusCode == nil)
+	assertTrue(t, result.ResponseTimeMs != nil)
+	assertTrue(t, result.ResponseSample != nil)
+}
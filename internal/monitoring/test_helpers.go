@@ -10,16 +10,22 @@ import (
 
 // MockDB implements the interfaces needed by the monitoring engine
 type MockDB struct {
-	endpoints       []data.Endpoint
-	monitoringLogs  []data.MonitoringLog
-	shouldFail      bool
-	failOnOperation string
+	endpoints         []data.Endpoint
+	monitoringLogs    []data.MonitoringLog
+	certificates      map[uuid.UUID]data.EndpointCertificate
+	incidents         map[uuid.UUID]data.Incident
+	endpointIncidents []data.EndpointIncident
+	timelines         []data.IncidentTimeline
+	shouldFail        bool
+	failOnOperation   string
 }
 
 func NewMockDB() *MockDB {
 	return &MockDB{
 		endpoints:      make([]data.Endpoint, 0),
 		monitoringLogs: make([]data.MonitoringLog, 0),
+		certificates:   make(map[uuid.UUID]data.EndpointCertificate),
+		incidents:      make(map[uuid.UUID]data.Incident),
 		shouldFail:     false,
 	}
 }
@@ -51,6 +57,15 @@ func (m *MockDB) CreateMonitoringLog(log *data.MonitoringLog) error {
 	return nil
 }
 
+func (m *MockDB) UpsertEndpointCertificate(cert *data.EndpointCertificate) error {
+	if m.shouldFail && m.failOnOperation == "UpsertEndpointCertificate" {
+		return gorm.ErrInvalidTransaction
+	}
+
+	m.certificates[cert.EndpointID] = *cert
+	return nil
+}
+
 // Additional methods needed by the engine
 func (m *MockDB) GetRecentMonitoringLogs(hours int) ([]data.MonitoringLog, error) {
 	if m.shouldFail && m.failOnOperation == "GetRecentMonitoringLogs" {
@@ -68,6 +83,85 @@ func (m *MockDB) GetRecentMonitoringLogs(hours int) ([]data.MonitoringLog, error
 	return recentLogs, nil
 }
 
+// Implement IncidentDB interface
+func (m *MockDB) GetEndpoint(id uuid.UUID) (*data.Endpoint, error) {
+	for _, endpoint := range m.endpoints {
+		if endpoint.ID == id {
+			return &endpoint, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockDB) GetEndpointCertificates() ([]data.EndpointCertificate, error) {
+	var certs []data.EndpointCertificate
+	for _, cert := range m.certificates {
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+func (m *MockDB) CreateIncident(incident *data.Incident) error {
+	if m.shouldFail && m.failOnOperation == "CreateIncident" {
+		return gorm.ErrInvalidTransaction
+	}
+
+	incident.ID = uuid.New()
+	m.incidents[incident.ID] = *incident
+	return nil
+}
+
+func (m *MockDB) GetIncident(id uuid.UUID) (*data.Incident, error) {
+	incident, exists := m.incidents[id]
+	if !exists {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &incident, nil
+}
+
+func (m *MockDB) UpdateIncident(incident *data.Incident) error {
+	m.incidents[incident.ID] = *incident
+	return nil
+}
+
+func (m *MockDB) CreateEndpointIncident(endpointIncident *data.EndpointIncident) error {
+	// Endpoints are associated with an incident once, like the database's unique constraint
+	for _, ei := range m.endpointIncidents {
+		if ei.EndpointID == endpointIncident.EndpointID && ei.IncidentID == endpointIncident.IncidentID {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	endpointIncident.ID = uuid.New()
+	m.endpointIncidents = append(m.endpointIncidents, *endpointIncident)
+	return nil
+}
+
+func (m *MockDB) GetEndpointIncidents(incidentID uuid.UUID) ([]data.EndpointIncident, error) {
+	var result []data.EndpointIncident
+	for _, ei := range m.endpointIncidents {
+		if ei.IncidentID == incidentID {
+			result = append(result, ei)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockDB) UpdateEndpointIncident(endpointIncident *data.EndpointIncident) error {
+	for i, ei := range m.endpointIncidents {
+		if ei.ID == endpointIncident.ID {
+			m.endpointIncidents[i] = *endpointIncident
+		}
+	}
+	return nil
+}
+
+func (m *MockDB) CreateIncidentTimeline(timeline *data.IncidentTimeline) error {
+	timeline.ID = uuid.New()
+	m.timelines = append(m.timelines, *timeline)
+	return nil
+}
+
 // Helper methods for test setup
 func (m *MockDB) AddEndpoint(endpoint data.Endpoint) {
 	endpoint.ID = uuid.New()
@@ -12,12 +12,13 @@ import (
 
 // ValidationResult represents the result of response validation
 type ValidationResult struct {
-	Success      bool              `json:"success"`
-	StatusValid  bool              `json:"status_valid"`
-	ContentValid bool              `json:"content_valid"`
-	ResponseTime time.Duration     `json:"response_time"`
-	Errors       []ValidationError `json:"errors,omitempty"`
-	Metrics      ValidationMetrics `json:"metrics"`
+	Success         bool              `json:"success"`
+	StatusValid     bool              `json:"status_valid"`
+	ContentValid    bool              `json:"content_valid"`
+	AssertionsValid bool              `json:"assertions_valid"`
+	ResponseTime    time.Duration     `json:"response_time"`
+	Errors          []ValidationError `json:"errors,omitempty"`
+	Metrics         ValidationMetrics `json:"metrics"`
 }
 
 // ValidationError represents a validation error
@@ -96,8 +97,11 @@ func (v *ResponseValidator) ValidateResponse(endpoint *data.Endpoint, response *
 		result.ContentValid = true // No content validation configured
 	}
 
+	// Evaluate the endpoint's response assertions
+	result.AssertionsValid = v.validateAssertions(endpoint, response, &result)
+
 	// Overall success is determined by all validation checks
-	result.Success = result.StatusValid && responseTimeValid && result.ContentValid
+	result.Success = result.StatusValid && responseTimeValid && result.ContentValid && result.AssertionsValid
 
 	// Record validation latency
 	result.Metrics.ValidationLatency = time.Since(startTime).Nanoseconds()
@@ -155,13 +159,10 @@ func (v *ResponseValidator) validateStatusCode(endpoint *data.Endpoint, response
 
 // validateResponseTime checks if the response time is within acceptable limits
 func (v *ResponseValidator) validateResponseTime(endpoint *data.Endpoint, responseTime time.Duration, result *ValidationResult) bool {
-	maxAllowed := time.Duration(endpoint.TimeoutSeconds) * time.Second
-	globalMax := time.Duration(v.config.MaxResponseTimeMs) * time.Millisecond
-
-	// Use the more restrictive limit
-	limit := maxAllowed
-	if globalMax < maxAllowed {
-		limit = globalMax
+	// The endpoint's own timeout takes precedence over the global limit
+	limit := time.Duration(v.config.MaxResponseTimeMs) * time.Millisecond
+	if endpoint.TimeoutSeconds > 0 {
+		limit = time.Duration(endpoint.TimeoutSeconds) * time.Second
 	}
 
 	if responseTime > limit {
@@ -296,6 +297,70 @@ func (v *ResponseValidator) isValidJSON(body string) bool {
 func (v *ResponseValidator) ValidateEndpointConfig(endpoint *data.Endpoint) []ValidationError {
 	var errors []ValidationError
 
+	switch endpoint.GetCheckType() {
+	case data.CheckTypeHTTP:
+		errors = append(errors, v.validateHTTPConfig(endpoint)...)
+	case data.CheckTypeTCP:
+		if _, err := ParseTCPAddress(endpoint.URL); err != nil {
+			errors = append(errors, ValidationError{
+				Type:        "invalid_tcp_address",
+				Field:       "url",
+				Expected:    "host:port",
+				Actual:      endpoint.URL,
+				Description: err.Error(),
+			})
+		}
+	case data.CheckTypeDNS:
+		errors = append(errors, v.validateDNSConfig(endpoint)...)
+	case data.CheckTypeHeartbeat:
+		if endpoint.HeartbeatGraceSeconds < 0 || endpoint.HeartbeatGraceSeconds > 86400 {
+			errors = append(errors, ValidationError{
+				Type:        "invalid_heartbeat_grace",
+				Field:       "heartbeat_grace_seconds",
+				Expected:    "0-86400 seconds",
+				Actual:      strconv.Itoa(endpoint.HeartbeatGraceSeconds),
+				Description: "Heartbeat grace period must be between 0 seconds and 24 hours",
+			})
+		}
+	default:
+		errors = append(errors, ValidationError{
+			Type:        "invalid_check_type",
+			Field:       "check_type",
+			Expected:    strings.Join(data.ValidCheckTypes, ", "),
+			Actual:      endpoint.CheckType,
+			Description: fmt.Sprintf("Check type must be one of: %s", strings.Join(data.ValidCheckTypes, ", ")),
+		})
+	}
+
+	// Validate timeout
+	if endpoint.TimeoutSeconds <= 0 || endpoint.TimeoutSeconds > 300 {
+		errors = append(errors, ValidationError{
+			Type:        "invalid_timeout",
+			Field:       "timeout_seconds",
+			Expected:    "1-300 seconds",
+			Actual:      strconv.Itoa(endpoint.TimeoutSeconds),
+			Description: "Timeout must be between 1 and 300 seconds",
+		})
+	}
+
+	// Validate check interval
+	if endpoint.CheckIntervalSeconds <= 0 || endpoint.CheckIntervalSeconds > 86400 {
+		errors = append(errors, ValidationError{
+			Type:        "invalid_check_interval",
+			Field:       "check_interval_seconds",
+			Expected:    "60-86400 seconds",
+			Actual:      strconv.Itoa(endpoint.CheckIntervalSeconds),
+			Description: "Check interval must be between 1 second and 24 hours",
+		})
+	}
+
+	return errors
+}
+
+// validateHTTPConfig validates the HTTP-specific endpoint configuration
+func (v *ResponseValidator) validateHTTPConfig(endpoint *data.Endpoint) []ValidationError {
+	var errors []ValidationError
+
 	// Validate URL
 	if endpoint.URL == "" {
 		errors = append(errors, ValidationError{
@@ -334,39 +399,76 @@ func (v *ResponseValidator) ValidateEndpointConfig(endpoint *data.Endpoint) []Va
 		})
 	}
 
-	// Validate timeout
-	if endpoint.TimeoutSeconds <= 0 || endpoint.TimeoutSeconds > 300 {
+	// Validate expected status code
+	if endpoint.ExpectedStatusCode < 100 || endpoint.ExpectedStatusCode > 599 {
 		errors = append(errors, ValidationError{
-			Type:        "invalid_timeout",
-			Field:       "timeout_seconds",
-			Expected:    "1-300 seconds",
-			Actual:      strconv.Itoa(endpoint.TimeoutSeconds),
-			Description: "Timeout must be between 1 and 300 seconds",
+			Type:        "invalid_status_code",
+			Field:       "expected_status_code",
+			Expected:    "100-599",
+			Actual:      strconv.Itoa(endpoint.ExpectedStatusCode),
+			Description: "Expected status code must be a valid HTTP status code (100-599)",
 		})
 	}
 
-	// Validate check interval
-	if endpoint.CheckIntervalSeconds <= 0 || endpoint.CheckIntervalSeconds > 86400 {
+	// Validate response assertions
+	for i, assertion := range endpoint.Assertions {
+		if err := ValidateAssertion(assertion); err != nil {
+			errors = append(errors, ValidationError{
+				Type:        "invalid_assertion",
+				Field:       fmt.Sprintf("assertions[%d]", i),
+				Expected:    "valid assertion",
+				Actual:      assertion.Type,
+				Description: fmt.Sprintf("Assertion %d is invalid: %s", i+1, err.Error()),
+			})
+		}
+	}
+
+	return errors
+}
+
+// validateDNSConfig validates the DNS-specific endpoint configuration
+func (v *ResponseValidator) validateDNSConfig(endpoint *data.Endpoint) []ValidationError {
+	var errors []ValidationError
+
+	if strings.TrimSpace(endpoint.URL) == "" {
 		errors = append(errors, ValidationError{
-			Type:        "invalid_check_interval",
-			Field:       "check_interval_seconds",
-			Expected:    "60-86400 seconds",
-			Actual:      strconv.Itoa(endpoint.CheckIntervalSeconds),
-			Description: "Check interval must be between 1 second and 24 hours",
+			Type:        "missing_hostname",
+			Field:       "url",
+			Expected:    "non-empty hostname",
+			Actual:      "empty",
+			Description: "Hostname is required for DNS checks",
 		})
 	}
 
-	// Validate expected status code
-	if endpoint.ExpectedStatusCode < 100 || endpoint.ExpectedStatusCode > 599 {
+	recordTypeValid := endpoint.DNSRecordType == ""
+	for _, recordType := range data.ValidDNSRecordTypes {
+		if strings.EqualFold(endpoint.DNSRecordType, recordType) {
+			recordTypeValid = true
+			break
+		}
+	}
+	if !recordTypeValid {
 		errors = append(errors, ValidationError{
-			Type:        "invalid_status_code",
-			Field:       "expected_status_code",
-			Expected:    "100-599",
-			Actual:      strconv.Itoa(endpoint.ExpectedStatusCode),
-			Description: "Expected status code must be a valid HTTP status code (100-599)",
+			Type:        "invalid_dns_record_type",
+			Field:       "dns_record_type",
+			Expected:    strings.Join(data.ValidDNSRecordTypes, ", "),
+			Actual:      endpoint.DNSRecordType,
+			Description: fmt.Sprintf("DNS record type must be one of: %s", strings.Join(data.ValidDNSRecordTypes, ", ")),
 		})
 	}
 
+	if endpoint.DNSResolver != "" {
+		if _, err := ParseResolverAddress(endpoint.DNSResolver); err != nil {
+			errors = append(errors, ValidationError{
+				Type:        "invalid_dns_resolver",
+				Field:       "dns_resolver",
+				Expected:    "host or host:port",
+				Actual:      endpoint.DNSResolver,
+				Description: err.Error(),
+			})
+		}
+	}
+
 	return errors
 }
 
@@ -15,6 +15,12 @@ import (
 // MonitoringDB defines the interface for database operations needed by the worker pool
 type MonitoringDB interface {
 	CreateMonitoringLog(log *data.MonitoringLog) error
+	UpsertEndpointCertificate(cert *data.EndpointCertificate) error
+}
+
+// MaintenanceChecker tells whether an endpoint is in a maintenance window at a given time
+type MaintenanceChecker interface {
+	InMaintenance(endpointID uuid.UUID, at time.Time) bool
 }
 
 // Job represents a monitoring task to be executed
@@ -33,12 +39,18 @@ type Result struct {
 	ResponseTimeMs *int
 	ErrorMessage   *string
 	ResponseSample *string
+	Certificate    *TLSCertificateInfo
 	ExecutedAt     time.Time
+	// InMaintenance is set when the check ran during a maintenance window of the endpoint
+	InMaintenance bool
 }
 
 // ResultCallback is a function type for handling monitoring results
 type ResultCallback func(endpointID, endpointName string, success bool, responseTime *int)
 
+// ResultHandler receives every result after it has been stored, in the order results are processed
+type ResultHandler func(result Result)
+
 // WorkerPool manages a pool of workers for executing monitoring jobs
 type WorkerPool struct {
 	workers        int
@@ -50,15 +62,22 @@ type WorkerPool struct {
 	logger         *log.Logger
 	db             MonitoringDB
 	httpClient     *HTTPClient
+	tcpClient      *TCPClient
+	dnsClient      *DNSClient
 	validator      *ResponseValidator
 	resultCallback ResultCallback
+	resultHandlers []ResultHandler
+	maintenance    MaintenanceChecker
+	mu             sync.RWMutex // held for writing while stopping so no submit races the channels closing
+	stopped        bool
 }
 
 // WorkerPoolConfig holds configuration for the worker pool
 type WorkerPoolConfig struct {
-	WorkerCount    int
-	JobQueueSize   int
-	ResultChanSize int
+	WorkerCount      int
+	JobQueueSize     int
+	ResultChanSize   int
+	HTTPClientConfig HTTPClientConfig // defaults for endpoints without their own timeout and retry settings
 }
 
 // NewWorkerPool creates a new worker pool with the specified configuration
@@ -74,11 +93,12 @@ func NewWorkerPool(config WorkerPoolConfig, logger *log.Logger, db MonitoringDB,
 		logger:         logger,
 		db:             db,
 		resultCallback: resultCallback,
-		httpClient: NewHTTPClient(HTTPClientConfig{
-			Timeout:       30 * time.Second,
-			MaxRetries:    3,
-			RetryDelay:    1 * time.Second,
-			MaxRetryDelay: 10 * time.Second,
+		httpClient:     NewHTTPClient(config.HTTPClientConfig),
+		tcpClient: NewTCPClient(TCPClientConfig{
+			Timeout: 30 * time.Second,
+		}),
+		dnsClient: NewDNSClient(DNSClientConfig{
+			Timeout: 30 * time.Second,
 		}),
 		validator: NewResponseValidator(ValidatorConfig{
 			MaxResponseTimeMs:        30000,
@@ -89,6 +109,16 @@ func NewWorkerPool(config WorkerPoolConfig, logger *log.Logger, db MonitoringDB,
 	}
 }
 
+// AddResultHandler registers a handler for the result stream. Handlers must be added before Start
+func (wp *WorkerPool) AddResultHandler(handler ResultHandler) {
+	wp.resultHandlers = append(wp.resultHandlers, handler)
+}
+
+// UseMaintenance marks results of endpoints in a maintenance window. It must be called before Start
+func (wp *WorkerPool) UseMaintenance(checker MaintenanceChecker) {
+	wp.maintenance = checker
+}
+
 // Start begins the worker pool operation
 func (wp *WorkerPool) Start() {
 	wp.logger.Info("starting worker pool", "workers", wp.workers)
@@ -109,8 +139,11 @@ func (wp *WorkerPool) Stop() {
 	wp.logger.Info("stopping worker pool")
 
 	// Close job queue and cancel context
+	wp.mu.Lock()
+	wp.stopped = true
 	close(wp.jobQueue)
 	wp.cancel()
+	wp.mu.Unlock()
 
 	// Wait for all workers to finish
 	wp.wg.Wait()
@@ -123,6 +156,12 @@ func (wp *WorkerPool) Stop() {
 
 // SubmitJob adds a job to the worker pool queue
 func (wp *WorkerPool) SubmitJob(job Job) error {
+	wp.mu.RLock()
+	defer wp.mu.RUnlock()
+	if wp.stopped {
+		return ErrWorkerPoolStopped
+	}
+
 	select {
 	case wp.jobQueue <- job:
 		return nil
@@ -134,6 +173,26 @@ func (wp *WorkerPool) SubmitJob(job Job) error {
 	}
 }
 
+// SubmitResult hands a result produced outside the workers, such as a heartbeat ping,
+// to the result processor so it is stored like any executed job
+func (wp *WorkerPool) SubmitResult(result Result) error {
+	wp.mu.RLock()
+	defer wp.mu.RUnlock()
+	if wp.stopped {
+		return ErrWorkerPoolStopped
+	}
+
+	select {
+	case wp.resultChan <- result:
+		return nil
+	case <-wp.ctx.Done():
+		return wp.ctx.Err()
+	default:
+		wp.logger.Warn("result queue is full, dropping result", "endpoint_id", result.Job.EndpointID)
+		return ErrResultQueueFull
+	}
+}
+
 // worker is the main worker function that processes jobs
 func (wp *WorkerPool) worker(workerID int) {
 	defer wp.wg.Done()
@@ -166,8 +225,53 @@ func (wp *WorkerPool) worker(workerID int) {
 	}
 }
 
-// executeJob performs the actual HTTP monitoring request
+// executeJob runs the endpoint's check, re-running a failed check up to the
+// endpoint's confirm retries before the failure is reported
 func (wp *WorkerPool) executeJob(job Job) Result {
+	result := wp.executeCheck(job)
+
+	confirmRetries := job.Endpoint.ConfirmRetries
+	for attempt := 1; !result.Success && attempt <= confirmRetries; attempt++ {
+		select {
+		case <-time.After(wp.httpClient.retryDelay(job.Endpoint)):
+		case <-wp.ctx.Done():
+			return result
+		}
+
+		wp.logger.Debug("re-running failed check to confirm failure",
+			"endpoint_id", job.EndpointID,
+			"attempt", attempt,
+			"confirm_retries", confirmRetries)
+
+		result = wp.executeCheck(job)
+	}
+
+	if !result.Success && confirmRetries > 0 && result.ErrorMessage != nil {
+		errMsg := fmt.Sprintf("%s (confirmed after %d retries)", *result.ErrorMessage, confirmRetries)
+		result.ErrorMessage = &errMsg
+	}
+
+	return result
+}
+
+// executeCheck dispatches the job to the checker for the endpoint's check type
+func (wp *WorkerPool) executeCheck(job Job) Result {
+	switch job.Endpoint.GetCheckType() {
+	case data.CheckTypeTCP:
+		return wp.executeTCPJob(job)
+	case data.CheckTypeDNS:
+		return wp.executeDNSJob(job)
+	case data.CheckTypeHeartbeat:
+		// Heartbeats are pushed by the monitored job, there is nothing to probe
+		errMsg := "heartbeat endpoints cannot be probed"
+		return Result{Job: job, ErrorMessage: &errMsg, ExecutedAt: time.Now()}
+	default:
+		return wp.executeHTTPJob(job)
+	}
+}
+
+// executeHTTPJob performs the actual HTTP monitoring request
+func (wp *WorkerPool) executeHTTPJob(job Job) Result {
 	start := time.Now()
 
 	result := Result{
@@ -177,6 +281,10 @@ func (wp *WorkerPool) executeJob(job Job) Result {
 
 	// Execute HTTP request
 	response, err := wp.httpClient.ExecuteRequest(job.Endpoint)
+	if response != nil {
+		result.Certificate = response.Certificate
+	}
+
 	if err != nil {
 		result.Success = false
 		errMsg := err.Error()
@@ -218,6 +326,86 @@ func (wp *WorkerPool) executeJob(job Job) Result {
 	return result
 }
 
+// executeTCPJob performs a TCP port check
+func (wp *WorkerPool) executeTCPJob(job Job) Result {
+	start := time.Now()
+
+	result := Result{
+		Job:        job,
+		ExecutedAt: start,
+	}
+
+	response, err := wp.tcpClient.ExecuteCheck(job.Endpoint)
+	if response != nil {
+		result.ResponseSample = response.BodySample
+	}
+
+	if err != nil {
+		result.Success = false
+		errMsg := err.Error()
+		result.ErrorMessage = &errMsg
+		wp.logger.Error("job execution failed",
+			"endpoint_id", job.EndpointID,
+			"check_type", data.CheckTypeTCP,
+			"error", err.Error())
+		return result
+	}
+
+	// Calculate response time
+	responseTimeMs := int(time.Since(start).Milliseconds())
+	result.ResponseTimeMs = &responseTimeMs
+	result.Success = true
+
+	wp.logger.Debug("job executed",
+		"endpoint_id", job.EndpointID,
+		"check_type", data.CheckTypeTCP,
+		"address", response.Address,
+		"response_time_ms", responseTimeMs)
+
+	return result
+}
+
+// executeDNSJob performs a DNS resolution check
+func (wp *WorkerPool) executeDNSJob(job Job) Result {
+	start := time.Now()
+
+	result := Result{
+		Job:        job,
+		ExecutedAt: start,
+	}
+
+	response, err := wp.dnsClient.ExecuteCheck(job.Endpoint)
+	if response != nil {
+		result.ResponseSample = response.BodySample
+	}
+
+	if err != nil {
+		result.Success = false
+		errMsg := err.Error()
+		result.ErrorMessage = &errMsg
+		wp.logger.Error("job execution failed",
+			"endpoint_id", job.EndpointID,
+			"check_type", data.CheckTypeDNS,
+			"error", err.Error())
+		return result
+	}
+
+	// Calculate response time
+	responseTimeMs := int(time.Since(start).Milliseconds())
+	result.ResponseTimeMs = &responseTimeMs
+	result.Success = true
+
+	wp.logger.Debug("job executed",
+		"endpoint_id", job.EndpointID,
+		"check_type", data.CheckTypeDNS,
+		"record_type", response.RecordType,
+		"resolver", response.Resolver,
+		"records", len(response.Records),
+		"response_time_ms", responseTimeMs)
+
+	return result
+}
+
 // resultProcessor handles the results from workers and saves them to database
 func (wp *WorkerPool) resultProcessor() {
 	defer wp.wg.Done()
@@ -232,6 +420,10 @@ func (wp *WorkerPool) resultProcessor() {
 				return
 			}
 
+			if wp.maintenance != nil {
+				result.InMaintenance = wp.maintenance.InMaintenance(result.Job.EndpointID, result.ExecutedAt)
+			}
+
 			// Save monitoring result to database
 			monitoringLog := &data.MonitoringLog{
 				EndpointID:         result.Job.EndpointID,
@@ -241,6 +433,7 @@ func (wp *WorkerPool) resultProcessor() {
 				ErrorMessage:       result.ErrorMessage,
 				Success:            result.Success,
 				ResponseBodySample: result.ResponseSample,
+				InMaintenance:      result.InMaintenance,
 			}
 
 			if err := wp.db.CreateMonitoringLog(monitoringLog); err != nil {
@@ -263,6 +456,21 @@ func (wp *WorkerPool) resultProcessor() {
 				}
 			}
 
+			// Record the latest certificate presented by HTTPS endpoints
+			if result.Certificate != nil {
+				cert := result.Certificate.toModel(result.Job.EndpointID, result.ExecutedAt)
+				if err := wp.db.UpsertEndpointCertificate(cert); err != nil {
+					wp.logger.Error("failed to save endpoint certificate",
+						"endpoint_id", result.Job.EndpointID,
+						"error", err.Error())
+				}
+			}
+
+			// Stream the result to subscribers such as the scheduler and incident detector
+			for _, handler := range wp.resultHandlers {
+				handler(result)
+			}
+
 		case <-wp.ctx.Done():
 			wp.logger.Debug("result processor stopping - context cancelled")
 			return
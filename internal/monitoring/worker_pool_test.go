@@ -0,0 +1,92 @@
+package monitoring
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+// newStatusSequenceServer serves the given status codes in order, repeating the last one
+func newStatusSequenceServer(t *testing.T, statuses ...int) (*httptest.Server, *int) {
+	t.Helper()
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[min(attempts, len(statuses)-1)]
+		attempts++
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &attempts
+}
+
+func newConfirmRetriesEndpoint(url string, confirmRetries int) *data.Endpoint {
+	retryDelayMs := 10
+	return &data.Endpoint{
+		URL:                url,
+		Method:             "GET",
+		ExpectedStatusCode: 200,
+		TimeoutSeconds:     5,
+		RetryDelayMs:       &retryDelayMs,
+		ConfirmRetries:     confirmRetries,
+	}
+}
+
+func TestWorkerPool_ExecuteJob_ConfirmRetriesRecovers(t *testing.T) {
+	server, attempts := newStatusSequenceServer(t, http.StatusNotFound, http.StatusNotFound, http.StatusOK)
+
+	wp := NewWorkerPool(WorkerPoolConfig{WorkerCount: 1, JobQueueSize: 1, ResultChanSize: 1}, log.New(io.Discard), NewMockDB(), nil)
+	endpoint := newConfirmRetriesEndpoint(server.URL, 2)
+
+	result := wp.executeJob(Job{Endpoint: endpoint, Timestamp: time.Now()})
+	assertTrue(t, result.Success)
+	assertEqual(t, 3, *attempts)
+}
+
+func TestWorkerPool_ExecuteJob_ConfirmRetriesConfirmsFailure(t *testing.T) {
+	server, attempts := newStatusSequenceServer(t, http.StatusNotFound)
+
+	wp := NewWorkerPool(WorkerPoolConfig{WorkerCount: 1, JobQueueSize: 1, ResultChanSize: 1}, log.New(io.Discard), NewMockDB(), nil)
+	endpoint := newConfirmRetriesEndpoint(server.URL, 2)
+
+	result := wp.executeJob(Job{Endpoint: endpoint, Timestamp: time.Now()})
+	assertTrue(t, !result.Success)
+	assertEqual(t, 3, *attempts)
+	assertTrue(t, result.ErrorMessage != nil)
+	assertTrue(t, strings.HasSuffix(*result.ErrorMessage, "(confirmed after 2 retries)"))
+}
+
+func TestWorkerPool_ExecuteJob_NoConfirmRetries(t *testing.T) {
+	server, attempts := newStatusSequenceServer(t, http.StatusNotFound)
+
+	wp := NewWorkerPool(WorkerPoolConfig{WorkerCount: 1, JobQueueSize: 1, ResultChanSize: 1}, log.New(io.Discard), NewMockDB(), nil)
+	endpoint := newConfirmRetriesEndpoint(server.URL, 0)
+
+	result := wp.executeJob(Job{Endpoint: endpoint, Timestamp: time.Now()})
+	assertTrue(t, !result.Success)
+	assertEqual(t, 1, *attempts)
+	assertTrue(t, !strings.Contains(*result.ErrorMessage, "confirmed"))
+}
+
+func TestWorkerPool_SubmitAfterStop(t *testing.T) {
+	pool := NewWorkerPool(WorkerPoolConfig{WorkerCount: 1, JobQueueSize: 1, ResultChanSize: 1}, log.New(io.Discard), NewMockDB(), nil)
+	pool.Start()
+	pool.Stop()
+
+	// Submitting to the closed channels must not panic
+	for i := 0; i < 100; i++ {
+		if err := pool.SubmitResult(Result{}); err != ErrWorkerPoolStopped {
+			t.Fatalf("Expected ErrWorkerPoolStopped, got %v", err)
+		}
+		if err := pool.SubmitJob(Job{}); err != ErrWorkerPoolStopped {
+			t.Fatalf("Expected ErrWorkerPoolStopped, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProviderFactory creates a new, unconfigured provider instance
+type ProviderFactory func() NotificationProvider
+
+// ChannelConfig describes a named notification channel as it is stored
+type ChannelConfig struct {
+	ID     uuid.UUID
+	Name   string
+	Config ProviderConfig
+	// Templates override the channel's messages by notification type
+	Templates map[NotificationType]MessageTemplate
+	// RateLimit overrides the throttle's rate limit for the channel, 0 disables it
+	RateLimit *int
+	// QuietHours holds back low severity notifications, nil sends everything right away
+	QuietHours *QuietHoursConfig
+}
+
+// Channel is a named, configured provider instance. Several channels may share a provider
+// type, for example one Slack webhook per team
+type Channel struct {
+	ID         uuid.UUID
+	Name       string
+	Provider   NotificationProvider
+	Templates  *TemplateSet
+	RateLimit  *int
+	QuietHours *QuietHours
+}
+
+// Type returns the channel's provider type
+func (c *Channel) Type() ProviderType {
+	return c.Provider.GetType()
+}
+
+// String returns a label identifying the channel in logs
+func (c *Channel) String() string {
+	return fmt.Sprintf("%s/%s", c.Provider.GetType(), c.Name)
+}
+
+// RegisterProviderFactory registers the factory used to create channels of a provider type
+func (s *Service) RegisterProviderFactory(providerType ProviderType, factory ProviderFactory) error {
+	if factory == nil {
+		return fmt.Errorf("factory cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.factories[providerType] = factory
+	return nil
+}
+
+// SupportsProviderType returns whether channels of the provider type can be created
+func (s *Service) SupportsProviderType(providerType ProviderType) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, exists := s.factories[providerType]
+	return exists
+}
+
+// NewChannelProvider creates a provider for the configuration, returning an error if the
+// provider type is unknown or rejects the settings
+func (s *Service) NewChannelProvider(config ProviderConfig) (NotificationProvider, error) {
+	s.mu.RLock()
+	factory, exists := s.factories[config.Type]
+	s.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("unsupported provider type: %s", config.Type)
+	}
+
+	provider := factory()
+	if err := provider.Configure(config); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// LoadChannels replaces all channels with the given configurations. Channels whose
+// configuration is rejected are left out and their errors returned by channel ID
+func (s *Service) LoadChannels(configs []ChannelConfig) map[uuid.UUID]error {
+	channels := make(map[uuid.UUID]*Channel, len(configs))
+	errs := make(map[uuid.UUID]error)
+
+	for _, config := range configs {
+		provider, err := s.NewChannelProvider(config.Config)
+		if err != nil {
+			errs[config.ID] = err
+			s.logger.Error("Failed to configure notification channel",
+				"channel_id", config.ID,
+				"name", config.Name,
+				"type", config.Config.Type,
+				"error", err)
+			continue
+		}
+
+		templates, err := CompileTemplates(config.Templates)
+		if err != nil {
+			errs[config.ID] = err
+			s.logger.Error("Failed to compile notification channel templates",
+				"channel_id", config.ID,
+				"name", config.Name,
+				"error", err)
+			continue
+		}
+
+		var quietHours *QuietHours
+		if config.QuietHours != nil {
+			if quietHours, err = NewQuietHours(*config.QuietHours); err != nil {
+				errs[config.ID] = fmt.Errorf("invalid quiet hours: %w", err)
+				s.logger.Error("Failed to configure notification channel quiet hours",
+					"channel_id", config.ID,
+					"name", config.Name,
+					"error", err)
+				continue
+			}
+		}
+
+		channels[config.ID] = &Channel{
+			ID:         config.ID,
+			Name:       config.Name,
+			Provider:   provider,
+			Templates:  templates,
+			RateLimit:  config.RateLimit,
+			QuietHours: quietHours,
+		}
+	}
+
+	s.mu.Lock()
+	s.channels = channels
+	s.mu.Unlock()
+
+	s.logger.Info("Loaded notification channels",
+		"channels", len(channels),
+		"failed", len(errs))
+
+	return errs
+}
+
+// GetChannel returns a channel by ID
+func (s *Service) GetChannel(id uuid.UUID) (*Channel, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	channel, exists := s.channels[id]
+	return channel, exists
+}
+
+// GetChannels returns all loaded channels ordered by type and name
+func (s *Service) GetChannels() []*Channel {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	channels := make([]*Channel, 0, len(s.channels))
+	for _, channel := range s.channels {
+		channels = append(channels, channel)
+	}
+
+	sort.Slice(channels, func(i, j int) bool {
+		if channels[i].Type() != channels[j].Type() {
+			return channels[i].Type() < channels[j].Type()
+		}
+		return channels[i].Name < channels[j].Name
+	})
+
+	return channels
+}
+
+// GetEnabledChannels returns all enabled channels ordered by type and name
+func (s *Service) GetEnabledChannels() []*Channel {
+	var enabled []*Channel
+	for _, channel := range s.GetChannels() {
+		if channel.Provider.IsEnabled() {
+			enabled = append(enabled, channel)
+		}
+	}
+	return enabled
+}
+
+// SendNotificationToChannel sends a notification through a single channel
+func (s *Service) SendNotificationToChannel(ctx context.Context, id uuid.UUID, data NotificationData) DeliveryResult {
+	channel, exists := s.GetChannel(id)
+	if !exists {
+		return DeliveryResult{
+			Success:   false,
+			Error:     fmt.Errorf("channel %s not found", id),
+			Timestamp: time.Now(),
+			Details:   "Channel not loaded",
+		}
+	}
+
+	if !channel.Provider.IsEnabled() {
+		return DeliveryResult{
+			Success:   false,
+			Error:     fmt.Errorf("channel %s is disabled", channel),
+			Timestamp: time.Now(),
+			Details:   "Channel disabled",
+		}
+	}
+
+	return s.sendThroughChannel(ctx, channel, data)
+}
+
+// SendNotificationToChannels sends a notification concurrently to the enabled channels the
+// routing rules select for it
+func (s *Service) SendNotificationToChannels(ctx context.Context, data NotificationData) map[uuid.UUID]DeliveryResult {
+	results := make(map[uuid.UUID]DeliveryResult)
+	routes := s.Route(data)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for _, route := range routes {
+		wg.Add(1)
+		go func(route ChannelRoute) {
+			defer wg.Done()
+
+			result := s.sendThroughChannel(ctx, route.Channel, s.forRoute(data, route))
+
+			mu.Lock()
+			results[route.Channel.ID] = result
+			mu.Unlock()
+		}(route)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// sendThroughChannel renders the notification with the channel's templates and sends it. A
+// template failing to render falls back to the default message rather than dropping the alert
+func (s *Service) sendThroughChannel(ctx context.Context, channel *Channel, data NotificationData) DeliveryResult {
+	rendered, err := channel.Templates.Render(data)
+	if err != nil {
+		s.logger.Error("Failed to render notification template, sending the default message",
+			"channel", channel.String(),
+			"type", data.Type,
+			"error", err)
+		rendered = data
+	}
+
+	return channel.Provider.SendNotification(ctx, rendered)
+}
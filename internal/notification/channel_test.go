@@ -0,0 +1,174 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecordingProvider takes its enabled flag and target from the configuration and records sends
+type RecordingProvider struct {
+	providerType ProviderType
+	enabled      bool
+	target       string
+	sent         *sync.Map // target -> NotificationData
+}
+
+func (p *RecordingProvider) GetType() ProviderType { return p.providerType }
+
+func (p *RecordingProvider) IsEnabled() bool { return p.enabled }
+
+func (p *RecordingProvider) Configure(config ProviderConfig) error {
+	target, ok := config.Settings["webhook_url"].(string)
+	if !ok || target == "" {
+		return errors.New("webhook_url is required")
+	}
+	p.enabled = config.Enabled
+	p.target = target
+	return nil
+}
+
+func (p *RecordingProvider) SendNotification(ctx context.Context, data NotificationData) DeliveryResult {
+	p.sent.Store(p.target, data)
+	return DeliveryResult{Success: true, Timestamp: time.Now()}
+}
+
+func (p *RecordingProvider) TestConnection(ctx context.Context) error { return nil }
+
+func newChannelTestService(sent *sync.Map) *Service {
+	service := NewService(nil)
+	service.RegisterProviderFactory(ProviderTypeSlack, func() NotificationProvider {
+		return &RecordingProvider{providerType: ProviderTypeSlack, sent: sent}
+	})
+	return service
+}
+
+func slackChannel(name, webhookURL string, enabled bool) ChannelConfig {
+	return ChannelConfig{
+		ID:   uuid.New(),
+		Name: name,
+		Config: ProviderConfig{
+			Type:     ProviderTypeSlack,
+			Enabled:  enabled,
+			Settings: map[string]interface{}{"webhook_url": webhookURL},
+		},
+	}
+}
+
+func TestServiceLoadChannels(t *testing.T) {
+	sent := &sync.Map{}
+	service := newChannelTestService(sent)
+
+	backend := slackChannel("Backend", "https://hooks.slack.com/backend", true)
+	frontend := slackChannel("Frontend", "https://hooks.slack.com/frontend", true)
+	disabled := slackChannel("Archived", "https://hooks.slack.com/archived", false)
+	invalid := slackChannel("Broken", "", true)
+	unknown := ChannelConfig{ID: uuid.New(), Name: "Pager", Config: ProviderConfig{Type: "pager", Enabled: true}}
+
+	errs := service.LoadChannels([]ChannelConfig{backend, frontend, disabled, invalid, unknown})
+	if len(errs) != 2 || errs[invalid.ID] == nil || errs[unknown.ID] == nil {
+		t.Fatalf("Expected errors for the invalid and unknown channels, got %v", errs)
+	}
+
+	channels := service.GetChannels()
+	if len(channels) != 3 {
+		t.Fatalf("Expected 3 channels, got %d", len(channels))
+	}
+	if channels[0].Name != "Archived" || channels[1].Name != "Backend" || channels[2].Name != "Frontend" {
+		t.Errorf("Expected channels ordered by name, got %s, %s, %s", channels[0].Name, channels[1].Name, channels[2].Name)
+	}
+
+	results := service.SendNotificationToChannels(context.Background(), NotificationData{Type: NotificationTypeEndpointDown})
+	if len(results) != 2 {
+		t.Fatalf("Expected results for 2 enabled channels, got %d", len(results))
+	}
+	for _, url := range []string{"https://hooks.slack.com/backend", "https://hooks.slack.com/frontend"} {
+		if _, ok := sent.Load(url); !ok {
+			t.Errorf("Expected notification to be sent to %s", url)
+		}
+	}
+	if _, ok := sent.Load("https://hooks.slack.com/archived"); ok {
+		t.Error("Expected disabled channel not to be sent to")
+	}
+
+	// Reloading replaces the channel set
+	service.LoadChannels([]ChannelConfig{backend})
+	if _, exists := service.GetChannel(frontend.ID); exists {
+		t.Error("Expected removed channel to be unloaded")
+	}
+	if _, exists := service.GetChannel(backend.ID); !exists {
+		t.Error("Expected remaining channel to stay loaded")
+	}
+}
+
+func TestServiceSendNotificationToChannel(t *testing.T) {
+	sent := &sync.Map{}
+	service := newChannelTestService(sent)
+
+	enabled := slackChannel("Backend", "https://hooks.slack.com/backend", true)
+	disabled := slackChannel("Archived", "https://hooks.slack.com/archived", false)
+	service.LoadChannels([]ChannelConfig{enabled, disabled})
+
+	if result := service.SendNotificationToChannel(context.Background(), enabled.ID, NotificationData{}); !result.Success {
+		t.Errorf("Expected send to succeed, got %v", result.Error)
+	}
+	if result := service.SendNotificationToChannel(context.Background(), disabled.ID, NotificationData{}); result.Success {
+		t.Error("Expected send to a disabled channel to fail")
+	}
+	if result := service.SendNotificationToChannel(context.Background(), uuid.New(), NotificationData{}); result.Success {
+		t.Error("Expected send to an unknown channel to fail")
+	}
+}
+
+func TestNotificationTriggerSendsToChannels(t *testing.T) {
+	sent := &sync.Map{}
+	service := newChannelTestService(sent)
+	service.LoadChannels([]ChannelConfig{slackChannel("Backend", "https://hooks.slack.com/backend", true)})
+
+	trigger := NewNotificationTrigger(service, nil)
+	if err := trigger.TriggerEndpointDown(context.Background(), testEndpointEvent("timeout")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data, ok := sent.Load("https://hooks.slack.com/backend")
+	if !ok {
+		t.Fatal("Expected notification to be sent to the channel")
+	}
+	if data.(NotificationData).Type != NotificationTypeEndpointDown {
+		t.Errorf("Expected %s notification, got %s", NotificationTypeEndpointDown, data.(NotificationData).Type)
+	}
+
+	// The endpoint, its check and the link into Watchtower travel with the notification
+	received := data.(NotificationData)
+	if received.EndpointID == nil || *received.EndpointID != received.Endpoints[0].ID {
+		t.Errorf("Expected the endpoint's UUID, got %v", received.EndpointID)
+	}
+	if received.Check == nil || *received.Check.StatusCode != 503 || received.URL != "https://status.example.com/admin/endpoints/api" {
+		t.Errorf("Expected check context and deep link, got %+v", received)
+	}
+}
+
+func TestServiceNewChannelProvider(t *testing.T) {
+	service := newChannelTestService(&sync.Map{})
+
+	if !service.SupportsProviderType(ProviderTypeSlack) {
+		t.Error("Expected slack to be supported")
+	}
+	if service.SupportsProviderType(ProviderTypeEmail) {
+		t.Error("Expected email not to be supported without a factory")
+	}
+
+	if _, err := service.NewChannelProvider(ProviderConfig{Type: ProviderTypeSlack, Settings: map[string]interface{}{}}); err == nil {
+		t.Error("Expected invalid settings to be rejected")
+	}
+	if _, err := service.NewChannelProvider(ProviderConfig{Type: ProviderTypeEmail}); err == nil {
+		t.Error("Expected unsupported type to be rejected")
+	}
+	if err := service.RegisterProviderFactory(ProviderTypeEmail, nil); err == nil {
+		t.Error("Expected nil factory to be rejected")
+	}
+}
@@ -0,0 +1,186 @@
+package notification
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DispatcherConfig contains configuration for the asynchronous dispatcher
+type DispatcherConfig struct {
+	Workers     int
+	QueueSize   int
+	SendTimeout time.Duration // upper bound for sending one notification to every provider
+}
+
+// DefaultDispatcherConfig returns a sensible default dispatcher configuration
+func DefaultDispatcherConfig() DispatcherConfig {
+	return DispatcherConfig{
+		Workers:     2,
+		QueueSize:   100,
+		SendTimeout: 2 * time.Minute,
+	}
+}
+
+// dispatchJob is a queued notification trigger
+type dispatchJob struct {
+	triggerType string
+	send        func(ctx context.Context) error
+}
+
+// Dispatcher sends notifications in the background so callers such as the monitoring
+// result processor and HTTP handlers never wait on a slow provider
+type Dispatcher struct {
+	trigger *NotificationTrigger
+	config  DispatcherConfig
+	queue   chan dispatchJob
+	ctx     context.Context
+	cancel  context.CancelFunc
+	mu      sync.RWMutex // held for writing while stopping so no enqueue races Stop waiting
+	wg      sync.WaitGroup
+	logger  *slog.Logger
+}
+
+// NewDispatcher creates a new dispatcher that sends through the given trigger
+func NewDispatcher(trigger *NotificationTrigger, config DispatcherConfig, logger *slog.Logger) *Dispatcher {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.SendTimeout <= 0 {
+		config.SendTimeout = DefaultDispatcherConfig().SendTimeout
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Dispatcher{
+		trigger: trigger,
+		config:  config,
+		queue:   make(chan dispatchJob, config.QueueSize),
+		ctx:     ctx,
+		cancel:  cancel,
+		logger:  logger,
+	}
+}
+
+// Start starts the dispatcher workers
+func (d *Dispatcher) Start() {
+	for i := 0; i < d.config.Workers; i++ {
+		d.wg.Add(1)
+		go d.worker()
+	}
+}
+
+// Stop stops accepting notifications and waits for queued ones to be sent
+func (d *Dispatcher) Stop() {
+	d.mu.Lock()
+	d.cancel()
+	d.mu.Unlock()
+
+	d.wg.Wait()
+}
+
+// worker sends queued notifications until the dispatcher is stopped and the queue is drained
+func (d *Dispatcher) worker() {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case job := <-d.queue:
+			d.send(job)
+		case <-d.ctx.Done():
+			for {
+				select {
+				case job := <-d.queue:
+					d.send(job)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// send runs a single trigger with the configured timeout
+func (d *Dispatcher) send(job dispatchJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.config.SendTimeout)
+	defer cancel()
+
+	if err := job.send(ctx); err != nil {
+		d.logger.Error("Notification dispatch failed",
+			"trigger_type", job.triggerType,
+			"error", err)
+	}
+}
+
+// enqueue queues a trigger without blocking and reports whether it was accepted. When the
+// queue is full the trigger is sent on its own goroutine rather than dropped, notifications
+// only become durable once a trigger has written them to the outbox
+func (d *Dispatcher) enqueue(triggerType string, send func(ctx context.Context) error) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.ctx.Err() != nil {
+		d.logger.Warn("Dispatcher stopped, dropping notification", "trigger_type", triggerType)
+		return false
+	}
+
+	job := dispatchJob{triggerType: triggerType, send: send}
+	select {
+	case d.queue <- job:
+	default:
+		d.logger.Warn("Notification queue is full, sending notification outside the queue", "trigger_type", triggerType)
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			d.send(job)
+		}()
+	}
+	return true
+}
+
+// Run queues a notification built on a worker. Callers use it when building the notification
+// needs the database, which they must not wait on, and send it through the given trigger
+func (d *Dispatcher) Run(triggerType string, send func(ctx context.Context, trigger *NotificationTrigger) error) bool {
+	return d.enqueue(triggerType, func(ctx context.Context) error {
+		return send(ctx, d.trigger)
+	})
+}
+
+// EndpointDown queues an endpoint down notification
+func (d *Dispatcher) EndpointDown(event EndpointEvent) bool {
+	return d.enqueue("endpoint_down", func(ctx context.Context) error {
+		return d.trigger.TriggerEndpointDown(ctx, event)
+	})
+}
+
+// EndpointUp queues an endpoint recovery notification
+func (d *Dispatcher) EndpointUp(event EndpointEvent, downDuration time.Duration) bool {
+	return d.enqueue("endpoint_up", func(ctx context.Context) error {
+		return d.trigger.TriggerEndpointUp(ctx, event, downDuration)
+	})
+}
+
+// IncidentCreated queues an incident created notification
+func (d *Dispatcher) IncidentCreated(incident IncidentInfo, description string) bool {
+	return d.enqueue("incident_created", func(ctx context.Context) error {
+		return d.trigger.TriggerIncidentCreated(ctx, incident, description)
+	})
+}
+
+// IncidentUpdated queues an incident updated notification
+func (d *Dispatcher) IncidentUpdated(incident IncidentInfo, updateMessage string) bool {
+	return d.enqueue("incident_updated", func(ctx context.Context) error {
+		return d.trigger.TriggerIncidentUpdated(ctx, incident, updateMessage)
+	})
+}
+
+// IncidentResolved queues an incident resolved notification
+func (d *Dispatcher) IncidentResolved(incident IncidentInfo, resolutionMessage string, incidentDuration time.Duration) bool {
+	return d.enqueue("incident_resolved", func(ctx context.Context) error {
+		return d.trigger.TriggerIncidentResolved(ctx, incident, resolutionMessage, incidentDuration)
+	})
+}
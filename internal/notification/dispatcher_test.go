@@ -0,0 +1,145 @@
+package notification
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// BlockingProvider records notifications and holds each send until released
+type BlockingProvider struct {
+	*MockProvider
+	release chan struct{}
+	sent    chan NotificationData
+}
+
+func NewBlockingProvider(providerType ProviderType) *BlockingProvider {
+	return &BlockingProvider{
+		MockProvider: NewMockProvider(providerType, true),
+		release:      make(chan struct{}),
+		sent:         make(chan NotificationData, 10),
+	}
+}
+
+func (b *BlockingProvider) SendNotification(ctx context.Context, data NotificationData) DeliveryResult {
+	select {
+	case <-b.release:
+	case <-ctx.Done():
+		return DeliveryResult{Success: false, Error: ctx.Err(), Timestamp: time.Now()}
+	}
+
+	b.sent <- data
+	return DeliveryResult{Success: true, Timestamp: time.Now()}
+}
+
+func newTestDispatcher(provider NotificationProvider, config DispatcherConfig) *Dispatcher {
+	service := NewService(nil)
+	service.RegisterProvider(provider)
+	return NewDispatcher(NewNotificationTrigger(service, nil), config, nil)
+}
+
+func TestDispatcherDoesNotBlockOnSlowProvider(t *testing.T) {
+	provider := NewBlockingProvider(ProviderTypeEmail)
+	dispatcher := newTestDispatcher(provider, DispatcherConfig{Workers: 1, QueueSize: 10, SendTimeout: 5 * time.Second})
+	dispatcher.Start()
+
+	start := time.Now()
+	if !dispatcher.EndpointDown(testEndpointEvent("Connection timeout")) {
+		t.Fatal("Expected notification to be queued")
+	}
+	if !dispatcher.IncidentCreated(testIncident("API outage", "high"), "API is failing") {
+		t.Fatal("Expected notification to be queued")
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("Expected dispatch to return immediately, took %v", elapsed)
+	}
+
+	close(provider.release)
+
+	select {
+	case data := <-provider.sent:
+		if data.Type != NotificationTypeEndpointDown {
+			t.Fatalf("Expected %s notification first, got %s", NotificationTypeEndpointDown, data.Type)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected notification to be sent")
+	}
+
+	dispatcher.Stop()
+	if len(provider.sent) != 1 {
+		t.Fatalf("Expected queued notification to be sent before stopping, got %d", len(provider.sent))
+	}
+}
+
+func TestDispatcherSendsOutsideQueueWhenFull(t *testing.T) {
+	provider := NewBlockingProvider(ProviderTypeEmail)
+	dispatcher := newTestDispatcher(provider, DispatcherConfig{Workers: 1, QueueSize: 1, SendTimeout: 5 * time.Second})
+
+	// Workers are not started, so the queue fills up
+	if !dispatcher.IncidentUpdated(testIncident("API outage", "high"), "Investigating") {
+		t.Fatal("Expected first notification to be queued")
+	}
+	if !dispatcher.IncidentUpdated(testIncident("API outage", "high"), "Still investigating") {
+		t.Fatal("Expected notification to be accepted when the queue is full")
+	}
+	close(provider.release)
+
+	select {
+	case data := <-provider.sent:
+		if data.Message != "Incident update: Still investigating" {
+			t.Errorf("Expected the notification that overflowed the queue, got %q", data.Message)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected notification overflowing the queue to be sent")
+	}
+
+	dispatcher.Start()
+	dispatcher.Stop()
+	if len(provider.sent) != 1 {
+		t.Fatalf("Expected queued notification to be sent before stopping, got %d", len(provider.sent))
+	}
+}
+
+func TestDispatcherRejectsAfterStop(t *testing.T) {
+	dispatcher := newTestDispatcher(NewMockProvider(ProviderTypeSlack, true), DefaultDispatcherConfig())
+	dispatcher.Start()
+	dispatcher.Stop()
+
+	if dispatcher.IncidentResolved(testIncident("API outage", "high"), "Fixed", time.Minute) {
+		t.Fatal("Expected notification to be rejected after stop")
+	}
+}
+
+func TestDispatcherRunBuildsNotificationOnWorker(t *testing.T) {
+	provider := NewBlockingProvider(ProviderTypeEmail)
+	dispatcher := newTestDispatcher(provider, DispatcherConfig{Workers: 1, QueueSize: 10, SendTimeout: 5 * time.Second})
+	dispatcher.Start()
+	defer dispatcher.Stop()
+
+	built := make(chan struct{})
+	queued := dispatcher.Run("endpoint_down", func(ctx context.Context, trigger *NotificationTrigger) error {
+		close(built)
+		event := testEndpointEvent("Connection timeout")
+		event.Link = "https://status.example.com/admin/endpoints/1"
+		return trigger.TriggerEndpointDown(ctx, event)
+	})
+	if !queued {
+		t.Fatal("Expected notification to be queued")
+	}
+
+	select {
+	case <-built:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected notification to be built on a worker")
+	}
+	close(provider.release)
+
+	select {
+	case data := <-provider.sent:
+		if data.URL != "https://status.example.com/admin/endpoints/1" {
+			t.Errorf("Expected the link built on the worker, got %q", data.URL)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected notification to be sent")
+	}
+}
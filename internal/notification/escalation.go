@@ -0,0 +1,275 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/data"
+	"gorm.io/gorm"
+)
+
+// EscalationStore persists escalation policies and the escalation progress of incidents
+type EscalationStore interface {
+	GetEscalationPolicies() ([]data.EscalationPolicy, error)
+	GetEscalationPolicy(id uuid.UUID) (*data.EscalationPolicy, error)
+	GetIncidentsDueForEscalation(now time.Time) ([]data.Incident, error)
+	UpdateIncidentEscalation(incident *data.Incident) error
+	CreateIncidentTimeline(timeline *data.IncidentTimeline) error
+}
+
+// EscalatorConfig contains configuration for the escalator
+type EscalatorConfig struct {
+	PollInterval time.Duration // how often due escalations are looked for
+}
+
+// DefaultEscalatorConfig returns a sensible default escalator configuration
+func DefaultEscalatorConfig() EscalatorConfig {
+	return EscalatorConfig{
+		PollInterval: 30 * time.Second,
+	}
+}
+
+// Escalator walks unacknowledged incidents through the levels of their escalation policy,
+// queueing a notification to each level's channels in the outbox once its delay has passed.
+// Acknowledging or resolving an incident stops its escalation
+type Escalator struct {
+	store  EscalationStore
+	outbox *Outbox
+	config EscalatorConfig
+	ackURL func(incidentID uuid.UUID) string
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	now    func() time.Time
+	logger *slog.Logger
+}
+
+// NewEscalator creates a new escalator queueing its notifications in the outbox
+func NewEscalator(store EscalationStore, outbox *Outbox, config EscalatorConfig, logger *slog.Logger) *Escalator {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultEscalatorConfig().PollInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Escalator{
+		store:  store,
+		outbox: outbox,
+		config: config,
+		ctx:    ctx,
+		cancel: cancel,
+		now:    time.Now,
+		logger: logger,
+	}
+}
+
+// UseAckLinks sets how the acknowledgement link sent with escalation notifications is built
+func (e *Escalator) UseAckLinks(ackURL func(incidentID uuid.UUID) string) {
+	e.ackURL = ackURL
+}
+
+// Start starts escalating due incidents in the background
+func (e *Escalator) Start() {
+	e.wg.Add(1)
+	go e.run()
+}
+
+// Stop stops escalating after the escalations in progress have finished
+func (e *Escalator) Stop() {
+	e.cancel()
+	e.wg.Wait()
+}
+
+// Assign attaches the first enabled escalation policy matching the incident's severity and
+// schedules its first level. Incidents that already have a policy are left alone. It reports
+// whether a policy was assigned
+func (e *Escalator) Assign(incident *data.Incident) (bool, error) {
+	if incident.EscalationPolicyID != nil || incident.AcknowledgedAt != nil || incident.Status == "resolved" {
+		return false, nil
+	}
+
+	policies, err := e.store.GetEscalationPolicies()
+	if err != nil {
+		return false, fmt.Errorf("failed to load escalation policies: %w", err)
+	}
+
+	for _, policy := range policies {
+		if !policy.Enabled || len(policy.Levels) == 0 {
+			continue
+		}
+		if len(policy.Severities) > 0 && !slices.ContainsFunc(policy.Severities, func(severity string) bool {
+			return strings.EqualFold(severity, incident.Severity)
+		}) {
+			continue
+		}
+
+		next := e.now().Add(levelDelay(policy.Levels[0]))
+		incident.EscalationPolicyID = &policy.ID
+		incident.EscalationLevel = 0
+		incident.NextEscalationAt = &next
+
+		if err := e.store.UpdateIncidentEscalation(incident); err != nil {
+			return false, fmt.Errorf("failed to assign escalation policy: %w", err)
+		}
+
+		e.logger.Info("Escalation policy assigned",
+			"incident_id", incident.ID,
+			"policy", policy.Name,
+			"next_escalation_at", next)
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// run escalates due incidents until the escalator is stopped
+func (e *Escalator) run() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		e.processDue()
+
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processDue escalates every incident whose next level is due and returns how many were escalated
+func (e *Escalator) processDue() int {
+	incidents, err := e.store.GetIncidentsDueForEscalation(e.now())
+	if err != nil {
+		e.logger.Error("Failed to load incidents due for escalation", "error", err)
+		return 0
+	}
+
+	escalated := 0
+	for i := range incidents {
+		if e.ctx.Err() != nil {
+			break
+		}
+
+		ok, err := e.escalate(&incidents[i])
+		if err != nil {
+			e.logger.Error("Failed to escalate incident",
+				"incident_id", incidents[i].ID,
+				"error", err)
+			continue
+		}
+		if ok {
+			escalated++
+		}
+	}
+	return escalated
+}
+
+// escalate notifies the incident's next escalation level and schedules the one after it. An
+// incident whose policy is gone, disabled or exhausted stops escalating
+func (e *Escalator) escalate(incident *data.Incident) (bool, error) {
+	var policy *data.EscalationPolicy
+	if incident.EscalationPolicyID != nil {
+		var err error
+		policy, err = e.store.GetEscalationPolicy(*incident.EscalationPolicyID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, fmt.Errorf("failed to load escalation policy: %w", err)
+		}
+	}
+
+	if policy == nil || !policy.Enabled || incident.EscalationLevel >= len(policy.Levels) {
+		incident.NextEscalationAt = nil
+		return false, e.store.UpdateIncidentEscalation(incident)
+	}
+
+	now := e.now()
+	level := policy.Levels[incident.EscalationLevel]
+	number := incident.EscalationLevel + 1
+
+	notification := NotificationData{
+		Type:           NotificationTypeIncidentEscalated,
+		Title:          fmt.Sprintf("Incident escalated: %s", incident.Title),
+		Message:        fmt.Sprintf("Unacknowledged for %s, escalation level %d of %d", now.Sub(incident.StartTime).Round(time.Minute), number, len(policy.Levels)),
+		IncidentID:     &incident.ID,
+		IncidentStatus: incident.Status,
+		Severity:       incident.Severity,
+		Timestamp:      now,
+		Metadata: map[string]interface{}{
+			"escalation_policy": policy.Name,
+			"escalation_level":  number,
+		},
+		Endpoints: incidentEndpoints(incident),
+	}
+	if e.ackURL != nil {
+		notification.URL = e.ackURL(incident.ID)
+	}
+
+	deliveries, err := e.outbox.EnqueueToChannels(notification, level.ChannelIDs)
+	if err != nil {
+		return false, err
+	}
+
+	timeline := &data.IncidentTimeline{
+		IncidentID: incident.ID,
+		EventType:  "escalated",
+		Message:    fmt.Sprintf("Escalated to level %d of policy '%s', %d channel(s) notified", number, policy.Name, len(deliveries)),
+	}
+	if err := e.store.CreateIncidentTimeline(timeline); err != nil {
+		e.logger.Error("Failed to create escalation timeline entry",
+			"incident_id", incident.ID,
+			"error", err)
+		// Continue anyway, the notification was queued
+	}
+
+	incident.EscalationLevel = number
+	incident.NextEscalationAt = nil
+	if number < len(policy.Levels) {
+		next := now.Add(levelDelay(policy.Levels[number]))
+		incident.NextEscalationAt = &next
+	}
+	if err := e.store.UpdateIncidentEscalation(incident); err != nil {
+		return false, fmt.Errorf("failed to record escalation: %w", err)
+	}
+
+	e.logger.Info("Incident escalated",
+		"incident_id", incident.ID,
+		"policy", policy.Name,
+		"level", number,
+		"channels", len(deliveries))
+	return true, nil
+}
+
+// levelDelay returns how long a level waits after the previous step
+func levelDelay(level data.EscalationLevel) time.Duration {
+	return time.Duration(level.DelayMinutes) * time.Minute
+}
+
+// incidentEndpoints describes the endpoints loaded with an incident
+func incidentEndpoints(incident *data.Incident) []EndpointInfo {
+	var endpoints []EndpointInfo
+	for _, endpointIncident := range incident.EndpointIncidents {
+		if endpointIncident.Endpoint == nil {
+			continue
+		}
+		endpoints = append(endpoints, EndpointInfo{
+			ID:   endpointIncident.Endpoint.ID,
+			Name: endpointIncident.Endpoint.Name,
+			URL:  endpointIncident.Endpoint.URL,
+			Tags: endpointIncident.Endpoint.Tags,
+		})
+	}
+	return endpoints
+}
@@ -0,0 +1,226 @@
+package notification
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/data"
+	"gorm.io/gorm"
+)
+
+// MemoryEscalationStore keeps escalation policies and incidents in memory
+type MemoryEscalationStore struct {
+	mu        sync.Mutex
+	policies  []data.EscalationPolicy
+	incidents map[uuid.UUID]data.Incident
+	timeline  []data.IncidentTimeline
+}
+
+func NewMemoryEscalationStore(policies ...data.EscalationPolicy) *MemoryEscalationStore {
+	return &MemoryEscalationStore{policies: policies, incidents: make(map[uuid.UUID]data.Incident)}
+}
+
+func (m *MemoryEscalationStore) GetEscalationPolicies() ([]data.EscalationPolicy, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]data.EscalationPolicy(nil), m.policies...), nil
+}
+
+func (m *MemoryEscalationStore) GetEscalationPolicy(id uuid.UUID) (*data.EscalationPolicy, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, policy := range m.policies {
+		if policy.ID == id {
+			return &policy, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MemoryEscalationStore) GetIncidentsDueForEscalation(now time.Time) ([]data.Incident, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var due []data.Incident
+	for _, incident := range m.incidents {
+		if incident.Status != "resolved" && incident.AcknowledgedAt == nil && incident.NextEscalationAt != nil && !incident.NextEscalationAt.After(now) {
+			due = append(due, incident)
+		}
+	}
+	return due, nil
+}
+
+func (m *MemoryEscalationStore) UpdateIncidentEscalation(incident *data.Incident) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.incidents[incident.ID] = *incident
+	return nil
+}
+
+func (m *MemoryEscalationStore) CreateIncidentTimeline(timeline *data.IncidentTimeline) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.timeline = append(m.timeline, *timeline)
+	return nil
+}
+
+func (m *MemoryEscalationStore) acknowledge(id uuid.UUID, at time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	incident := m.incidents[id]
+	incident.AcknowledgedAt = &at
+	incident.NextEscalationAt = nil
+	m.incidents[id] = incident
+}
+
+func (m *MemoryEscalationStore) get(id uuid.UUID) data.Incident {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.incidents[id]
+}
+
+func newTestEscalator(t *testing.T) (*Escalator, *MemoryEscalationStore, *MemoryDeliveryStore, ChannelConfig, ChannelConfig, *time.Time) {
+	t.Helper()
+
+	deliveries := NewMemoryDeliveryStore()
+	outbox, primary := newTestOutbox(&FlakyProvider{}, deliveries, 3)
+	secondary := ChannelConfig{ID: uuid.New(), Name: "Managers", Config: ProviderConfig{Type: ProviderTypeWebhook, Enabled: true}}
+	outbox.service.LoadChannels([]ChannelConfig{primary, secondary})
+
+	policy := data.EscalationPolicy{
+		ID:         uuid.New(),
+		Name:       "Critical",
+		Enabled:    true,
+		Severities: data.StringList{"critical"},
+		Levels: data.EscalationLevels{
+			{DelayMinutes: 5, ChannelIDs: []uuid.UUID{primary.ID}},
+			{DelayMinutes: 15, ChannelIDs: []uuid.UUID{secondary.ID}},
+		},
+	}
+	store := NewMemoryEscalationStore(policy)
+
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	escalator := NewEscalator(store, outbox, EscalatorConfig{}, nil)
+	escalator.now = func() time.Time { return now }
+	outbox.now = escalator.now
+	escalator.UseAckLinks(func(incidentID uuid.UUID) string {
+		return "https://status.example.com/api/v1/incidents/" + incidentID.String() + "/ack"
+	})
+
+	return escalator, store, deliveries, primary, secondary, &now
+}
+
+func TestEscalatorEscalatesThroughLevels(t *testing.T) {
+	escalator, store, deliveries, primary, secondary, now := newTestEscalator(t)
+
+	incident := &data.Incident{ID: uuid.New(), Title: "API outage", Severity: "CRITICAL", Status: "open", StartTime: *now}
+	assigned, err := escalator.Assign(incident)
+	if err != nil || !assigned {
+		t.Fatalf("Expected policy to be assigned, got %v / %v", assigned, err)
+	}
+	if !incident.NextEscalationAt.Equal(now.Add(5 * time.Minute)) {
+		t.Fatalf("Expected first level after 5m, got %v", incident.NextEscalationAt)
+	}
+
+	// Nothing is due before the first delay
+	if escalated := escalator.processDue(); escalated != 0 {
+		t.Errorf("Expected no escalation before the delay, got %d", escalated)
+	}
+
+	// First level notifies the primary channel
+	*now = now.Add(5 * time.Minute)
+	if escalated := escalator.processDue(); escalated != 1 {
+		t.Fatalf("Expected one escalation, got %d", escalated)
+	}
+	stored := store.get(incident.ID)
+	if stored.EscalationLevel != 1 || !stored.NextEscalationAt.Equal(now.Add(15*time.Minute)) {
+		t.Errorf("Expected level 1 with the next level after 15m, got %d at %v", stored.EscalationLevel, stored.NextEscalationAt)
+	}
+	if len(deliveries.deliveries) != 1 {
+		t.Fatalf("Expected one queued delivery, got %d", len(deliveries.deliveries))
+	}
+	for _, delivery := range deliveries.deliveries {
+		if delivery.ChannelID != primary.ID || delivery.EventType != string(NotificationTypeIncidentEscalated) {
+			t.Errorf("Expected escalation delivery on the primary channel, got %+v", delivery)
+		}
+		if url, _ := delivery.Payload["url"].(string); !strings.Contains(url, incident.ID.String()+"/ack") {
+			t.Errorf("Expected acknowledgement link in the notification, got %q", url)
+		}
+	}
+
+	// Second level notifies the secondary channel and the policy is exhausted
+	*now = now.Add(15 * time.Minute)
+	escalator.processDue()
+	stored = store.get(incident.ID)
+	if stored.EscalationLevel != 2 || stored.NextEscalationAt != nil {
+		t.Errorf("Expected escalation to finish at level 2, got %d at %v", stored.EscalationLevel, stored.NextEscalationAt)
+	}
+	secondaryDeliveries := 0
+	for _, delivery := range deliveries.deliveries {
+		if delivery.ChannelID == secondary.ID {
+			secondaryDeliveries++
+		}
+	}
+	if secondaryDeliveries != 1 {
+		t.Errorf("Expected one delivery on the secondary channel, got %d", secondaryDeliveries)
+	}
+
+	if len(store.timeline) != 2 || store.timeline[0].EventType != "escalated" {
+		t.Errorf("Expected a timeline entry per escalation, got %+v", store.timeline)
+	}
+}
+
+func TestEscalatorStopsWhenAcknowledged(t *testing.T) {
+	escalator, store, deliveries, _, _, now := newTestEscalator(t)
+
+	incident := &data.Incident{ID: uuid.New(), Title: "API outage", Severity: "critical", Status: "open", StartTime: *now}
+	escalator.Assign(incident)
+	store.acknowledge(incident.ID, *now)
+
+	*now = now.Add(time.Hour)
+	if escalated := escalator.processDue(); escalated != 0 {
+		t.Errorf("Expected no escalation after acknowledgement, got %d", escalated)
+	}
+	if len(deliveries.deliveries) != 0 {
+		t.Errorf("Expected no deliveries, got %d", len(deliveries.deliveries))
+	}
+}
+
+func TestEscalatorAssignMatchesSeverity(t *testing.T) {
+	escalator, _, _, _, _, _ := newTestEscalator(t)
+
+	incident := &data.Incident{ID: uuid.New(), Severity: "low", Status: "open"}
+	assigned, err := escalator.Assign(incident)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if assigned || incident.EscalationPolicyID != nil {
+		t.Error("Expected no policy for a low severity incident")
+	}
+}
+
+func TestEscalatorStopsForDisabledPolicy(t *testing.T) {
+	escalator, store, deliveries, _, _, now := newTestEscalator(t)
+
+	incident := &data.Incident{ID: uuid.New(), Severity: "critical", Status: "open"}
+	escalator.Assign(incident)
+	store.policies[0].Enabled = false
+
+	*now = now.Add(time.Hour)
+	escalator.processDue()
+	if stored := store.get(incident.ID); stored.NextEscalationAt != nil || stored.EscalationLevel != 0 {
+		t.Errorf("Expected escalation to stop, got level %d at %v", stored.EscalationLevel, stored.NextEscalationAt)
+	}
+	if len(deliveries.deliveries) != 0 {
+		t.Errorf("Expected no deliveries, got %d", len(deliveries.deliveries))
+	}
+}
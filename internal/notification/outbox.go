@@ -0,0 +1,563 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+// DeliveryStore persists the notification outbox
+type DeliveryStore interface {
+	CreateNotificationDeliveries(deliveries []data.NotificationDelivery) error
+	GetDueNotificationDeliveries(now time.Time, limit int) ([]data.NotificationDelivery, error)
+	UpdateNotificationDelivery(delivery *data.NotificationDelivery) error
+	GetHeldNotificationDeliveries() ([]data.NotificationDelivery, error)
+}
+
+// OutboxConfig contains configuration for the notification outbox
+type OutboxConfig struct {
+	Workers      int           // deliveries sent concurrently
+	BatchSize    int           // due deliveries loaded per poll
+	PollInterval time.Duration // how often due deliveries are looked for when idle
+	SendTimeout  time.Duration // upper bound for a single delivery attempt
+	Retry        RetryConfig   // attempts and backoff between them
+}
+
+// DefaultOutboxConfig returns a sensible default outbox configuration
+func DefaultOutboxConfig() OutboxConfig {
+	return OutboxConfig{
+		Workers:      4,
+		BatchSize:    50,
+		PollInterval: 5 * time.Second,
+		SendTimeout:  30 * time.Second,
+		Retry: RetryConfig{
+			MaxAttempts:   5,
+			InitialDelay:  10 * time.Second,
+			MaxDelay:      10 * time.Minute,
+			BackoffFactor: 3.0,
+			EnableJitter:  true,
+		},
+	}
+}
+
+// Outbox persists a delivery for every channel a notification is routed to and works them in
+// the background, retrying failures with backoff. Deliveries survive restarts, one interrupted
+// mid-send is sent again
+type Outbox struct {
+	service  *Service
+	store    DeliveryStore
+	config   OutboxConfig
+	throttle *Throttle
+	wake     chan struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	now      func() time.Time
+	logger   *slog.Logger
+}
+
+// NewOutbox creates a new outbox delivering through the service's channels
+func NewOutbox(service *Service, store DeliveryStore, config OutboxConfig, logger *slog.Logger) *Outbox {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	defaults := DefaultOutboxConfig()
+	if config.Workers <= 0 {
+		config.Workers = defaults.Workers
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaults.PollInterval
+	}
+	if config.SendTimeout <= 0 {
+		config.SendTimeout = defaults.SendTimeout
+	}
+	if config.Retry.MaxAttempts <= 0 {
+		config.Retry.MaxAttempts = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Outbox{
+		service: service,
+		store:   store,
+		config:  config,
+		wake:    make(chan struct{}, 1),
+		ctx:     ctx,
+		cancel:  cancel,
+		now:     time.Now,
+		logger:  logger,
+	}
+}
+
+// Start starts working the outbox, including deliveries left over from a previous run
+func (o *Outbox) Start() {
+	o.wg.Add(1)
+	go o.run()
+}
+
+// Stop stops working the outbox after the deliveries in flight have been attempted
+func (o *Outbox) Stop() {
+	o.cancel()
+	o.wg.Wait()
+}
+
+// UseThrottle makes the outbox suppress duplicate, rate limited and flapping notifications it
+// routes. Call before enqueueing notifications
+func (o *Outbox) UseThrottle(throttle *Throttle) {
+	o.throttle = throttle
+}
+
+// Enqueue persists a delivery for every channel the notification is routed to and returns
+// them. Deliveries held back by a channel's quiet hours are stored as held for its next
+// digest, or as suppressed without one. With a throttle, deliveries it suppresses are stored
+// as suppressed instead of pending
+func (o *Outbox) Enqueue(notification NotificationData) ([]data.NotificationDelivery, error) {
+	routes := o.service.Route(notification)
+	now := o.now()
+	if o.throttle == nil {
+		return o.enqueue(notification, routes, o.filter(now))
+	}
+
+	decision, flapping := o.throttle.observeChange(notification, routes, now)
+	if decision == flapNone {
+		return o.enqueue(notification, routes, o.filter(now))
+	}
+
+	suppressed, err := o.enqueue(notification, routes, func(*Channel, NotificationData) (string, string) {
+		return data.NotificationDeliverySuppressed, SuppressedFlapping
+	})
+	if err != nil || decision == flapOngoing {
+		return suppressed, err
+	}
+
+	o.logger.Warn("Endpoint is flapping, pausing its up, down and incident notifications",
+		"endpoint_id", notification.EndpointID,
+		"state_changes", flapping.Metadata["state_changes"])
+
+	deliveries, err := o.enqueue(*flapping, routes, o.filter(now))
+	return append(suppressed, deliveries...), err
+}
+
+// EnqueueToChannels persists a pending delivery for each of the given enabled channels,
+// bypassing routing rules, quiet hours and the throttle, and returns them. Unknown or disabled channels
+// are skipped
+func (o *Outbox) EnqueueToChannels(notification NotificationData, channelIDs []uuid.UUID) ([]data.NotificationDelivery, error) {
+	routes := make([]ChannelRoute, 0, len(channelIDs))
+	for _, id := range channelIDs {
+		channel, exists := o.service.GetChannel(id)
+		if !exists || !channel.Provider.IsEnabled() {
+			continue
+		}
+		routes = append(routes, ChannelRoute{Channel: channel})
+	}
+	return o.enqueue(notification, routes, nil)
+}
+
+// deliveryFilter returns the status and reason a delivery of the notification to the channel
+// is stored with instead of pending, or empty strings when it is sent
+type deliveryFilter func(channel *Channel, notification NotificationData) (status string, reason string)
+
+// filter returns the checks for notifications queued at the given time. Quiet hours come first
+// so held notifications do not count towards the channel's rate limit
+func (o *Outbox) filter(now time.Time) deliveryFilter {
+	return func(channel *Channel, notification NotificationData) (string, string) {
+		if quiet := channel.QuietHours; quiet != nil && quiet.Holds(notification, now) {
+			if quiet.Digest() == DigestNone {
+				return data.NotificationDeliverySuppressed, SuppressedQuietHours
+			}
+			return data.NotificationDeliveryHeld, SuppressedQuietHours
+		}
+		if o.throttle != nil {
+			if reason := o.throttle.Check(channel, notification, now); reason != "" {
+				return data.NotificationDeliverySuppressed, reason
+			}
+		}
+		return "", ""
+	}
+}
+
+// enqueueStabilized queues the summaries of endpoints that stopped flapping
+func (o *Outbox) enqueueStabilized() {
+	if o.throttle == nil {
+		return
+	}
+
+	now := o.now()
+	for _, stabilized := range o.throttle.stabilized(now) {
+		o.logger.Info("Endpoint stopped flapping",
+			"endpoint_id", stabilized.notification.EndpointID,
+			"state_changes", stabilized.notification.Metadata["state_changes"])
+
+		if _, err := o.enqueue(stabilized.notification, stabilized.routes, o.filter(now)); err != nil {
+			o.logger.Error("Failed to queue endpoint stabilized notification",
+				"endpoint_id", stabilized.notification.EndpointID,
+				"error", err)
+		}
+	}
+}
+
+// enqueueDigests queues a digest for every channel whose held notifications are due and marks
+// the held deliveries as included in it. Deliveries held for a channel that is not loaded wait
+// until it is again
+func (o *Outbox) enqueueDigests() {
+	held, err := o.store.GetHeldNotificationDeliveries()
+	if err != nil {
+		o.logger.Error("Failed to load held notification deliveries", "error", err)
+		return
+	}
+
+	byChannel := make(map[uuid.UUID][]data.NotificationDelivery)
+	for _, delivery := range held {
+		byChannel[delivery.ChannelID] = append(byChannel[delivery.ChannelID], delivery)
+	}
+
+	now := o.now()
+	for channelID, deliveries := range byChannel {
+		channel, exists := o.service.GetChannel(channelID)
+		if !exists {
+			continue
+		}
+		// Without quiet hours or a digest any leftovers go out right away
+		if channel.QuietHours != nil && !channel.QuietHours.DigestDue(deliveries[0].CreatedAt, now) {
+			continue
+		}
+
+		queued, err := o.enqueue(digestNotification(channel, deliveries, now), []ChannelRoute{{Channel: channel}}, nil)
+		if err != nil {
+			o.logger.Error("Failed to queue notification digest",
+				"channel", channel,
+				"error", err)
+			continue
+		}
+
+		for i := range deliveries {
+			deliveries[i].Status = data.NotificationDeliverySuppressed
+			deliveries[i].SuppressedReason = SuppressedDigested
+			deliveries[i].ProviderResponse = fmt.Sprintf("Included in digest %s", queued[0].EventID)
+			if err := o.store.UpdateNotificationDelivery(&deliveries[i]); err != nil {
+				o.logger.Error("Failed to mark notification delivery as digested",
+					"delivery_id", deliveries[i].ID,
+					"error", err)
+			}
+		}
+
+		o.logger.Info("Queued notification digest",
+			"channel", channel,
+			"event_id", queued[0].EventID,
+			"notifications", len(deliveries))
+	}
+}
+
+// enqueue persists a delivery per route, sharing one event ID. Routes the filter gives a status
+// for are stored with it, the others as pending. Recipients are resolved now so a delivery
+// retried after a handoff still reaches whoever was on call for the event
+func (o *Outbox) enqueue(notification NotificationData, routes []ChannelRoute, filter deliveryFilter) ([]data.NotificationDelivery, error) {
+	if len(routes) == 0 {
+		return nil, nil
+	}
+
+	eventID := uuid.New()
+	notification.EventID = &eventID
+	now := o.now()
+	deliveries := make([]data.NotificationDelivery, 0, len(routes))
+	pending, held := 0, 0
+	for _, route := range routes {
+		channel := route.Channel
+		payload, err := encodePayload(o.service.forRoute(notification, route))
+		if err != nil {
+			return nil, err
+		}
+
+		status, reason := data.NotificationDeliveryPending, ""
+		if filter != nil {
+			if filtered, why := filter(channel, notification); filtered != "" {
+				status, reason = filtered, why
+			}
+		}
+		switch status {
+		case data.NotificationDeliveryPending:
+			pending++
+		case data.NotificationDeliveryHeld:
+			held++
+		}
+
+		deliveries = append(deliveries, data.NotificationDelivery{
+			EventID:          eventID,
+			EventType:        string(notification.Type),
+			Title:            notification.Title,
+			Severity:         notification.Severity,
+			ChannelID:        channel.ID,
+			ChannelName:      channel.Name,
+			ChannelType:      string(channel.Type()),
+			Payload:          payload,
+			Status:           status,
+			SuppressedReason: reason,
+			MaxAttempts:      o.config.Retry.MaxAttempts,
+			NextAttemptAt:    now,
+			CreatedAt:        now,
+		})
+	}
+
+	if err := o.store.CreateNotificationDeliveries(deliveries); err != nil {
+		return nil, fmt.Errorf("failed to store notification deliveries: %w", err)
+	}
+
+	if pending+held < len(deliveries) {
+		o.logger.Info("Notification suppressed",
+			"event_id", eventID,
+			"event_type", notification.Type,
+			"suppressed", len(deliveries)-pending-held,
+			"channels", len(deliveries))
+	}
+	if held > 0 {
+		o.logger.Info("Notification held for digest",
+			"event_id", eventID,
+			"event_type", notification.Type,
+			"held", held,
+			"channels", len(deliveries))
+	}
+	if pending == 0 {
+		return deliveries, nil
+	}
+
+	// Wake the worker so new deliveries do not wait for the next poll
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+
+	return deliveries, nil
+}
+
+// run polls for due deliveries until the outbox is stopped
+func (o *Outbox) run() {
+	defer o.wg.Done()
+
+	ticker := time.NewTicker(o.config.PollInterval)
+	defer ticker.Stop()
+
+	var digestsCheckedAt time.Time
+	for {
+		o.enqueueStabilized()
+
+		// Digests are due on the minute at the earliest, no need to look more often
+		if now := o.now(); now.Sub(digestsCheckedAt) >= time.Minute {
+			o.enqueueDigests()
+			digestsCheckedAt = now
+		}
+
+		// Keep going while full batches come back so a backlog drains without waiting
+		for o.ctx.Err() == nil {
+			if o.processDue() < o.config.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-o.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-o.wake:
+		}
+	}
+}
+
+// processDue attempts a batch of due deliveries and returns how many were attempted
+func (o *Outbox) processDue() int {
+	deliveries, err := o.store.GetDueNotificationDeliveries(o.now(), o.config.BatchSize)
+	if err != nil {
+		o.logger.Error("Failed to load due notification deliveries", "error", err)
+		return 0
+	}
+
+	sem := make(chan struct{}, o.config.Workers)
+	var wg sync.WaitGroup
+
+	for i := range deliveries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(delivery *data.NotificationDelivery) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			o.attempt(delivery)
+		}(&deliveries[i])
+	}
+
+	wg.Wait()
+	return len(deliveries)
+}
+
+// attempt sends a delivery once and records the outcome, scheduling a retry on failure
+func (o *Outbox) attempt(delivery *data.NotificationDelivery) {
+	started := o.now()
+	result, retryable := o.send(delivery)
+	finished := o.now()
+
+	delivery.Attempts++
+	delivery.ProviderResponse = result.Details
+
+	entry := data.DeliveryAttempt{
+		Attempt:     delivery.Attempts,
+		AttemptedAt: started,
+		Success:     result.Success,
+		Response:    result.Details,
+		DurationMs:  finished.Sub(started).Milliseconds(),
+	}
+
+	switch {
+	case result.Success:
+		delivery.Status = data.NotificationDeliveryDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &finished
+
+	case !retryable || delivery.Attempts >= delivery.MaxAttempts:
+		delivery.Status = data.NotificationDeliveryFailed
+		delivery.LastError = errorString(result.Error)
+		entry.Error = delivery.LastError
+
+	default:
+		delivery.LastError = errorString(result.Error)
+		delivery.NextAttemptAt = finished.Add(o.config.Retry.Delay(delivery.Attempts))
+		entry.Error = delivery.LastError
+		if len(result.FailedRecipients) > 0 {
+			o.retryRecipients(delivery, result.FailedRecipients)
+		}
+	}
+
+	delivery.AttemptLog = append(delivery.AttemptLog, entry)
+
+	if err := o.store.UpdateNotificationDelivery(delivery); err != nil {
+		o.logger.Error("Failed to record notification delivery attempt",
+			"delivery_id", delivery.ID,
+			"error", err)
+		return
+	}
+
+	switch delivery.Status {
+	case data.NotificationDeliveryDelivered:
+		o.logger.Info("Notification delivered",
+			"delivery_id", delivery.ID,
+			"channel", delivery.ChannelName,
+			"event_type", delivery.EventType,
+			"attempt", delivery.Attempts)
+	case data.NotificationDeliveryFailed:
+		o.logger.Error("Notification delivery failed",
+			"delivery_id", delivery.ID,
+			"channel", delivery.ChannelName,
+			"event_type", delivery.EventType,
+			"attempts", delivery.Attempts,
+			"error", delivery.LastError)
+	default:
+		o.logger.Warn("Notification delivery failed, retrying",
+			"delivery_id", delivery.ID,
+			"channel", delivery.ChannelName,
+			"event_type", delivery.EventType,
+			"attempt", delivery.Attempts,
+			"next_attempt_at", delivery.NextAttemptAt,
+			"error", delivery.LastError)
+	}
+}
+
+// retryRecipients addresses the stored notification to the recipients a partially failed
+// attempt did not reach, so recipients that got it are not sent it again
+func (o *Outbox) retryRecipients(delivery *data.NotificationDelivery, recipients []string) {
+	notification, err := decodePayload(delivery.Payload)
+	if err != nil {
+		o.logger.Error("Failed to narrow notification delivery to failed recipients",
+			"delivery_id", delivery.ID,
+			"error", err)
+		return
+	}
+
+	notification.Recipients = recipients
+	payload, err := encodePayload(notification)
+	if err != nil {
+		o.logger.Error("Failed to narrow notification delivery to failed recipients",
+			"delivery_id", delivery.ID,
+			"error", err)
+		return
+	}
+	delivery.Payload = payload
+}
+
+// send delivers the stored notification through its channel. The returned flag is false when
+// retrying cannot help, such as when the channel no longer exists
+func (o *Outbox) send(delivery *data.NotificationDelivery) (DeliveryResult, bool) {
+	channel, exists := o.service.GetChannel(delivery.ChannelID)
+	if !exists {
+		return DeliveryResult{
+			Success:   false,
+			Error:     fmt.Errorf("channel %s no longer exists", delivery.ChannelID),
+			Timestamp: o.now(),
+			Details:   "Channel not loaded",
+		}, false
+	}
+	if !channel.Provider.IsEnabled() {
+		return DeliveryResult{
+			Success:   false,
+			Error:     fmt.Errorf("channel %s is disabled", channel),
+			Timestamp: o.now(),
+			Details:   "Channel disabled",
+		}, false
+	}
+
+	notification, err := decodePayload(delivery.Payload)
+	if err != nil {
+		return DeliveryResult{
+			Success:   false,
+			Error:     fmt.Errorf("invalid notification payload: %w", err),
+			Timestamp: o.now(),
+		}, false
+	}
+
+	// Sends in flight are not cut short by Stop
+	ctx, cancel := context.WithTimeout(context.Background(), o.config.SendTimeout)
+	defer cancel()
+
+	return o.service.sendThroughChannel(ctx, channel, notification), true
+}
+
+// encodePayload converts a notification to its stored form
+func encodePayload(notification NotificationData) (data.JSONMap, error) {
+	encoded, err := json.Marshal(notification)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	var payload data.JSONMap
+	if err := json.Unmarshal(encoded, &payload); err != nil {
+		return nil, fmt.Errorf("failed to encode notification: %w", err)
+	}
+	return payload, nil
+}
+
+// decodePayload converts a stored notification back
+func decodePayload(payload data.JSONMap) (NotificationData, error) {
+	var notification NotificationData
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return notification, err
+	}
+	err = json.Unmarshal(encoded, &notification)
+	return notification, err
+}
+
+// errorString returns the error's message, or a placeholder for failures without an error
+func errorString(err error) string {
+	if err == nil {
+		return "unknown error"
+	}
+	return err.Error()
+}
@@ -0,0 +1,303 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+// MemoryDeliveryStore keeps outbox deliveries in memory
+type MemoryDeliveryStore struct {
+	mu         sync.Mutex
+	deliveries map[uuid.UUID]data.NotificationDelivery
+}
+
+func NewMemoryDeliveryStore() *MemoryDeliveryStore {
+	return &MemoryDeliveryStore{deliveries: make(map[uuid.UUID]data.NotificationDelivery)}
+}
+
+func (m *MemoryDeliveryStore) CreateNotificationDeliveries(deliveries []data.NotificationDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range deliveries {
+		deliveries[i].ID = uuid.New()
+		m.deliveries[deliveries[i].ID] = deliveries[i]
+	}
+	return nil
+}
+
+func (m *MemoryDeliveryStore) GetDueNotificationDeliveries(now time.Time, limit int) ([]data.NotificationDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var due []data.NotificationDelivery
+	for _, delivery := range m.deliveries {
+		if delivery.Status == data.NotificationDeliveryPending && !delivery.NextAttemptAt.After(now) && len(due) < limit {
+			due = append(due, delivery)
+		}
+	}
+	return due, nil
+}
+
+func (m *MemoryDeliveryStore) GetHeldNotificationDeliveries() ([]data.NotificationDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var held []data.NotificationDelivery
+	for _, delivery := range m.deliveries {
+		if delivery.Status == data.NotificationDeliveryHeld {
+			held = append(held, delivery)
+		}
+	}
+	sort.Slice(held, func(i, j int) bool { return held[i].CreatedAt.Before(held[j].CreatedAt) })
+	return held, nil
+}
+
+func (m *MemoryDeliveryStore) UpdateNotificationDelivery(delivery *data.NotificationDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (m *MemoryDeliveryStore) get(id uuid.UUID) data.NotificationDelivery {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.deliveries[id]
+}
+
+// FlakyProvider fails a set number of sends before succeeding
+type FlakyProvider struct {
+	mu        sync.Mutex
+	failures  int
+	unreached []string // recipients reported as failed along with the failures
+	received  []NotificationData
+}
+
+func (p *FlakyProvider) GetType() ProviderType { return ProviderTypeWebhook }
+
+func (p *FlakyProvider) IsEnabled() bool { return true }
+
+func (p *FlakyProvider) Configure(config ProviderConfig) error { return nil }
+
+func (p *FlakyProvider) SendNotification(ctx context.Context, data NotificationData) DeliveryResult {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failures > 0 {
+		p.failures--
+		return DeliveryResult{Success: false, Error: errors.New("503 Service Unavailable"), Timestamp: time.Now(), Details: "HTTP 503", FailedRecipients: p.unreached}
+	}
+	p.received = append(p.received, data)
+	return DeliveryResult{Success: true, Timestamp: time.Now(), Details: "HTTP 200"}
+}
+
+func (p *FlakyProvider) TestConnection(ctx context.Context) error { return nil }
+
+func newTestOutbox(provider *FlakyProvider, store DeliveryStore, maxAttempts int) (*Outbox, ChannelConfig) {
+	service := NewService(nil)
+	service.RegisterProviderFactory(ProviderTypeWebhook, func() NotificationProvider { return provider })
+
+	channel := ChannelConfig{ID: uuid.New(), Name: "Ops", Config: ProviderConfig{Type: ProviderTypeWebhook, Enabled: true}}
+	service.LoadChannels([]ChannelConfig{channel})
+
+	outbox := NewOutbox(service, store, OutboxConfig{
+		Retry: RetryConfig{MaxAttempts: maxAttempts, InitialDelay: time.Minute, MaxDelay: time.Hour, BackoffFactor: 2},
+	}, nil)
+	return outbox, channel
+}
+
+func TestOutboxRetriesWithBackoff(t *testing.T) {
+	store := NewMemoryDeliveryStore()
+	provider := &FlakyProvider{failures: 2}
+	outbox, channel := newTestOutbox(provider, store, 5)
+
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	outbox.now = func() time.Time { return now }
+
+	deliveries, err := outbox.Enqueue(NotificationData{
+		Type:      NotificationTypeEndpointDown,
+		Title:     "API is DOWN",
+		Severity:  "critical",
+		Endpoints: []EndpointInfo{{ID: uuid.New(), Name: "API", Tags: []string{"backend"}}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].ChannelID != channel.ID || deliveries[0].ChannelName != "Ops" {
+		t.Fatalf("Expected one delivery for the channel, got %+v", deliveries)
+	}
+	id := deliveries[0].ID
+
+	// First attempt fails and is retried after the initial delay
+	outbox.processDue()
+	delivery := store.get(id)
+	if delivery.Status != data.NotificationDeliveryPending || delivery.Attempts != 1 {
+		t.Fatalf("Expected pending delivery after one attempt, got %s after %d", delivery.Status, delivery.Attempts)
+	}
+	if !delivery.NextAttemptAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("Expected next attempt after 1m, got %v", delivery.NextAttemptAt.Sub(now))
+	}
+	if delivery.LastError != "503 Service Unavailable" || delivery.ProviderResponse != "HTTP 503" {
+		t.Errorf("Expected provider error to be recorded, got %q / %q", delivery.LastError, delivery.ProviderResponse)
+	}
+
+	// Nothing is due before the backoff expires
+	if attempted := outbox.processDue(); attempted != 0 {
+		t.Errorf("Expected no due deliveries, got %d", attempted)
+	}
+
+	// Second attempt fails and backs off further
+	now = now.Add(time.Minute)
+	outbox.processDue()
+	delivery = store.get(id)
+	if !delivery.NextAttemptAt.Equal(now.Add(2 * time.Minute)) {
+		t.Errorf("Expected next attempt after 2m, got %v", delivery.NextAttemptAt.Sub(now))
+	}
+
+	// Third attempt succeeds
+	now = now.Add(2 * time.Minute)
+	outbox.processDue()
+	delivery = store.get(id)
+	if delivery.Status != data.NotificationDeliveryDelivered || delivery.DeliveredAt == nil {
+		t.Fatalf("Expected delivered status, got %s", delivery.Status)
+	}
+	if len(delivery.AttemptLog) != 3 || delivery.AttemptLog[2].Response != "HTTP 200" || !delivery.AttemptLog[2].Success {
+		t.Errorf("Expected three recorded attempts ending in success, got %+v", delivery.AttemptLog)
+	}
+
+	// The provider receives the notification as it was queued
+	if len(provider.received) != 1 {
+		t.Fatalf("Expected one notification to be received, got %d", len(provider.received))
+	}
+	received := provider.received[0]
+	if received.Title != "API is DOWN" || received.Severity != "critical" || len(received.Endpoints) != 1 || received.Endpoints[0].Tags[0] != "backend" {
+		t.Errorf("Expected the queued notification to be sent, got %+v", received)
+	}
+	if received.EventID == nil || *received.EventID != deliveries[0].EventID {
+		t.Errorf("Expected the notification to carry the delivery's event ID, got %v", received.EventID)
+	}
+}
+
+func TestOutboxRetriesOnlyFailedRecipients(t *testing.T) {
+	store := NewMemoryDeliveryStore()
+	provider := &FlakyProvider{failures: 1, unreached: []string{"dev@example.com"}}
+	outbox, _ := newTestOutbox(provider, store, 3)
+
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	outbox.now = func() time.Time { return now }
+
+	deliveries, err := outbox.Enqueue(NotificationData{
+		Type:       NotificationTypeIncidentCreated,
+		Title:      "API outage",
+		Recipients: []string{"ops@example.com", "dev@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	outbox.processDue()
+	now = now.Add(time.Minute)
+	outbox.processDue()
+
+	delivery := store.get(deliveries[0].ID)
+	if delivery.Status != data.NotificationDeliveryDelivered {
+		t.Fatalf("Expected the retry to be delivered, got %s", delivery.Status)
+	}
+	if len(provider.received) != 1 || len(provider.received[0].Recipients) != 1 || provider.received[0].Recipients[0] != "dev@example.com" {
+		t.Errorf("Expected the retry to go to the failed recipient only, got %+v", provider.received)
+	}
+}
+
+func TestOutboxGivesUpAfterMaxAttempts(t *testing.T) {
+	store := NewMemoryDeliveryStore()
+	outbox, _ := newTestOutbox(&FlakyProvider{failures: 5}, store, 2)
+
+	now := time.Now()
+	outbox.now = func() time.Time { return now }
+
+	deliveries, _ := outbox.Enqueue(NotificationData{Type: NotificationTypeIncidentCreated})
+	outbox.processDue()
+	now = now.Add(time.Hour)
+	outbox.processDue()
+
+	delivery := store.get(deliveries[0].ID)
+	if delivery.Status != data.NotificationDeliveryFailed || delivery.Attempts != 2 {
+		t.Errorf("Expected failed delivery after 2 attempts, got %s after %d", delivery.Status, delivery.Attempts)
+	}
+}
+
+func TestOutboxFailsDeliveryForRemovedChannel(t *testing.T) {
+	store := NewMemoryDeliveryStore()
+	outbox, _ := newTestOutbox(&FlakyProvider{}, store, 5)
+
+	deliveries, _ := outbox.Enqueue(NotificationData{Type: NotificationTypeIncidentCreated})
+	outbox.service.LoadChannels(nil)
+	outbox.processDue()
+
+	delivery := store.get(deliveries[0].ID)
+	if delivery.Status != data.NotificationDeliveryFailed || delivery.Attempts != 1 {
+		t.Errorf("Expected delivery to fail without retry, got %s after %d attempts", delivery.Status, delivery.Attempts)
+	}
+}
+
+func TestOutboxDeliversPendingOnStart(t *testing.T) {
+	store := NewMemoryDeliveryStore()
+	provider := &FlakyProvider{}
+
+	// A delivery queued before a restart
+	outbox, channel := newTestOutbox(provider, store, 3)
+	payload, err := encodePayload(NotificationData{Type: NotificationTypeEndpointUp, Title: "API is UP"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	pending := []data.NotificationDelivery{{
+		ChannelID:     channel.ID,
+		Payload:       payload,
+		Status:        data.NotificationDeliveryPending,
+		MaxAttempts:   3,
+		NextAttemptAt: time.Now().Add(-time.Minute),
+	}}
+	store.CreateNotificationDeliveries(pending)
+
+	outbox.Start()
+	defer outbox.Stop()
+
+	deadline := time.After(2 * time.Second)
+	for store.get(pending[0].ID).Status != data.NotificationDeliveryDelivered {
+		select {
+		case <-deadline:
+			t.Fatal("Expected pending delivery to be delivered after start")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestTriggerQueuesInOutbox(t *testing.T) {
+	store := NewMemoryDeliveryStore()
+	provider := &FlakyProvider{}
+	outbox, _ := newTestOutbox(provider, store, 3)
+
+	trigger := NewNotificationTrigger(outbox.service, nil)
+	trigger.UseOutbox(outbox)
+
+	if err := trigger.TriggerIncidentCreated(context.Background(), testIncident("API outage", "high"), "API is failing"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(provider.received) != 0 {
+		t.Error("Expected notification to be queued rather than sent")
+	}
+	if len(store.deliveries) != 1 {
+		t.Errorf("Expected one queued delivery, got %d", len(store.deliveries))
+	}
+}
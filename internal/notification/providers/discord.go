@@ -196,12 +196,20 @@ func (d *DiscordProvider) buildDiscordMessage(data notification.NotificationData
 
 	if data.URL != "" {
 		fields = append(fields, DiscordEmbedField{
-			Name:   "URL",
+			Name:   "Link",
 			Value:  data.URL,
 			Inline: false,
 		})
 	}
 
+	for _, detail := range data.Details() {
+		fields = append(fields, DiscordEmbedField{
+			Name:   detail.Label,
+			Value:  detail.Value,
+			Inline: len(detail.Value) <= 40,
+		})
+	}
+
 	if data.Severity != "" {
 		fields = append(fields, DiscordEmbedField{
 			Name:   "Severity",
@@ -213,7 +221,7 @@ func (d *DiscordProvider) buildDiscordMessage(data notification.NotificationData
 	if data.EndpointID != nil {
 		fields = append(fields, DiscordEmbedField{
 			Name:   "Endpoint ID",
-			Value:  fmt.Sprintf("%d", *data.EndpointID),
+			Value:  data.EndpointID.String(),
 			Inline: true,
 		})
 	}
@@ -221,7 +229,7 @@ func (d *DiscordProvider) buildDiscordMessage(data notification.NotificationData
 	if data.IncidentID != nil {
 		fields = append(fields, DiscordEmbedField{
 			Name:   "Incident ID",
-			Value:  fmt.Sprintf("%d", *data.IncidentID),
+			Value:  data.IncidentID.String(),
 			Inline: true,
 		})
 	}
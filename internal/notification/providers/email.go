@@ -3,38 +3,53 @@ package providers
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"html/template"
 	"log/slog"
-	"net/smtp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/i4o-oss/watchtower/internal/notification"
 )
 
-// EmailProvider implements notification.NotificationProvider for email notifications
+// EmailProvider implements notification.NotificationProvider for email notifications. One
+// connection to the SMTP server is kept open between sends and shared by the recipients of a
+// notification, it is closed after smtpIdleTimeout without use
 type EmailProvider struct {
-	enabled   bool
-	smtpHost  string
-	smtpPort  string
-	username  string
-	password  string
-	fromEmail string
-	fromName  string
-	toEmails  []string
-	logger    *slog.Logger
+	enabled       bool
+	smtpHost      string
+	smtpPort      string
+	tlsMode       string
+	tlsConfig     *tls.Config
+	authMechanism string
+	username      string
+	password      string
+	fromEmail     string
+	fromName      string
+	toEmails      []string
+	logger        *slog.Logger
+
+	mu        sync.Mutex // serializes sends over the pooled session
+	session   *smtpSession
+	idleTimer *time.Timer
 }
 
 // EmailConfig contains configuration for the email provider
 type EmailConfig struct {
-	SMTPHost  string   `json:"smtp_host"`
-	SMTPPort  string   `json:"smtp_port"`
-	Username  string   `json:"username"`
-	Password  string   `json:"password"`
-	FromEmail string   `json:"from_email"`
-	FromName  string   `json:"from_name"`
-	ToEmails  []string `json:"to_emails"`
+	SMTPHost      string   `json:"smtp_host"`
+	SMTPPort      string   `json:"smtp_port"`
+	TLSMode       string   `json:"tls_mode"`       // none, starttls or implicit_tls, implicit_tls on port 465 and starttls otherwise by default
+	CACert        string   `json:"ca_cert"`        // PEM certificates trusted instead of the system roots
+	AuthMechanism string   `json:"auth_mechanism"` // none, plain, login or cram-md5, plain when a username is set by default
+	Username      string   `json:"username"`
+	Password      string   `json:"password"`
+	FromEmail     string   `json:"from_email"`
+	FromName      string   `json:"from_name"`
+	ToEmails      []string `json:"to_emails"`
 }
 
 // NewEmailProvider creates a new email notification provider
@@ -76,14 +91,51 @@ func (e *EmailProvider) Configure(config notification.ProviderConfig) error {
 		return fmt.Errorf("smtp_port is required")
 	}
 
-	username, ok := config.Settings["username"].(string)
-	if !ok || username == "" {
-		return fmt.Errorf("username is required")
+	// Extract connection security, by default implicit TLS on the submissions port and
+	// STARTTLS elsewhere
+	tlsMode, _ := config.Settings["tls_mode"].(string)
+	tlsMode = strings.ToLower(tlsMode)
+	if tlsMode == "" {
+		tlsMode = SMTPTLSStartTLS
+		if smtpPort == "465" {
+			tlsMode = SMTPTLSImplicit
+		}
+	}
+	if tlsMode != SMTPTLSNone && tlsMode != SMTPTLSStartTLS && tlsMode != SMTPTLSImplicit {
+		return fmt.Errorf("tls_mode must be none, starttls or implicit_tls")
 	}
 
-	password, ok := config.Settings["password"].(string)
-	if !ok || password == "" {
-		return fmt.Errorf("password is required")
+	tlsConfig := &tls.Config{ServerName: smtpHost, MinVersion: tls.VersionTLS12}
+	if caCert, _ := config.Settings["ca_cert"].(string); caCert != "" {
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM([]byte(caCert)) {
+			return fmt.Errorf("ca_cert must contain PEM encoded certificates")
+		}
+		tlsConfig.RootCAs = roots
+	}
+
+	// Extract optional authentication, internal relays often accept mail without it
+	username, _ := config.Settings["username"].(string)
+	password, _ := config.Settings["password"].(string)
+	authMechanism, _ := config.Settings["auth_mechanism"].(string)
+	authMechanism = strings.ToLower(authMechanism)
+	if authMechanism == "" {
+		authMechanism = SMTPAuthNone
+		if username != "" {
+			authMechanism = SMTPAuthPlain
+		}
+	}
+	switch authMechanism {
+	case SMTPAuthNone:
+	case SMTPAuthPlain, SMTPAuthLogin, SMTPAuthCRAMMD5:
+		if username == "" {
+			return fmt.Errorf("username is required")
+		}
+		if password == "" {
+			return fmt.Errorf("password is required")
+		}
+	default:
+		return fmt.Errorf("auth_mechanism must be none, plain, login or cram-md5")
 	}
 
 	fromEmail, ok := config.Settings["from_email"].(string)
@@ -119,8 +171,16 @@ func (e *EmailProvider) Configure(config notification.ProviderConfig) error {
 		return fmt.Errorf("at least one email address is required in to_emails")
 	}
 
+	// Settings may have changed, the next send connects again
+	e.mu.Lock()
+	e.closeSession()
+	e.mu.Unlock()
+
 	e.smtpHost = smtpHost
 	e.smtpPort = smtpPort
+	e.tlsMode = tlsMode
+	e.tlsConfig = tlsConfig
+	e.authMechanism = authMechanism
 	e.username = username
 	e.password = password
 	e.fromEmail = fromEmail
@@ -131,6 +191,8 @@ func (e *EmailProvider) Configure(config notification.ProviderConfig) error {
 	e.logger.Info("Email provider configured",
 		"smtp_host", smtpHost,
 		"smtp_port", smtpPort,
+		"tls_mode", tlsMode,
+		"auth_mechanism", authMechanism,
 		"from_email", fromEmail,
 		"to_emails_count", len(toEmails),
 		"enabled", e.enabled)
@@ -161,32 +223,51 @@ func (e *EmailProvider) SendNotification(ctx context.Context, data notification.
 		}
 	}
 
-	// Send email to all recipients
-	for _, toEmail := range e.toEmails {
-		err = e.sendEmail(toEmail, subject, htmlBody, textBody)
-		if err != nil {
+	// Send email to all recipients, notifications addressed to someone (such as the user on
+	// call) replace the configured recipients. Every recipient is attempted over the pooled
+	// connection, failures are reported together
+	recipients := e.toEmails
+	if len(data.Recipients) > 0 {
+		recipients = data.Recipients
+	}
+
+	e.mu.Lock()
+	var failures, failedRecipients []string
+	var errs []error
+	for _, toEmail := range recipients {
+		if err := e.sendEmail(ctx, toEmail, subject, htmlBody, textBody); err != nil {
 			e.logger.Error("Failed to send email",
 				"to", toEmail,
 				"subject", subject,
 				"error", err)
-			return notification.DeliveryResult{
-				Success:   false,
-				Error:     fmt.Errorf("failed to send email to %s: %w", toEmail, err),
-				Timestamp: time.Now(),
-				Details:   fmt.Sprintf("SMTP send failed: %s", err.Error()),
-			}
+			failures = append(failures, fmt.Sprintf("%s: %s", toEmail, err.Error()))
+			failedRecipients = append(failedRecipients, toEmail)
+			errs = append(errs, &EmailRecipientError{Recipient: toEmail, Err: err})
+		}
+	}
+	e.scheduleIdleClose()
+	e.mu.Unlock()
+
+	// Recipients that got the email are not sent it again when the delivery is retried
+	if len(failures) > 0 {
+		return notification.DeliveryResult{
+			Success:          false,
+			Error:            fmt.Errorf("failed to send email to %d of %d recipients: %w", len(failures), len(recipients), errors.Join(errs...)),
+			Timestamp:        time.Now(),
+			Details:          fmt.Sprintf("Sent to %d of %d recipients, SMTP send failed: %s", len(recipients)-len(failures), len(recipients), strings.Join(failures, "; ")),
+			FailedRecipients: failedRecipients,
 		}
 	}
 
 	e.logger.Info("Email notification sent successfully",
-		"recipients", len(e.toEmails),
+		"recipients", len(recipients),
 		"subject", subject,
 		"type", data.Type)
 
 	return notification.DeliveryResult{
 		Success:   true,
 		Timestamp: time.Now(),
-		Details:   fmt.Sprintf("Email sent to %d recipients", len(e.toEmails)),
+		Details:   fmt.Sprintf("Email sent to %d recipients", len(recipients)),
 	}
 }
 
@@ -196,39 +277,92 @@ func (e *EmailProvider) TestConnection(ctx context.Context) error {
 		return fmt.Errorf("email provider is disabled")
 	}
 
-	// Test SMTP connection
-	auth := smtp.PlainAuth("", e.username, e.password, e.smtpHost)
-	addr := fmt.Sprintf("%s:%s", e.smtpHost, e.smtpPort)
-
-	// Try to connect and authenticate
-	client, err := smtp.Dial(addr)
+	// Connect, secure the connection and authenticate on a connection of its own
+	session, err := e.dial(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to connect to SMTP server: %w", err)
-	}
-	defer client.Close()
-
-	if err = client.Auth(auth); err != nil {
-		return fmt.Errorf("SMTP authentication failed: %w", err)
+		return err
 	}
+	session.close()
 
 	return nil
 }
 
-// sendEmail sends an email using SMTP
-func (e *EmailProvider) sendEmail(to, subject, htmlBody, textBody string) error {
-	auth := smtp.PlainAuth("", e.username, e.password, e.smtpHost)
-	addr := fmt.Sprintf("%s:%s", e.smtpHost, e.smtpPort)
+// EmailRecipientError reports a recipient an email could not be sent to
+type EmailRecipientError struct {
+	Recipient string
+	Err       error
+}
+
+func (e *EmailRecipientError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Recipient, e.Err.Error())
+}
+
+func (e *EmailRecipientError) Unwrap() error {
+	return e.Err
+}
+
+// dial opens a new session with the configured server
+func (e *EmailProvider) dial(ctx context.Context) (*smtpSession, error) {
+	auth := smtpAuth(e.authMechanism, e.username, e.password, e.smtpHost)
+	return dialSMTP(ctx, e.smtpHost, e.smtpPort, e.tlsMode, e.tlsConfig, e.authMechanism, auth)
+}
+
+// sendEmail sends an email over the pooled session, connecting first when there is none or it
+// went stale. The session is dropped when the connection fails, not when the server refuses
+// the message. Callers hold e.mu
+func (e *EmailProvider) sendEmail(ctx context.Context, to, subject, htmlBody, textBody string) error {
+	if e.session != nil {
+		e.session.setDeadline(ctx)
+		if time.Since(e.session.lastUsed) >= smtpIdleTimeout || e.session.client.Noop() != nil {
+			e.closeSession()
+		}
+	}
+	if e.session == nil {
+		session, err := e.dial(ctx)
+		if err != nil {
+			return err
+		}
+		e.session = session
+	}
 
 	// Create email message
 	msg := e.buildEmailMessage(to, subject, htmlBody, textBody)
 
-	// Send email
-	err := smtp.SendMail(addr, auth, e.fromEmail, []string{to}, []byte(msg))
-	if err != nil {
-		return fmt.Errorf("SMTP send failed: %w", err)
+	e.session.setDeadline(ctx)
+	err := e.session.send(e.fromEmail, to, []byte(msg))
+	if err != nil && !isSMTPRejection(err) {
+		e.closeSession()
 	}
+	return err
+}
 
-	return nil
+// scheduleIdleClose closes the pooled session once it has not been used for smtpIdleTimeout.
+// Callers hold e.mu
+func (e *EmailProvider) scheduleIdleClose() {
+	if e.idleTimer != nil {
+		e.idleTimer.Stop()
+	}
+	if e.session == nil {
+		return
+	}
+
+	e.idleTimer = time.AfterFunc(smtpIdleTimeout, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		if e.session != nil && time.Since(e.session.lastUsed) >= smtpIdleTimeout {
+			e.closeSession()
+		}
+	})
+}
+
+// closeSession closes the pooled session if there is one. Callers hold e.mu
+func (e *EmailProvider) closeSession() {
+	if e.session == nil {
+		return
+	}
+	e.session.close()
+	e.session = nil
 }
 
 // buildEmailMessage builds the raw email message
@@ -280,12 +414,17 @@ func (e *EmailProvider) generateSubject(data notification.NotificationData) stri
 	}
 }
 
-// generateEmailBody generates both HTML and text email bodies
+// generateEmailBody generates both HTML and text email bodies. A channel's HTML template
+// replaces the built in HTML layout
 func (e *EmailProvider) generateEmailBody(data notification.NotificationData) (string, string, error) {
 	// Generate HTML body
-	htmlBody, err := e.generateHTMLBody(data)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to generate HTML body: %w", err)
+	htmlBody := data.HTMLMessage
+	if htmlBody == "" {
+		var err error
+		htmlBody, err = e.generateHTMLBody(data)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to generate HTML body: %w", err)
+		}
 	}
 
 	// Generate text body
@@ -326,11 +465,14 @@ func (e *EmailProvider) generateHTMLBody(data notification.NotificationData) (st
         <div class="content">
             <p>{{.Message}}</p>
             {{if .URL}}
-            <p><strong>URL:</strong> <a href="{{.URL}}">{{.URL}}</a></p>
+            <p><strong>Link:</strong> <a href="{{.URL}}">{{.URL}}</a></p>
             {{end}}
             {{if .Severity}}
             <p><strong>Severity:</strong> {{.Severity}}</p>
             {{end}}
+            {{range .Details}}
+            <p><strong>{{.Label}}:</strong> {{.Value}}</p>
+            {{end}}
             <div class="metadata">
                 <p class="timestamp">Time: {{.FormattedTime}}</p>
                 {{if .EndpointID}}
@@ -379,7 +521,7 @@ func (e *EmailProvider) generateTextBody(data notification.NotificationData) str
 
 	// URL
 	if data.URL != "" {
-		buf.WriteString(fmt.Sprintf("URL: %s\n", data.URL))
+		buf.WriteString(fmt.Sprintf("Link: %s\n", data.URL))
 	}
 
 	// Severity
@@ -387,6 +529,11 @@ func (e *EmailProvider) generateTextBody(data notification.NotificationData) str
 		buf.WriteString(fmt.Sprintf("Severity: %s\n", data.Severity))
 	}
 
+	// Endpoint, check and incident context
+	for _, detail := range data.Details() {
+		buf.WriteString(fmt.Sprintf("%s: %s\n", detail.Label, detail.Value))
+	}
+
 	buf.WriteString("\n")
 
 	// Metadata
@@ -394,11 +541,11 @@ func (e *EmailProvider) generateTextBody(data notification.NotificationData) str
 	buf.WriteString(fmt.Sprintf("Time: %s\n", data.Timestamp.Format("2006-01-02 15:04:05 UTC")))
 
 	if data.EndpointID != nil {
-		buf.WriteString(fmt.Sprintf("Endpoint ID: %d\n", *data.EndpointID))
+		buf.WriteString(fmt.Sprintf("Endpoint ID: %s\n", data.EndpointID))
 	}
 
 	if data.IncidentID != nil {
-		buf.WriteString(fmt.Sprintf("Incident ID: %d\n", *data.IncidentID))
+		buf.WriteString(fmt.Sprintf("Incident ID: %s\n", data.IncidentID))
 	}
 
 	buf.WriteString("\n---\n")
@@ -414,6 +561,7 @@ func (e *EmailProvider) buildTemplateData(data notification.NotificationData) ma
 		"Message":       data.Message,
 		"URL":           data.URL,
 		"Severity":      data.Severity,
+		"Details":       data.Details(),
 		"EndpointID":    data.EndpointID,
 		"IncidentID":    data.IncidentID,
 		"FormattedTime": data.Timestamp.Format("2006-01-02 15:04:05 UTC"),
@@ -2,13 +2,297 @@ package providers
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
 	"log/slog"
+	"math/big"
+	"net"
+	"net/textproto"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/i4o-oss/watchtower/internal/notification"
 )
 
+// smtpTestServer is a minimal in-process SMTP server supporting STARTTLS, implicit TLS and
+// PLAIN, LOGIN and CRAM-MD5 authentication
+type smtpTestServer struct {
+	listener    net.Listener
+	tlsConfig   *tls.Config
+	implicitTLS bool
+	startTLS    bool
+	mechanisms  []string        // advertised AUTH mechanisms, authentication is optional
+	username    string          // accepted credentials
+	password    string          // accepted credentials
+	rejected    map[string]bool // recipients refused with 550
+
+	mu          sync.Mutex
+	connections int
+	messages    []smtpTestMessage
+}
+
+// smtpTestMessage is a message the test server accepted
+type smtpTestMessage struct {
+	From string
+	To   string
+	Auth string // mechanism the client authenticated with
+	TLS  bool
+	Data string
+}
+
+// newSMTPTestServer starts a test server on a random local port, the certificate it presents
+// is returned as PEM to be used as the client's CA
+func newSMTPTestServer(t *testing.T, configure func(*smtpTestServer)) (*smtpTestServer, string) {
+	t.Helper()
+
+	certificate, caCert := testCertificate(t)
+	server := &smtpTestServer{
+		tlsConfig: &tls.Config{Certificates: []tls.Certificate{certificate}},
+		username:  "alerts",
+		password:  "s3cret",
+		rejected:  map[string]bool{},
+	}
+	if configure != nil {
+		configure(server)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	if server.implicitTLS {
+		listener = tls.NewListener(listener, server.tlsConfig)
+	}
+	server.listener = listener
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+
+	return server, caCert
+}
+
+func (s *smtpTestServer) port() string {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return port
+}
+
+func (s *smtpTestServer) received() ([]smtpTestMessage, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.messages), s.connections
+}
+
+func (s *smtpTestServer) serve(conn net.Conn) {
+	defer func() { conn.Close() }()
+
+	s.mu.Lock()
+	s.connections++
+	s.mu.Unlock()
+
+	_, secure := conn.(*tls.Conn)
+	tp := textproto.NewConn(conn)
+	var auth, from string
+	var to []string
+
+	tp.PrintfLine("220 localhost ESMTP test server")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			lines := []string{"localhost"}
+			if s.startTLS && !secure {
+				lines = append(lines, "STARTTLS")
+			}
+			if len(s.mechanisms) > 0 {
+				lines = append(lines, "AUTH "+strings.Join(s.mechanisms, " "))
+			}
+			for i, l := range lines {
+				separator := "-"
+				if i == len(lines)-1 {
+					separator = " "
+				}
+				tp.PrintfLine("250%s%s", separator, l)
+			}
+		case "STARTTLS":
+			tp.PrintfLine("220 Ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, tp, secure = tlsConn, textproto.NewConn(tlsConn), true
+		case "AUTH":
+			mechanism, initial, _ := strings.Cut(arg, " ")
+			mechanism = strings.ToUpper(mechanism)
+			if s.authenticate(tp, mechanism, initial) {
+				auth = mechanism
+				tp.PrintfLine("235 Authentication successful")
+			} else {
+				tp.PrintfLine("535 Authentication failed")
+			}
+		case "MAIL":
+			_, address, _ := strings.Cut(arg, ":")
+			from = strings.Trim(address, "<>")
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			_, address, _ := strings.Cut(arg, ":")
+			address = strings.Trim(address, "<>")
+			if s.rejected[address] {
+				tp.PrintfLine("550 5.1.1 No such user")
+				continue
+			}
+			to = append(to, address)
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 Go ahead")
+			lines, err := tp.ReadDotLines()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			for _, recipient := range to {
+				s.messages = append(s.messages, smtpTestMessage{From: from, To: recipient, Auth: auth, TLS: secure, Data: strings.Join(lines, "\n")})
+			}
+			s.mu.Unlock()
+			from, to = "", nil
+			tp.PrintfLine("250 OK queued")
+		case "RSET":
+			from, to = "", nil
+			tp.PrintfLine("250 OK")
+		case "NOOP":
+			tp.PrintfLine("250 OK")
+		case "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+		default:
+			tp.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+func (s *smtpTestServer) authenticate(tp *textproto.Conn, mechanism, initial string) bool {
+	if !slices.Contains(s.mechanisms, mechanism) {
+		return false
+	}
+
+	challenge := func(prompt string) string {
+		tp.PrintfLine("334 %s", base64.StdEncoding.EncodeToString([]byte(prompt)))
+		line, _ := tp.ReadLine()
+		decoded, _ := base64.StdEncoding.DecodeString(line)
+		return string(decoded)
+	}
+
+	switch mechanism {
+	case "PLAIN":
+		response := challenge
+		if initial != "" {
+			decoded, _ := base64.StdEncoding.DecodeString(initial)
+			response = func(string) string { return string(decoded) }
+		}
+		parts := strings.Split(response(""), "\x00")
+		return len(parts) == 3 && parts[1] == s.username && parts[2] == s.password
+	case "LOGIN":
+		return challenge("Username:") == s.username && challenge("Password:") == s.password
+	case "CRAM-MD5":
+		nonce := "<1896.697170952@localhost>"
+		username, digest, _ := strings.Cut(challenge(nonce), " ")
+		mac := hmac.New(md5.New, []byte(s.password))
+		mac.Write([]byte(nonce))
+		return username == s.username && digest == hex.EncodeToString(mac.Sum(nil))
+	default:
+		return false
+	}
+}
+
+// testCertificate returns a self-signed certificate for 127.0.0.1 and its PEM encoding
+func testCertificate(t *testing.T) (tls.Certificate, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Watchtower test SMTP server"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, string(caCert)
+}
+
+// newTestEmailProvider configures an email provider for the test server, settings override the
+// defaults
+func newTestEmailProvider(t *testing.T, server *smtpTestServer, settings map[string]interface{}) *EmailProvider {
+	t.Helper()
+
+	config := map[string]interface{}{
+		"smtp_host":  "127.0.0.1",
+		"smtp_port":  server.port(),
+		"tls_mode":   SMTPTLSNone,
+		"from_email": "alerts@example.com",
+		"to_emails":  []string{"ops@example.com", "dev@example.com"},
+	}
+	for key, value := range settings {
+		config[key] = value
+	}
+
+	provider := NewEmailProvider(nil)
+	err := provider.Configure(notification.ProviderConfig{Type: notification.ProviderTypeEmail, Enabled: true, Settings: config})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return provider
+}
+
+func testEmailNotification() notification.NotificationData {
+	return notification.NotificationData{
+		Type:      notification.NotificationTypeEndpointDown,
+		Title:     "API",
+		Message:   "API is not responding",
+		Severity:  "critical",
+		Timestamp: time.Now(),
+	}
+}
+
 func TestNewEmailProvider(t *testing.T) {
 	provider := NewEmailProvider(nil)
 
@@ -104,3 +388,165 @@ func TestEmailProviderSendNotificationDisabled(t *testing.T) {
 		t.Fatal("Expected error for disabled provider")
 	}
 }
+
+func TestEmailProviderStartTLSWithAuthMechanisms(t *testing.T) {
+	for _, mechanism := range []string{SMTPAuthPlain, SMTPAuthLogin, SMTPAuthCRAMMD5} {
+		t.Run(mechanism, func(t *testing.T) {
+			server, caCert := newSMTPTestServer(t, func(s *smtpTestServer) {
+				s.startTLS = true
+				s.mechanisms = []string{"PLAIN", "LOGIN", "CRAM-MD5"}
+			})
+			provider := newTestEmailProvider(t, server, map[string]interface{}{
+				"tls_mode":       SMTPTLSStartTLS,
+				"ca_cert":        caCert,
+				"auth_mechanism": mechanism,
+				"username":       "alerts",
+				"password":       "s3cret",
+			})
+
+			if err := provider.TestConnection(context.Background()); err != nil {
+				t.Fatalf("Expected the connection test to pass, got %v", err)
+			}
+
+			result := provider.SendNotification(context.Background(), testEmailNotification())
+			if !result.Success {
+				t.Fatalf("Expected the email to be sent, got %v", result.Error)
+			}
+
+			messages, _ := server.received()
+			if len(messages) != 2 {
+				t.Fatalf("Expected a message per recipient, got %d", len(messages))
+			}
+			for _, message := range messages {
+				if !message.TLS || message.Auth != strings.ToUpper(mechanism) {
+					t.Errorf("Expected the message over TLS with %s auth, got %+v", mechanism, message)
+				}
+			}
+		})
+	}
+}
+
+func TestEmailProviderImplicitTLSRequiresTrustedCertificate(t *testing.T) {
+	server, caCert := newSMTPTestServer(t, func(s *smtpTestServer) { s.implicitTLS = true })
+
+	untrusted := newTestEmailProvider(t, server, map[string]interface{}{"tls_mode": SMTPTLSImplicit})
+	if err := untrusted.TestConnection(context.Background()); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Errorf("Expected the self-signed certificate to be rejected, got %v", err)
+	}
+
+	provider := newTestEmailProvider(t, server, map[string]interface{}{"tls_mode": SMTPTLSImplicit, "ca_cert": caCert})
+	if result := provider.SendNotification(context.Background(), testEmailNotification()); !result.Success {
+		t.Fatalf("Expected the email to be sent, got %v", result.Error)
+	}
+	if messages, _ := server.received(); len(messages) != 2 || !messages[0].TLS || messages[0].Auth != "" {
+		t.Errorf("Expected unauthenticated messages over TLS, got %+v", messages)
+	}
+}
+
+func TestEmailProviderRequiresStartTLSSupport(t *testing.T) {
+	server, _ := newSMTPTestServer(t, nil)
+	provider := newTestEmailProvider(t, server, map[string]interface{}{"tls_mode": SMTPTLSStartTLS})
+
+	if err := provider.TestConnection(context.Background()); err == nil || !strings.Contains(err.Error(), "does not support STARTTLS") {
+		t.Errorf("Expected STARTTLS to be required, got %v", err)
+	}
+}
+
+func TestEmailProviderReportsRecipientErrors(t *testing.T) {
+	server, _ := newSMTPTestServer(t, func(s *smtpTestServer) {
+		s.rejected["gone@example.com"] = true
+	})
+	provider := newTestEmailProvider(t, server, map[string]interface{}{
+		"to_emails": []string{"ops@example.com", "gone@example.com", "dev@example.com"},
+	})
+
+	result := provider.SendNotification(context.Background(), testEmailNotification())
+	if result.Success {
+		t.Fatal("Expected the send to fail for the rejected recipient")
+	}
+	if !strings.Contains(result.Details, "gone@example.com: recipient rejected: 550") {
+		t.Errorf("Expected the rejected recipient in the details, got %q", result.Details)
+	}
+	if len(result.FailedRecipients) != 1 || result.FailedRecipients[0] != "gone@example.com" {
+		t.Errorf("Expected only the rejected recipient to be retried, got %v", result.FailedRecipients)
+	}
+
+	var recipientErr *EmailRecipientError
+	if !errors.As(result.Error, &recipientErr) || recipientErr.Recipient != "gone@example.com" {
+		t.Errorf("Expected a recipient error, got %v", result.Error)
+	}
+	var smtpErr *textproto.Error
+	if !errors.As(result.Error, &smtpErr) || smtpErr.Code != 550 {
+		t.Errorf("Expected the server's reply in the error, got %v", result.Error)
+	}
+
+	// The other recipients are still sent to, over the same connection
+	messages, connections := server.received()
+	if len(messages) != 2 || messages[1].To != "dev@example.com" {
+		t.Errorf("Expected the other recipients to receive the email, got %+v", messages)
+	}
+	if connections != 1 {
+		t.Errorf("Expected a single connection, got %d", connections)
+	}
+}
+
+func TestEmailProviderReusesConnection(t *testing.T) {
+	server, _ := newSMTPTestServer(t, nil)
+	provider := newTestEmailProvider(t, server, nil)
+
+	for i := 0; i < 3; i++ {
+		if result := provider.SendNotification(context.Background(), testEmailNotification()); !result.Success {
+			t.Fatalf("Expected send %d to succeed, got %v", i+1, result.Error)
+		}
+	}
+
+	messages, connections := server.received()
+	if len(messages) != 6 || connections != 1 {
+		t.Errorf("Expected 6 messages over one connection, got %d over %d", len(messages), connections)
+	}
+
+	// A connection the server dropped is replaced
+	provider.mu.Lock()
+	provider.session.conn.Close()
+	provider.mu.Unlock()
+
+	if result := provider.SendNotification(context.Background(), testEmailNotification()); !result.Success {
+		t.Fatalf("Expected the send to reconnect, got %v", result.Error)
+	}
+	if _, connections := server.received(); connections != 2 {
+		t.Errorf("Expected a second connection, got %d", connections)
+	}
+}
+
+func TestEmailProviderConfigureValidatesSecurity(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		want     string
+	}{
+		{"unknown tls mode", map[string]interface{}{"tls_mode": "ssl"}, "tls_mode"},
+		{"unknown auth mechanism", map[string]interface{}{"auth_mechanism": "xoauth2", "username": "a", "password": "b"}, "auth_mechanism"},
+		{"auth without username", map[string]interface{}{"auth_mechanism": "login"}, "username is required"},
+		{"username without password", map[string]interface{}{"username": "alerts"}, "password is required"},
+		{"invalid ca", map[string]interface{}{"ca_cert": "not a certificate"}, "ca_cert"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := map[string]interface{}{
+				"smtp_host":  "smtp.example.com",
+				"smtp_port":  "25",
+				"from_email": "alerts@example.com",
+				"to_emails":  []string{"ops@example.com"},
+			}
+			for key, value := range tt.settings {
+				settings[key] = value
+			}
+
+			err := NewEmailProvider(nil).Configure(notification.ProviderConfig{Type: notification.ProviderTypeEmail, Enabled: true, Settings: settings})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
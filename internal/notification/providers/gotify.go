@@ -0,0 +1,253 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/i4o-oss/watchtower/internal/notification"
+)
+
+// GotifyProvider implements notification.NotificationProvider for a self-hosted Gotify server,
+// pushing messages with an application token
+type GotifyProvider struct {
+	enabled    bool
+	serverURL  string
+	appToken   string
+	logger     *slog.Logger
+	httpClient *http.Client
+}
+
+// GotifyConfig contains configuration for the Gotify provider
+type GotifyConfig struct {
+	ServerURL string `json:"server_url"`
+	AppToken  string `json:"app_token"`
+}
+
+// GotifyMessage represents a Gotify message
+type GotifyMessage struct {
+	Title    string                 `json:"title"`
+	Message  string                 `json:"message"`
+	Priority int                    `json:"priority"`
+	Extras   map[string]interface{} `json:"extras,omitempty"`
+}
+
+// NewGotifyProvider creates a new Gotify notification provider
+func NewGotifyProvider(logger *slog.Logger) *GotifyProvider {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &GotifyProvider{
+		enabled: false,
+		logger:  logger,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// GetType returns the provider type
+func (g *GotifyProvider) GetType() notification.ProviderType {
+	return notification.ProviderTypeGotify
+}
+
+// IsEnabled returns whether this provider is enabled
+func (g *GotifyProvider) IsEnabled() bool {
+	return g.enabled
+}
+
+// Configure sets up the provider with the given configuration
+func (g *GotifyProvider) Configure(config notification.ProviderConfig) error {
+	if config.Type != notification.ProviderTypeGotify {
+		return fmt.Errorf("invalid provider type: expected %s, got %s", notification.ProviderTypeGotify, config.Type)
+	}
+
+	// Extract server URL, Gotify is always self-hosted
+	serverURL, ok := config.Settings["server_url"].(string)
+	if !ok || serverURL == "" {
+		return fmt.Errorf("server_url is required")
+	}
+	if !strings.HasPrefix(serverURL, "http://") && !strings.HasPrefix(serverURL, "https://") {
+		return fmt.Errorf("server_url must be an http or https URL")
+	}
+
+	// Extract application token
+	appToken, ok := config.Settings["app_token"].(string)
+	if !ok || appToken == "" {
+		return fmt.Errorf("app_token is required")
+	}
+
+	g.serverURL = strings.TrimRight(serverURL, "/")
+	g.appToken = appToken
+	g.enabled = config.Enabled
+
+	g.logger.Info("Gotify provider configured",
+		"server_url", g.serverURL,
+		"enabled", g.enabled)
+
+	return nil
+}
+
+// SendNotification pushes a Gotify message
+func (g *GotifyProvider) SendNotification(ctx context.Context, data notification.NotificationData) notification.DeliveryResult {
+	if !g.enabled {
+		return notification.DeliveryResult{
+			Success:   false,
+			Error:     fmt.Errorf("gotify provider is disabled"),
+			Timestamp: time.Now(),
+			Details:   "Provider disabled",
+		}
+	}
+
+	// Build Gotify message
+	message := g.buildGotifyMessage(data)
+
+	// Send Gotify message
+	err := g.sendMessage(ctx, message)
+	if err != nil {
+		g.logger.Error("Failed to send Gotify notification",
+			"error", err)
+		return notification.DeliveryResult{
+			Success:   false,
+			Error:     fmt.Errorf("failed to send Gotify notification: %w", err),
+			Timestamp: time.Now(),
+			Details:   fmt.Sprintf("Gotify request failed: %s", err.Error()),
+		}
+	}
+
+	g.logger.Info("Gotify notification sent successfully",
+		"priority", message.Priority,
+		"type", data.Type)
+
+	return notification.DeliveryResult{
+		Success:   true,
+		Timestamp: time.Now(),
+		Details:   fmt.Sprintf("Gotify message sent with priority %d", message.Priority),
+	}
+}
+
+// TestConnection tests if the provider is properly configured and can send messages
+func (g *GotifyProvider) TestConnection(ctx context.Context) error {
+	if !g.enabled {
+		return fmt.Errorf("gotify provider is disabled")
+	}
+
+	// Create a test message
+	testData := notification.NotificationData{
+		Type:      notification.NotificationTypeEndpointDown,
+		Title:     "Test Notification",
+		Message:   "This is a test notification from Watchtower",
+		Severity:  "info",
+		Timestamp: time.Now(),
+	}
+
+	// Send test message
+	err := g.sendMessage(ctx, g.buildGotifyMessage(testData))
+	if err != nil {
+		return fmt.Errorf("gotify test failed: %w", err)
+	}
+
+	return nil
+}
+
+// buildGotifyMessage builds a markdown Gotify message from notification data
+func (g *GotifyProvider) buildGotifyMessage(data notification.NotificationData) GotifyMessage {
+	var b strings.Builder
+	if data.Message != "" {
+		b.WriteString(data.Message)
+		b.WriteString("\n\n")
+	}
+	if data.Severity != "" {
+		fmt.Fprintf(&b, "**Severity:** %s  \n", data.Severity)
+	}
+	for _, detail := range data.Details() {
+		fmt.Fprintf(&b, "**%s:** %s  \n", detail.Label, detail.Value)
+	}
+	if data.IncidentID != nil {
+		fmt.Fprintf(&b, "**Incident ID:** `%s`  \n", data.IncidentID.String())
+	}
+	fmt.Fprintf(&b, "**Time:** %s", data.Timestamp.UTC().Format("2006-01-02 15:04:05 UTC"))
+	if data.URL != "" {
+		fmt.Fprintf(&b, "\n\n[View details](%s)", data.URL)
+	}
+
+	extras := map[string]interface{}{
+		"client::display": map[string]string{"contentType": "text/markdown"},
+	}
+	if data.URL != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]string{"url": data.URL},
+		}
+	}
+
+	return GotifyMessage{
+		Title:    data.Title,
+		Message:  b.String(),
+		Priority: gotifyPriority(data),
+		Extras:   extras,
+	}
+}
+
+// gotifyPriority maps the severity to Gotify's priorities from 0 to 10. The Android client
+// alerts with sound from 4 and pops up from 8. Recoveries are sent at a normal priority
+func gotifyPriority(data notification.NotificationData) int {
+	switch data.Type {
+	case notification.NotificationTypeEndpointUp, notification.NotificationTypeIncidentResolved:
+		return 5
+	}
+
+	switch strings.ToLower(data.Severity) {
+	case "critical":
+		return 10
+	case "high":
+		return 8
+	case "medium":
+		return 5
+	case "low":
+		return 3
+	case "info":
+		return 1
+	default:
+		return 5
+	}
+}
+
+// sendMessage posts the message to the server
+func (g *GotifyProvider) sendMessage(ctx context.Context, message GotifyMessage) error {
+	// Marshal message to JSON
+	jsonPayload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Gotify message: %w", err)
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, "POST", g.serverURL+"/message", bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gotify-Key", g.appToken)
+
+	// Send request
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check response status
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Gotify returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/i4o-oss/watchtower/internal/notification"
+)
+
+func TestNewGotifyProvider(t *testing.T) {
+	provider := NewGotifyProvider(nil)
+
+	if provider == nil {
+		t.Fatal("Expected provider to be created")
+	}
+
+	if provider.GetType() != notification.ProviderTypeGotify {
+		t.Fatalf("Expected provider type %v, got %v", notification.ProviderTypeGotify, provider.GetType())
+	}
+
+	if provider.IsEnabled() {
+		t.Fatal("Expected provider to be disabled by default")
+	}
+}
+
+func TestGotifyProviderConfigureInvalidType(t *testing.T) {
+	provider := NewGotifyProvider(nil)
+
+	err := provider.Configure(notification.ProviderConfig{
+		Type:     notification.ProviderTypeSlack,
+		Enabled:  true,
+		Settings: map[string]interface{}{},
+	})
+	if err == nil {
+		t.Fatal("Expected error for invalid provider type")
+	}
+}
+
+func TestGotifyProviderConfigureMissingSettings(t *testing.T) {
+	provider := NewGotifyProvider(nil)
+
+	err := provider.Configure(notification.ProviderConfig{
+		Type:     notification.ProviderTypeGotify,
+		Enabled:  true,
+		Settings: map[string]interface{}{"server_url": "https://gotify.example.com"},
+	})
+	if err == nil {
+		t.Fatal("Expected error for missing app_token")
+	}
+
+	err = provider.Configure(notification.ProviderConfig{
+		Type:     notification.ProviderTypeGotify,
+		Enabled:  true,
+		Settings: map[string]interface{}{"app_token": "AbCdEf"},
+	})
+	if err == nil {
+		t.Fatal("Expected error for missing server_url")
+	}
+}
+
+func TestGotifyProviderSendNotificationDisabled(t *testing.T) {
+	provider := NewGotifyProvider(nil)
+
+	result := provider.SendNotification(context.Background(), notification.NotificationData{
+		Type:      notification.NotificationTypeEndpointDown,
+		Title:     "Test",
+		Timestamp: time.Now(),
+	})
+
+	if result.Success {
+		t.Fatal("Expected failure when provider is disabled")
+	}
+}
+
+func TestGotifyProviderSendsMessage(t *testing.T) {
+	var received GotifyMessage
+	var path, token string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		token = r.Header.Get("X-Gotify-Key")
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	provider := NewGotifyProvider(nil)
+	err := provider.Configure(notification.ProviderConfig{
+		Type:    notification.ProviderTypeGotify,
+		Enabled: true,
+		Settings: map[string]interface{}{
+			"server_url": server.URL + "/",
+			"app_token":  "AbCdEf",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	result := provider.SendNotification(context.Background(), notification.NotificationData{
+		Type:      notification.NotificationTypeEndpointDown,
+		Title:     "API is DOWN",
+		Severity:  "high",
+		Timestamp: time.Now(),
+	})
+	if !result.Success {
+		t.Fatalf("Expected success, got %v", result.Error)
+	}
+
+	if path != "/message" || token != "AbCdEf" {
+		t.Errorf("Expected an authenticated message request, got %s with token %q", path, token)
+	}
+	if received.Title != "API is DOWN" || received.Priority != 8 {
+		t.Errorf("Expected a high priority message, got %+v", received)
+	}
+}
+
+func TestGotifyProviderReportsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"Unauthorized","errorCode":401}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	provider := NewGotifyProvider(nil)
+	provider.Configure(notification.ProviderConfig{
+		Type:     notification.ProviderTypeGotify,
+		Enabled:  true,
+		Settings: map[string]interface{}{"server_url": server.URL, "app_token": "wrong"},
+	})
+
+	if err := provider.TestConnection(context.Background()); err == nil {
+		t.Fatal("Expected test to fail for a rejected token")
+	}
+}
@@ -0,0 +1,306 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/i4o-oss/watchtower/internal/notification"
+)
+
+// DefaultNtfyServerURL is the public ntfy server
+const DefaultNtfyServerURL = "https://ntfy.sh"
+
+// NtfyProvider implements notification.NotificationProvider for ntfy, publishing to a topic on
+// ntfy.sh or a self-hosted server. The message priority follows the notification's severity
+type NtfyProvider struct {
+	enabled     bool
+	serverURL   string
+	topic       string
+	accessToken string
+	username    string
+	password    string
+	logger      *slog.Logger
+	httpClient  *http.Client
+}
+
+// NtfyConfig contains configuration for the ntfy provider
+type NtfyConfig struct {
+	ServerURL   string `json:"server_url"`
+	Topic       string `json:"topic"`
+	AccessToken string `json:"access_token"`
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+}
+
+// NtfyMessage represents a message published as JSON to the server root
+type NtfyMessage struct {
+	Topic    string   `json:"topic"`
+	Title    string   `json:"title,omitempty"`
+	Message  string   `json:"message"`
+	Priority int      `json:"priority,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Click    string   `json:"click,omitempty"`
+	Markdown bool     `json:"markdown,omitempty"`
+}
+
+// NewNtfyProvider creates a new ntfy notification provider
+func NewNtfyProvider(logger *slog.Logger) *NtfyProvider {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &NtfyProvider{
+		enabled: false,
+		logger:  logger,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// GetType returns the provider type
+func (n *NtfyProvider) GetType() notification.ProviderType {
+	return notification.ProviderTypeNtfy
+}
+
+// IsEnabled returns whether this provider is enabled
+func (n *NtfyProvider) IsEnabled() bool {
+	return n.enabled
+}
+
+// Configure sets up the provider with the given configuration
+func (n *NtfyProvider) Configure(config notification.ProviderConfig) error {
+	if config.Type != notification.ProviderTypeNtfy {
+		return fmt.Errorf("invalid provider type: expected %s, got %s", notification.ProviderTypeNtfy, config.Type)
+	}
+
+	// Extract topic
+	topic, ok := config.Settings["topic"].(string)
+	if !ok || topic == "" {
+		return fmt.Errorf("topic is required")
+	}
+	if strings.ContainsAny(topic, "/ ") {
+		return fmt.Errorf("topic must not contain slashes or spaces")
+	}
+
+	// Extract optional server URL for self-hosted servers
+	serverURL, _ := config.Settings["server_url"].(string)
+	if serverURL == "" {
+		serverURL = DefaultNtfyServerURL
+	}
+	if !strings.HasPrefix(serverURL, "http://") && !strings.HasPrefix(serverURL, "https://") {
+		return fmt.Errorf("server_url must be an http or https URL")
+	}
+
+	// Extract optional authentication, an access token or username and password
+	accessToken, _ := config.Settings["access_token"].(string)
+	username, _ := config.Settings["username"].(string)
+	password, _ := config.Settings["password"].(string)
+	if accessToken != "" && username != "" {
+		return fmt.Errorf("use either access_token or username and password, not both")
+	}
+	if (username == "") != (password == "") {
+		return fmt.Errorf("username and password must be set together")
+	}
+
+	n.serverURL = strings.TrimRight(serverURL, "/")
+	n.topic = topic
+	n.accessToken = accessToken
+	n.username = username
+	n.password = password
+	n.enabled = config.Enabled
+
+	n.logger.Info("ntfy provider configured",
+		"server_url", n.serverURL,
+		"topic", topic,
+		"authenticated", accessToken != "" || username != "",
+		"enabled", n.enabled)
+
+	return nil
+}
+
+// SendNotification publishes an ntfy message
+func (n *NtfyProvider) SendNotification(ctx context.Context, data notification.NotificationData) notification.DeliveryResult {
+	if !n.enabled {
+		return notification.DeliveryResult{
+			Success:   false,
+			Error:     fmt.Errorf("ntfy provider is disabled"),
+			Timestamp: time.Now(),
+			Details:   "Provider disabled",
+		}
+	}
+
+	// Build ntfy message
+	message := n.buildNtfyMessage(data)
+
+	// Publish ntfy message
+	err := n.publish(ctx, message)
+	if err != nil {
+		n.logger.Error("Failed to send ntfy notification",
+			"topic", n.topic,
+			"error", err)
+		return notification.DeliveryResult{
+			Success:   false,
+			Error:     fmt.Errorf("failed to send ntfy notification: %w", err),
+			Timestamp: time.Now(),
+			Details:   fmt.Sprintf("ntfy publish failed: %s", err.Error()),
+		}
+	}
+
+	n.logger.Info("ntfy notification sent successfully",
+		"topic", n.topic,
+		"priority", message.Priority,
+		"type", data.Type)
+
+	return notification.DeliveryResult{
+		Success:   true,
+		Timestamp: time.Now(),
+		Details:   fmt.Sprintf("ntfy message published to %s with priority %d", n.topic, message.Priority),
+	}
+}
+
+// TestConnection tests if the provider is properly configured and can publish messages
+func (n *NtfyProvider) TestConnection(ctx context.Context) error {
+	if !n.enabled {
+		return fmt.Errorf("ntfy provider is disabled")
+	}
+
+	// Create a test message
+	testData := notification.NotificationData{
+		Type:      notification.NotificationTypeEndpointDown,
+		Title:     "Test Notification",
+		Message:   "This is a test notification from Watchtower",
+		Severity:  "info",
+		Timestamp: time.Now(),
+	}
+
+	// Publish test message
+	err := n.publish(ctx, n.buildNtfyMessage(testData))
+	if err != nil {
+		return fmt.Errorf("ntfy test failed: %w", err)
+	}
+
+	return nil
+}
+
+// buildNtfyMessage builds an ntfy message from notification data
+func (n *NtfyProvider) buildNtfyMessage(data notification.NotificationData) NtfyMessage {
+	var b strings.Builder
+	if data.Message != "" {
+		b.WriteString(data.Message)
+		b.WriteString("\n\n")
+	}
+	if data.Severity != "" {
+		fmt.Fprintf(&b, "Severity: %s\n", data.Severity)
+	}
+	for _, detail := range data.Details() {
+		fmt.Fprintf(&b, "%s: %s\n", detail.Label, detail.Value)
+	}
+	if data.IncidentID != nil {
+		fmt.Fprintf(&b, "Incident ID: %s\n", data.IncidentID.String())
+	}
+	fmt.Fprintf(&b, "Time: %s", data.Timestamp.UTC().Format("2006-01-02 15:04:05 UTC"))
+
+	tags := []string{n.getTagForType(data.Type)}
+	if data.Severity != "" {
+		tags = append(tags, strings.ToLower(data.Severity))
+	}
+
+	return NtfyMessage{
+		Topic:    n.topic,
+		Title:    data.Title,
+		Message:  b.String(),
+		Priority: ntfyPriority(data),
+		Tags:     tags,
+		Click:    data.URL,
+	}
+}
+
+// ntfyPriority maps the severity to ntfy's priorities from 1 (min) to 5 (max). Recoveries are
+// sent at the default priority so they do not page as loudly as the failure did
+func ntfyPriority(data notification.NotificationData) int {
+	switch data.Type {
+	case notification.NotificationTypeEndpointUp, notification.NotificationTypeIncidentResolved:
+		return 3
+	}
+
+	switch strings.ToLower(data.Severity) {
+	case "critical":
+		return 5
+	case "high":
+		return 4
+	case "medium":
+		return 3
+	case "low":
+		return 2
+	case "info":
+		return 1
+	default:
+		return 3
+	}
+}
+
+// publish posts the message to the server
+func (n *NtfyProvider) publish(ctx context.Context, message NtfyMessage) error {
+	// Marshal message to JSON
+	jsonPayload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ntfy message: %w", err)
+	}
+
+	// Create HTTP request, JSON messages are published to the server root
+	req, err := http.NewRequestWithContext(ctx, "POST", n.serverURL+"/", bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	switch {
+	case n.accessToken != "":
+		req.Header.Set("Authorization", "Bearer "+n.accessToken)
+	case n.username != "":
+		req.SetBasicAuth(n.username, n.password)
+	}
+
+	// Send request
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check response status
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("ntfy returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	return nil
+}
+
+// getTagForType returns the ntfy emoji tag for the notification type
+func (n *NtfyProvider) getTagForType(notificationType notification.NotificationType) string {
+	switch notificationType {
+	case notification.NotificationTypeEndpointDown:
+		return "red_circle"
+	case notification.NotificationTypeEndpointUp:
+		return "green_circle"
+	case notification.NotificationTypeIncidentCreated:
+		return "rotating_light"
+	case notification.NotificationTypeIncidentUpdated:
+		return "clipboard"
+	case notification.NotificationTypeIncidentResolved:
+		return "white_check_mark"
+	case notification.NotificationTypeIncidentEscalated:
+		return "pager"
+	default:
+		return "loudspeaker"
+	}
+}
@@ -0,0 +1,142 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/i4o-oss/watchtower/internal/notification"
+)
+
+func TestNewNtfyProvider(t *testing.T) {
+	provider := NewNtfyProvider(nil)
+
+	if provider == nil {
+		t.Fatal("Expected provider to be created")
+	}
+
+	if provider.GetType() != notification.ProviderTypeNtfy {
+		t.Fatalf("Expected provider type %v, got %v", notification.ProviderTypeNtfy, provider.GetType())
+	}
+
+	if provider.IsEnabled() {
+		t.Fatal("Expected provider to be disabled by default")
+	}
+}
+
+func TestNtfyProviderConfigureInvalidType(t *testing.T) {
+	provider := NewNtfyProvider(nil)
+
+	err := provider.Configure(notification.ProviderConfig{
+		Type:     notification.ProviderTypeSlack,
+		Enabled:  true,
+		Settings: map[string]interface{}{},
+	})
+	if err == nil {
+		t.Fatal("Expected error for invalid provider type")
+	}
+}
+
+func TestNtfyProviderConfigureValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+	}{
+		{"missing topic", map[string]interface{}{}},
+		{"invalid topic", map[string]interface{}{"topic": "alerts/prod"}},
+		{"invalid server URL", map[string]interface{}{"topic": "alerts", "server_url": "ntfy.example.com"}},
+		{"token and password", map[string]interface{}{"topic": "alerts", "access_token": "tk_abc", "username": "ops", "password": "secret"}},
+		{"username without password", map[string]interface{}{"topic": "alerts", "username": "ops"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewNtfyProvider(nil)
+			err := provider.Configure(notification.ProviderConfig{Type: notification.ProviderTypeNtfy, Enabled: true, Settings: tt.settings})
+			if err == nil {
+				t.Fatal("Expected configuration error")
+			}
+		})
+	}
+}
+
+func TestNtfyProviderSendNotificationDisabled(t *testing.T) {
+	provider := NewNtfyProvider(nil)
+
+	result := provider.SendNotification(context.Background(), notification.NotificationData{
+		Type:      notification.NotificationTypeEndpointDown,
+		Title:     "Test",
+		Timestamp: time.Now(),
+	})
+
+	if result.Success {
+		t.Fatal("Expected failure when provider is disabled")
+	}
+}
+
+func TestNtfyProviderPublishes(t *testing.T) {
+	var received NtfyMessage
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	provider := NewNtfyProvider(nil)
+	err := provider.Configure(notification.ProviderConfig{
+		Type:    notification.ProviderTypeNtfy,
+		Enabled: true,
+		Settings: map[string]interface{}{
+			"server_url":   server.URL,
+			"topic":        "watchtower-alerts",
+			"access_token": "tk_abc",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	result := provider.SendNotification(context.Background(), notification.NotificationData{
+		Type:      notification.NotificationTypeIncidentCreated,
+		Title:     "API outage",
+		Message:   "API is failing",
+		Severity:  "critical",
+		URL:       "https://status.example.com",
+		Timestamp: time.Now(),
+	})
+	if !result.Success {
+		t.Fatalf("Expected success, got %v", result.Error)
+	}
+
+	if authorization != "Bearer tk_abc" {
+		t.Errorf("Expected bearer authentication, got %q", authorization)
+	}
+	if received.Topic != "watchtower-alerts" || received.Title != "API outage" || received.Click != "https://status.example.com" {
+		t.Errorf("Expected the notification to be published to the topic, got %+v", received)
+	}
+	if received.Priority != 5 {
+		t.Errorf("Expected critical notifications at max priority, got %d", received.Priority)
+	}
+}
+
+func TestNtfyPriority(t *testing.T) {
+	tests := []struct {
+		data     notification.NotificationData
+		expected int
+	}{
+		{notification.NotificationData{Type: notification.NotificationTypeIncidentCreated, Severity: "high"}, 4},
+		{notification.NotificationData{Type: notification.NotificationTypeIncidentCreated, Severity: "info"}, 1},
+		{notification.NotificationData{Type: notification.NotificationTypeIncidentResolved, Severity: "critical"}, 3},
+		{notification.NotificationData{Type: notification.NotificationTypeEndpointDown}, 3},
+	}
+
+	for _, tt := range tests {
+		if got := ntfyPriority(tt.data); got != tt.expected {
+			t.Errorf("Expected priority %d for %s/%s, got %d", tt.expected, tt.data.Type, tt.data.Severity, got)
+		}
+	}
+}
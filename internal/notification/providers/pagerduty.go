@@ -0,0 +1,367 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/i4o-oss/watchtower/internal/notification"
+)
+
+// DefaultPagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const DefaultPagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDuty event actions
+const (
+	pagerDutyActionTrigger = "trigger"
+	pagerDutyActionResolve = "resolve"
+)
+
+// PagerDutyProvider implements notification.NotificationProvider for the PagerDuty Events API v2.
+// Down and incident events trigger an alert, recovery and resolution events resolve it. Alerts are
+// deduplicated per incident, or per endpoint for endpoint events
+type PagerDutyProvider struct {
+	enabled    bool
+	routingKey string
+	eventsURL  string
+	source     string
+	logger     *slog.Logger
+	httpClient *http.Client
+}
+
+// PagerDutyConfig contains configuration for the PagerDuty provider
+type PagerDutyConfig struct {
+	RoutingKey string `json:"routing_key"`
+	EventsURL  string `json:"events_url"`
+	Source     string `json:"source"`
+}
+
+// PagerDutyEvent represents a PagerDuty Events API v2 event
+type PagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *PagerDutyPayload `json:"payload,omitempty"`
+	Client      string            `json:"client,omitempty"`
+	ClientURL   string            `json:"client_url,omitempty"`
+	Links       []PagerDutyLink   `json:"links,omitempty"`
+}
+
+// PagerDutyPayload describes the alert of a trigger event
+type PagerDutyPayload struct {
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      string                 `json:"severity"`
+	Timestamp     string                 `json:"timestamp,omitempty"`
+	Component     string                 `json:"component,omitempty"`
+	Class         string                 `json:"class,omitempty"`
+	CustomDetails map[string]interface{} `json:"custom_details,omitempty"`
+}
+
+// PagerDutyLink represents a link attached to a PagerDuty alert
+type PagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+// PagerDutyResponse represents the Events API response
+type PagerDutyResponse struct {
+	Status   string   `json:"status"`
+	Message  string   `json:"message"`
+	DedupKey string   `json:"dedup_key"`
+	Errors   []string `json:"errors"`
+}
+
+// NewPagerDutyProvider creates a new PagerDuty notification provider
+func NewPagerDutyProvider(logger *slog.Logger) *PagerDutyProvider {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &PagerDutyProvider{
+		enabled: false,
+		logger:  logger,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// GetType returns the provider type
+func (p *PagerDutyProvider) GetType() notification.ProviderType {
+	return notification.ProviderTypePagerDuty
+}
+
+// IsEnabled returns whether this provider is enabled
+func (p *PagerDutyProvider) IsEnabled() bool {
+	return p.enabled
+}
+
+// Configure sets up the provider with the given configuration
+func (p *PagerDutyProvider) Configure(config notification.ProviderConfig) error {
+	if config.Type != notification.ProviderTypePagerDuty {
+		return fmt.Errorf("invalid provider type: expected %s, got %s", notification.ProviderTypePagerDuty, config.Type)
+	}
+
+	// Extract the integration's routing key
+	routingKey, ok := config.Settings["routing_key"].(string)
+	if !ok || routingKey == "" {
+		return fmt.Errorf("routing_key is required")
+	}
+
+	// Extract optional events URL, useful for testing against a local stub
+	eventsURL, _ := config.Settings["events_url"].(string)
+	if eventsURL == "" {
+		eventsURL = DefaultPagerDutyEventsURL
+	}
+	if !strings.HasPrefix(eventsURL, "http://") && !strings.HasPrefix(eventsURL, "https://") {
+		return fmt.Errorf("events_url must be an http or https URL")
+	}
+
+	// Extract optional source shown on alerts
+	source, _ := config.Settings["source"].(string)
+	if source == "" {
+		source = "watchtower"
+	}
+
+	p.routingKey = routingKey
+	p.eventsURL = eventsURL
+	p.source = source
+	p.enabled = config.Enabled
+
+	p.logger.Info("PagerDuty provider configured",
+		"events_url", eventsURL,
+		"source", source,
+		"enabled", p.enabled)
+
+	return nil
+}
+
+// SendNotification sends a PagerDuty event
+func (p *PagerDutyProvider) SendNotification(ctx context.Context, data notification.NotificationData) notification.DeliveryResult {
+	if !p.enabled {
+		return notification.DeliveryResult{
+			Success:   false,
+			Error:     fmt.Errorf("pagerduty provider is disabled"),
+			Timestamp: time.Now(),
+			Details:   "Provider disabled",
+		}
+	}
+
+	event, ok := p.buildEvent(data)
+	if !ok {
+		return notification.DeliveryResult{
+			Success:   true,
+			Timestamp: time.Now(),
+			Details:   fmt.Sprintf("Skipped: %s events are not sent to PagerDuty", data.Type),
+		}
+	}
+
+	response, err := p.sendEvent(ctx, event)
+	if err != nil {
+		p.logger.Error("Failed to send PagerDuty event",
+			"event_action", event.EventAction,
+			"dedup_key", event.DedupKey,
+			"error", err)
+		return notification.DeliveryResult{
+			Success:   false,
+			Error:     fmt.Errorf("failed to send PagerDuty event: %w", err),
+			Timestamp: time.Now(),
+			Details:   fmt.Sprintf("PagerDuty request failed: %s", err.Error()),
+		}
+	}
+
+	p.logger.Info("PagerDuty event sent successfully",
+		"event_action", event.EventAction,
+		"dedup_key", response.DedupKey,
+		"type", data.Type)
+
+	return notification.DeliveryResult{
+		Success:   true,
+		Timestamp: time.Now(),
+		Details:   fmt.Sprintf("PagerDuty %s accepted (dedup key %s)", event.EventAction, response.DedupKey),
+	}
+}
+
+// TestConnection sends a low severity test alert and resolves it straight away
+func (p *PagerDutyProvider) TestConnection(ctx context.Context) error {
+	if !p.enabled {
+		return fmt.Errorf("pagerduty provider is disabled")
+	}
+
+	dedupKey := fmt.Sprintf("watchtower-test-%d", time.Now().UnixNano())
+	trigger := PagerDutyEvent{
+		RoutingKey:  p.routingKey,
+		EventAction: pagerDutyActionTrigger,
+		DedupKey:    dedupKey,
+		Client:      "Watchtower",
+		Payload: &PagerDutyPayload{
+			Summary:   "Test Notification from Watchtower",
+			Source:    p.source,
+			Severity:  "info",
+			Timestamp: time.Now().Format(time.RFC3339),
+		},
+	}
+	if _, err := p.sendEvent(ctx, trigger); err != nil {
+		return fmt.Errorf("pagerduty test failed: %w", err)
+	}
+
+	resolve := PagerDutyEvent{RoutingKey: p.routingKey, EventAction: pagerDutyActionResolve, DedupKey: dedupKey}
+	if _, err := p.sendEvent(ctx, resolve); err != nil {
+		return fmt.Errorf("pagerduty test failed to resolve the test alert: %w", err)
+	}
+
+	return nil
+}
+
+// buildEvent builds the PagerDuty event for a notification. It returns false for notification
+// types that do not map to an event action
+func (p *PagerDutyProvider) buildEvent(data notification.NotificationData) (PagerDutyEvent, bool) {
+	var action string
+	switch data.Type {
+	case notification.NotificationTypeEndpointDown,
+		notification.NotificationTypeIncidentCreated,
+		notification.NotificationTypeIncidentUpdated,
+		notification.NotificationTypeIncidentEscalated:
+		action = pagerDutyActionTrigger
+	case notification.NotificationTypeEndpointUp,
+		notification.NotificationTypeIncidentResolved:
+		action = pagerDutyActionResolve
+	default:
+		return PagerDutyEvent{}, false
+	}
+
+	event := PagerDutyEvent{
+		RoutingKey:  p.routingKey,
+		EventAction: action,
+		DedupKey:    pagerDutyDedupKey(data),
+	}
+	if action == pagerDutyActionResolve {
+		return event, true
+	}
+
+	summary := data.Title
+	if data.Message != "" {
+		summary = fmt.Sprintf("%s: %s", data.Title, data.Message)
+	}
+	if len(summary) > 1024 {
+		summary = summary[:1021] + "..."
+	}
+
+	details := map[string]interface{}{
+		"message": data.Message,
+		"type":    string(data.Type),
+	}
+	if data.Severity != "" {
+		details["watchtower_severity"] = data.Severity
+	}
+	for _, detail := range data.Details() {
+		details[strings.ToLower(strings.ReplaceAll(detail.Label, " ", "_"))] = detail.Value
+	}
+	for key, value := range data.Metadata {
+		details[key] = value
+	}
+
+	var component string
+	if len(data.Endpoints) > 0 {
+		names := make([]string, 0, len(data.Endpoints))
+		for _, endpoint := range data.Endpoints {
+			names = append(names, endpoint.Name)
+		}
+		component = names[0]
+		details["endpoints"] = names
+	}
+
+	event.Client = "Watchtower"
+	event.Payload = &PagerDutyPayload{
+		Summary:       summary,
+		Source:        p.source,
+		Severity:      pagerDutySeverity(data),
+		Timestamp:     data.Timestamp.Format(time.RFC3339),
+		Component:     component,
+		Class:         string(data.Type),
+		CustomDetails: details,
+	}
+	if data.URL != "" {
+		event.ClientURL = data.URL
+		event.Links = []PagerDutyLink{{Href: data.URL, Text: "Open in Watchtower"}}
+	}
+
+	return event, true
+}
+
+// pagerDutyDedupKey returns the key that ties trigger and resolve events to one alert: the
+// incident for incident events and for endpoint events of an outage with an incident, so an
+// outage opens a single alert, and otherwise the endpoint
+func pagerDutyDedupKey(data notification.NotificationData) string {
+	switch {
+	case data.IncidentID != nil:
+		return "watchtower-incident-" + data.IncidentID.String()
+	case data.OutageIncidentID != nil:
+		return "watchtower-incident-" + data.OutageIncidentID.String()
+	case len(data.Endpoints) > 0:
+		return "watchtower-endpoint-" + data.Endpoints[0].ID.String()
+	default:
+		return "watchtower-" + strings.ToLower(strings.Join(strings.Fields(data.Title), "-"))
+	}
+}
+
+// pagerDutySeverity maps Watchtower severities to PagerDuty's critical, error, warning and info
+func pagerDutySeverity(data notification.NotificationData) string {
+	switch strings.ToLower(data.Severity) {
+	case "critical":
+		return "critical"
+	case "high":
+		return "error"
+	case "medium":
+		return "warning"
+	case "low", "info":
+		return "info"
+	default:
+		return "error"
+	}
+}
+
+// sendEvent posts an event to the Events API
+func (p *PagerDutyProvider) sendEvent(ctx context.Context, event PagerDutyEvent) (*PagerDutyResponse, error) {
+	// Marshal event to JSON
+	jsonPayload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal PagerDuty event: %w", err)
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, "POST", p.eventsURL, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send request
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, _ := io.ReadAll(resp.Body)
+
+	// Check response status, the Events API answers 202 Accepted
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("PagerDuty returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var response PagerDutyResponse
+	if err := json.Unmarshal(bodyBytes, &response); err != nil || response.DedupKey == "" {
+		response.DedupKey = event.DedupKey
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,224 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/notification"
+)
+
+// pagerDutyStub records the events posted to it and answers like the Events API
+type pagerDutyStub struct {
+	mu     sync.Mutex
+	events []PagerDutyEvent
+	status int
+}
+
+func (s *pagerDutyStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var event PagerDutyEvent
+	json.NewDecoder(r.Body).Decode(&event)
+
+	s.mu.Lock()
+	s.events = append(s.events, event)
+	status := s.status
+	s.mu.Unlock()
+
+	if status == 0 {
+		status = http.StatusAccepted
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(PagerDutyResponse{Status: "success", Message: "Event processed", DedupKey: event.DedupKey})
+}
+
+func newTestPagerDutyProvider(t *testing.T, stub *pagerDutyStub) *PagerDutyProvider {
+	t.Helper()
+
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+
+	provider := NewPagerDutyProvider(nil)
+	err := provider.Configure(notification.ProviderConfig{
+		Type:    notification.ProviderTypePagerDuty,
+		Enabled: true,
+		Settings: map[string]interface{}{
+			"routing_key": "R0UT1NGK3Y",
+			"events_url":  server.URL,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return provider
+}
+
+func TestNewPagerDutyProvider(t *testing.T) {
+	provider := NewPagerDutyProvider(nil)
+
+	if provider.GetType() != notification.ProviderTypePagerDuty {
+		t.Fatalf("Expected provider type %v, got %v", notification.ProviderTypePagerDuty, provider.GetType())
+	}
+
+	if provider.IsEnabled() {
+		t.Fatal("Expected provider to be disabled by default")
+	}
+}
+
+func TestPagerDutyProviderConfigure(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		wantErr  bool
+	}{
+		{"routing key only", map[string]interface{}{"routing_key": "key"}, false},
+		{"custom events URL", map[string]interface{}{"routing_key": "key", "events_url": "http://localhost:8081/v2/enqueue"}, false},
+		{"missing routing key", map[string]interface{}{}, true},
+		{"invalid events URL", map[string]interface{}{"routing_key": "key", "events_url": "ftp://example.com"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewPagerDutyProvider(nil)
+			err := provider.Configure(notification.ProviderConfig{Type: notification.ProviderTypePagerDuty, Enabled: true, Settings: tt.settings})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	provider := NewPagerDutyProvider(nil)
+	provider.Configure(notification.ProviderConfig{Type: notification.ProviderTypePagerDuty, Enabled: true, Settings: map[string]interface{}{"routing_key": "key"}})
+	if provider.eventsURL != DefaultPagerDutyEventsURL {
+		t.Errorf("Expected default events URL, got %s", provider.eventsURL)
+	}
+}
+
+func TestPagerDutyProviderTriggersAndResolvesIncident(t *testing.T) {
+	stub := &pagerDutyStub{}
+	provider := newTestPagerDutyProvider(t, stub)
+
+	incidentID := uuid.New()
+	endpoint := notification.EndpointInfo{ID: uuid.New(), Name: "API"}
+
+	result := provider.SendNotification(context.Background(), notification.NotificationData{
+		Type:       notification.NotificationTypeIncidentCreated,
+		Title:      "API outage",
+		Message:    "API is failing",
+		IncidentID: &incidentID,
+		Severity:   "high",
+		URL:        "https://status.example.com/incidents/1",
+		Timestamp:  time.Now(),
+		Endpoints:  []notification.EndpointInfo{endpoint},
+	})
+	if !result.Success {
+		t.Fatalf("Expected trigger to succeed, got %v", result.Error)
+	}
+
+	result = provider.SendNotification(context.Background(), notification.NotificationData{
+		Type:       notification.NotificationTypeIncidentResolved,
+		Title:      "API outage",
+		IncidentID: &incidentID,
+		Timestamp:  time.Now(),
+		Endpoints:  []notification.EndpointInfo{endpoint},
+	})
+	if !result.Success {
+		t.Fatalf("Expected resolve to succeed, got %v", result.Error)
+	}
+
+	if len(stub.events) != 2 {
+		t.Fatalf("Expected two events, got %d", len(stub.events))
+	}
+	trigger, resolve := stub.events[0], stub.events[1]
+
+	expectedKey := "watchtower-incident-" + incidentID.String()
+	if trigger.DedupKey != expectedKey || resolve.DedupKey != expectedKey {
+		t.Errorf("Expected dedup key %s for both events, got %s and %s", expectedKey, trigger.DedupKey, resolve.DedupKey)
+	}
+	if trigger.EventAction != "trigger" || resolve.EventAction != "resolve" {
+		t.Errorf("Expected trigger then resolve, got %s then %s", trigger.EventAction, resolve.EventAction)
+	}
+	if trigger.RoutingKey != "R0UT1NGK3Y" {
+		t.Errorf("Expected routing key to be sent, got %q", trigger.RoutingKey)
+	}
+	if trigger.Payload == nil || trigger.Payload.Severity != "error" || trigger.Payload.Component != "API" {
+		t.Errorf("Expected payload with mapped severity and component, got %+v", trigger.Payload)
+	}
+	if len(trigger.Links) != 1 || trigger.Links[0].Href != "https://status.example.com/incidents/1" {
+		t.Errorf("Expected incident link, got %+v", trigger.Links)
+	}
+	if resolve.Payload != nil {
+		t.Errorf("Expected resolve without payload, got %+v", resolve.Payload)
+	}
+}
+
+func TestPagerDutyDedupKeyForEndpoints(t *testing.T) {
+	endpoint := notification.EndpointInfo{ID: uuid.New(), Name: "API"}
+	down := notification.NotificationData{Type: notification.NotificationTypeEndpointDown, Endpoints: []notification.EndpointInfo{endpoint}}
+	up := notification.NotificationData{Type: notification.NotificationTypeEndpointUp, Endpoints: []notification.EndpointInfo{endpoint}}
+
+	if pagerDutyDedupKey(down) != pagerDutyDedupKey(up) || pagerDutyDedupKey(down) != "watchtower-endpoint-"+endpoint.ID.String() {
+		t.Errorf("Expected endpoint events to share the endpoint dedup key, got %s and %s", pagerDutyDedupKey(down), pagerDutyDedupKey(up))
+	}
+}
+
+func TestPagerDutyDedupKeyForOutageWithIncident(t *testing.T) {
+	endpoint := notification.EndpointInfo{ID: uuid.New(), Name: "API"}
+	incidentID := uuid.New()
+	down := notification.NotificationData{Type: notification.NotificationTypeEndpointDown, Endpoints: []notification.EndpointInfo{endpoint}, OutageIncidentID: &incidentID}
+	created := notification.NotificationData{Type: notification.NotificationTypeIncidentCreated, Endpoints: []notification.EndpointInfo{endpoint}, IncidentID: &incidentID}
+
+	if pagerDutyDedupKey(down) != pagerDutyDedupKey(created) || pagerDutyDedupKey(down) != "watchtower-incident-"+incidentID.String() {
+		t.Errorf("Expected the endpoint and incident events of an outage to share the incident dedup key, got %s and %s", pagerDutyDedupKey(down), pagerDutyDedupKey(created))
+	}
+}
+
+func TestPagerDutySeverity(t *testing.T) {
+	tests := map[string]string{
+		"critical": "critical",
+		"HIGH":     "error",
+		"medium":   "warning",
+		"low":      "info",
+		"info":     "info",
+		"":         "error",
+	}
+
+	for severity, expected := range tests {
+		if got := pagerDutySeverity(notification.NotificationData{Severity: severity}); got != expected {
+			t.Errorf("Expected %q to map to %s, got %s", severity, expected, got)
+		}
+	}
+}
+
+func TestPagerDutyProviderSkipsUnmappedTypes(t *testing.T) {
+	stub := &pagerDutyStub{}
+	provider := newTestPagerDutyProvider(t, stub)
+
+	result := provider.SendNotification(context.Background(), notification.NotificationData{Type: "digest", Title: "Daily digest"})
+	if !result.Success || len(stub.events) != 0 {
+		t.Errorf("Expected unmapped type to be skipped, got %+v with %d events", result, len(stub.events))
+	}
+}
+
+func TestPagerDutyProviderReportsRejectedEvents(t *testing.T) {
+	stub := &pagerDutyStub{status: http.StatusBadRequest}
+	provider := newTestPagerDutyProvider(t, stub)
+
+	result := provider.SendNotification(context.Background(), notification.NotificationData{Type: notification.NotificationTypeEndpointDown, Title: "API is DOWN"})
+	if result.Success || result.Error == nil {
+		t.Error("Expected rejected event to fail")
+	}
+}
+
+func TestPagerDutyProviderSendNotificationDisabled(t *testing.T) {
+	provider := NewPagerDutyProvider(nil)
+
+	result := provider.SendNotification(context.Background(), notification.NotificationData{Type: notification.NotificationTypeEndpointDown})
+	if result.Success {
+		t.Fatal("Expected notification to fail when provider is disabled")
+	}
+}
@@ -197,12 +197,20 @@ func (s *SlackProvider) buildSlackMessage(data notification.NotificationData) Sl
 
 	if data.URL != "" {
 		fields = append(fields, SlackField{
-			Title: "URL",
+			Title: "Link",
 			Value: data.URL,
 			Short: false,
 		})
 	}
 
+	for _, detail := range data.Details() {
+		fields = append(fields, SlackField{
+			Title: detail.Label,
+			Value: detail.Value,
+			Short: len(detail.Value) <= 40,
+		})
+	}
+
 	if data.Severity != "" {
 		fields = append(fields, SlackField{
 			Title: "Severity",
@@ -214,7 +222,7 @@ func (s *SlackProvider) buildSlackMessage(data notification.NotificationData) Sl
 	if data.EndpointID != nil {
 		fields = append(fields, SlackField{
 			Title: "Endpoint ID",
-			Value: fmt.Sprintf("%d", *data.EndpointID),
+			Value: data.EndpointID.String(),
 			Short: true,
 		})
 	}
@@ -222,7 +230,7 @@ func (s *SlackProvider) buildSlackMessage(data notification.NotificationData) Sl
 	if data.IncidentID != nil {
 		fields = append(fields, SlackField{
 			Title: "Incident ID",
-			Value: fmt.Sprintf("%d", *data.IncidentID),
+			Value: data.IncidentID.String(),
 			Short: true,
 		})
 	}
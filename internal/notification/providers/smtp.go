@@ -0,0 +1,211 @@
+package providers
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// SMTP connection security modes
+const (
+	SMTPTLSNone     = "none"         // plain text, for internal relays
+	SMTPTLSStartTLS = "starttls"     // upgraded with STARTTLS, which the server must offer
+	SMTPTLSImplicit = "implicit_tls" // TLS from the start, usually on port 465
+)
+
+// SMTP authentication mechanisms
+const (
+	SMTPAuthNone    = "none"
+	SMTPAuthPlain   = "plain"
+	SMTPAuthLogin   = "login"
+	SMTPAuthCRAMMD5 = "cram-md5"
+)
+
+// smtpTimeout bounds connecting and each exchange with the server when the context has no deadline
+const smtpTimeout = 30 * time.Second
+
+// smtpIdleTimeout is how long an unused connection is kept open for the next send
+const smtpIdleTimeout = 30 * time.Second
+
+// smtpSession is an open, authenticated connection to the SMTP server
+type smtpSession struct {
+	conn     net.Conn
+	client   *smtp.Client
+	lastUsed time.Time
+}
+
+// setDeadline bounds the next exchanges with the server by the context
+func (s *smtpSession) setDeadline(ctx context.Context) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	s.conn.SetDeadline(deadline)
+}
+
+// send delivers a message to a single recipient. Errors wrapping a *textproto.Error are the
+// server refusing the message, the connection is still usable after them
+func (s *smtpSession) send(from, to string, msg []byte) error {
+	if err := s.client.Mail(from); err != nil {
+		s.client.Reset()
+		return fmt.Errorf("sender rejected: %w", err)
+	}
+	if err := s.client.Rcpt(to); err != nil {
+		s.client.Reset()
+		return fmt.Errorf("recipient rejected: %w", err)
+	}
+
+	w, err := s.client.Data()
+	if err != nil {
+		s.client.Reset()
+		return fmt.Errorf("message rejected: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("message rejected: %w", err)
+	}
+
+	s.lastUsed = time.Now()
+	return nil
+}
+
+// close ends the session, politely when the server still listens
+func (s *smtpSession) close() {
+	s.conn.SetDeadline(time.Now().Add(time.Second))
+	if err := s.client.Quit(); err != nil {
+		s.client.Close()
+	}
+}
+
+// dialSMTP connects to the server, secures the connection as the mode requires and
+// authenticates when auth is set
+func dialSMTP(ctx context.Context, host, port, tlsMode string, tlsConfig *tls.Config, mechanism string, auth smtp.Auth) (*smtpSession, error) {
+	addr := net.JoinHostPort(host, port)
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+
+	var conn net.Conn
+	var err error
+	if tlsMode == SMTPTLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig.Clone()}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+
+	session := &smtpSession{conn: conn, lastUsed: time.Now()}
+	session.setDeadline(ctx)
+
+	session.client, err = smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+
+	if tlsMode == SMTPTLSStartTLS {
+		if ok, _ := session.client.Extension("STARTTLS"); !ok {
+			session.close()
+			return nil, fmt.Errorf("SMTP server does not support STARTTLS")
+		}
+		if err := session.client.StartTLS(tlsConfig.Clone()); err != nil {
+			session.client.Close()
+			return nil, fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+
+	if auth != nil {
+		name := strings.ToUpper(mechanism)
+		if ok, mechanisms := session.client.Extension("AUTH"); !ok || !containsFold(strings.Fields(mechanisms), name) {
+			session.close()
+			return nil, fmt.Errorf("SMTP server does not support AUTH %s", name)
+		}
+		if err := session.client.Auth(auth); err != nil {
+			session.close()
+			return nil, fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	return session, nil
+}
+
+// smtpAuth returns the smtp.Auth of the mechanism, nil without authentication
+func smtpAuth(mechanism, username, password, host string) smtp.Auth {
+	switch mechanism {
+	case SMTPAuthPlain:
+		return smtp.PlainAuth("", username, password, host)
+	case SMTPAuthLogin:
+		return &loginAuth{username: username, password: password, host: host}
+	case SMTPAuthCRAMMD5:
+		return smtp.CRAMMD5Auth(username, password)
+	default:
+		return nil
+	}
+}
+
+// loginAuth implements the LOGIN mechanism, which net/smtp lacks. Like PLAIN it sends the
+// password as is, so only over TLS or to localhost
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+// Start begins the LOGIN exchange
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+// Next answers the server's username and password prompts
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+
+	prompt := strings.ToLower(strings.TrimSpace(string(fromServer)))
+	switch {
+	case strings.HasPrefix(prompt, "username"):
+		return []byte(a.username), nil
+	case strings.HasPrefix(prompt, "password"):
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected LOGIN prompt: %q", fromServer)
+	}
+}
+
+// isLocalhost returns whether the host is the local machine, where credentials may be sent
+// without TLS
+func isLocalhost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
+// containsFold returns whether the list contains the value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSMTPRejection returns whether the error is the server refusing a command rather than the
+// connection failing
+func isSMTPRejection(err error) bool {
+	var protoErr *textproto.Error
+	return errors.As(err, &protoErr)
+}
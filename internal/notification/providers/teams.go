@@ -0,0 +1,355 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/i4o-oss/watchtower/internal/notification"
+)
+
+// TeamsProvider implements notification.NotificationProvider for Microsoft Teams, posting
+// Adaptive Cards to an incoming webhook or Workflows URL
+type TeamsProvider struct {
+	enabled    bool
+	webhookURL string
+	logger     *slog.Logger
+	httpClient *http.Client
+}
+
+// TeamsConfig contains configuration for the Teams provider
+type TeamsConfig struct {
+	WebhookURL string `json:"webhook_url"`
+}
+
+// TeamsMessage represents a Teams webhook message carrying an Adaptive Card
+type TeamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []TeamsAttachment `json:"attachments"`
+}
+
+// TeamsAttachment wraps the Adaptive Card of a Teams message
+type TeamsAttachment struct {
+	ContentType string       `json:"contentType"`
+	ContentURL  *string      `json:"contentUrl"`
+	Content     AdaptiveCard `json:"content"`
+}
+
+// AdaptiveCard represents an Adaptive Card
+type AdaptiveCard struct {
+	Schema  string                `json:"$schema"`
+	Type    string                `json:"type"`
+	Version string                `json:"version"`
+	Body    []AdaptiveCardElement `json:"body"`
+	Actions []AdaptiveCardAction  `json:"actions,omitempty"`
+	MSTeams map[string]string     `json:"msteams,omitempty"`
+}
+
+// AdaptiveCardElement represents the containers, text blocks and fact sets of a card
+type AdaptiveCardElement struct {
+	Type     string                `json:"type"`
+	Text     string                `json:"text,omitempty"`
+	Size     string                `json:"size,omitempty"`
+	Weight   string                `json:"weight,omitempty"`
+	Color    string                `json:"color,omitempty"`
+	Wrap     bool                  `json:"wrap,omitempty"`
+	IsSubtle bool                  `json:"isSubtle,omitempty"`
+	Style    string                `json:"style,omitempty"`
+	Bleed    bool                  `json:"bleed,omitempty"`
+	Items    []AdaptiveCardElement `json:"items,omitempty"`
+	Facts    []AdaptiveCardFact    `json:"facts,omitempty"`
+}
+
+// AdaptiveCardFact represents a fact in a fact set
+type AdaptiveCardFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// AdaptiveCardAction represents a card action
+type AdaptiveCardAction struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// NewTeamsProvider creates a new Teams notification provider
+func NewTeamsProvider(logger *slog.Logger) *TeamsProvider {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &TeamsProvider{
+		enabled: false,
+		logger:  logger,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// GetType returns the provider type
+func (t *TeamsProvider) GetType() notification.ProviderType {
+	return notification.ProviderTypeTeams
+}
+
+// IsEnabled returns whether this provider is enabled
+func (t *TeamsProvider) IsEnabled() bool {
+	return t.enabled
+}
+
+// Configure sets up the provider with the given configuration
+func (t *TeamsProvider) Configure(config notification.ProviderConfig) error {
+	if config.Type != notification.ProviderTypeTeams {
+		return fmt.Errorf("invalid provider type: expected %s, got %s", notification.ProviderTypeTeams, config.Type)
+	}
+
+	// Extract webhook URL
+	webhookURL, ok := config.Settings["webhook_url"].(string)
+	if !ok || webhookURL == "" {
+		return fmt.Errorf("webhook_url is required")
+	}
+	if !strings.HasPrefix(webhookURL, "http://") && !strings.HasPrefix(webhookURL, "https://") {
+		return fmt.Errorf("webhook_url must be an http or https URL")
+	}
+
+	t.webhookURL = webhookURL
+	t.enabled = config.Enabled
+
+	t.logger.Info("Teams provider configured",
+		"enabled", t.enabled)
+
+	return nil
+}
+
+// SendNotification sends a Teams notification
+func (t *TeamsProvider) SendNotification(ctx context.Context, data notification.NotificationData) notification.DeliveryResult {
+	if !t.enabled {
+		return notification.DeliveryResult{
+			Success:   false,
+			Error:     fmt.Errorf("teams provider is disabled"),
+			Timestamp: time.Now(),
+			Details:   "Provider disabled",
+		}
+	}
+
+	// Build Teams message
+	message := t.buildTeamsMessage(data)
+
+	// Send Teams webhook
+	err := t.sendTeamsWebhook(ctx, message)
+	if err != nil {
+		t.logger.Error("Failed to send Teams notification",
+			"error", err)
+		return notification.DeliveryResult{
+			Success:   false,
+			Error:     fmt.Errorf("failed to send Teams notification: %w", err),
+			Timestamp: time.Now(),
+			Details:   fmt.Sprintf("Teams webhook failed: %s", err.Error()),
+		}
+	}
+
+	t.logger.Info("Teams notification sent successfully",
+		"type", data.Type)
+
+	return notification.DeliveryResult{
+		Success:   true,
+		Timestamp: time.Now(),
+		Details:   "Teams notification sent successfully",
+	}
+}
+
+// TestConnection tests if the provider is properly configured and can send messages
+func (t *TeamsProvider) TestConnection(ctx context.Context) error {
+	if !t.enabled {
+		return fmt.Errorf("teams provider is disabled")
+	}
+
+	// Create a test message
+	testData := notification.NotificationData{
+		Type:      notification.NotificationTypeEndpointDown,
+		Title:     "Test Notification",
+		Message:   "This is a test notification from Watchtower",
+		Severity:  "info",
+		Timestamp: time.Now(),
+	}
+
+	// Send test message
+	err := t.sendTeamsWebhook(ctx, t.buildTeamsMessage(testData))
+	if err != nil {
+		return fmt.Errorf("teams test failed: %w", err)
+	}
+
+	return nil
+}
+
+// buildTeamsMessage builds a Teams message with an Adaptive Card from notification data
+func (t *TeamsProvider) buildTeamsMessage(data notification.NotificationData) TeamsMessage {
+	color := t.getColor(data)
+
+	// Header with the title on a background matching the severity
+	header := AdaptiveCardElement{
+		Type:  "Container",
+		Style: color,
+		Bleed: true,
+		Items: []AdaptiveCardElement{
+			{
+				Type:   "TextBlock",
+				Text:   fmt.Sprintf("%s %s", t.getEmojiForType(data.Type), data.Title),
+				Size:   "Large",
+				Weight: "Bolder",
+				Wrap:   true,
+			},
+		},
+	}
+
+	body := []AdaptiveCardElement{header}
+
+	if data.Message != "" {
+		body = append(body, AdaptiveCardElement{
+			Type: "TextBlock",
+			Text: data.Message,
+			Wrap: true,
+		})
+	}
+
+	// Add facts for additional information
+	var facts []AdaptiveCardFact
+
+	if data.Severity != "" {
+		facts = append(facts, AdaptiveCardFact{Title: "Severity", Value: data.Severity})
+	}
+
+	for _, detail := range data.Details() {
+		facts = append(facts, AdaptiveCardFact{Title: detail.Label, Value: detail.Value})
+	}
+
+	if data.IncidentID != nil {
+		facts = append(facts, AdaptiveCardFact{Title: "Incident ID", Value: data.IncidentID.String()})
+	}
+
+	facts = append(facts, AdaptiveCardFact{Title: "Time", Value: data.Timestamp.UTC().Format("2006-01-02 15:04:05 UTC")})
+
+	body = append(body, AdaptiveCardElement{
+		Type:  "FactSet",
+		Facts: facts,
+	})
+
+	body = append(body, AdaptiveCardElement{
+		Type:     "TextBlock",
+		Text:     "Sent by Watchtower",
+		Size:     "Small",
+		IsSubtle: true,
+		Wrap:     true,
+	})
+
+	card := AdaptiveCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body:    body,
+		MSTeams: map[string]string{"width": "Full"},
+	}
+
+	// Link back to the incident or endpoint
+	if data.URL != "" {
+		title := "View details"
+		if data.IncidentID != nil {
+			title = "View incident"
+		}
+		card.Actions = []AdaptiveCardAction{{Type: "Action.OpenUrl", Title: title, URL: data.URL}}
+	}
+
+	return TeamsMessage{
+		Type: "message",
+		Attachments: []TeamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content:     card,
+		}},
+	}
+}
+
+// sendTeamsWebhook sends the Teams webhook request
+func (t *TeamsProvider) sendTeamsWebhook(ctx context.Context, message TeamsMessage) error {
+	// Marshal message to JSON
+	jsonPayload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Teams message: %w", err)
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, "POST", t.webhookURL, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send request
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check response status, incoming webhooks answer 200 and Workflows 202
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Teams webhook returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	return nil
+}
+
+// getColor returns the Adaptive Card container style for the notification. Recoveries are
+// green, everything else follows the severity and falls back to the notification type
+func (t *TeamsProvider) getColor(data notification.NotificationData) string {
+	switch data.Type {
+	case notification.NotificationTypeEndpointUp, notification.NotificationTypeIncidentResolved:
+		return "good" // Green
+	}
+
+	switch strings.ToLower(data.Severity) {
+	case "critical", "high":
+		return "attention" // Red
+	case "medium":
+		return "warning" // Yellow
+	case "low", "info":
+		return "accent" // Blue
+	}
+
+	switch data.Type {
+	case notification.NotificationTypeEndpointDown, notification.NotificationTypeIncidentCreated, notification.NotificationTypeIncidentEscalated:
+		return "attention" // Red
+	case notification.NotificationTypeIncidentUpdated:
+		return "warning" // Yellow
+	default:
+		return "emphasis" // Grey
+	}
+}
+
+// getEmojiForType returns the appropriate emoji for the notification type
+func (t *TeamsProvider) getEmojiForType(notificationType notification.NotificationType) string {
+	switch notificationType {
+	case notification.NotificationTypeEndpointDown:
+		return "🔴"
+	case notification.NotificationTypeEndpointUp:
+		return "🟢"
+	case notification.NotificationTypeIncidentCreated:
+		return "🚨"
+	case notification.NotificationTypeIncidentUpdated:
+		return "📋"
+	case notification.NotificationTypeIncidentResolved:
+		return "✅"
+	case notification.NotificationTypeIncidentEscalated:
+		return "📟"
+	default:
+		return "📢"
+	}
+}
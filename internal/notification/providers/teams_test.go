@@ -0,0 +1,177 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/notification"
+)
+
+func TestNewTeamsProvider(t *testing.T) {
+	provider := NewTeamsProvider(nil)
+
+	if provider == nil {
+		t.Fatal("Expected provider to be created")
+	}
+
+	if provider.GetType() != notification.ProviderTypeTeams {
+		t.Fatalf("Expected provider type %v, got %v", notification.ProviderTypeTeams, provider.GetType())
+	}
+
+	if provider.IsEnabled() {
+		t.Fatal("Expected provider to be disabled by default")
+	}
+}
+
+func TestTeamsProviderConfigure(t *testing.T) {
+	provider := NewTeamsProvider(slog.Default())
+
+	config := notification.ProviderConfig{
+		Type:    notification.ProviderTypeTeams,
+		Enabled: true,
+		Settings: map[string]interface{}{
+			"webhook_url": "https://prod-00.westus.logic.azure.com/workflows/0000/triggers/manual/paths/invoke",
+		},
+	}
+
+	err := provider.Configure(config)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !provider.IsEnabled() {
+		t.Fatal("Expected provider to be enabled after configuration")
+	}
+}
+
+func TestTeamsProviderConfigureInvalidType(t *testing.T) {
+	provider := NewTeamsProvider(nil)
+
+	config := notification.ProviderConfig{
+		Type:     notification.ProviderTypeSlack,
+		Enabled:  true,
+		Settings: map[string]interface{}{},
+	}
+
+	err := provider.Configure(config)
+	if err == nil {
+		t.Fatal("Expected error for invalid provider type")
+	}
+}
+
+func TestTeamsProviderConfigureMissingURL(t *testing.T) {
+	provider := NewTeamsProvider(nil)
+
+	config := notification.ProviderConfig{
+		Type:     notification.ProviderTypeTeams,
+		Enabled:  true,
+		Settings: map[string]interface{}{},
+	}
+
+	err := provider.Configure(config)
+	if err == nil {
+		t.Fatal("Expected error for missing webhook_url")
+	}
+}
+
+func TestTeamsProviderSendNotificationDisabled(t *testing.T) {
+	provider := NewTeamsProvider(nil)
+
+	result := provider.SendNotification(context.Background(), notification.NotificationData{
+		Type:      notification.NotificationTypeEndpointDown,
+		Title:     "Test",
+		Timestamp: time.Now(),
+	})
+
+	if result.Success {
+		t.Fatal("Expected failure when provider is disabled")
+	}
+}
+
+func TestTeamsProviderSendsAdaptiveCard(t *testing.T) {
+	var received TeamsMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		// Workflows accept the message asynchronously
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	provider := NewTeamsProvider(nil)
+	err := provider.Configure(notification.ProviderConfig{
+		Type:     notification.ProviderTypeTeams,
+		Enabled:  true,
+		Settings: map[string]interface{}{"webhook_url": server.URL},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	incidentID := uuid.New()
+	result := provider.SendNotification(context.Background(), notification.NotificationData{
+		Type:       notification.NotificationTypeIncidentCreated,
+		Title:      "API outage",
+		Message:    "API is failing",
+		Severity:   "critical",
+		IncidentID: &incidentID,
+		URL:        "https://status.example.com/admin/incidents/" + incidentID.String(),
+		Endpoints:  []notification.EndpointInfo{{ID: uuid.New(), Name: "API"}, {ID: uuid.New(), Name: "Web"}},
+		Timestamp:  time.Now(),
+	})
+	if !result.Success {
+		t.Fatalf("Expected success, got %v", result.Error)
+	}
+
+	if received.Type != "message" || len(received.Attachments) != 1 {
+		t.Fatalf("Expected a message with one attachment, got %+v", received)
+	}
+	attachment := received.Attachments[0]
+	if attachment.ContentType != "application/vnd.microsoft.card.adaptive" {
+		t.Errorf("Expected an Adaptive Card, got %s", attachment.ContentType)
+	}
+
+	card := attachment.Content
+	if card.Body[0].Style != "attention" {
+		t.Errorf("Expected critical incidents to use the attention style, got %s", card.Body[0].Style)
+	}
+
+	facts := make(map[string]string)
+	for _, element := range card.Body {
+		for _, fact := range element.Facts {
+			facts[fact.Title] = fact.Value
+		}
+	}
+	if facts["Endpoints"] != "API, Web" || facts["Incident ID"] != incidentID.String() {
+		t.Errorf("Expected endpoint and incident facts, got %v", facts)
+	}
+
+	if len(card.Actions) != 1 || card.Actions[0].Type != "Action.OpenUrl" || card.Actions[0].Title != "View incident" {
+		t.Fatalf("Expected a link back to the incident, got %+v", card.Actions)
+	}
+}
+
+func TestTeamsProviderColor(t *testing.T) {
+	provider := NewTeamsProvider(nil)
+
+	tests := []struct {
+		data     notification.NotificationData
+		expected string
+	}{
+		{notification.NotificationData{Type: notification.NotificationTypeIncidentResolved, Severity: "critical"}, "good"},
+		{notification.NotificationData{Type: notification.NotificationTypeIncidentCreated, Severity: "medium"}, "warning"},
+		{notification.NotificationData{Type: notification.NotificationTypeIncidentCreated, Severity: "low"}, "accent"},
+		{notification.NotificationData{Type: notification.NotificationTypeEndpointDown}, "attention"},
+	}
+
+	for _, tt := range tests {
+		if got := provider.getColor(tt.data); got != tt.expected {
+			t.Errorf("Expected %s for %s/%s, got %s", tt.expected, tt.data.Type, tt.data.Severity, got)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/i4o-oss/watchtower/internal/notification"
+)
+
+// DefaultTelegramAPIURL is the Telegram Bot API base URL
+const DefaultTelegramAPIURL = "https://api.telegram.org"
+
+// TelegramProvider implements notification.NotificationProvider for the Telegram Bot API,
+// sending each notification to every configured chat
+type TelegramProvider struct {
+	enabled    bool
+	botToken   string
+	chatIDs    []string
+	apiURL     string
+	logger     *slog.Logger
+	httpClient *http.Client
+}
+
+// TelegramConfig contains configuration for the Telegram provider
+type TelegramConfig struct {
+	BotToken string   `json:"bot_token"`
+	ChatIDs  []string `json:"chat_ids"`
+	APIURL   string   `json:"api_url"`
+}
+
+// TelegramMessage represents a Bot API sendMessage request
+type TelegramMessage struct {
+	ChatID                string `json:"chat_id"`
+	Text                  string `json:"text"`
+	ParseMode             string `json:"parse_mode"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+}
+
+// TelegramResponse represents a Bot API response
+type TelegramResponse struct {
+	OK          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+}
+
+// NewTelegramProvider creates a new Telegram notification provider
+func NewTelegramProvider(logger *slog.Logger) *TelegramProvider {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &TelegramProvider{
+		enabled: false,
+		logger:  logger,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// GetType returns the provider type
+func (t *TelegramProvider) GetType() notification.ProviderType {
+	return notification.ProviderTypeTelegram
+}
+
+// IsEnabled returns whether this provider is enabled
+func (t *TelegramProvider) IsEnabled() bool {
+	return t.enabled
+}
+
+// Configure sets up the provider with the given configuration
+func (t *TelegramProvider) Configure(config notification.ProviderConfig) error {
+	if config.Type != notification.ProviderTypeTelegram {
+		return fmt.Errorf("invalid provider type: expected %s, got %s", notification.ProviderTypeTelegram, config.Type)
+	}
+
+	// Extract bot token
+	botToken, ok := config.Settings["bot_token"].(string)
+	if !ok || botToken == "" {
+		return fmt.Errorf("bot_token is required")
+	}
+
+	// Extract chat IDs, numeric IDs or @channel usernames
+	chatIDs, err := telegramChatIDs(config.Settings["chat_ids"])
+	if err != nil {
+		return err
+	}
+	if len(chatIDs) == 0 {
+		return fmt.Errorf("at least one chat ID is required in chat_ids")
+	}
+
+	// Extract optional API URL, useful for testing against a local stub
+	apiURL, _ := config.Settings["api_url"].(string)
+	if apiURL == "" {
+		apiURL = DefaultTelegramAPIURL
+	}
+	if !strings.HasPrefix(apiURL, "http://") && !strings.HasPrefix(apiURL, "https://") {
+		return fmt.Errorf("api_url must be an http or https URL")
+	}
+
+	t.botToken = botToken
+	t.chatIDs = chatIDs
+	t.apiURL = strings.TrimRight(apiURL, "/")
+	t.enabled = config.Enabled
+
+	t.logger.Info("Telegram provider configured",
+		"api_url", t.apiURL,
+		"chat_ids_count", len(chatIDs),
+		"enabled", t.enabled)
+
+	return nil
+}
+
+// telegramChatIDs reads chat IDs given as a list or a comma separated string. JSON numbers are
+// accepted as Telegram chat IDs are numeric
+func telegramChatIDs(value interface{}) ([]string, error) {
+	var chatIDs []string
+	add := func(chatID string) {
+		if chatID = strings.TrimSpace(chatID); chatID != "" {
+			chatIDs = append(chatIDs, chatID)
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil, fmt.Errorf("chat_ids is required")
+	case string:
+		for _, chatID := range strings.Split(v, ",") {
+			add(chatID)
+		}
+	case []string:
+		for _, chatID := range v {
+			add(chatID)
+		}
+	case []interface{}:
+		for _, chatID := range v {
+			switch id := chatID.(type) {
+			case string:
+				add(id)
+			case float64:
+				add(strconv.FormatInt(int64(id), 10))
+			default:
+				return nil, fmt.Errorf("chat_ids must contain strings or numbers")
+			}
+		}
+	default:
+		return nil, fmt.Errorf("chat_ids must be an array of chat IDs")
+	}
+
+	return chatIDs, nil
+}
+
+// SendNotification sends the notification to every configured chat
+func (t *TelegramProvider) SendNotification(ctx context.Context, data notification.NotificationData) notification.DeliveryResult {
+	if !t.enabled {
+		return notification.DeliveryResult{
+			Success:   false,
+			Error:     fmt.Errorf("telegram provider is disabled"),
+			Timestamp: time.Now(),
+			Details:   "Provider disabled",
+		}
+	}
+
+	text := t.buildMessageText(data)
+
+	// Every chat is attempted, failures are reported together
+	var failures []string
+	for _, chatID := range t.chatIDs {
+		if err := t.sendMessage(ctx, chatID, text); err != nil {
+			t.logger.Error("Failed to send Telegram notification",
+				"chat_id", chatID,
+				"error", err)
+			failures = append(failures, fmt.Sprintf("%s: %s", chatID, err.Error()))
+		}
+	}
+
+	if len(failures) > 0 {
+		return notification.DeliveryResult{
+			Success:   false,
+			Error:     fmt.Errorf("failed to send Telegram notification to %d of %d chats", len(failures), len(t.chatIDs)),
+			Timestamp: time.Now(),
+			Details:   fmt.Sprintf("Telegram sendMessage failed: %s", strings.Join(failures, "; ")),
+		}
+	}
+
+	t.logger.Info("Telegram notification sent successfully",
+		"chats", len(t.chatIDs),
+		"type", data.Type)
+
+	return notification.DeliveryResult{
+		Success:   true,
+		Timestamp: time.Now(),
+		Details:   fmt.Sprintf("Telegram notification sent to %d chats", len(t.chatIDs)),
+	}
+}
+
+// TestConnection tests if the provider is properly configured and can send messages
+func (t *TelegramProvider) TestConnection(ctx context.Context) error {
+	if !t.enabled {
+		return fmt.Errorf("telegram provider is disabled")
+	}
+
+	// Create a test message
+	testData := notification.NotificationData{
+		Type:      notification.NotificationTypeEndpointDown,
+		Title:     "Test Notification",
+		Message:   "This is a test notification from Watchtower",
+		Severity:  "info",
+		Timestamp: time.Now(),
+	}
+
+	text := t.buildMessageText(testData)
+	for _, chatID := range t.chatIDs {
+		if err := t.sendMessage(ctx, chatID, text); err != nil {
+			return fmt.Errorf("telegram test failed for chat %s: %w", chatID, err)
+		}
+	}
+
+	return nil
+}
+
+// buildMessageText builds the HTML formatted message text from notification data
+func (t *TelegramProvider) buildMessageText(data notification.NotificationData) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s <b>%s</b>\n", t.getEmojiForType(data.Type), html.EscapeString(data.Title))
+	if data.Message != "" {
+		fmt.Fprintf(&b, "\n%s\n", html.EscapeString(data.Message))
+	}
+
+	b.WriteString("\n")
+	if data.Severity != "" {
+		fmt.Fprintf(&b, "<b>Severity:</b> %s\n", html.EscapeString(data.Severity))
+	}
+	for _, detail := range data.Details() {
+		fmt.Fprintf(&b, "<b>%s:</b> %s\n", detail.Label, html.EscapeString(detail.Value))
+	}
+	if data.IncidentID != nil {
+		fmt.Fprintf(&b, "<b>Incident ID:</b> <code>%s</code>\n", data.IncidentID.String())
+	}
+	fmt.Fprintf(&b, "<b>Time:</b> %s\n", data.Timestamp.UTC().Format("2006-01-02 15:04:05 UTC"))
+
+	if data.URL != "" {
+		fmt.Fprintf(&b, "\n<a href=\"%s\">View details</a>", html.EscapeString(data.URL))
+	}
+
+	return b.String()
+}
+
+// sendMessage sends a message to a single chat
+func (t *TelegramProvider) sendMessage(ctx context.Context, chatID, text string) error {
+	message := TelegramMessage{
+		ChatID:                chatID,
+		Text:                  text,
+		ParseMode:             "HTML",
+		DisableWebPagePreview: true,
+	}
+
+	// Marshal message to JSON
+	jsonPayload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Telegram message: %w", err)
+	}
+
+	// Create HTTP request
+	endpoint := fmt.Sprintf("%s/bot%s/sendMessage", t.apiURL, t.botToken)
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send request, the URL carries the bot token so it is kept out of the error
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %w", redactURLError(err))
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, _ := io.ReadAll(resp.Body)
+
+	var response TelegramResponse
+	json.Unmarshal(bodyBytes, &response)
+
+	// Check response status
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || !response.OK {
+		if response.Description != "" {
+			return fmt.Errorf("Telegram returned status %d: %s", resp.StatusCode, response.Description)
+		}
+		return fmt.Errorf("Telegram returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	return nil
+}
+
+// redactURLError strips the request URL from HTTP client errors
+func redactURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// getEmojiForType returns the appropriate emoji for the notification type
+func (t *TelegramProvider) getEmojiForType(notificationType notification.NotificationType) string {
+	switch notificationType {
+	case notification.NotificationTypeEndpointDown:
+		return "🔴"
+	case notification.NotificationTypeEndpointUp:
+		return "🟢"
+	case notification.NotificationTypeIncidentCreated:
+		return "🚨"
+	case notification.NotificationTypeIncidentUpdated:
+		return "📋"
+	case notification.NotificationTypeIncidentResolved:
+		return "✅"
+	case notification.NotificationTypeIncidentEscalated:
+		return "📟"
+	default:
+		return "📢"
+	}
+}
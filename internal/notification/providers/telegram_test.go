@@ -0,0 +1,175 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/i4o-oss/watchtower/internal/notification"
+)
+
+// telegramStub records the messages posted to it and answers like the Bot API
+type telegramStub struct {
+	mu       sync.Mutex
+	paths    []string
+	messages []TelegramMessage
+	failChat string
+}
+
+func (s *telegramStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var message TelegramMessage
+	json.NewDecoder(r.Body).Decode(&message)
+
+	s.mu.Lock()
+	s.paths = append(s.paths, r.URL.Path)
+	s.messages = append(s.messages, message)
+	s.mu.Unlock()
+
+	if message.ChatID == s.failChat {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(TelegramResponse{OK: false, ErrorCode: 400, Description: "Bad Request: chat not found"})
+		return
+	}
+	json.NewEncoder(w).Encode(TelegramResponse{OK: true})
+}
+
+func newTestTelegramProvider(t *testing.T, stub *telegramStub) *TelegramProvider {
+	t.Helper()
+
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+
+	provider := NewTelegramProvider(nil)
+	err := provider.Configure(notification.ProviderConfig{
+		Type:    notification.ProviderTypeTelegram,
+		Enabled: true,
+		Settings: map[string]interface{}{
+			"bot_token": "123456:ABC-DEF",
+			"chat_ids":  []interface{}{float64(-1001234567890), "@watchtower_alerts"},
+			"api_url":   server.URL,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return provider
+}
+
+func TestNewTelegramProvider(t *testing.T) {
+	provider := NewTelegramProvider(nil)
+
+	if provider == nil {
+		t.Fatal("Expected provider to be created")
+	}
+
+	if provider.GetType() != notification.ProviderTypeTelegram {
+		t.Fatalf("Expected provider type %v, got %v", notification.ProviderTypeTelegram, provider.GetType())
+	}
+
+	if provider.IsEnabled() {
+		t.Fatal("Expected provider to be disabled by default")
+	}
+}
+
+func TestTelegramProviderConfigureInvalidType(t *testing.T) {
+	provider := NewTelegramProvider(nil)
+
+	err := provider.Configure(notification.ProviderConfig{
+		Type:     notification.ProviderTypeSlack,
+		Enabled:  true,
+		Settings: map[string]interface{}{},
+	})
+	if err == nil {
+		t.Fatal("Expected error for invalid provider type")
+	}
+}
+
+func TestTelegramProviderConfigureValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+	}{
+		{"missing bot token", map[string]interface{}{"chat_ids": "42"}},
+		{"missing chat IDs", map[string]interface{}{"bot_token": "123456:ABC-DEF"}},
+		{"empty chat IDs", map[string]interface{}{"bot_token": "123456:ABC-DEF", "chat_ids": " , "}},
+		{"invalid API URL", map[string]interface{}{"bot_token": "123456:ABC-DEF", "chat_ids": "42", "api_url": "ftp://example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewTelegramProvider(nil)
+			err := provider.Configure(notification.ProviderConfig{Type: notification.ProviderTypeTelegram, Enabled: true, Settings: tt.settings})
+			if err == nil {
+				t.Fatal("Expected configuration error")
+			}
+		})
+	}
+}
+
+func TestTelegramProviderSendNotificationDisabled(t *testing.T) {
+	provider := NewTelegramProvider(nil)
+
+	result := provider.SendNotification(context.Background(), notification.NotificationData{
+		Type:      notification.NotificationTypeEndpointDown,
+		Title:     "Test",
+		Timestamp: time.Now(),
+	})
+
+	if result.Success {
+		t.Fatal("Expected failure when provider is disabled")
+	}
+}
+
+func TestTelegramProviderSendsToEveryChat(t *testing.T) {
+	stub := &telegramStub{}
+	provider := newTestTelegramProvider(t, stub)
+
+	result := provider.SendNotification(context.Background(), notification.NotificationData{
+		Type:      notification.NotificationTypeEndpointDown,
+		Title:     "API <prod> is DOWN",
+		Message:   "Connection refused",
+		Severity:  "critical",
+		Endpoints: []notification.EndpointInfo{{Name: "API"}},
+		Timestamp: time.Now(),
+	})
+	if !result.Success {
+		t.Fatalf("Expected success, got %v", result.Error)
+	}
+
+	if len(stub.messages) != 2 || stub.messages[0].ChatID != "-1001234567890" || stub.messages[1].ChatID != "@watchtower_alerts" {
+		t.Fatalf("Expected a message per chat, got %+v", stub.messages)
+	}
+	if stub.paths[0] != "/bot123456:ABC-DEF/sendMessage" {
+		t.Errorf("Expected the bot's sendMessage method, got %s", stub.paths[0])
+	}
+
+	message := stub.messages[0]
+	if message.ParseMode != "HTML" || !strings.Contains(message.Text, "<b>API &lt;prod&gt; is DOWN</b>") {
+		t.Errorf("Expected an escaped HTML message, got %q", message.Text)
+	}
+}
+
+func TestTelegramProviderReportsFailedChats(t *testing.T) {
+	stub := &telegramStub{failChat: "@watchtower_alerts"}
+	provider := newTestTelegramProvider(t, stub)
+
+	result := provider.SendNotification(context.Background(), notification.NotificationData{
+		Type:      notification.NotificationTypeEndpointUp,
+		Title:     "API is UP",
+		Timestamp: time.Now(),
+	})
+	if result.Success {
+		t.Fatal("Expected failure when a chat cannot be reached")
+	}
+	if !strings.Contains(result.Details, "chat not found") || strings.Contains(result.Details, "ABC-DEF") {
+		t.Errorf("Expected the chat's error without the bot token, got %q", result.Details)
+	}
+	if len(stub.messages) != 2 {
+		t.Errorf("Expected every chat to be attempted, got %d", len(stub.messages))
+	}
+}
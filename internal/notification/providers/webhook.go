@@ -3,6 +3,8 @@ package providers
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,22 +12,40 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/i4o-oss/watchtower/internal/notification"
+	"github.com/i4o-oss/watchtower/pkg/webhook"
 )
 
-// WebhookProvider implements notification.NotificationProvider for generic webhook notifications
+// Webhook signing settings. While a secret is rotated the previous one keeps signing requests
+// until the rotation expires
+const (
+	webhookSecretSetting         = "secret"
+	webhookPreviousSecretSetting = "previous_secret"
+	webhookRotationExpiresAt     = "rotation_expires_at"
+)
+
+// WebhookProvider implements notification.NotificationProvider for generic webhook notifications.
+// Requests carry a versioned webhook.Event and are signed when the channel has a secret
 type WebhookProvider struct {
-	enabled    bool
-	webhookURL string
-	headers    map[string]string
-	logger     *slog.Logger
-	httpClient *http.Client
+	enabled           bool
+	webhookURL        string
+	headers           map[string]string
+	secret            string
+	previousSecret    string
+	rotationExpiresAt time.Time
+	now               func() time.Time
+	logger            *slog.Logger
+	httpClient        *http.Client
 }
 
 // WebhookConfig contains configuration for the webhook provider
 type WebhookConfig struct {
-	WebhookURL string            `json:"webhook_url"`
-	Headers    map[string]string `json:"headers"`
+	WebhookURL        string            `json:"webhook_url"`
+	Headers           map[string]string `json:"headers"`
+	Secret            string            `json:"secret"`
+	PreviousSecret    string            `json:"previous_secret"`
+	RotationExpiresAt string            `json:"rotation_expires_at"`
 }
 
 // NewWebhookProvider creates a new webhook notification provider
@@ -37,6 +57,7 @@ func NewWebhookProvider(logger *slog.Logger) *WebhookProvider {
 	return &WebhookProvider{
 		enabled: false,
 		headers: make(map[string]string),
+		now:     time.Now,
 		logger:  logger,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
@@ -86,13 +107,34 @@ func (w *WebhookProvider) Configure(config notification.ProviderConfig) error {
 		headers["Content-Type"] = "application/json"
 	}
 
+	// Extract signing secrets (optional)
+	secret, _ := config.Settings[webhookSecretSetting].(string)
+	previousSecret, _ := config.Settings[webhookPreviousSecretSetting].(string)
+
+	var rotationExpiresAt time.Time
+	if previousSecret != "" {
+		if secret == "" {
+			return fmt.Errorf("previous_secret requires a secret")
+		}
+		expiresAt, _ := config.Settings[webhookRotationExpiresAt].(string)
+		parsed, err := time.Parse(time.RFC3339, expiresAt)
+		if err != nil {
+			return fmt.Errorf("rotation_expires_at must be an RFC 3339 time when previous_secret is set")
+		}
+		rotationExpiresAt = parsed
+	}
+
 	w.webhookURL = webhookURL
 	w.headers = headers
+	w.secret = secret
+	w.previousSecret = previousSecret
+	w.rotationExpiresAt = rotationExpiresAt
 	w.enabled = config.Enabled
 
 	w.logger.Info("Webhook provider configured",
 		"webhook_url", webhookURL,
 		"headers_count", len(headers),
+		"signed", secret != "",
 		"enabled", w.enabled)
 
 	return nil
@@ -162,33 +204,70 @@ func (w *WebhookProvider) TestConnection(ctx context.Context) error {
 	return nil
 }
 
-// buildWebhookPayload builds the JSON payload for the webhook
-func (w *WebhookProvider) buildWebhookPayload(data notification.NotificationData) map[string]interface{} {
-	payload := map[string]interface{}{
-		"type":      string(data.Type),
-		"title":     data.Title,
-		"message":   data.Message,
-		"timestamp": data.Timestamp.Format(time.RFC3339),
+// buildWebhookPayload builds the versioned event sent as the webhook body. Queued notifications
+// keep their event ID across retries so receivers can deduplicate
+func (w *WebhookProvider) buildWebhookPayload(data notification.NotificationData) webhook.Event {
+	eventID := uuid.New()
+	if data.EventID != nil {
+		eventID = *data.EventID
 	}
 
-	if data.URL != "" {
-		payload["url"] = data.URL
+	event := webhook.Event{
+		Version:    webhook.SchemaVersion,
+		ID:         eventID,
+		Type:       string(data.Type),
+		OccurredAt: data.Timestamp.UTC(),
+		Title:      data.Title,
+		Message:    data.Message,
+		Severity:   data.Severity,
+		URL:        data.URL,
+		Metadata:   data.Metadata,
 	}
-	if data.Severity != "" {
-		payload["severity"] = data.Severity
+
+	endpoints := make([]webhook.Endpoint, 0, len(data.Endpoints))
+	for _, endpoint := range data.Endpoints {
+		endpoints = append(endpoints, webhook.Endpoint{ID: endpoint.ID, Name: endpoint.Name, URL: endpoint.URL, Tags: endpoint.Tags})
 	}
-	if data.EndpointID != nil {
-		payload["endpoint_id"] = *data.EndpointID
+
+	switch {
+	case data.IncidentID != nil:
+		event.Incident = &webhook.Incident{
+			ID:        *data.IncidentID,
+			Title:     data.Title,
+			Severity:  data.Severity,
+			Status:    data.IncidentStatus,
+			Endpoints: endpoints,
+		}
+	case len(endpoints) > 0:
+		event.Endpoint = &endpoints[0]
 	}
-	if data.IncidentID != nil {
-		payload["incident_id"] = *data.IncidentID
+
+	if data.Check != nil {
+		event.Check = &webhook.Check{
+			StatusCode:     data.Check.StatusCode,
+			ResponseTimeMs: data.Check.ResponseTimeMs,
+			Error:          data.Check.Error,
+		}
 	}
 
-	return payload
+	return event
+}
+
+// signingSecrets returns the secrets requests are signed with, including the previous secret
+// until its rotation expires
+func (w *WebhookProvider) signingSecrets() []string {
+	if w.secret == "" {
+		return nil
+	}
+	secrets := []string{w.secret}
+	if w.previousSecret != "" && w.now().Before(w.rotationExpiresAt) {
+		secrets = append(secrets, w.previousSecret)
+	}
+	return secrets
 }
 
 // sendWebhookRequest sends the HTTP webhook request
-func (w *WebhookProvider) sendWebhookRequest(ctx context.Context, payload map[string]interface{}) error {
+func (w *WebhookProvider) sendWebhookRequest(ctx context.Context, payload webhook.Event) error {
 	// Marshal payload to JSON
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
@@ -205,6 +284,13 @@ func (w *WebhookProvider) sendWebhookRequest(ctx context.Context, payload map[st
 	for key, value := range w.headers {
 		req.Header.Set(key, value)
 	}
+	req.Header.Set(webhook.EventIDHeader, payload.ID.String())
+	req.Header.Set(webhook.EventTypeHeader, payload.Type)
+
+	// Sign at send time so every attempt carries a fresh timestamp
+	if secrets := w.signingSecrets(); len(secrets) > 0 {
+		req.Header.Set(webhook.SignatureHeader, webhook.Sign(jsonPayload, w.now(), secrets...))
+	}
 
 	// Send request
 	resp, err := w.httpClient.Do(req)
@@ -221,3 +307,34 @@ func (w *WebhookProvider) sendWebhookRequest(ctx context.Context, payload map[st
 
 	return nil
 }
+
+// GenerateWebhookSecret returns a new random signing secret
+func GenerateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
+
+// RotateWebhookSecret replaces the signing secret in a webhook channel's settings with a new
+// one and returns it. The replaced secret keeps signing requests for the grace period so
+// receivers can switch over without rejecting deliveries
+func RotateWebhookSecret(settings map[string]interface{}, gracePeriod time.Duration, now time.Time) (string, error) {
+	secret, err := GenerateWebhookSecret()
+	if err != nil {
+		return "", err
+	}
+
+	current, _ := settings[webhookSecretSetting].(string)
+	if current != "" && gracePeriod > 0 {
+		settings[webhookPreviousSecretSetting] = current
+		settings[webhookRotationExpiresAt] = now.Add(gracePeriod).UTC().Format(time.RFC3339)
+	} else {
+		delete(settings, webhookPreviousSecretSetting)
+		delete(settings, webhookRotationExpiresAt)
+	}
+	settings[webhookSecretSetting] = secret
+
+	return secret, nil
+}
@@ -2,11 +2,16 @@ package providers
 
 import (
 	"context"
+	"io"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/i4o-oss/watchtower/internal/notification"
+	"github.com/i4o-oss/watchtower/pkg/webhook"
 )
 
 func TestNewWebhookProvider(t *testing.T) {
@@ -101,3 +106,106 @@ func TestWebhookProviderSendNotificationDisabled(t *testing.T) {
 		t.Fatal("Expected error for disabled provider")
 	}
 }
+
+func TestWebhookProviderSendsSignedEvent(t *testing.T) {
+	var body []byte
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		headers = r.Header
+	}))
+	defer server.Close()
+
+	provider := NewWebhookProvider(nil)
+	err := provider.Configure(notification.ProviderConfig{
+		Type:    notification.ProviderTypeWebhook,
+		Enabled: true,
+		Settings: map[string]interface{}{
+			"webhook_url": server.URL,
+			"secret":      "whsec_current",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	eventID, incidentID, endpointID := uuid.New(), uuid.New(), uuid.New()
+	result := provider.SendNotification(context.Background(), notification.NotificationData{
+		Type:       notification.NotificationTypeIncidentCreated,
+		Title:      "API outage",
+		Severity:   "high",
+		EventID:    &eventID,
+		IncidentID: &incidentID,
+		Endpoints:  []notification.EndpointInfo{{ID: endpointID, Name: "API"}},
+		Timestamp:  time.Now(),
+	})
+	if !result.Success {
+		t.Fatalf("Expected success, got %v", result.Error)
+	}
+
+	if headers.Get(webhook.EventIDHeader) != eventID.String() || headers.Get(webhook.EventTypeHeader) != "incident_created" {
+		t.Errorf("Expected event headers, got %v", headers)
+	}
+
+	event, err := webhook.ParseEvent(body, headers.Get(webhook.SignatureHeader), "whsec_current")
+	if err != nil {
+		t.Fatalf("Expected a verifiable event, got %v", err)
+	}
+	if event.ID != eventID || event.Incident == nil || event.Incident.ID != incidentID || event.Incident.Endpoints[0].ID != endpointID {
+		t.Errorf("Expected the incident event, got %+v", event)
+	}
+}
+
+func TestWebhookProviderSignsWithPreviousSecretDuringRotation(t *testing.T) {
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	settings := map[string]interface{}{"webhook_url": "https://webhook.example.com/notify", "secret": "whsec_old"}
+
+	secret, err := RotateWebhookSecret(settings, 24*time.Hour, now)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if secret == "whsec_old" || settings["secret"] != secret || settings["previous_secret"] != "whsec_old" {
+		t.Fatalf("Expected the old secret to be kept as previous, got %v", settings)
+	}
+
+	provider := NewWebhookProvider(nil)
+	if err := provider.Configure(notification.ProviderConfig{Type: notification.ProviderTypeWebhook, Enabled: true, Settings: settings}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	provider.now = func() time.Time { return now.Add(time.Hour) }
+	if secrets := provider.signingSecrets(); len(secrets) != 2 || secrets[1] != "whsec_old" {
+		t.Errorf("Expected both secrets during rotation, got %v", secrets)
+	}
+
+	provider.now = func() time.Time { return now.Add(25 * time.Hour) }
+	if secrets := provider.signingSecrets(); len(secrets) != 1 || secrets[0] != secret {
+		t.Errorf("Expected only the new secret after rotation, got %v", secrets)
+	}
+}
+
+func TestWebhookProviderPayloadCarriesCheck(t *testing.T) {
+	provider := NewWebhookProvider(nil)
+
+	endpointID := uuid.New()
+	statusCode, responseTime := 503, 1250
+	event := provider.buildWebhookPayload(notification.NotificationData{
+		Type:       notification.NotificationTypeEndpointDown,
+		Title:      "API is down",
+		EndpointID: &endpointID,
+		URL:        "https://status.example.com/admin/endpoints/" + endpointID.String(),
+		Endpoints:  []notification.EndpointInfo{{ID: endpointID, Name: "API", URL: "https://api.example.com/health"}},
+		Check:      &notification.CheckInfo{StatusCode: &statusCode, ResponseTimeMs: &responseTime, Error: "unexpected status"},
+		Timestamp:  time.Now(),
+	})
+
+	if event.Endpoint == nil || event.Endpoint.ID != endpointID || event.Endpoint.URL != "https://api.example.com/health" {
+		t.Errorf("Expected the endpoint with its URL, got %+v", event.Endpoint)
+	}
+	if event.Check == nil || *event.Check.StatusCode != 503 || *event.Check.ResponseTimeMs != 1250 || event.Check.Error != "unexpected status" {
+		t.Errorf("Expected the check context, got %+v", event.Check)
+	}
+	if event.URL != "https://status.example.com/admin/endpoints/"+endpointID.String() {
+		t.Errorf("Expected the deep link, got %q", event.URL)
+	}
+}
@@ -0,0 +1,246 @@
+package notification
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+// Reasons recorded on deliveries held back by quiet hours
+const (
+	SuppressedQuietHours = "quiet_hours" // held or dropped during quiet hours
+	SuppressedDigested   = "digested"    // held and later sent as part of a digest
+)
+
+// maxDigestLines caps the notifications listed in a digest message
+const maxDigestLines = 50
+
+// Digest modes of quiet hours
+const (
+	DigestNone   = ""
+	DigestHourly = "hourly"
+	DigestDaily  = "daily"
+)
+
+// Severities in ascending order
+var Severities = []string{"info", "low", "medium", "high", "critical"}
+
+// severityRank returns the position of a severity in Severities, unknown severities rank lowest
+func severityRank(severity string) int {
+	return slices.Index(Severities, strings.ToLower(severity))
+}
+
+// QuietHoursConfig describes a channel's quiet hours as they are stored
+type QuietHoursConfig struct {
+	Start       string // HH:MM
+	End         string // HH:MM, before Start for windows spanning midnight
+	Timezone    string // IANA name, UTC when empty
+	MinSeverity string // notifications at or above it are never held
+	Digest      string // DigestNone, DigestHourly or DigestDaily
+}
+
+// QuietHours decides which of a channel's notifications are held back. Notifications below the
+// minimum severity are held while the quiet window is open. Without a digest held
+// notifications are dropped, with one they are sent together: hourly digests go out on the
+// hour and daily digests when the quiet window ends, or at midnight without a window. When a
+// minimum severity is set, digests also batch notifications below it outside the quiet window.
+// Digests never go out while the window is open
+type QuietHours struct {
+	start       int // minutes after midnight, -1 without a window
+	end         int
+	location    *time.Location
+	minSeverity string
+	digest      string
+}
+
+// NewQuietHours validates the configuration and returns the quiet hours it describes
+func NewQuietHours(config QuietHoursConfig) (*QuietHours, error) {
+	location := time.UTC
+	if config.Timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(config.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone: %s", config.Timezone)
+		}
+	}
+
+	quiet := &QuietHours{start: -1, end: -1, location: location, digest: config.Digest}
+
+	switch {
+	case config.Start == "" && config.End == "":
+		if config.Digest == DigestNone {
+			return nil, fmt.Errorf("start and end are required without a digest")
+		}
+	case config.Start == "" || config.End == "":
+		return nil, fmt.Errorf("start and end must be set together")
+	default:
+		var err error
+		if quiet.start, err = parseTimeOfDay(config.Start); err != nil {
+			return nil, fmt.Errorf("invalid start: %w", err)
+		}
+		if quiet.end, err = parseTimeOfDay(config.End); err != nil {
+			return nil, fmt.Errorf("invalid end: %w", err)
+		}
+		if quiet.start == quiet.end {
+			return nil, fmt.Errorf("start and end must differ")
+		}
+	}
+
+	if config.MinSeverity != "" {
+		if severityRank(config.MinSeverity) < 0 {
+			return nil, fmt.Errorf("min severity must be one of: %s", strings.Join(Severities, ", "))
+		}
+		quiet.minSeverity = strings.ToLower(config.MinSeverity)
+	}
+
+	if !slices.Contains([]string{DigestNone, DigestHourly, DigestDaily}, config.Digest) {
+		return nil, fmt.Errorf("digest must be hourly, daily or empty")
+	}
+	// Otherwise every notification would wait for the digest
+	if quiet.start < 0 && quiet.minSeverity == "" {
+		return nil, fmt.Errorf("min severity is required for a digest without start and end")
+	}
+
+	return quiet, nil
+}
+
+// parseTimeOfDay parses HH:MM into minutes after midnight
+func parseTimeOfDay(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a HH:MM time", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Digest returns the digest mode
+func (q *QuietHours) Digest() string {
+	return q.digest
+}
+
+// Active returns whether the quiet window is open at the time
+func (q *QuietHours) Active(at time.Time) bool {
+	if q.start < 0 {
+		return false
+	}
+
+	local := at.In(q.location)
+	minute := local.Hour()*60 + local.Minute()
+	if q.start < q.end {
+		return minute >= q.start && minute < q.end
+	}
+	// The window spans midnight
+	return minute >= q.start || minute < q.end
+}
+
+// Holds returns whether the notification is held back at the time, for the next digest or for
+// good without one
+func (q *QuietHours) Holds(data NotificationData, at time.Time) bool {
+	if q.minSeverity == "" {
+		return q.Active(at)
+	}
+	if severityRank(data.Severity) >= severityRank(q.minSeverity) {
+		return false
+	}
+	return q.Active(at) || q.digest != DigestNone
+}
+
+// DigestDue returns whether a digest of notifications held since the time is due
+func (q *QuietHours) DigestDue(since, now time.Time) bool {
+	if q.Active(now) {
+		return false
+	}
+
+	local := now.In(q.location)
+	var boundary time.Time
+	switch q.digest {
+	case DigestHourly:
+		boundary = time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), 0, 0, 0, q.location)
+		// The window may close off the hour, everything held during it is due at once
+		if q.start >= 0 {
+			if end := lastTimeOfDay(local, q.end); end.After(boundary) {
+				boundary = end
+			}
+		}
+	case DigestDaily:
+		minute := 0
+		if q.start >= 0 {
+			minute = q.end
+		}
+		boundary = lastTimeOfDay(local, minute)
+	default:
+		// Without a digest nothing is held, leftovers go out at once
+		return true
+	}
+
+	return since.Before(boundary)
+}
+
+// lastTimeOfDay returns the latest time at or before the local time that is the given minutes
+// after midnight
+func lastTimeOfDay(local time.Time, minute int) time.Time {
+	last := time.Date(local.Year(), local.Month(), local.Day(), minute/60, minute%60, 0, 0, local.Location())
+	if last.After(local) {
+		last = last.AddDate(0, 0, -1)
+	}
+	return last
+}
+
+// digestNotification summarizes the held deliveries of a channel, oldest first, in a single
+// notification with the highest severity among them
+func digestNotification(channel *Channel, held []data.NotificationDelivery, now time.Time) NotificationData {
+	location := time.UTC
+	if channel.QuietHours != nil {
+		location = channel.QuietHours.location
+	}
+
+	noun := "notifications"
+	if len(held) == 1 {
+		noun = "notification"
+	}
+
+	since := held[0].CreatedAt
+	lines := []string{fmt.Sprintf("%d %s held since %s:", len(held), noun, since.In(location).Format("02 Jan 15:04 MST"))}
+	severity := ""
+	eventTypes := make(map[string]int)
+	for i, delivery := range held {
+		if severityRank(delivery.Severity) > severityRank(severity) {
+			severity = strings.ToLower(delivery.Severity)
+		}
+		eventTypes[delivery.EventType]++
+
+		if i == maxDigestLines {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(held)-maxDigestLines))
+		}
+		if i >= maxDigestLines {
+			continue
+		}
+		label := delivery.Severity
+		if label == "" {
+			label = delivery.EventType
+		}
+		lines = append(lines, fmt.Sprintf("- %s [%s] %s", delivery.CreatedAt.In(location).Format("02 Jan 15:04"), label, delivery.Title))
+	}
+
+	types := make([]string, 0, len(eventTypes))
+	for eventType := range eventTypes {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+
+	return NotificationData{
+		Type:      NotificationTypeDigest,
+		Title:     fmt.Sprintf("Digest: %d %s", len(held), noun),
+		Message:   strings.Join(lines, "\n"),
+		Severity:  severity,
+		Timestamp: now,
+		Metadata: map[string]interface{}{
+			"notifications": len(held),
+			"since":         since.Format(time.RFC3339),
+			"event_types":   strings.Join(types, ", "),
+		},
+	}
+}
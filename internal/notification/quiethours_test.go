@@ -0,0 +1,206 @@
+package notification
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+func newQuietOutbox(t *testing.T, config QuietHoursConfig) (*Outbox, *MemoryDeliveryStore, *FlakyProvider, *time.Time) {
+	t.Helper()
+
+	store := NewMemoryDeliveryStore()
+	provider := &FlakyProvider{}
+	outbox, channel := newTestOutbox(provider, store, 3)
+	channel.QuietHours = &config
+	if errs := outbox.service.LoadChannels([]ChannelConfig{channel}); len(errs) > 0 {
+		t.Fatalf("Expected the channel to load, got %v", errs)
+	}
+
+	// 23:00 in Berlin
+	now := time.Date(2025, 9, 1, 21, 0, 0, 0, time.UTC)
+	outbox.now = func() time.Time { return now }
+	return outbox, store, provider, &now
+}
+
+func TestQuietHoursWindow(t *testing.T) {
+	quiet, err := NewQuietHours(QuietHoursConfig{Start: "22:00", End: "07:30", Timezone: "Europe/Berlin"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2025, 9, 1, 21, 59, 0, 0, berlin), false},
+		{time.Date(2025, 9, 1, 22, 0, 0, 0, berlin), true},
+		{time.Date(2025, 9, 2, 3, 0, 0, 0, berlin), true},
+		{time.Date(2025, 9, 2, 7, 29, 0, 0, berlin), true},
+		{time.Date(2025, 9, 2, 7, 30, 0, 0, berlin), false},
+		// 21:00 UTC is 23:00 in Berlin
+		{time.Date(2025, 9, 1, 21, 0, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		if got := quiet.Active(tt.at); got != tt.want {
+			t.Errorf("Expected Active(%s) to be %v", tt.at, tt.want)
+		}
+	}
+}
+
+func TestNewQuietHoursRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config QuietHoursConfig
+		want   string
+	}{
+		{"missing window", QuietHoursConfig{}, "start and end are required"},
+		{"missing end", QuietHoursConfig{Start: "22:00"}, "set together"},
+		{"bad time", QuietHoursConfig{Start: "25:00", End: "07:00"}, "invalid start"},
+		{"empty window", QuietHoursConfig{Start: "07:00", End: "07:00"}, "must differ"},
+		{"bad timezone", QuietHoursConfig{Start: "22:00", End: "07:00", Timezone: "Mars/Olympus"}, "invalid timezone"},
+		{"bad severity", QuietHoursConfig{Start: "22:00", End: "07:00", MinSeverity: "urgent"}, "min severity"},
+		{"bad digest", QuietHoursConfig{Start: "22:00", End: "07:00", Digest: "weekly"}, "digest"},
+		{"digest of everything", QuietHoursConfig{Digest: DigestHourly}, "min severity is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewQuietHours(tt.config)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// A digest without a window batches low severity notifications all day
+	if _, err := NewQuietHours(QuietHoursConfig{MinSeverity: "high", Digest: DigestDaily}); err != nil {
+		t.Errorf("Expected a digest without a window to be valid, got %v", err)
+	}
+}
+
+func TestOutboxSuppressesDuringQuietHours(t *testing.T) {
+	outbox, _, _, now := newQuietOutbox(t, QuietHoursConfig{
+		Start: "22:00", End: "07:00", Timezone: "Europe/Berlin", MinSeverity: "high",
+	})
+
+	low, _ := outbox.Enqueue(NotificationData{Type: NotificationTypeEndpointUp, Title: "API is UP", Severity: "info"})
+	if low[0].Status != data.NotificationDeliverySuppressed || low[0].SuppressedReason != SuppressedQuietHours {
+		t.Errorf("Expected the info notification to be suppressed, got %s (%s)", low[0].Status, low[0].SuppressedReason)
+	}
+
+	// The severity floor bypasses quiet hours
+	critical, _ := outbox.Enqueue(NotificationData{Type: NotificationTypeEndpointDown, Title: "API is DOWN", Severity: "critical"})
+	if critical[0].Status != data.NotificationDeliveryPending {
+		t.Errorf("Expected the critical notification to be queued, got %s", critical[0].Status)
+	}
+
+	// 08:00 in Berlin
+	*now = now.Add(9 * time.Hour)
+	daytime, _ := outbox.Enqueue(NotificationData{Type: NotificationTypeEndpointUp, Title: "API is UP", Severity: "info"})
+	if daytime[0].Status != data.NotificationDeliveryPending {
+		t.Errorf("Expected notifications to be queued outside quiet hours, got %s", daytime[0].Status)
+	}
+}
+
+func TestOutboxSendsRightAwayOutsideQuietHoursWithDigest(t *testing.T) {
+	outbox, _, _, now := newQuietOutbox(t, QuietHoursConfig{
+		Start: "22:00", End: "07:00", Timezone: "Europe/Berlin", Digest: DigestDaily,
+	})
+
+	// 14:00 in Berlin, without a severity floor only the window holds notifications back
+	*now = now.Add(15 * time.Hour)
+	down, _ := outbox.Enqueue(NotificationData{Type: NotificationTypeEndpointDown, Title: "API is DOWN", Severity: "critical"})
+	if down[0].Status != data.NotificationDeliveryPending {
+		t.Errorf("Expected the critical notification to be queued, got %s (%s)", down[0].Status, down[0].SuppressedReason)
+	}
+	up, _ := outbox.Enqueue(NotificationData{Type: NotificationTypeEndpointUp, Title: "API is UP", Severity: "info"})
+	if up[0].Status != data.NotificationDeliveryPending {
+		t.Errorf("Expected the info notification to be queued, got %s (%s)", up[0].Status, up[0].SuppressedReason)
+	}
+}
+
+func TestOutboxSendsDigestWhenQuietHoursEnd(t *testing.T) {
+	outbox, store, provider, now := newQuietOutbox(t, QuietHoursConfig{
+		Start: "22:00", End: "07:30", Timezone: "Europe/Berlin", MinSeverity: "critical", Digest: DigestHourly,
+	})
+
+	for _, severity := range []string{"info", "high"} {
+		deliveries, _ := outbox.Enqueue(NotificationData{Type: NotificationTypeIncidentUpdated, Title: "API outage", Message: severity, Severity: severity})
+		if deliveries[0].Status != data.NotificationDeliveryHeld || deliveries[0].SuppressedReason != SuppressedQuietHours {
+			t.Fatalf("Expected the %s notification to be held, got %s", severity, deliveries[0].Status)
+		}
+		*now = now.Add(time.Hour)
+	}
+
+	// No digest while quiet hours last
+	outbox.enqueueDigests()
+	if outbox.processDue() != 0 {
+		t.Fatalf("Expected no digest during quiet hours")
+	}
+
+	// 07:45 in Berlin, the window closed off the hour
+	*now = time.Date(2025, 9, 2, 5, 45, 0, 0, time.UTC)
+	outbox.enqueueDigests()
+	outbox.processDue()
+
+	if len(provider.received) != 1 {
+		t.Fatalf("Expected a single digest, got %+v", provider.received)
+	}
+	digest := provider.received[0]
+	if digest.Type != NotificationTypeDigest || digest.Title != "Digest: 2 notifications" || digest.Severity != "high" {
+		t.Errorf("Expected a high severity digest of 2 notifications, got %+v", digest)
+	}
+	if !strings.Contains(digest.Message, "01 Sep 23:00 [info] API outage") {
+		t.Errorf("Expected held notifications listed in local time, got %q", digest.Message)
+	}
+
+	digested := 0
+	for _, delivery := range store.deliveries {
+		if delivery.SuppressedReason == SuppressedDigested {
+			digested++
+		}
+	}
+	if digested != 2 {
+		t.Errorf("Expected the held deliveries to be marked digested, got %d", digested)
+	}
+
+	// Outside quiet hours low severity notifications wait for the next hour
+	outbox.Enqueue(NotificationData{Type: NotificationTypeEndpointUp, Title: "API is UP", Severity: "info"})
+	outbox.enqueueDigests()
+	if outbox.processDue() != 0 {
+		t.Fatalf("Expected no digest before the hour")
+	}
+	*now = now.Add(15 * time.Minute)
+	outbox.enqueueDigests()
+	outbox.processDue()
+	if len(provider.received) != 2 || provider.received[1].Title != "Digest: 1 notification" {
+		t.Errorf("Expected the next digest on the hour, got %+v", provider.received)
+	}
+}
+
+func TestQuietHoursDailyDigestDue(t *testing.T) {
+	quiet, err := NewQuietHours(QuietHoursConfig{Start: "20:00", End: "08:00", Digest: DigestDaily})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	since := time.Date(2025, 9, 1, 22, 0, 0, 0, time.UTC)
+	tests := []struct {
+		now  time.Time
+		want bool
+	}{
+		{time.Date(2025, 9, 1, 23, 0, 0, 0, time.UTC), false},
+		{time.Date(2025, 9, 2, 6, 0, 0, 0, time.UTC), false},
+		{time.Date(2025, 9, 2, 8, 0, 0, 0, time.UTC), true},
+		{time.Date(2025, 9, 2, 15, 0, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		if got := quiet.DigestDue(since, tt.now); got != tt.want {
+			t.Errorf("Expected DigestDue at %s to be %v", tt.now, tt.want)
+		}
+	}
+}
@@ -28,6 +28,29 @@ func DefaultRetryConfig() RetryConfig {
 	}
 }
 
+// Delay returns the backoff before the attempt following the given one
+func (c RetryConfig) Delay(attempt int) time.Duration {
+	// Calculate exponential backoff
+	delay := float64(c.InitialDelay) * math.Pow(c.BackoffFactor, float64(attempt-1))
+
+	// Apply maximum delay cap
+	if delay > float64(c.MaxDelay) {
+		delay = float64(c.MaxDelay)
+	}
+
+	duration := time.Duration(delay)
+
+	// Add jitter if enabled
+	if c.EnableJitter && duration >= 4 {
+		// Add up to 25% jitter
+		jitterRange := float64(duration) * 0.25
+		jitter := time.Duration(float64(time.Now().UnixNano() % int64(jitterRange)))
+		duration += jitter
+	}
+
+	return duration
+}
+
 // RetryableNotificationSender wraps a NotificationProvider with retry logic
 type RetryableNotificationSender struct {
 	provider NotificationProvider
@@ -125,25 +148,7 @@ func (r *RetryableNotificationSender) SendNotificationWithRetry(ctx context.Cont
 
 // calculateDelay calculates the delay for the next retry attempt
 func (r *RetryableNotificationSender) calculateDelay(attempt int) time.Duration {
-	// Calculate exponential backoff
-	delay := float64(r.config.InitialDelay) * math.Pow(r.config.BackoffFactor, float64(attempt-1))
-
-	// Apply maximum delay cap
-	if delay > float64(r.config.MaxDelay) {
-		delay = float64(r.config.MaxDelay)
-	}
-
-	duration := time.Duration(delay)
-
-	// Add jitter if enabled
-	if r.config.EnableJitter {
-		// Add up to 25% jitter
-		jitterRange := float64(duration) * 0.25
-		jitter := time.Duration(float64(time.Now().UnixNano() % int64(jitterRange)))
-		duration += jitter
-	}
-
-	return duration
+	return r.config.Delay(attempt)
 }
 
 // isRetryableError determines if an error is retryable
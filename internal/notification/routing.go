@@ -0,0 +1,165 @@
+package notification
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RoutingRule sends notifications matching all of its non-empty criteria to its channels.
+// Endpoint IDs and tags are alternatives: a notification matches if any of its endpoints has
+// one of the IDs or one of the tags. Email sent through a rule with on-call schedules goes to
+// whoever is on call instead of the channel's recipients
+type RoutingRule struct {
+	ID                uuid.UUID
+	Name              string
+	EventTypes        []NotificationType
+	Severities        []string
+	EndpointIDs       []uuid.UUID
+	EndpointTags      []string
+	ChannelIDs        []uuid.UUID
+	OnCallScheduleIDs []uuid.UUID
+}
+
+// Matches returns whether the rule applies to the notification
+func (r *RoutingRule) Matches(data NotificationData) bool {
+	if len(r.EventTypes) > 0 && !slices.Contains(r.EventTypes, data.Type) {
+		return false
+	}
+
+	if len(r.Severities) > 0 && !slices.ContainsFunc(r.Severities, func(severity string) bool {
+		return strings.EqualFold(severity, data.Severity)
+	}) {
+		return false
+	}
+
+	if len(r.EndpointIDs) == 0 && len(r.EndpointTags) == 0 {
+		return true
+	}
+
+	for _, endpoint := range data.Endpoints {
+		if slices.Contains(r.EndpointIDs, endpoint.ID) {
+			return true
+		}
+		for _, tag := range endpoint.Tags {
+			if slices.ContainsFunc(r.EndpointTags, func(ruleTag string) bool {
+				return strings.EqualFold(ruleTag, tag)
+			}) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ChannelRoute is a channel selected to receive a notification and the rules that selected it.
+// Channels no rule refers to receive every notification and have no rules
+type ChannelRoute struct {
+	Channel *Channel
+	Rules   []RoutingRule
+}
+
+// SetRoutingRules replaces the routing rules
+func (s *Service) SetRoutingRules(rules []RoutingRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rules = slices.Clone(rules)
+}
+
+// GetRoutingRules returns the routing rules
+func (s *Service) GetRoutingRules() []RoutingRule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.rules)
+}
+
+// Route returns the enabled channels that should receive the notification, ordered by type and
+// name. A channel referred to by a routing rule only receives notifications matching one of its
+// rules, channels without rules receive everything
+func (s *Service) Route(data NotificationData) []ChannelRoute {
+	rules := s.GetRoutingRules()
+
+	routed := make(map[uuid.UUID]bool)
+	matched := make(map[uuid.UUID][]RoutingRule)
+	for _, rule := range rules {
+		isMatch := rule.Matches(data)
+		for _, channelID := range rule.ChannelIDs {
+			routed[channelID] = true
+			if isMatch {
+				matched[channelID] = append(matched[channelID], rule)
+			}
+		}
+	}
+
+	var routes []ChannelRoute
+	for _, channel := range s.GetEnabledChannels() {
+		if routed[channel.ID] && len(matched[channel.ID]) == 0 {
+			continue
+		}
+		routes = append(routes, ChannelRoute{Channel: channel, Rules: matched[channel.ID]})
+	}
+
+	return routes
+}
+
+// OnCallResolver returns the email address of whoever is on call for a schedule at a time
+type OnCallResolver func(scheduleID uuid.UUID, at time.Time) (string, error)
+
+// SetOnCallResolver sets how on-call schedules referred to by routing rules are resolved
+func (s *Service) SetOnCallResolver(resolver OnCallResolver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.onCall = resolver
+}
+
+// Recipients returns the on-call email addresses for an email channel route whose rules target
+// on-call schedules. It returns nil when the channel's own recipients apply, including when no
+// one on call could be resolved
+func (s *Service) Recipients(route ChannelRoute, at time.Time) []string {
+	if route.Channel.Type() != ProviderTypeEmail {
+		return nil
+	}
+
+	s.mu.RLock()
+	resolver := s.onCall
+	s.mu.RUnlock()
+	if resolver == nil {
+		return nil
+	}
+
+	var recipients []string
+	for _, rule := range route.Rules {
+		for _, scheduleID := range rule.OnCallScheduleIDs {
+			email, err := resolver(scheduleID, at)
+			if err != nil {
+				s.logger.Warn("Failed to resolve on-call recipient",
+					"rule", rule.Name,
+					"schedule_id", scheduleID,
+					"error", err)
+				continue
+			}
+			if email != "" && !slices.Contains(recipients, email) {
+				recipients = append(recipients, email)
+			}
+		}
+	}
+	return recipients
+}
+
+// forRoute returns the notification as sent through the route
+func (s *Service) forRoute(data NotificationData, route ChannelRoute) NotificationData {
+	at := data.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if recipients := s.Recipients(route, at); len(recipients) > 0 {
+		data.Recipients = recipients
+	}
+	return data
+}
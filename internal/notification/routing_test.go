@@ -0,0 +1,181 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRoutingRuleMatches(t *testing.T) {
+	apiID := uuid.New()
+	api := EndpointInfo{ID: apiID, Name: "API", Tags: []string{"backend", "payments"}}
+	web := EndpointInfo{ID: uuid.New(), Name: "Web", Tags: []string{"frontend"}}
+
+	tests := []struct {
+		name     string
+		rule     RoutingRule
+		data     NotificationData
+		expected bool
+	}{
+		{
+			name:     "empty rule matches everything",
+			rule:     RoutingRule{},
+			data:     NotificationData{Type: NotificationTypeIncidentCreated, Severity: "low"},
+			expected: true,
+		},
+		{
+			name:     "event type",
+			rule:     RoutingRule{EventTypes: []NotificationType{NotificationTypeEndpointDown}},
+			data:     NotificationData{Type: NotificationTypeEndpointUp},
+			expected: false,
+		},
+		{
+			name:     "severity is case insensitive",
+			rule:     RoutingRule{Severities: []string{"critical"}},
+			data:     NotificationData{Type: NotificationTypeIncidentCreated, Severity: "CRITICAL"},
+			expected: true,
+		},
+		{
+			name:     "severity mismatch",
+			rule:     RoutingRule{Severities: []string{"critical"}},
+			data:     NotificationData{Type: NotificationTypeIncidentCreated, Severity: "low"},
+			expected: false,
+		},
+		{
+			name:     "endpoint ID",
+			rule:     RoutingRule{EndpointIDs: []uuid.UUID{apiID}},
+			data:     NotificationData{Endpoints: []EndpointInfo{web, api}},
+			expected: true,
+		},
+		{
+			name:     "endpoint tag",
+			rule:     RoutingRule{EndpointTags: []string{"frontend"}},
+			data:     NotificationData{Endpoints: []EndpointInfo{web}},
+			expected: true,
+		},
+		{
+			name:     "endpoint IDs or tags",
+			rule:     RoutingRule{EndpointIDs: []uuid.UUID{uuid.New()}, EndpointTags: []string{"payments"}},
+			data:     NotificationData{Endpoints: []EndpointInfo{api}},
+			expected: true,
+		},
+		{
+			name:     "endpoint criteria require an endpoint",
+			rule:     RoutingRule{EndpointTags: []string{"backend"}},
+			data:     NotificationData{Type: NotificationTypeIncidentCreated},
+			expected: false,
+		},
+		{
+			name: "all criteria must match",
+			rule: RoutingRule{
+				EventTypes:   []NotificationType{NotificationTypeIncidentCreated},
+				Severities:   []string{"critical"},
+				EndpointTags: []string{"backend"},
+			},
+			data:     NotificationData{Type: NotificationTypeIncidentCreated, Severity: "high", Endpoints: []EndpointInfo{api}},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Matches(tt.data); got != tt.expected {
+				t.Errorf("Expected match %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestServiceRoute(t *testing.T) {
+	sent := &sync.Map{}
+	service := newChannelTestService(sent)
+
+	onCall := slackChannel("On-call", "https://hooks.slack.com/oncall", true)
+	backend := slackChannel("Backend", "https://hooks.slack.com/backend", true)
+	general := slackChannel("General", "https://hooks.slack.com/general", true)
+	service.LoadChannels([]ChannelConfig{onCall, backend, general})
+
+	criticalRule := RoutingRule{ID: uuid.New(), Name: "Critical", Severities: []string{"critical"}, ChannelIDs: []uuid.UUID{onCall.ID}}
+	backendRule := RoutingRule{ID: uuid.New(), Name: "Backend", EndpointTags: []string{"backend"}, ChannelIDs: []uuid.UUID{backend.ID}}
+	service.SetRoutingRules([]RoutingRule{criticalRule, backendRule})
+
+	routeNames := func(data NotificationData) map[string]int {
+		names := make(map[string]int)
+		for _, route := range service.Route(data) {
+			names[route.Channel.Name] = len(route.Rules)
+		}
+		return names
+	}
+
+	// Low severity frontend event only reaches the unrouted channel
+	names := routeNames(NotificationData{Severity: "low", Endpoints: []EndpointInfo{{ID: uuid.New(), Tags: []string{"frontend"}}}})
+	if len(names) != 1 || names["General"] != 0 {
+		t.Errorf("Expected only the general channel, got %v", names)
+	}
+
+	// Critical backend event reaches every channel
+	names = routeNames(NotificationData{Severity: "critical", Endpoints: []EndpointInfo{{ID: uuid.New(), Tags: []string{"backend"}}}})
+	if len(names) != 3 || names["On-call"] != 1 || names["Backend"] != 1 {
+		t.Errorf("Expected all channels with their matching rules, got %v", names)
+	}
+
+	// Sending follows the routes
+	service.SendNotificationToChannels(context.Background(), NotificationData{Severity: "critical"})
+	if _, ok := sent.Load("https://hooks.slack.com/oncall"); !ok {
+		t.Error("Expected critical notification on the on-call channel")
+	}
+	if _, ok := sent.Load("https://hooks.slack.com/backend"); ok {
+		t.Error("Expected backend channel to be skipped")
+	}
+
+	// Without rules every enabled channel receives everything
+	service.SetRoutingRules(nil)
+	if names := routeNames(NotificationData{Severity: "low"}); len(names) != 3 {
+		t.Errorf("Expected all channels without rules, got %v", names)
+	}
+}
+
+func TestServiceRoutesToOnCall(t *testing.T) {
+	sent := &sync.Map{}
+	service := newChannelTestService(sent)
+	service.RegisterProviderFactory(ProviderTypeEmail, func() NotificationProvider {
+		return &RecordingProvider{providerType: ProviderTypeEmail, sent: sent}
+	})
+
+	email := slackChannel("Ops email", "ops-email", true)
+	email.Config.Type = ProviderTypeEmail
+	chat := slackChannel("Ops chat", "https://hooks.slack.com/ops", true)
+	service.LoadChannels([]ChannelConfig{email, chat})
+
+	primary, secondary := uuid.New(), uuid.New()
+	service.SetRoutingRules([]RoutingRule{{
+		Name:              "Critical to on-call",
+		Severities:        []string{"critical"},
+		ChannelIDs:        []uuid.UUID{email.ID, chat.ID},
+		OnCallScheduleIDs: []uuid.UUID{primary, secondary},
+	}})
+	service.SetOnCallResolver(func(scheduleID uuid.UUID, at time.Time) (string, error) {
+		if scheduleID == primary {
+			return "alice@example.com", nil
+		}
+		return "", errors.New("schedule has no participants")
+	})
+
+	service.SendNotificationToChannels(context.Background(), NotificationData{Severity: "critical", Timestamp: time.Now()})
+
+	// Email goes to whoever is on call, unresolvable schedules are skipped
+	value, _ := sent.Load("ops-email")
+	if recipients := value.(NotificationData).Recipients; len(recipients) != 1 || recipients[0] != "alice@example.com" {
+		t.Errorf("Expected email to the on-call user, got %v", recipients)
+	}
+
+	// Other channel types are not addressed to people
+	value, _ = sent.Load("https://hooks.slack.com/ops")
+	if recipients := value.(NotificationData).Recipients; len(recipients) != 0 {
+		t.Errorf("Expected no recipients for chat, got %v", recipients)
+	}
+}
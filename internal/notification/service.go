@@ -6,11 +6,17 @@ import (
 	"log/slog"
 	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
-// Service manages notification providers and handles notification sending
+// Service manages notification providers and channels and handles notification sending
 type Service struct {
 	providers map[ProviderType]NotificationProvider
+	factories map[ProviderType]ProviderFactory
+	channels  map[uuid.UUID]*Channel
+	rules     []RoutingRule
+	onCall    OnCallResolver
 	mu        sync.RWMutex
 	logger    *slog.Logger
 }
@@ -23,6 +29,8 @@ func NewService(logger *slog.Logger) *Service {
 
 	return &Service{
 		providers: make(map[ProviderType]NotificationProvider),
+		factories: make(map[ProviderType]ProviderFactory),
+		channels:  make(map[uuid.UUID]*Channel),
 		logger:    logger,
 	}
 }
@@ -118,7 +126,7 @@ func (s *Service) SendNotificationToAll(ctx context.Context, data NotificationDa
 	providers := s.GetEnabledProviders()
 
 	if len(providers) == 0 {
-		s.logger.Warn("No enabled providers found for notification", "type", data.Type)
+		s.logger.Debug("No enabled providers found for notification", "type", data.Type)
 		return results
 	}
 
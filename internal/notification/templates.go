@@ -0,0 +1,400 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Message templates replace the title and message a channel sends for a notification type.
+// Title and Body are text/template sources, HTML is an html/template source email channels send
+// in place of their built in layout. Providers keep their own formatting around the rendered
+// title and message. Templates are executed with TemplateData, documented by TemplateVariables,
+// and can use TemplateFunctions besides the builtins.
+
+// maxTemplateLength bounds the length of each template source
+const maxTemplateLength = 10000
+
+// MessageTemplate holds the template sources for one notification type, empty sources keep
+// the default
+type MessageTemplate struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+	HTML  string `json:"html,omitempty"`
+}
+
+// TemplateData is the data message templates are executed with
+type TemplateData struct {
+	Type      string
+	Title     string
+	Message   string
+	Severity  string
+	URL       string
+	Timestamp time.Time
+	Endpoint  *EndpointInfo
+	Endpoints []EndpointInfo
+	Check     *TemplateCheck
+	Incident  *TemplateIncident
+	Details   []Detail
+	Metadata  map[string]interface{}
+}
+
+// TemplateCheck is the check result available to templates, unknown values are zero
+type TemplateCheck struct {
+	StatusCode     int
+	ResponseTimeMs int
+	Error          string
+}
+
+// TemplateIncident is the incident available to templates
+type TemplateIncident struct {
+	ID     uuid.UUID
+	Status string
+}
+
+// TemplateVariable documents a value available to templates
+type TemplateVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// TemplateVariables documents the fields of TemplateData
+var TemplateVariables = []TemplateVariable{
+	{Name: ".Type", Description: "The notification type, such as endpoint_down"},
+	{Name: ".Title", Description: "The default title, such as \"API is DOWN\""},
+	{Name: ".Message", Description: "The default message"},
+	{Name: ".Severity", Description: "info, low, medium, high or critical"},
+	{Name: ".URL", Description: "Link to the endpoint or incident in Watchtower, empty when no domain is configured"},
+	{Name: ".Timestamp", Description: "When the notification was triggered"},
+	{Name: ".Endpoint", Description: "The first endpoint the notification is about, with .ID, .Name, .URL and .Tags"},
+	{Name: ".Endpoints", Description: "Every endpoint the notification is about"},
+	{Name: ".Check", Description: "The check behind endpoint notifications, with .StatusCode, .ResponseTimeMs and .Error. Nil for incident notifications"},
+	{Name: ".Incident", Description: "The incident of incident notifications, with .ID and .Status. Nil for endpoint notifications"},
+	{Name: ".Details", Description: "The labelled context providers show by default, each with .Label and .Value"},
+	{Name: ".Metadata", Description: "Extra values such as down_duration, incident_duration, escalation_level or state_changes"},
+}
+
+// TemplateFunctions documents the functions available to templates besides the builtins
+var TemplateFunctions = []TemplateVariable{
+	{Name: "upper", Description: "Converts a string to upper case"},
+	{Name: "lower", Description: "Converts a string to lower case"},
+	{Name: "join", Description: "Joins a list of strings with a separator: join .Endpoint.Tags \", \""},
+	{Name: "formatTime", Description: "Formats a time with a Go layout: formatTime .Timestamp \"2006-01-02 15:04 MST\""},
+}
+
+// RenderedMessage is the output of a channel's templates
+type RenderedMessage struct {
+	Title   string `json:"title"`
+	Message string `json:"message"`
+	HTML    string `json:"html,omitempty"`
+}
+
+// templateFuncs are the functions available to templates besides the builtins
+var templateFuncs = map[string]interface{}{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"join":  strings.Join,
+	"formatTime": func(t time.Time, layout string) string {
+		return t.Format(layout)
+	},
+}
+
+// compiledTemplate is a parsed MessageTemplate, nil templates keep the default
+type compiledTemplate struct {
+	title *texttemplate.Template
+	body  *texttemplate.Template
+	html  *htmltemplate.Template
+}
+
+// TemplateSet is a channel's compiled message templates by notification type
+type TemplateSet struct {
+	templates map[NotificationType]*compiledTemplate
+}
+
+// CompileTemplates parses the templates and executes each against sample data of its
+// notification type, so templates referring to unknown fields are rejected before they are
+// used. Types are checked in order and the first error is returned
+func CompileTemplates(templates map[NotificationType]MessageTemplate) (*TemplateSet, error) {
+	types := make([]NotificationType, 0, len(templates))
+	for notificationType := range templates {
+		types = append(types, notificationType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	set := &TemplateSet{templates: make(map[NotificationType]*compiledTemplate, len(templates))}
+	for _, notificationType := range types {
+		compiled, err := compileTemplate(notificationType, templates[notificationType])
+		if err != nil {
+			return nil, fmt.Errorf("%s template: %w", notificationType, err)
+		}
+		if compiled != nil {
+			set.templates[notificationType] = compiled
+		}
+	}
+
+	return set, nil
+}
+
+// compileTemplate parses and checks a single message template, returning nil when it keeps
+// every default
+func compileTemplate(notificationType NotificationType, template MessageTemplate) (*compiledTemplate, error) {
+	sample, ok := SampleNotification(notificationType)
+	if !ok {
+		return nil, fmt.Errorf("unknown notification type")
+	}
+
+	for name, source := range map[string]string{"title": template.Title, "body": template.Body, "html": template.HTML} {
+		if len(source) > maxTemplateLength {
+			return nil, fmt.Errorf("%s must be no more than %d characters", name, maxTemplateLength)
+		}
+	}
+
+	compiled := &compiledTemplate{}
+	var err error
+	if strings.TrimSpace(template.Title) != "" {
+		if compiled.title, err = texttemplate.New("title").Funcs(templateFuncs).Parse(template.Title); err != nil {
+			return nil, fmt.Errorf("invalid title: %w", err)
+		}
+	}
+	if strings.TrimSpace(template.Body) != "" {
+		if compiled.body, err = texttemplate.New("body").Funcs(templateFuncs).Parse(template.Body); err != nil {
+			return nil, fmt.Errorf("invalid body: %w", err)
+		}
+	}
+	if strings.TrimSpace(template.HTML) != "" {
+		if compiled.html, err = htmltemplate.New("html").Funcs(templateFuncs).Parse(template.HTML); err != nil {
+			return nil, fmt.Errorf("invalid html: %w", err)
+		}
+	}
+
+	if compiled.title == nil && compiled.body == nil && compiled.html == nil {
+		return nil, nil
+	}
+
+	rendered, err := compiled.render(sample)
+	if err != nil {
+		return nil, err
+	}
+	if compiled.title != nil && strings.TrimSpace(rendered.Title) == "" {
+		return nil, fmt.Errorf("title renders empty")
+	}
+
+	return compiled, nil
+}
+
+// render executes the templates, keeping the notification's title and message where no
+// template is set
+func (c *compiledTemplate) render(data NotificationData) (RenderedMessage, error) {
+	vars := NewTemplateData(data)
+	rendered := RenderedMessage{Title: data.Title, Message: data.Message}
+
+	var b bytes.Buffer
+	if c.title != nil {
+		if err := c.title.Execute(&b, vars); err != nil {
+			return RenderedMessage{}, fmt.Errorf("failed to render title: %w", err)
+		}
+		// Titles are single line in every provider
+		rendered.Title = strings.Join(strings.Fields(b.String()), " ")
+		b.Reset()
+	}
+	if c.body != nil {
+		if err := c.body.Execute(&b, vars); err != nil {
+			return RenderedMessage{}, fmt.Errorf("failed to render body: %w", err)
+		}
+		rendered.Message = strings.TrimSpace(b.String())
+		b.Reset()
+	}
+	if c.html != nil {
+		if err := c.html.Execute(&b, vars); err != nil {
+			return RenderedMessage{}, fmt.Errorf("failed to render html: %w", err)
+		}
+		rendered.HTML = b.String()
+	}
+
+	return rendered, nil
+}
+
+// Has returns whether the set holds a template for the notification type
+func (t *TemplateSet) Has(notificationType NotificationType) bool {
+	if t == nil {
+		return false
+	}
+	_, exists := t.templates[notificationType]
+	return exists
+}
+
+// Render returns the notification with its title, message and HTML message rendered by the
+// template for its type. Notifications without a template are returned unchanged
+func (t *TemplateSet) Render(data NotificationData) (NotificationData, error) {
+	if !t.Has(data.Type) {
+		return data, nil
+	}
+
+	rendered, err := t.templates[data.Type].render(data)
+	if err != nil {
+		return data, err
+	}
+
+	data.Title = rendered.Title
+	data.Message = rendered.Message
+	data.HTMLMessage = rendered.HTML
+	return data, nil
+}
+
+// PreviewTemplate compiles the template and renders it against sample data of the
+// notification type
+func PreviewTemplate(notificationType NotificationType, template MessageTemplate) (RenderedMessage, error) {
+	sample, ok := SampleNotification(notificationType)
+	if !ok {
+		return RenderedMessage{}, fmt.Errorf("unknown notification type: %s", notificationType)
+	}
+
+	compiled, err := compileTemplate(notificationType, template)
+	if err != nil {
+		return RenderedMessage{}, err
+	}
+	if compiled == nil {
+		return RenderedMessage{Title: sample.Title, Message: sample.Message}, nil
+	}
+
+	return compiled.render(sample)
+}
+
+// NewTemplateData converts a notification to the data templates are executed with
+func NewTemplateData(data NotificationData) TemplateData {
+	vars := TemplateData{
+		Type:      string(data.Type),
+		Title:     data.Title,
+		Message:   data.Message,
+		Severity:  data.Severity,
+		URL:       data.URL,
+		Timestamp: data.Timestamp,
+		Endpoints: data.Endpoints,
+		Details:   data.Details(),
+		Metadata:  data.Metadata,
+	}
+
+	if len(data.Endpoints) > 0 {
+		vars.Endpoint = &data.Endpoints[0]
+	}
+
+	if data.Check != nil {
+		check := &TemplateCheck{Error: data.Check.Error}
+		if data.Check.StatusCode != nil {
+			check.StatusCode = *data.Check.StatusCode
+		}
+		if data.Check.ResponseTimeMs != nil {
+			check.ResponseTimeMs = *data.Check.ResponseTimeMs
+		}
+		vars.Check = check
+	}
+
+	if data.IncidentID != nil {
+		vars.Incident = &TemplateIncident{ID: *data.IncidentID, Status: data.IncidentStatus}
+	}
+
+	return vars
+}
+
+// SampleNotification returns a notification of the type as Watchtower would send it, used to
+// check and preview templates. The second value is false for unknown types
+func SampleNotification(notificationType NotificationType) (NotificationData, bool) {
+	timestamp := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	endpoint := EndpointInfo{
+		ID:   uuid.MustParse("3b241101-e2bb-4255-8caf-4136c566a962"),
+		Name: "API",
+		URL:  "https://api.example.com/health",
+		Tags: []string{"production"},
+	}
+	incidentID := uuid.MustParse("9a7b3c4d-1e2f-4a5b-8c6d-7e8f9a0b1c2d")
+	statusCode, responseTime := 503, 1250
+
+	endpointData := func(title, message, severity string, check CheckInfo) NotificationData {
+		return NotificationData{
+			Type:       notificationType,
+			Title:      title,
+			Message:    message,
+			EndpointID: &endpoint.ID,
+			Severity:   severity,
+			URL:        "https://status.example.com/admin/endpoints/" + endpoint.ID.String(),
+			Timestamp:  timestamp,
+			Endpoints:  []EndpointInfo{endpoint},
+			Check:      &check,
+		}
+	}
+	incidentData := func(title, message, severity, status string) NotificationData {
+		return NotificationData{
+			Type:           notificationType,
+			Title:          title,
+			Message:        message,
+			IncidentID:     &incidentID,
+			IncidentStatus: status,
+			Severity:       severity,
+			URL:            "https://status.example.com/admin/incidents/" + incidentID.String(),
+			Timestamp:      timestamp,
+			Endpoints:      []EndpointInfo{endpoint},
+		}
+	}
+
+	switch notificationType {
+	case NotificationTypeEndpointDown:
+		return endpointData("API is DOWN",
+			"Endpoint API (https://api.example.com/health) is not responding: unexpected status code 503",
+			"critical",
+			CheckInfo{StatusCode: &statusCode, ResponseTimeMs: &responseTime, Error: "unexpected status code 503"}), true
+	case NotificationTypeEndpointUp:
+		statusCode, responseTime = 200, 180
+		data := endpointData("API is UP",
+			"Endpoint API (https://api.example.com/health) has recovered after being down for 12m0s",
+			"info",
+			CheckInfo{StatusCode: &statusCode, ResponseTimeMs: &responseTime})
+		data.Metadata = map[string]interface{}{"down_duration": "12m0s"}
+		return data, true
+	case NotificationTypeEndpointFlapping:
+		data := endpointData("API is flapping",
+			"Endpoint API went up or down 4 times in 6m0s. Up, down and incident notifications are paused until it is stable for 10m0s",
+			"high",
+			CheckInfo{StatusCode: &statusCode, ResponseTimeMs: &responseTime, Error: "unexpected status code 503"})
+		data.Metadata = map[string]interface{}{"state_changes": 4}
+		return data, true
+	case NotificationTypeEndpointStabilized:
+		statusCode, responseTime = 200, 180
+		data := endpointData("API stopped flapping and is UP",
+			"Endpoint API went up or down 9 times over 24m0s and has been UP for 10m0s",
+			"info",
+			CheckInfo{StatusCode: &statusCode, ResponseTimeMs: &responseTime})
+		data.Metadata = map[string]interface{}{"state_changes": 9, "flapping_duration": "24m0s", "state": "up"}
+		return data, true
+	case NotificationTypeIncidentCreated:
+		return incidentData("API outage", "A new incident has been created: API is returning errors", "high", "open"), true
+	case NotificationTypeIncidentUpdated:
+		return incidentData("API outage", "Incident update: A fix is being deployed", "high", "monitoring"), true
+	case NotificationTypeIncidentResolved:
+		data := incidentData("API outage", "Incident resolved: The fix is deployed (Duration: 45m0s)", "info", "resolved")
+		data.Metadata = map[string]interface{}{"incident_duration": "45m0s"}
+		return data, true
+	case NotificationTypeIncidentEscalated:
+		data := incidentData("Incident escalated: API outage", "Unacknowledged for 15m0s, escalation level 1 of 2", "high", "open")
+		data.Metadata = map[string]interface{}{"escalation_policy": "Production", "escalation_level": 1}
+		return data, true
+	case NotificationTypeDigest:
+		return NotificationData{
+			Type:  notificationType,
+			Title: "Digest: 2 notifications",
+			Message: "2 notifications held since 01 Sep 02:10 UTC:\n" +
+				"- 01 Sep 02:10 [info] API is UP\n" +
+				"- 01 Sep 04:45 [info] Web is UP",
+			Severity:  "info",
+			Timestamp: timestamp,
+			Metadata:  map[string]interface{}{"notifications": 2, "since": "2025-09-01T02:10:00Z"},
+		}, true
+	default:
+		return NotificationData{}, false
+	}
+}
@@ -0,0 +1,122 @@
+package notification
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCompileTemplatesRendersTitleAndBody(t *testing.T) {
+	templates, err := CompileTemplates(map[NotificationType]MessageTemplate{
+		NotificationTypeEndpointDown: {
+			Title: "[{{upper .Severity}}] {{.Endpoint.Name}} down",
+			Body:  "{{.Endpoint.URL}} returned {{.Check.StatusCode}} in {{.Check.ResponseTimeMs}} ms",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data, _ := SampleNotification(NotificationTypeEndpointDown)
+	rendered, err := templates.Render(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if rendered.Title != "[CRITICAL] API down" {
+		t.Errorf("Expected the rendered title, got %q", rendered.Title)
+	}
+	if rendered.Message != "https://api.example.com/health returned 503 in 1250 ms" {
+		t.Errorf("Expected the rendered message, got %q", rendered.Message)
+	}
+
+	// Types without a template keep the default message
+	incident, _ := SampleNotification(NotificationTypeIncidentCreated)
+	unchanged, err := templates.Render(incident)
+	if err != nil || unchanged.Title != incident.Title || unchanged.Message != incident.Message {
+		t.Errorf("Expected the default incident message, got %+v (%v)", unchanged, err)
+	}
+}
+
+func TestCompileTemplatesRejectsInvalidTemplates(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType NotificationType
+		template  MessageTemplate
+		want      string
+	}{
+		{"syntax error", NotificationTypeEndpointDown, MessageTemplate{Title: "{{.Title"}, "invalid title"},
+		{"unknown field", NotificationTypeEndpointDown, MessageTemplate{Body: "{{.Hostname}}"}, "Hostname"},
+		{"check on incidents", NotificationTypeIncidentCreated, MessageTemplate{Body: "{{.Check.StatusCode}}"}, "nil pointer"},
+		{"empty title", NotificationTypeEndpointUp, MessageTemplate{Title: "{{if false}}x{{end}}"}, "title renders empty"},
+		{"unknown type", "endpoint_paused", MessageTemplate{Title: "Paused"}, "unknown notification type"},
+		{"unclosed html tag", NotificationTypeEndpointDown, MessageTemplate{HTML: "<a href=\"{{.URL}}\">{{.Title}}"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileTemplates(map[NotificationType]MessageTemplate{tt.eventType: tt.template})
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestPreviewTemplateRendersHTML(t *testing.T) {
+	rendered, err := PreviewTemplate(NotificationTypeIncidentCreated, MessageTemplate{
+		HTML: "<h1>{{.Title}}</h1><p>{{.Message}}</p>{{range .Details}}<b>{{.Label}}</b>{{end}}",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if rendered.Title != "API outage" || !strings.HasPrefix(rendered.HTML, "<h1>API outage</h1>") {
+		t.Errorf("Expected the sample incident, got %+v", rendered)
+	}
+	if !strings.Contains(rendered.HTML, "<b>Incident status</b>") {
+		t.Errorf("Expected the details in the html, got %q", rendered.HTML)
+	}
+
+	rendered, err = PreviewTemplate(NotificationTypeEndpointDown, MessageTemplate{HTML: "<p>{{.Metadata.note}}</p>"})
+	if err != nil || rendered.HTML != "<p></p>" {
+		t.Errorf("Expected missing metadata to render empty, got %q (%v)", rendered.HTML, err)
+	}
+}
+
+func TestServiceSendsTemplatedMessages(t *testing.T) {
+	sent := &sync.Map{}
+	service := newChannelTestService(sent)
+
+	templated := slackChannel("Templated", "https://hooks.slack.com/templated", true)
+	templated.Templates = map[NotificationType]MessageTemplate{
+		NotificationTypeEndpointDown: {Title: "{{.Endpoint.Name}} needs attention"},
+	}
+	plain := slackChannel("Plain", "https://hooks.slack.com/plain", true)
+	broken := slackChannel("Broken", "https://hooks.slack.com/broken", true)
+	broken.Templates = map[NotificationType]MessageTemplate{
+		NotificationTypeEndpointDown: {Title: "{{.Incident.ID}}"},
+	}
+
+	errs := service.LoadChannels([]ChannelConfig{templated, plain, broken})
+	if len(errs) != 1 || errs[broken.ID] == nil {
+		t.Fatalf("Expected the broken template to be rejected, got %v", errs)
+	}
+
+	data, _ := SampleNotification(NotificationTypeEndpointDown)
+	service.SendNotificationToChannels(context.Background(), data)
+
+	got, _ := sent.Load("https://hooks.slack.com/templated")
+	if got == nil || got.(NotificationData).Title != "API needs attention" {
+		t.Errorf("Expected the templated title, got %+v", got)
+	}
+	got, _ = sent.Load("https://hooks.slack.com/plain")
+	if got == nil || got.(NotificationData).Title != "API is DOWN" {
+		t.Errorf("Expected the default title, got %+v", got)
+	}
+}
@@ -0,0 +1,316 @@
+package notification
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reasons recorded on suppressed deliveries
+const (
+	SuppressedDuplicate   = "duplicate"
+	SuppressedRateLimited = "rate_limited"
+	SuppressedFlapping    = "flapping"
+)
+
+// ThrottleConfig contains configuration for notification throttling. Zero values disable the
+// corresponding check
+type ThrottleConfig struct {
+	DedupWindow     time.Duration // identical notifications to a channel within it are suppressed
+	RateLimit       int           // notifications a channel receives per RatePeriod, channels may override it
+	RatePeriod      time.Duration // period RateLimit applies to
+	FlapThreshold   int           // up and down changes within FlapWindow that mark an endpoint flapping
+	FlapWindow      time.Duration // period FlapThreshold applies to
+	FlapStableAfter time.Duration // how long a flapping endpoint must keep its state to be stable again
+}
+
+// DefaultThrottleConfig returns a sensible default throttle configuration
+func DefaultThrottleConfig() ThrottleConfig {
+	return ThrottleConfig{
+		DedupWindow:     5 * time.Minute,
+		RateLimit:       30,
+		RatePeriod:      time.Hour,
+		FlapThreshold:   4,
+		FlapWindow:      10 * time.Minute,
+		FlapStableAfter: 10 * time.Minute,
+	}
+}
+
+// flapDecision is what happens to an endpoint notification because of flapping
+type flapDecision int
+
+const (
+	flapNone    flapDecision = iota // sent as usual
+	flapStarted                     // suppressed and replaced by a flapping notification
+	flapOngoing                     // suppressed while the endpoint is flapping
+)
+
+// flapState tracks an endpoint's recent up and down changes
+type flapState struct {
+	changes  []time.Time // changes within the flap window, or every change while flapping
+	flapping bool
+	last     NotificationData // latest change while flapping
+	routes   []ChannelRoute   // routes of the latest change while flapping
+}
+
+// stabilizedEndpoint is a summary of a flapping period that ended, sent through the routes of
+// the endpoint's latest change
+type stabilizedEndpoint struct {
+	notification NotificationData
+	routes       []ChannelRoute
+}
+
+// recentNotification is a notification queued to a channel within the dedup window
+type recentNotification struct {
+	at         time.Time
+	kind       NotificationType
+	endpointID *uuid.UUID
+}
+
+// Throttle decides which queued notifications are suppressed: identical notifications to a
+// channel within the dedup window, notifications over a channel's rate limit and the up, down
+// and incident opened or resolved notifications of a flapping endpoint. A flapping endpoint gets
+// a single flapping notification and a summary once it is stable. State is kept in memory and
+// starts over on restart
+type Throttle struct {
+	config ThrottleConfig
+	mu     sync.Mutex
+	recent map[uuid.UUID]map[string]recentNotification // by channel and fingerprint
+	sent   map[uuid.UUID][]time.Time                   // queued times within the rate period by channel
+	flaps  map[uuid.UUID]*flapState                    // by endpoint
+}
+
+// NewThrottle creates a new throttle
+func NewThrottle(config ThrottleConfig) *Throttle {
+	return &Throttle{
+		config: config,
+		recent: make(map[uuid.UUID]map[string]recentNotification),
+		sent:   make(map[uuid.UUID][]time.Time),
+		flaps:  make(map[uuid.UUID]*flapState),
+	}
+}
+
+// fingerprint identifies identical notifications regardless of when they were triggered
+func fingerprint(data NotificationData) string {
+	var endpointID, incidentID string
+	if data.EndpointID != nil {
+		endpointID = data.EndpointID.String()
+	}
+	if data.IncidentID != nil {
+		incidentID = data.IncidentID.String()
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		string(data.Type), endpointID, incidentID, data.IncidentStatus, data.Severity, data.Title, data.Message,
+	}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Check returns why the notification must not be queued for the channel, or "" when it may be.
+// Allowed notifications count towards the channel's dedup window and rate limit
+func (t *Throttle) Check(channel *Channel, data NotificationData, now time.Time) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := fingerprint(data)
+	if t.config.DedupWindow > 0 {
+		if last, exists := t.recent[channel.ID][key]; exists && now.Sub(last.at) < t.config.DedupWindow {
+			return SuppressedDuplicate
+		}
+	}
+
+	limit := t.config.RateLimit
+	if channel.RateLimit != nil {
+		limit = *channel.RateLimit
+	}
+	if limit > 0 && t.config.RatePeriod > 0 {
+		sent := pruneUntil(t.sent[channel.ID], now.Add(-t.config.RatePeriod))
+		t.sent[channel.ID] = sent
+		if len(sent) >= limit {
+			return SuppressedRateLimited
+		}
+		t.sent[channel.ID] = append(sent, now)
+	}
+
+	if t.config.DedupWindow > 0 {
+		if t.recent[channel.ID] == nil {
+			t.recent[channel.ID] = make(map[string]recentNotification)
+		}
+		t.forgetOppositeChanges(channel.ID, data)
+		t.recent[channel.ID][key] = recentNotification{at: now, kind: data.Type, endpointID: data.EndpointID}
+	}
+
+	return ""
+}
+
+// forgetOppositeChanges drops the channel's recent up notifications of an endpoint going down,
+// or its down notifications when it comes up. A new outage after a recovery is never a duplicate
+// of the previous one, even with the same error
+func (t *Throttle) forgetOppositeChanges(channelID uuid.UUID, data NotificationData) {
+	if data.EndpointID == nil || !isEndpointChange(data.Type) {
+		return
+	}
+	for key, recent := range t.recent[channelID] {
+		if recent.endpointID != nil && *recent.endpointID == *data.EndpointID &&
+			isEndpointChange(recent.kind) && recent.kind != data.Type {
+			delete(t.recent[channelID], key)
+		}
+	}
+}
+
+// isEndpointChange returns whether notifications of the type report an endpoint going up or down
+func isEndpointChange(notificationType NotificationType) bool {
+	return notificationType == NotificationTypeEndpointDown || notificationType == NotificationTypeEndpointUp
+}
+
+// observeChange records an endpoint going up or down. When the change makes the endpoint
+// flapping the flapping notification replacing it is returned. Every outage of a flapping
+// endpoint also opens and resolves an incident, those notifications are suppressed with its changes
+func (t *Throttle) observeChange(data NotificationData, routes []ChannelRoute, now time.Time) (flapDecision, *NotificationData) {
+	if t.config.FlapThreshold <= 0 {
+		return flapNone, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if data.Type == NotificationTypeIncidentCreated || data.Type == NotificationTypeIncidentResolved {
+		if t.concernsFlappingEndpoint(data) {
+			return flapOngoing, nil
+		}
+		return flapNone, nil
+	}
+	if data.EndpointID == nil || !isEndpointChange(data.Type) {
+		return flapNone, nil
+	}
+
+	state, exists := t.flaps[*data.EndpointID]
+	if !exists {
+		state = &flapState{}
+		t.flaps[*data.EndpointID] = state
+	}
+
+	if state.flapping {
+		state.changes = append(state.changes, now)
+		state.last = data
+		state.routes = routes
+		return flapOngoing, nil
+	}
+
+	state.changes = append(pruneUntil(state.changes, now.Add(-t.config.FlapWindow)), now)
+	if len(state.changes) < t.config.FlapThreshold {
+		return flapNone, nil
+	}
+
+	state.flapping = true
+	state.last = data
+	state.routes = routes
+
+	flapping := data
+	flapping.Type = NotificationTypeEndpointFlapping
+	flapping.Title = fmt.Sprintf("%s is flapping", endpointName(data))
+	flapping.Message = fmt.Sprintf("Endpoint %s went up or down %d times in %s. Up, down and incident notifications are paused until it is stable for %s",
+		endpointName(data), len(state.changes), now.Sub(state.changes[0]).Round(time.Second), t.config.FlapStableAfter)
+	flapping.Severity = "high"
+	flapping.Metadata = map[string]interface{}{
+		"state_changes": len(state.changes),
+	}
+	return flapStarted, &flapping
+}
+
+// concernsFlappingEndpoint returns whether any endpoint the notification is about is flapping
+func (t *Throttle) concernsFlappingEndpoint(data NotificationData) bool {
+	for _, endpoint := range data.Endpoints {
+		if state, exists := t.flaps[endpoint.ID]; exists && state.flapping {
+			return true
+		}
+	}
+	return false
+}
+
+// stabilized ends the flapping periods of endpoints that kept their state for FlapStableAfter
+// and returns their summaries. It also drops state that no longer matters
+func (t *Throttle) stabilized(now time.Time) []stabilizedEndpoint {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var summaries []stabilizedEndpoint
+	for endpointID, state := range t.flaps {
+		lastChange := state.changes[len(state.changes)-1]
+		if !state.flapping {
+			if now.Sub(lastChange) >= t.config.FlapWindow {
+				delete(t.flaps, endpointID)
+			}
+			continue
+		}
+		if now.Sub(lastChange) < t.config.FlapStableAfter {
+			continue
+		}
+
+		delete(t.flaps, endpointID)
+
+		current := "UP"
+		severity := "info"
+		if state.last.Type == NotificationTypeEndpointDown {
+			current = "DOWN"
+			severity = "critical"
+		}
+		duration := lastChange.Sub(state.changes[0]).Round(time.Second)
+
+		summary := state.last
+		summary.Type = NotificationTypeEndpointStabilized
+		summary.Title = fmt.Sprintf("%s stopped flapping and is %s", endpointName(state.last), current)
+		summary.Message = fmt.Sprintf("Endpoint %s went up or down %d times over %s and has been %s for %s",
+			endpointName(state.last), len(state.changes), duration, current, now.Sub(lastChange).Round(time.Second))
+		summary.Severity = severity
+		summary.Timestamp = now
+		summary.Metadata = map[string]interface{}{
+			"state_changes":     len(state.changes),
+			"flapping_duration": duration.String(),
+			"state":             strings.ToLower(current),
+		}
+		summaries = append(summaries, stabilizedEndpoint{notification: summary, routes: state.routes})
+	}
+
+	for channelID, recent := range t.recent {
+		for key, last := range recent {
+			if now.Sub(last.at) >= t.config.DedupWindow {
+				delete(recent, key)
+			}
+		}
+		if len(recent) == 0 {
+			delete(t.recent, channelID)
+		}
+	}
+	for channelID, sent := range t.sent {
+		if sent = pruneUntil(sent, now.Add(-t.config.RatePeriod)); len(sent) == 0 {
+			delete(t.sent, channelID)
+		} else {
+			t.sent[channelID] = sent
+		}
+	}
+
+	return summaries
+}
+
+// endpointName returns the name of the endpoint an endpoint notification is about
+func endpointName(data NotificationData) string {
+	if len(data.Endpoints) > 0 {
+		return data.Endpoints[0].Name
+	}
+	return data.EndpointID.String()
+}
+
+// pruneUntil drops the times up to and including the cutoff from a list in ascending order
+func pruneUntil(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+	return times[i:]
+}
@@ -0,0 +1,270 @@
+package notification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+func newThrottledOutbox(t *testing.T, config ThrottleConfig) (*Outbox, *MemoryDeliveryStore, *FlakyProvider, *time.Time) {
+	t.Helper()
+
+	store := NewMemoryDeliveryStore()
+	provider := &FlakyProvider{}
+	outbox, _ := newTestOutbox(provider, store, 3)
+	outbox.UseThrottle(NewThrottle(config))
+
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	outbox.now = func() time.Time { return now }
+	return outbox, store, provider, &now
+}
+
+func endpointChange(notificationType NotificationType, endpoint EndpointInfo) NotificationData {
+	title := endpoint.Name + " is UP"
+	if notificationType == NotificationTypeEndpointDown {
+		title = endpoint.Name + " is DOWN"
+	}
+	return NotificationData{
+		Type:       notificationType,
+		Title:      title,
+		EndpointID: &endpoint.ID,
+		Endpoints:  []EndpointInfo{endpoint},
+	}
+}
+
+func TestThrottleSuppressesDuplicates(t *testing.T) {
+	outbox, store, _, now := newThrottledOutbox(t, ThrottleConfig{DedupWindow: 5 * time.Minute})
+
+	incidentID := uuid.New()
+	update := NotificationData{Type: NotificationTypeIncidentUpdated, Title: "API outage", Message: "Investigating", IncidentID: &incidentID}
+
+	first, _ := outbox.Enqueue(update)
+	duplicate, _ := outbox.Enqueue(update)
+	if first[0].Status != data.NotificationDeliveryPending {
+		t.Errorf("Expected the first update to be queued, got %s", first[0].Status)
+	}
+	if duplicate[0].Status != data.NotificationDeliverySuppressed || duplicate[0].SuppressedReason != SuppressedDuplicate {
+		t.Errorf("Expected the duplicate to be suppressed, got %s (%s)", duplicate[0].Status, duplicate[0].SuppressedReason)
+	}
+
+	// A different message is not a duplicate
+	update.Message = "Fix deployed"
+	different, _ := outbox.Enqueue(update)
+	if different[0].Status != data.NotificationDeliveryPending {
+		t.Errorf("Expected a different update to be queued, got %s", different[0].Status)
+	}
+
+	// The same message is sent again once the window has passed
+	*now = now.Add(5 * time.Minute)
+	again, _ := outbox.Enqueue(update)
+	if again[0].Status != data.NotificationDeliveryPending {
+		t.Errorf("Expected the update to be queued after the window, got %s", again[0].Status)
+	}
+
+	// Suppressed deliveries are kept in the history but never sent
+	if attempted := outbox.processDue(); attempted != 3 {
+		t.Errorf("Expected 3 deliveries to be attempted, got %d", attempted)
+	}
+	if len(store.deliveries) != 4 {
+		t.Errorf("Expected 4 deliveries in the history, got %d", len(store.deliveries))
+	}
+}
+
+func TestThrottleSendsOutageAfterRecovery(t *testing.T) {
+	outbox, _, _, now := newThrottledOutbox(t, ThrottleConfig{DedupWindow: 5 * time.Minute})
+	endpoint := EndpointInfo{ID: uuid.New(), Name: "API"}
+
+	// Down, up and down again with the same error all reach the channel, so its latest
+	// message matches the endpoint's state
+	for _, notificationType := range []NotificationType{
+		NotificationTypeEndpointDown, NotificationTypeEndpointUp, NotificationTypeEndpointDown,
+	} {
+		deliveries, _ := outbox.Enqueue(endpointChange(notificationType, endpoint))
+		if deliveries[0].Status != data.NotificationDeliveryPending {
+			t.Errorf("Expected %s to be queued, got %s (%s)", notificationType, deliveries[0].Status, deliveries[0].SuppressedReason)
+		}
+		*now = now.Add(time.Minute)
+	}
+
+	// A repeated down without a recovery in between is still a duplicate
+	repeated, _ := outbox.Enqueue(endpointChange(NotificationTypeEndpointDown, endpoint))
+	if repeated[0].SuppressedReason != SuppressedDuplicate {
+		t.Errorf("Expected a repeated down to be suppressed, got %s (%s)", repeated[0].Status, repeated[0].SuppressedReason)
+	}
+}
+
+func TestThrottleRateLimitsChannels(t *testing.T) {
+	outbox, _, _, now := newThrottledOutbox(t, ThrottleConfig{RateLimit: 2, RatePeriod: time.Hour})
+
+	statuses := func() []string {
+		var statuses []string
+		for i := 0; i < 3; i++ {
+			incidentID := uuid.New()
+			deliveries, _ := outbox.Enqueue(NotificationData{Type: NotificationTypeIncidentCreated, IncidentID: &incidentID})
+			statuses = append(statuses, deliveries[0].Status+deliveries[0].SuppressedReason)
+		}
+		return statuses
+	}
+
+	got := statuses()
+	if got[0] != "pending" || got[1] != "pending" || got[2] != "suppressedrate_limited" {
+		t.Errorf("Expected the third notification to be rate limited, got %v", got)
+	}
+
+	// The limit frees up as the period slides
+	*now = now.Add(time.Hour)
+	if got := statuses(); got[0] != "pending" || got[2] != "suppressedrate_limited" {
+		t.Errorf("Expected the limit to apply again after the period, got %v", got)
+	}
+
+	// A channel override of 0 disables the limit
+	for _, channel := range outbox.service.GetChannels() {
+		unlimited := 0
+		channel.RateLimit = &unlimited
+	}
+	if got := statuses(); got[2] != "pending" {
+		t.Errorf("Expected no limit with an override of 0, got %v", got)
+	}
+}
+
+func TestThrottleCollapsesFlapping(t *testing.T) {
+	outbox, store, provider, now := newThrottledOutbox(t, ThrottleConfig{
+		FlapThreshold:   4,
+		FlapWindow:      10 * time.Minute,
+		FlapStableAfter: 10 * time.Minute,
+	})
+
+	endpoint := EndpointInfo{ID: uuid.New(), Name: "API"}
+	changes := []NotificationType{
+		NotificationTypeEndpointDown, NotificationTypeEndpointUp, NotificationTypeEndpointDown,
+		NotificationTypeEndpointUp, NotificationTypeEndpointDown, NotificationTypeEndpointUp,
+	}
+
+	var flapping []data.NotificationDelivery
+	for i, change := range changes {
+		deliveries, err := outbox.Enqueue(endpointChange(change, endpoint))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		switch {
+		case i < 3:
+			if len(deliveries) != 1 || deliveries[0].Status != data.NotificationDeliveryPending {
+				t.Errorf("Expected change %d to be queued, got %+v", i+1, deliveries)
+			}
+		case i == 3:
+			// The fourth change is suppressed and replaced by a flapping notification
+			if len(deliveries) != 2 || deliveries[0].SuppressedReason != SuppressedFlapping {
+				t.Fatalf("Expected the change to be suppressed for flapping, got %+v", deliveries)
+			}
+			flapping = deliveries[1:]
+		default:
+			if len(deliveries) != 1 || deliveries[0].SuppressedReason != SuppressedFlapping {
+				t.Errorf("Expected change %d to be suppressed while flapping, got %+v", i+1, deliveries)
+			}
+		}
+		*now = now.Add(time.Minute)
+	}
+
+	if flapping[0].EventType != string(NotificationTypeEndpointFlapping) || flapping[0].Status != data.NotificationDeliveryPending {
+		t.Errorf("Expected a queued flapping notification, got %+v", flapping[0])
+	}
+
+	// Not stable yet
+	outbox.enqueueStabilized()
+	if len(store.deliveries) != 7 {
+		t.Fatalf("Expected no summary before the endpoint is stable, got %d deliveries", len(store.deliveries))
+	}
+
+	*now = now.Add(10 * time.Minute)
+	outbox.enqueueStabilized()
+	outbox.processDue()
+
+	var summary *NotificationData
+	for i, received := range provider.received {
+		if received.Type == NotificationTypeEndpointStabilized {
+			summary = &provider.received[i]
+		}
+	}
+	if summary == nil || summary.Title != "API stopped flapping and is UP" {
+		t.Fatalf("Expected the stabilized summary, got %+v", provider.received)
+	}
+	if summary.Metadata["state_changes"] != float64(6) {
+		t.Errorf("Expected 6 state changes in the summary, got %v", summary.Metadata["state_changes"])
+	}
+
+	// After stabilizing changes are sent as usual
+	deliveries, _ := outbox.Enqueue(endpointChange(NotificationTypeEndpointDown, endpoint))
+	if deliveries[0].Status != data.NotificationDeliveryPending {
+		t.Errorf("Expected changes to be sent after stabilizing, got %s", deliveries[0].Status)
+	}
+}
+
+func TestThrottleSuppressesIncidentsOfFlappingEndpoint(t *testing.T) {
+	outbox, _, _, now := newThrottledOutbox(t, ThrottleConfig{
+		FlapThreshold:   4,
+		FlapWindow:      10 * time.Minute,
+		FlapStableAfter: 10 * time.Minute,
+	})
+
+	endpoint := EndpointInfo{ID: uuid.New(), Name: "API"}
+	other := EndpointInfo{ID: uuid.New(), Name: "Web"}
+	incident := func(notificationType NotificationType, endpoints ...EndpointInfo) NotificationData {
+		incidentID := uuid.New()
+		return NotificationData{Type: notificationType, Title: "Endpoint API is failing", IncidentID: &incidentID, Endpoints: endpoints}
+	}
+
+	// Each outage opens an incident and each recovery resolves it, until the endpoint flaps
+	for i := 0; i < 4; i++ {
+		change, incidentEvent := NotificationTypeEndpointDown, NotificationTypeIncidentCreated
+		if i%2 == 1 {
+			change, incidentEvent = NotificationTypeEndpointUp, NotificationTypeIncidentResolved
+		}
+		if _, err := outbox.Enqueue(endpointChange(change, endpoint)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		deliveries, err := outbox.Enqueue(incident(incidentEvent, endpoint))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		flapping := i == 3
+		if suppressed := deliveries[0].SuppressedReason == SuppressedFlapping; suppressed != flapping {
+			t.Errorf("Expected %s %d suppressed for flapping to be %v, got %+v", incidentEvent, i/2+1, flapping, deliveries)
+		}
+		*now = now.Add(time.Minute)
+	}
+
+	down, _ := outbox.Enqueue(endpointChange(NotificationTypeEndpointDown, endpoint))
+	created, _ := outbox.Enqueue(incident(NotificationTypeIncidentCreated, endpoint))
+	if down[0].SuppressedReason != SuppressedFlapping || created[0].SuppressedReason != SuppressedFlapping {
+		t.Errorf("Expected the next outage and its incident to be suppressed while flapping, got %+v and %+v", down, created)
+	}
+
+	// Incidents of other endpoints are sent as usual
+	deliveries, _ := outbox.Enqueue(incident(NotificationTypeIncidentCreated, other))
+	if deliveries[0].Status != data.NotificationDeliveryPending {
+		t.Errorf("Expected incidents of other endpoints to be queued, got %s (%s)", deliveries[0].Status, deliveries[0].SuppressedReason)
+	}
+
+	*now = now.Add(10 * time.Minute)
+	outbox.enqueueStabilized()
+	deliveries, _ = outbox.Enqueue(incident(NotificationTypeIncidentCreated, endpoint))
+	if deliveries[0].Status != data.NotificationDeliveryPending {
+		t.Errorf("Expected incidents to be sent after stabilizing, got %s (%s)", deliveries[0].Status, deliveries[0].SuppressedReason)
+	}
+}
+
+func TestOutboxEnqueueToChannelsBypassesThrottle(t *testing.T) {
+	outbox, _, _, _ := newThrottledOutbox(t, ThrottleConfig{DedupWindow: time.Hour})
+
+	channelID := outbox.service.GetChannels()[0].ID
+	escalation := NotificationData{Type: NotificationTypeIncidentEscalated, Title: "Incident escalated: API outage"}
+	for i := 0; i < 2; i++ {
+		deliveries, _ := outbox.EnqueueToChannels(escalation, []uuid.UUID{channelID})
+		if deliveries[0].Status != data.NotificationDeliveryPending {
+			t.Errorf("Expected escalations to bypass the throttle, got %s", deliveries[0].Status)
+		}
+	}
+}
@@ -4,13 +4,14 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"math"
 	"time"
 )
 
-// NotificationTrigger handles triggering notifications based on system events
+// NotificationTrigger handles triggering notifications based on system events. The optional
+// endpoints passed to each trigger are the endpoints the event concerns and drive routing
 type NotificationTrigger struct {
 	service *Service
+	outbox  *Outbox
 	logger  *slog.Logger
 }
 
@@ -26,114 +27,124 @@ func NewNotificationTrigger(service *Service, logger *slog.Logger) *Notification
 	}
 }
 
+// UseOutbox makes the trigger queue channel notifications in the outbox instead of sending
+// them directly. Call before triggering notifications
+func (nt *NotificationTrigger) UseOutbox(outbox *Outbox) {
+	nt.outbox = outbox
+}
+
 // TriggerEndpointDown sends notifications when an endpoint goes down
-func (nt *NotificationTrigger) TriggerEndpointDown(ctx context.Context, endpointID int, endpointName, endpointURL, errorMessage string) error {
+func (nt *NotificationTrigger) TriggerEndpointDown(ctx context.Context, event EndpointEvent) error {
+	endpoint := event.Endpoint
+	reason := event.Check.Error
+	if reason == "" {
+		reason = "check failed"
+	}
+
 	data := NotificationData{
-		Type:       NotificationTypeEndpointDown,
-		Title:      fmt.Sprintf("%s is DOWN", endpointName),
-		Message:    fmt.Sprintf("Endpoint %s (%s) is not responding: %s", endpointName, endpointURL, errorMessage),
-		EndpointID: &endpointID,
-		URL:        endpointURL,
-		Severity:   "critical",
-		Timestamp:  time.Now(),
+		Type:             NotificationTypeEndpointDown,
+		Title:            fmt.Sprintf("%s is DOWN", endpoint.Name),
+		Message:          fmt.Sprintf("Endpoint %s (%s) is not responding: %s", endpoint.Name, endpoint.URL, reason),
+		EndpointID:       &endpoint.ID,
+		URL:              event.Link,
+		Severity:         "critical",
+		Timestamp:        time.Now(),
+		Endpoints:        []EndpointInfo{endpoint},
+		Check:            &event.Check,
+		OutageIncidentID: event.IncidentID,
 	}
 
 	nt.logger.Info("Triggering endpoint down notification",
-		"endpoint_id", endpointID,
-		"endpoint_name", endpointName,
-		"url", endpointURL)
+		"endpoint_id", endpoint.ID,
+		"endpoint_name", endpoint.Name,
+		"url", endpoint.URL)
 
-	results := nt.service.SendNotificationToAll(ctx, data)
-	return nt.logResults("endpoint_down", results)
+	return nt.sendToAll(ctx, "endpoint_down", data)
 }
 
 // TriggerEndpointUp sends notifications when an endpoint comes back up
-func (nt *NotificationTrigger) TriggerEndpointUp(ctx context.Context, endpointID int, endpointName, endpointURL string, downDuration time.Duration) error {
+func (nt *NotificationTrigger) TriggerEndpointUp(ctx context.Context, event EndpointEvent, downDuration time.Duration) error {
+	endpoint := event.Endpoint
+
 	data := NotificationData{
-		Type:       NotificationTypeEndpointUp,
-		Title:      fmt.Sprintf("%s is UP", endpointName),
-		Message:    fmt.Sprintf("Endpoint %s (%s) has recovered after being down for %v", endpointName, endpointURL, downDuration),
-		EndpointID: &endpointID,
-		URL:        endpointURL,
-		Severity:   "info",
-		Timestamp:  time.Now(),
+		Type:             NotificationTypeEndpointUp,
+		Title:            fmt.Sprintf("%s is UP", endpoint.Name),
+		Message:          fmt.Sprintf("Endpoint %s (%s) has recovered after being down for %v", endpoint.Name, endpoint.URL, downDuration),
+		EndpointID:       &endpoint.ID,
+		URL:              event.Link,
+		Severity:         "info",
+		Timestamp:        time.Now(),
+		Endpoints:        []EndpointInfo{endpoint},
+		Check:            &event.Check,
+		OutageIncidentID: event.IncidentID,
+		Metadata: map[string]interface{}{
+			"down_duration": downDuration.String(),
+		},
 	}
 
 	nt.logger.Info("Triggering endpoint up notification",
-		"endpoint_id", endpointID,
-		"endpoint_name", endpointName,
-		"url", endpointURL,
+		"endpoint_id", endpoint.ID,
+		"endpoint_name", endpoint.Name,
+		"url", endpoint.URL,
 		"down_duration", downDuration)
 
-	results := nt.service.SendNotificationToAll(ctx, data)
-	return nt.logResults("endpoint_up", results)
+	return nt.sendToAll(ctx, "endpoint_up", data)
 }
 
 // TriggerIncidentCreated sends notifications when a new incident is created
-func (nt *NotificationTrigger) TriggerIncidentCreated(ctx context.Context, incidentID int, title, description, severity string, affectedEndpoints []int) error {
-	data := NotificationData{
-		Type:       NotificationTypeIncidentCreated,
-		Title:      title,
-		Message:    fmt.Sprintf("A new incident has been created: %s", description),
-		IncidentID: &incidentID,
-		Severity:   severity,
-		Timestamp:  time.Now(),
-		Metadata: map[string]interface{}{
-			"affected_endpoints": affectedEndpoints,
-		},
-	}
+func (nt *NotificationTrigger) TriggerIncidentCreated(ctx context.Context, incident IncidentInfo, description string) error {
+	data := incidentNotification(NotificationTypeIncidentCreated, incident, fmt.Sprintf("A new incident has been created: %s", description))
 
 	nt.logger.Info("Triggering incident created notification",
-		"incident_id", incidentID,
-		"title", title,
-		"severity", severity,
-		"affected_endpoints", len(affectedEndpoints))
+		"incident_id", incident.ID,
+		"title", incident.Title,
+		"severity", incident.Severity,
+		"affected_endpoints", len(incident.Endpoints))
 
-	results := nt.service.SendNotificationToAll(ctx, data)
-	return nt.logResults("incident_created", results)
+	return nt.sendToAll(ctx, "incident_created", data)
 }
 
 // TriggerIncidentUpdated sends notifications when an incident is updated
-func (nt *NotificationTrigger) TriggerIncidentUpdated(ctx context.Context, incidentID int, title, updateMessage, severity string) error {
-	data := NotificationData{
-		Type:       NotificationTypeIncidentUpdated,
-		Title:      title,
-		Message:    fmt.Sprintf("Incident update: %s", updateMessage),
-		IncidentID: &incidentID,
-		Severity:   severity,
-		Timestamp:  time.Now(),
-	}
+func (nt *NotificationTrigger) TriggerIncidentUpdated(ctx context.Context, incident IncidentInfo, updateMessage string) error {
+	data := incidentNotification(NotificationTypeIncidentUpdated, incident, fmt.Sprintf("Incident update: %s", updateMessage))
 
 	nt.logger.Info("Triggering incident updated notification",
-		"incident_id", incidentID,
-		"title", title,
-		"severity", severity)
+		"incident_id", incident.ID,
+		"title", incident.Title,
+		"severity", incident.Severity)
 
-	results := nt.service.SendNotificationToAll(ctx, data)
-	return nt.logResults("incident_updated", results)
+	return nt.sendToAll(ctx, "incident_updated", data)
 }
 
 // TriggerIncidentResolved sends notifications when an incident is resolved
-func (nt *NotificationTrigger) TriggerIncidentResolved(ctx context.Context, incidentID int, title, resolutionMessage string, incidentDuration time.Duration) error {
-	data := NotificationData{
-		Type:       NotificationTypeIncidentResolved,
-		Title:      title,
-		Message:    fmt.Sprintf("Incident resolved: %s (Duration: %v)", resolutionMessage, incidentDuration),
-		IncidentID: &incidentID,
-		Severity:   "info",
-		Timestamp:  time.Now(),
-		Metadata: map[string]interface{}{
-			"incident_duration": incidentDuration.String(),
-		},
+func (nt *NotificationTrigger) TriggerIncidentResolved(ctx context.Context, incident IncidentInfo, resolutionMessage string, incidentDuration time.Duration) error {
+	data := incidentNotification(NotificationTypeIncidentResolved, incident, fmt.Sprintf("Incident resolved: %s (Duration: %v)", resolutionMessage, incidentDuration))
+	data.Severity = "info"
+	data.Metadata = map[string]interface{}{
+		"incident_duration": incidentDuration.String(),
 	}
 
 	nt.logger.Info("Triggering incident resolved notification",
-		"incident_id", incidentID,
-		"title", title,
+		"incident_id", incident.ID,
+		"title", incident.Title,
 		"duration", incidentDuration)
 
-	results := nt.service.SendNotificationToAll(ctx, data)
-	return nt.logResults("incident_resolved", results)
+	return nt.sendToAll(ctx, "incident_resolved", data)
+}
+
+// incidentNotification builds the notification data shared by incident notifications
+func incidentNotification(notificationType NotificationType, incident IncidentInfo, message string) NotificationData {
+	return NotificationData{
+		Type:           notificationType,
+		Title:          incident.Title,
+		Message:        message,
+		IncidentID:     &incident.ID,
+		IncidentStatus: incident.Status,
+		Severity:       incident.Severity,
+		URL:            incident.Link,
+		Timestamp:      time.Now(),
+		Endpoints:      incident.Endpoints,
+	}
 }
 
 // TriggerTestNotification sends a test notification to verify configuration
@@ -148,23 +159,59 @@ func (nt *NotificationTrigger) TriggerTestNotification(ctx context.Context, prov
 
 	nt.logger.Info("Triggering test notification", "provider_type", providerType)
 
-	var results map[ProviderType]DeliveryResult
 	if providerType != nil {
 		// Send to specific provider
 		result := nt.service.SendNotification(ctx, *providerType, data)
-		results = map[ProviderType]DeliveryResult{
-			*providerType: result,
+		return nt.logResults("test_notification", map[string]DeliveryResult{
+			string(*providerType): result,
+		})
+	}
+
+	// Send to all providers and channels
+	return nt.sendToAll(ctx, "test_notification", data)
+}
+
+// sendToAll sends the notification to every enabled provider and routed channel and logs the
+// results. With an outbox, channel notifications are queued for delivery instead
+func (nt *NotificationTrigger) sendToAll(ctx context.Context, triggerType string, data NotificationData) error {
+	results := make(map[string]DeliveryResult)
+	for providerType, result := range nt.service.SendNotificationToAll(ctx, data) {
+		results[string(providerType)] = result
+	}
+
+	if nt.outbox != nil {
+		deliveries, err := nt.outbox.Enqueue(data)
+		if err != nil {
+			nt.logger.Error("Failed to queue notification",
+				"trigger_type", triggerType,
+				"error", err)
+			return err
+		}
+
+		nt.logger.Info("Notification queued",
+			"trigger_type", triggerType,
+			"channels", len(deliveries))
+
+		if len(results) == 0 {
+			return nil
 		}
-	} else {
-		// Send to all providers
-		results = nt.service.SendNotificationToAll(ctx, data)
+		return nt.logResults(triggerType, results)
 	}
 
-	return nt.logResults("test_notification", results)
+	for channelID, result := range nt.service.SendNotificationToChannels(ctx, data) {
+		label := channelID.String()
+		if channel, exists := nt.service.GetChannel(channelID); exists {
+			label = channel.String()
+		}
+		results[label] = result
+	}
+
+	return nt.logResults(triggerType, results)
 }
 
-// logResults logs the results of notification attempts and returns an error if any failed
-func (nt *NotificationTrigger) logResults(triggerType string, results map[ProviderType]DeliveryResult) error {
+// logResults logs the results of notification attempts, keyed by provider or channel, and
+// returns an error if any failed
+func (nt *NotificationTrigger) logResults(triggerType string, results map[string]DeliveryResult) error {
 	var failures []string
 	successCount := 0
 
@@ -219,37 +266,37 @@ func NewRetryableNotificationTrigger(trigger *NotificationTrigger, retryConfig R
 }
 
 // TriggerEndpointDownWithRetry triggers endpoint down notification with retry logic
-func (rnt *RetryableNotificationTrigger) TriggerEndpointDownWithRetry(ctx context.Context, endpointID int, endpointName, endpointURL, errorMessage string) error {
+func (rnt *RetryableNotificationTrigger) TriggerEndpointDownWithRetry(ctx context.Context, event EndpointEvent) error {
 	return rnt.executeWithRetry(ctx, "endpoint_down", func() error {
-		return rnt.trigger.TriggerEndpointDown(ctx, endpointID, endpointName, endpointURL, errorMessage)
+		return rnt.trigger.TriggerEndpointDown(ctx, event)
 	})
 }
 
 // TriggerEndpointUpWithRetry triggers endpoint up notification with retry logic
-func (rnt *RetryableNotificationTrigger) TriggerEndpointUpWithRetry(ctx context.Context, endpointID int, endpointName, endpointURL string, downDuration time.Duration) error {
+func (rnt *RetryableNotificationTrigger) TriggerEndpointUpWithRetry(ctx context.Context, event EndpointEvent, downDuration time.Duration) error {
 	return rnt.executeWithRetry(ctx, "endpoint_up", func() error {
-		return rnt.trigger.TriggerEndpointUp(ctx, endpointID, endpointName, endpointURL, downDuration)
+		return rnt.trigger.TriggerEndpointUp(ctx, event, downDuration)
 	})
 }
 
 // TriggerIncidentCreatedWithRetry triggers incident created notification with retry logic
-func (rnt *RetryableNotificationTrigger) TriggerIncidentCreatedWithRetry(ctx context.Context, incidentID int, title, description, severity string, affectedEndpoints []int) error {
+func (rnt *RetryableNotificationTrigger) TriggerIncidentCreatedWithRetry(ctx context.Context, incident IncidentInfo, description string) error {
 	return rnt.executeWithRetry(ctx, "incident_created", func() error {
-		return rnt.trigger.TriggerIncidentCreated(ctx, incidentID, title, description, severity, affectedEndpoints)
+		return rnt.trigger.TriggerIncidentCreated(ctx, incident, description)
 	})
 }
 
 // TriggerIncidentUpdatedWithRetry triggers incident updated notification with retry logic
-func (rnt *RetryableNotificationTrigger) TriggerIncidentUpdatedWithRetry(ctx context.Context, incidentID int, title, updateMessage, severity string) error {
+func (rnt *RetryableNotificationTrigger) TriggerIncidentUpdatedWithRetry(ctx context.Context, incident IncidentInfo, updateMessage string) error {
 	return rnt.executeWithRetry(ctx, "incident_updated", func() error {
-		return rnt.trigger.TriggerIncidentUpdated(ctx, incidentID, title, updateMessage, severity)
+		return rnt.trigger.TriggerIncidentUpdated(ctx, incident, updateMessage)
 	})
 }
 
 // TriggerIncidentResolvedWithRetry triggers incident resolved notification with retry logic
-func (rnt *RetryableNotificationTrigger) TriggerIncidentResolvedWithRetry(ctx context.Context, incidentID int, title, resolutionMessage string, incidentDuration time.Duration) error {
+func (rnt *RetryableNotificationTrigger) TriggerIncidentResolvedWithRetry(ctx context.Context, incident IncidentInfo, resolutionMessage string, incidentDuration time.Duration) error {
 	return rnt.executeWithRetry(ctx, "incident_resolved", func() error {
-		return rnt.trigger.TriggerIncidentResolved(ctx, incidentID, title, resolutionMessage, incidentDuration)
+		return rnt.trigger.TriggerIncidentResolved(ctx, incident, resolutionMessage, incidentDuration)
 	})
 }
 
@@ -312,25 +359,7 @@ func (rnt *RetryableNotificationTrigger) executeWithRetry(ctx context.Context, t
 	return fmt.Errorf("notification trigger failed after %d attempts: %w", rnt.retryConfig.MaxAttempts, lastError)
 }
 
-// calculateDelay calculates the delay for the next retry attempt (same logic as retry.go)
+// calculateDelay calculates the delay for the next retry attempt
 func (rnt *RetryableNotificationTrigger) calculateDelay(attempt int) time.Duration {
-	// Calculate exponential backoff
-	delay := float64(rnt.retryConfig.InitialDelay) * math.Pow(rnt.retryConfig.BackoffFactor, float64(attempt-1))
-
-	// Apply maximum delay cap
-	if delay > float64(rnt.retryConfig.MaxDelay) {
-		delay = float64(rnt.retryConfig.MaxDelay)
-	}
-
-	duration := time.Duration(delay)
-
-	// Add jitter if enabled
-	if rnt.retryConfig.EnableJitter {
-		// Add up to 25% jitter
-		jitterRange := float64(duration) * 0.25
-		jitter := time.Duration(float64(time.Now().UnixNano() % int64(jitterRange)))
-		duration += jitter
-	}
-
-	return duration
+	return rnt.retryConfig.Delay(attempt)
 }
@@ -5,8 +5,25 @@ import (
 	"log/slog"
 	"testing"
 	"time"
+
+	"github.com/google/uuid"
 )
 
+// testEndpointEvent describes a failing check of an API endpoint
+func testEndpointEvent(checkError string) EndpointEvent {
+	statusCode, responseTime := 503, 1250
+	return EndpointEvent{
+		Endpoint: EndpointInfo{ID: uuid.New(), Name: "API Server", URL: "https://api.example.com"},
+		Check:    CheckInfo{StatusCode: &statusCode, ResponseTimeMs: &responseTime, Error: checkError},
+		Link:     "https://status.example.com/admin/endpoints/api",
+	}
+}
+
+// testIncident describes an open incident
+func testIncident(title, severity string) IncidentInfo {
+	return IncidentInfo{ID: uuid.New(), Title: title, Severity: severity, Status: "open"}
+}
+
 func TestNewNotificationTrigger(t *testing.T) {
 	service := NewService(nil)
 	trigger := NewNotificationTrigger(service, nil)
@@ -27,7 +44,7 @@ func TestTriggerEndpointDown(t *testing.T) {
 
 	trigger := NewNotificationTrigger(service, slog.Default())
 
-	err := trigger.TriggerEndpointDown(context.Background(), 123, "API Server", "https://api.example.com", "Connection timeout")
+	err := trigger.TriggerEndpointDown(context.Background(), testEndpointEvent("Connection timeout"))
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -41,7 +58,7 @@ func TestTriggerEndpointUp(t *testing.T) {
 	trigger := NewNotificationTrigger(service, slog.Default())
 
 	downDuration := 5 * time.Minute
-	err := trigger.TriggerEndpointUp(context.Background(), 123, "API Server", "https://api.example.com", downDuration)
+	err := trigger.TriggerEndpointUp(context.Background(), testEndpointEvent(""), downDuration)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -54,8 +71,7 @@ func TestTriggerIncidentCreated(t *testing.T) {
 
 	trigger := NewNotificationTrigger(service, slog.Default())
 
-	affectedEndpoints := []int{123, 456}
-	err := trigger.TriggerIncidentCreated(context.Background(), 789, "Database Issue", "Database is experiencing high latency", "critical", affectedEndpoints)
+	err := trigger.TriggerIncidentCreated(context.Background(), testIncident("Database Issue", "critical"), "Database is experiencing high latency")
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -68,7 +84,7 @@ func TestTriggerIncidentUpdated(t *testing.T) {
 
 	trigger := NewNotificationTrigger(service, slog.Default())
 
-	err := trigger.TriggerIncidentUpdated(context.Background(), 789, "Database Issue", "Issue has been identified and fix is in progress", "high")
+	err := trigger.TriggerIncidentUpdated(context.Background(), testIncident("Database Issue", "high"), "Issue has been identified and fix is in progress")
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -82,7 +98,7 @@ func TestTriggerIncidentResolved(t *testing.T) {
 	trigger := NewNotificationTrigger(service, slog.Default())
 
 	incidentDuration := 2 * time.Hour
-	err := trigger.TriggerIncidentResolved(context.Background(), 789, "Database Issue", "Database latency has returned to normal", incidentDuration)
+	err := trigger.TriggerIncidentResolved(context.Background(), testIncident("Database Issue", "critical"), "Database latency has returned to normal", incidentDuration)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -117,7 +133,7 @@ func TestTriggerWithFailedProvider(t *testing.T) {
 
 	trigger := NewNotificationTrigger(service, slog.Default())
 
-	err := trigger.TriggerEndpointDown(context.Background(), 123, "API Server", "https://api.example.com", "Connection timeout")
+	err := trigger.TriggerEndpointDown(context.Background(), testEndpointEvent("Connection timeout"))
 	if err == nil {
 		t.Fatal("Expected error when provider fails")
 	}
@@ -155,7 +171,7 @@ func TestRetryableNotificationTriggerSuccess(t *testing.T) {
 
 	retryableTrigger := NewRetryableNotificationTrigger(trigger, retryConfig, slog.Default())
 
-	err := retryableTrigger.TriggerEndpointDownWithRetry(context.Background(), 123, "API Server", "https://api.example.com", "Connection timeout")
+	err := retryableTrigger.TriggerEndpointDownWithRetry(context.Background(), testEndpointEvent("Connection timeout"))
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -178,8 +194,41 @@ func TestRetryableNotificationTriggerMaxAttemptsReached(t *testing.T) {
 
 	retryableTrigger := NewRetryableNotificationTrigger(trigger, retryConfig, slog.Default())
 
-	err := retryableTrigger.TriggerEndpointDownWithRetry(context.Background(), 123, "API Server", "https://api.example.com", "Connection timeout")
+	err := retryableTrigger.TriggerEndpointDownWithRetry(context.Background(), testEndpointEvent("Connection timeout"))
 	if err == nil {
 		t.Fatal("Expected error when max attempts reached")
 	}
 }
+
+func TestNotificationDataDetails(t *testing.T) {
+	event := testEndpointEvent("connection refused")
+	data := NotificationData{
+		Endpoints:      []EndpointInfo{event.Endpoint},
+		Check:          &event.Check,
+		IncidentStatus: "investigating",
+	}
+
+	expected := []Detail{
+		{Label: "Endpoint", Value: "API Server"},
+		{Label: "Endpoint URL", Value: "https://api.example.com"},
+		{Label: "Status code", Value: "503"},
+		{Label: "Response time", Value: "1250 ms"},
+		{Label: "Last error", Value: "connection refused"},
+		{Label: "Incident status", Value: "investigating"},
+	}
+	details := data.Details()
+	if len(details) != len(expected) {
+		t.Fatalf("Expected %d details, got %+v", len(expected), details)
+	}
+	for i := range expected {
+		if details[i] != expected[i] {
+			t.Errorf("Expected %+v, got %+v", expected[i], details[i])
+		}
+	}
+
+	// Several endpoints are listed without a single URL
+	data = NotificationData{Endpoints: []EndpointInfo{{Name: "API", URL: "https://api.example.com"}, {Name: "Web"}}}
+	if details := data.Details(); len(details) != 1 || details[0].Value != "API, Web" {
+		t.Errorf("Expected the endpoint names only, got %+v", details)
+	}
+}
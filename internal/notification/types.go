@@ -2,7 +2,12 @@ package notification
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // NotificationType represents the type of notification
@@ -14,29 +19,142 @@ const (
 	NotificationTypeIncidentCreated  NotificationType = "incident_created"
 	NotificationTypeIncidentUpdated  NotificationType = "incident_updated"
 	NotificationTypeIncidentResolved NotificationType = "incident_resolved"
+	// NotificationTypeIncidentEscalated is sent to an escalation level's channels while an
+	// incident stays unacknowledged
+	NotificationTypeIncidentEscalated NotificationType = "incident_escalated"
+	// NotificationTypeEndpointFlapping replaces the up and down notifications of an endpoint
+	// changing state too often
+	NotificationTypeEndpointFlapping NotificationType = "endpoint_flapping"
+	// NotificationTypeEndpointStabilized summarizes a flapping period once the endpoint is stable
+	NotificationTypeEndpointStabilized NotificationType = "endpoint_stabilized"
+	// NotificationTypeDigest batches the notifications a channel held during quiet hours
+	NotificationTypeDigest NotificationType = "digest"
 )
 
 // NotificationData contains the data to be sent in a notification
 type NotificationData struct {
-	Type       NotificationType
-	Title      string
-	Message    string
-	EndpointID *int
-	IncidentID *int
-	Severity   string
-	URL        string
-	Timestamp  time.Time
-	Metadata   map[string]interface{}
+	Type       NotificationType `json:"type"`
+	Title      string           `json:"title"`
+	Message    string           `json:"message"`
+	EndpointID *uuid.UUID       `json:"endpoint_id,omitempty"`
+	IncidentID *uuid.UUID       `json:"incident_id,omitempty"`
+	Severity   string           `json:"severity"`
+	// URL links to the endpoint or incident in Watchtower
+	URL       string                 `json:"url,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	// Endpoints are the endpoints the notification is about, used for routing
+	Endpoints []EndpointInfo `json:"endpoints,omitempty"`
+	// Recipients replace the channel's configured recipients, such as the user on call
+	Recipients []string `json:"recipients,omitempty"`
+	// EventID identifies the event across channels and retries once it is queued
+	EventID *uuid.UUID `json:"event_id,omitempty"`
+	// IncidentStatus is the status of the incident an incident notification is about
+	IncidentStatus string `json:"incident_status,omitempty"`
+	// Check is the check behind an endpoint notification
+	Check *CheckInfo `json:"check,omitempty"`
+	// OutageIncidentID is the incident an endpoint notification's outage belongs to. Unlike
+	// IncidentID it does not make the notification about the incident
+	OutageIncidentID *uuid.UUID `json:"outage_incident_id,omitempty"`
+	// HTMLMessage is the message rendered by a channel's HTML template, set just before sending
+	HTMLMessage string `json:"-"`
+}
+
+// EndpointInfo identifies an endpoint a notification is about
+type EndpointInfo struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	URL  string    `json:"url,omitempty"`
+	Tags []string  `json:"tags,omitempty"`
+}
+
+// CheckInfo describes the check result that changed an endpoint's state
+type CheckInfo struct {
+	StatusCode     *int   `json:"status_code,omitempty"`
+	ResponseTimeMs *int   `json:"response_time_ms,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// EndpointEvent describes the endpoint an endpoint notification is about
+type EndpointEvent struct {
+	Endpoint EndpointInfo
+	Check    CheckInfo
+	// Link is the endpoint's page in Watchtower
+	Link string
+	// IncidentID is the incident opened for or resolved with the endpoint's outage, if any
+	IncidentID *uuid.UUID
+}
+
+// IncidentInfo describes the incident an incident notification is about
+type IncidentInfo struct {
+	ID        uuid.UUID
+	Title     string
+	Severity  string
+	Status    string
+	Endpoints []EndpointInfo
+	// Link is the incident's page in Watchtower
+	Link string
+}
+
+// Detail is a labelled piece of context providers render alongside the message
+type Detail struct {
+	Label string
+	Value string
+}
+
+// Details returns the notification's endpoint, check and incident context in display order,
+// leaving out what is unknown
+func (d NotificationData) Details() []Detail {
+	var details []Detail
+
+	if len(d.Endpoints) > 0 {
+		names := make([]string, 0, len(d.Endpoints))
+		for _, endpoint := range d.Endpoints {
+			names = append(names, endpoint.Name)
+		}
+		label := "Endpoint"
+		if len(names) > 1 {
+			label = "Endpoints"
+		}
+		details = append(details, Detail{Label: label, Value: strings.Join(names, ", ")})
+
+		if len(d.Endpoints) == 1 && d.Endpoints[0].URL != "" {
+			details = append(details, Detail{Label: "Endpoint URL", Value: d.Endpoints[0].URL})
+		}
+	}
+
+	if d.Check != nil {
+		if d.Check.StatusCode != nil {
+			details = append(details, Detail{Label: "Status code", Value: strconv.Itoa(*d.Check.StatusCode)})
+		}
+		if d.Check.ResponseTimeMs != nil {
+			details = append(details, Detail{Label: "Response time", Value: fmt.Sprintf("%d ms", *d.Check.ResponseTimeMs)})
+		}
+		if d.Check.Error != "" {
+			details = append(details, Detail{Label: "Last error", Value: d.Check.Error})
+		}
+	}
+
+	if d.IncidentStatus != "" {
+		details = append(details, Detail{Label: "Incident status", Value: d.IncidentStatus})
+	}
+
+	return details
 }
 
 // ProviderType represents the type of notification provider
 type ProviderType string
 
 const (
-	ProviderTypeEmail   ProviderType = "email"
-	ProviderTypeSlack   ProviderType = "slack"
-	ProviderTypeDiscord ProviderType = "discord"
-	ProviderTypeWebhook ProviderType = "webhook"
+	ProviderTypeEmail     ProviderType = "email"
+	ProviderTypeSlack     ProviderType = "slack"
+	ProviderTypeDiscord   ProviderType = "discord"
+	ProviderTypeWebhook   ProviderType = "webhook"
+	ProviderTypePagerDuty ProviderType = "pagerduty"
+	ProviderTypeTeams     ProviderType = "teams"
+	ProviderTypeTelegram  ProviderType = "telegram"
+	ProviderTypeNtfy      ProviderType = "ntfy"
+	ProviderTypeGotify    ProviderType = "gotify"
 )
 
 // ProviderConfig contains configuration for a notification provider
@@ -52,6 +170,9 @@ type DeliveryResult struct {
 	Error     error
 	Timestamp time.Time
 	Details   string
+	// FailedRecipients are the recipients a failed delivery to several recipients did not
+	// reach, retries only go to them
+	FailedRecipients []string
 }
 
 // NotificationProvider defines the interface that all notification providers must implement
@@ -0,0 +1,130 @@
+package oncall
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+// ErrNoParticipants is returned for schedules without anyone in the rotation
+var ErrNoParticipants = errors.New("schedule has no participants")
+
+// Shift is a period during which one user is on call
+type Shift struct {
+	UserID uuid.UUID `json:"user_id"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	// OverrideID is set when an override replaces the rotation
+	OverrideID *uuid.UUID `json:"override_id,omitempty"`
+}
+
+// ParseHandoffTime parses an HH:MM handoff time into hours and minutes
+func ParseHandoffTime(value string) (int, int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("handoff time must be formatted as HH:MM: %w", err)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// RotationShift returns the rotation's shift containing the given time, ignoring overrides
+func RotationShift(schedule *data.OnCallSchedule, at time.Time) (*Shift, error) {
+	if len(schedule.Participants) == 0 {
+		return nil, ErrNoParticipants
+	}
+
+	location, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", schedule.Timezone, err)
+	}
+	hour, minute, err := ParseHandoffTime(schedule.HandoffTime)
+	if err != nil {
+		return nil, err
+	}
+
+	days := 1
+	if schedule.RotationType == data.OnCallRotationWeekly {
+		days = 7
+	}
+
+	// Handoffs are counted in calendar days so shifts keep their wall clock time across DST changes
+	first := handoffBefore(schedule, schedule.RotationStart.In(location), hour, minute, days)
+	start := handoffBefore(schedule, at.In(location), hour, minute, days)
+	end := addDays(start, days)
+
+	shifts := floorDiv(daysBetween(first, start), days)
+	index := floorMod(shifts, len(schedule.Participants))
+
+	return &Shift{
+		UserID: schedule.Participants[index],
+		Start:  start,
+		End:    end,
+	}, nil
+}
+
+// CurrentShift returns who is on call at the given time. The most recently created override
+// covering the time replaces the rotation
+func CurrentShift(schedule *data.OnCallSchedule, overrides []data.OnCallOverride, at time.Time) (*Shift, error) {
+	var active *data.OnCallOverride
+	for i := range overrides {
+		override := &overrides[i]
+		if override.ScheduleID != schedule.ID || at.Before(override.StartTime) || !at.Before(override.EndTime) {
+			continue
+		}
+		if active == nil || override.CreatedAt.After(active.CreatedAt) {
+			active = override
+		}
+	}
+
+	if active != nil {
+		return &Shift{
+			UserID:     active.UserID,
+			Start:      active.StartTime,
+			End:        active.EndTime,
+			OverrideID: &active.ID,
+		}, nil
+	}
+
+	return RotationShift(schedule, at)
+}
+
+// handoffBefore returns the last handoff at or before the local time
+func handoffBefore(schedule *data.OnCallSchedule, local time.Time, hour, minute, days int) time.Time {
+	handoff := time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, local.Location())
+
+	if days == 7 {
+		back := (int(local.Weekday()) - schedule.HandoffWeekday + 7) % 7
+		handoff = addDays(handoff, -back)
+	}
+	if handoff.After(local) {
+		handoff = addDays(handoff, -days)
+	}
+	return handoff
+}
+
+// addDays moves a time by calendar days, keeping its wall clock time
+func addDays(t time.Time, days int) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()+days, t.Hour(), t.Minute(), 0, 0, t.Location())
+}
+
+// daysBetween counts the calendar days from a to b
+func daysBetween(a, b time.Time) int {
+	dateA := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	dateB := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(dateB.Sub(dateA).Hours() / 24)
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+func floorMod(a, b int) int {
+	return ((a % b) + b) % b
+}
@@ -0,0 +1,148 @@
+package oncall
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+func TestRotationShiftWeekly(t *testing.T) {
+	alice, bob, carol := uuid.New(), uuid.New(), uuid.New()
+	location, _ := time.LoadLocation("America/New_York")
+
+	schedule := &data.OnCallSchedule{
+		ID:             uuid.New(),
+		Timezone:       "America/New_York",
+		RotationType:   data.OnCallRotationWeekly,
+		HandoffTime:    "09:00",
+		HandoffWeekday: int(time.Monday),
+		RotationStart:  time.Date(2025, 9, 1, 9, 0, 0, 0, location), // Monday
+		Participants:   data.UUIDList{alice, bob, carol},
+	}
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		expected uuid.UUID
+		start    time.Time
+	}{
+		{"first shift", time.Date(2025, 9, 3, 12, 0, 0, 0, location), alice, time.Date(2025, 9, 1, 9, 0, 0, 0, location)},
+		{"just before handoff", time.Date(2025, 9, 8, 8, 59, 0, 0, location), alice, time.Date(2025, 9, 1, 9, 0, 0, 0, location)},
+		{"at handoff", time.Date(2025, 9, 8, 9, 0, 0, 0, location), bob, time.Date(2025, 9, 8, 9, 0, 0, 0, location)},
+		{"third shift", time.Date(2025, 9, 20, 0, 0, 0, 0, location), carol, time.Date(2025, 9, 15, 9, 0, 0, 0, location)},
+		{"wraps around", time.Date(2025, 9, 22, 10, 0, 0, 0, location), alice, time.Date(2025, 9, 22, 9, 0, 0, 0, location)},
+		{"across DST change", time.Date(2025, 11, 3, 9, 30, 0, 0, location), alice, time.Date(2025, 11, 3, 9, 0, 0, 0, location)},
+		{"before rotation start", time.Date(2025, 8, 28, 12, 0, 0, 0, location), carol, time.Date(2025, 8, 25, 9, 0, 0, 0, location)},
+		{"time in another zone", time.Date(2025, 9, 8, 13, 30, 0, 0, time.UTC), bob, time.Date(2025, 9, 8, 9, 0, 0, 0, location)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shift, err := RotationShift(schedule, tt.at)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if shift.UserID != tt.expected {
+				t.Errorf("Expected participant %d, got participant %d", indexOf(schedule.Participants, tt.expected), indexOf(schedule.Participants, shift.UserID))
+			}
+			if !shift.Start.Equal(tt.start) || !shift.End.Equal(addDays(tt.start, 7)) {
+				t.Errorf("Expected shift from %v, got %v to %v", tt.start, shift.Start, shift.End)
+			}
+		})
+	}
+}
+
+func TestRotationShiftDaily(t *testing.T) {
+	alice, bob := uuid.New(), uuid.New()
+	schedule := &data.OnCallSchedule{
+		Timezone:      "UTC",
+		RotationType:  data.OnCallRotationDaily,
+		HandoffTime:   "18:30",
+		RotationStart: time.Date(2025, 9, 1, 20, 0, 0, 0, time.UTC),
+		Participants:  data.UUIDList{alice, bob},
+	}
+
+	// The rotation start falls in the shift that began at the previous handoff
+	shift, _ := RotationShift(schedule, time.Date(2025, 9, 2, 10, 0, 0, 0, time.UTC))
+	if shift.UserID != alice || !shift.Start.Equal(time.Date(2025, 9, 1, 18, 30, 0, 0, time.UTC)) {
+		t.Errorf("Expected first participant from the 1st, got %+v", shift)
+	}
+
+	shift, _ = RotationShift(schedule, time.Date(2025, 9, 2, 18, 30, 0, 0, time.UTC))
+	if shift.UserID != bob || !shift.End.Equal(time.Date(2025, 9, 3, 18, 30, 0, 0, time.UTC)) {
+		t.Errorf("Expected second participant until the 3rd, got %+v", shift)
+	}
+}
+
+func TestCurrentShiftOverrides(t *testing.T) {
+	alice, bob, carol := uuid.New(), uuid.New(), uuid.New()
+	schedule := &data.OnCallSchedule{
+		ID:            uuid.New(),
+		Timezone:      "UTC",
+		RotationType:  data.OnCallRotationWeekly,
+		HandoffTime:   "09:00",
+		RotationStart: time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC),
+		Participants:  data.UUIDList{alice},
+	}
+
+	start := time.Date(2025, 9, 3, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	overrides := []data.OnCallOverride{
+		{ID: uuid.New(), ScheduleID: schedule.ID, UserID: bob, StartTime: start, EndTime: end, CreatedAt: start},
+		{ID: uuid.New(), ScheduleID: schedule.ID, UserID: carol, StartTime: start.Add(12 * time.Hour), EndTime: end, CreatedAt: start.Add(time.Hour)},
+	}
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		expected uuid.UUID
+		override bool
+	}{
+		{"before overrides", start.Add(-time.Minute), alice, false},
+		{"override", start, bob, true},
+		{"newer override wins", start.Add(12 * time.Hour), carol, true},
+		{"override end is exclusive", end, alice, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shift, err := CurrentShift(schedule, overrides, tt.at)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if shift.UserID != tt.expected || (shift.OverrideID != nil) != tt.override {
+				t.Errorf("Expected %s (override %v), got %+v", tt.expected, tt.override, shift)
+			}
+		})
+	}
+}
+
+func TestRotationShiftErrors(t *testing.T) {
+	schedule := &data.OnCallSchedule{Timezone: "UTC", RotationType: data.OnCallRotationDaily, HandoffTime: "09:00"}
+	if _, err := RotationShift(schedule, time.Now()); err != ErrNoParticipants {
+		t.Errorf("Expected ErrNoParticipants, got %v", err)
+	}
+
+	schedule.Participants = data.UUIDList{uuid.New()}
+	schedule.Timezone = "Mars/Olympus_Mons"
+	if _, err := RotationShift(schedule, time.Now()); err == nil {
+		t.Error("Expected an error for an unknown timezone")
+	}
+
+	schedule.Timezone = "UTC"
+	schedule.HandoffTime = "9am"
+	if _, err := RotationShift(schedule, time.Now()); err == nil {
+		t.Error("Expected an error for an invalid handoff time")
+	}
+}
+
+func indexOf(ids data.UUIDList, id uuid.UUID) int {
+	for i, candidate := range ids {
+		if candidate == id {
+			return i
+		}
+	}
+	return -1
+}
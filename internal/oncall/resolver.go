@@ -0,0 +1,66 @@
+package oncall
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/i4o-oss/watchtower/internal/data"
+)
+
+// Store loads schedules, their overrides and the users on call
+type Store interface {
+	GetOnCallSchedule(id uuid.UUID) (*data.OnCallSchedule, error)
+	GetOnCallOverrides(scheduleID uuid.UUID, from, to time.Time) ([]data.OnCallOverride, error)
+	GetUserByID(id uuid.UUID) (*data.User, error)
+}
+
+// OnCall is the user on call for a schedule and their shift
+type OnCall struct {
+	Shift
+	User *data.User `json:"user"`
+}
+
+// Resolver answers who is on call for a schedule
+type Resolver struct {
+	store Store
+}
+
+// NewResolver creates a new resolver reading from the store
+func NewResolver(store Store) *Resolver {
+	return &Resolver{store: store}
+}
+
+// OnCallAt returns who is on call for the schedule at the given time
+func (r *Resolver) OnCallAt(scheduleID uuid.UUID, at time.Time) (*OnCall, error) {
+	schedule, err := r.store.GetOnCallSchedule(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := r.store.GetOnCallOverrides(scheduleID, at, at.Add(time.Nanosecond))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load overrides: %w", err)
+	}
+
+	shift, err := CurrentShift(schedule, overrides, at)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := r.store.GetUserByID(shift.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load on-call user %s: %w", shift.UserID, err)
+	}
+
+	return &OnCall{Shift: *shift, User: user}, nil
+}
+
+// Email returns the email address of whoever is on call for the schedule at the given time
+func (r *Resolver) Email(scheduleID uuid.UUID, at time.Time) (string, error) {
+	onCall, err := r.OnCallAt(scheduleID, at)
+	if err != nil {
+		return "", err
+	}
+	return onCall.User.Email, nil
+}
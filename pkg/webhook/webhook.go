@@ -0,0 +1,225 @@
+// Package webhook describes the events Watchtower posts to webhook channels and verifies their
+// signatures. Receivers can import it to check that a request came from Watchtower before
+// trusting its body.
+//
+// Every request body is a JSON encoded Event. The schema is versioned, fields are only added
+// within a version and Version changes when fields are removed or change meaning:
+//
+//	{
+//	  "version": "1",
+//	  "id": "5f0c6b1e-...",            // stays the same when a delivery is retried
+//	  "type": "incident_created",
+//	  "occurred_at": "2025-09-01T12:00:00Z",
+//	  "title": "API outage",
+//	  "message": "API is failing",
+//	  "severity": "high",
+//	  "url": "https://status.example.com/admin/incidents/...",  // the endpoint or incident in Watchtower
+//	  "endpoint": {"id": "...", "name": "API", "url": "https://api.example.com/health",
+//	               "tags": ["backend"]},                              // endpoint_down and endpoint_up
+//	  "check": {"status_code": 503, "response_time_ms": 1250,
+//	            "error": "..."},                                      // endpoint_down and endpoint_up
+//	  "incident": {"id": "...", "title": "API outage", "severity": "high", "status": "open",
+//	               "endpoints": [{"id": "...", "name": "API"}]},      // incident events
+//	  "metadata": {}
+//	}
+//
+// Requests carry the event's ID and type in the X-Watchtower-Event-Id and X-Watchtower-Event-Type
+// headers and are signed in X-Watchtower-Signature:
+//
+//	X-Watchtower-Signature: t=1756728000,v1=5257a869e7ec...
+//
+// where t is the Unix time the request was sent and each v1 is the hex encoded HMAC-SHA256 of
+// "<t>.<body>" keyed by a channel secret. While a secret is being rotated the request is signed
+// with both the new and the previous secret, so receivers can switch secrets at their own pace.
+// Checking t against the current time keeps captured requests from being replayed later.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SchemaVersion is the version of the Event schema
+const SchemaVersion = "1"
+
+// Request headers set on every webhook
+const (
+	SignatureHeader = "X-Watchtower-Signature"
+	EventIDHeader   = "X-Watchtower-Event-Id"
+	EventTypeHeader = "X-Watchtower-Event-Type"
+)
+
+// DefaultTolerance is how old a signature may be before it is rejected as a replay
+const DefaultTolerance = 5 * time.Minute
+
+// maxBodyBytes bounds the request bodies ParseRequest reads
+const maxBodyBytes = 1 << 20
+
+// signatureScheme identifies HMAC-SHA256 signatures in the signature header
+const signatureScheme = "v1"
+
+var (
+	// ErrMissingSignature is returned when the request has no signature header
+	ErrMissingSignature = errors.New("missing webhook signature")
+	// ErrInvalidSignatureHeader is returned when the signature header cannot be parsed
+	ErrInvalidSignatureHeader = errors.New("invalid webhook signature header")
+	// ErrSignatureExpired is returned when the signature is older than the tolerance
+	ErrSignatureExpired = errors.New("webhook signature timestamp is outside the tolerance")
+	// ErrSignatureMismatch is returned when no signature matches any of the secrets
+	ErrSignatureMismatch = errors.New("webhook signature does not match")
+)
+
+// Event is the body of every webhook request
+type Event struct {
+	Version    string                 `json:"version"`
+	ID         uuid.UUID              `json:"id"`
+	Type       string                 `json:"type"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Title      string                 `json:"title"`
+	Message    string                 `json:"message"`
+	Severity   string                 `json:"severity,omitempty"`
+	URL        string                 `json:"url,omitempty"`
+	Endpoint   *Endpoint              `json:"endpoint,omitempty"`
+	Check      *Check                 `json:"check,omitempty"`
+	Incident   *Incident              `json:"incident,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Endpoint identifies a monitored endpoint
+type Endpoint struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	URL  string    `json:"url,omitempty"`
+	Tags []string  `json:"tags,omitempty"`
+}
+
+// Check is the check result that changed an endpoint's state
+type Check struct {
+	StatusCode     *int   `json:"status_code,omitempty"`
+	ResponseTimeMs *int   `json:"response_time_ms,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// Incident identifies an incident and the endpoints it affects
+type Incident struct {
+	ID        uuid.UUID  `json:"id"`
+	Title     string     `json:"title"`
+	Severity  string     `json:"severity,omitempty"`
+	Status    string     `json:"status,omitempty"`
+	Endpoints []Endpoint `json:"endpoints,omitempty"`
+}
+
+// Sign returns the signature header value for the body sent at the given time, with one
+// signature per secret
+func Sign(body []byte, timestamp time.Time, secrets ...string) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+
+	parts := make([]string, 0, len(secrets)+1)
+	parts = append(parts, "t="+t)
+	for _, secret := range secrets {
+		parts = append(parts, signatureScheme+"="+hex.EncodeToString(computeSignature(t, body, secret)))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Verify checks that the signature header holds a valid signature of the body by one of the
+// secrets, made no longer ago than the tolerance. A zero tolerance uses DefaultTolerance
+func Verify(body []byte, header string, tolerance time.Duration, secrets ...string) error {
+	return verifyAt(body, header, tolerance, time.Now(), secrets...)
+}
+
+// verifyAt verifies the signature header as of the given time
+func verifyAt(body []byte, header string, tolerance time.Duration, now time.Time, secrets ...string) error {
+	if header == "" {
+		return ErrMissingSignature
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+
+	var t string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return ErrInvalidSignatureHeader
+		}
+		switch key {
+		case "t":
+			t = value
+		case signatureScheme:
+			signature, err := hex.DecodeString(value)
+			if err != nil {
+				return ErrInvalidSignatureHeader
+			}
+			signatures = append(signatures, signature)
+		}
+	}
+
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignatureHeader
+	}
+
+	// Reject signatures from too far in the past or the future
+	age := now.Sub(time.Unix(unix, 0))
+	if age > tolerance || age < -tolerance {
+		return ErrSignatureExpired
+	}
+
+	for _, secret := range secrets {
+		expected := computeSignature(t, body, secret)
+		for _, signature := range signatures {
+			if hmac.Equal(expected, signature) {
+				return nil
+			}
+		}
+	}
+	return ErrSignatureMismatch
+}
+
+// computeSignature returns the HMAC-SHA256 of "<t>.<body>"
+func computeSignature(t string, body []byte, secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// ParseEvent verifies the body against the signature header with DefaultTolerance and decodes
+// the event
+func ParseEvent(body []byte, header string, secrets ...string) (*Event, error) {
+	if err := Verify(body, header, DefaultTolerance, secrets...); err != nil {
+		return nil, err
+	}
+
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("invalid webhook event: %w", err)
+	}
+	if event.Version != SchemaVersion {
+		return nil, fmt.Errorf("unsupported webhook event version %q", event.Version)
+	}
+	return &event, nil
+}
+
+// ParseRequest reads, verifies and decodes the event of an incoming webhook request
+func ParseRequest(r *http.Request, secrets ...string) (*Event, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook body: %w", err)
+	}
+	return ParseEvent(body, r.Header.Get(SignatureHeader), secrets...)
+}
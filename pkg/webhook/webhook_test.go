@@ -0,0 +1,94 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"version":"1","type":"endpoint_down"}`)
+	sentAt := time.Unix(1756728000, 0)
+
+	header := Sign(body, sentAt, "whsec_current")
+	if !strings.HasPrefix(header, "t=1756728000,v1=") {
+		t.Fatalf("Expected timestamp and signature, got %q", header)
+	}
+
+	tests := []struct {
+		name     string
+		body     []byte
+		header   string
+		now      time.Time
+		secrets  []string
+		expected error
+	}{
+		{"valid", body, header, sentAt.Add(time.Minute), []string{"whsec_current"}, nil},
+		{"any of the secrets", body, header, sentAt, []string{"whsec_old", "whsec_current"}, nil},
+		{"wrong secret", body, header, sentAt, []string{"whsec_other"}, ErrSignatureMismatch},
+		{"tampered body", []byte(`{"version":"1","type":"endpoint_up"}`), header, sentAt, []string{"whsec_current"}, ErrSignatureMismatch},
+		{"replayed", body, header, sentAt.Add(10 * time.Minute), []string{"whsec_current"}, ErrSignatureExpired},
+		{"from the future", body, header, sentAt.Add(-10 * time.Minute), []string{"whsec_current"}, ErrSignatureExpired},
+		{"missing", body, "", sentAt, []string{"whsec_current"}, ErrMissingSignature},
+		{"no signatures", body, "t=1756728000", sentAt, []string{"whsec_current"}, ErrInvalidSignatureHeader},
+		{"malformed", body, "t=1756728000,v1=zz", sentAt, []string{"whsec_current"}, ErrInvalidSignatureHeader},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyAt(tt.body, tt.header, DefaultTolerance, tt.now, tt.secrets...)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestSignWithRotatedSecrets(t *testing.T) {
+	body := []byte(`{}`)
+	now := time.Now()
+
+	header := Sign(body, now, "whsec_new", "whsec_old")
+
+	// Receivers that still have the old secret and those that switched both verify
+	for _, secret := range []string{"whsec_new", "whsec_old"} {
+		if err := verifyAt(body, header, 0, now, secret); err != nil {
+			t.Errorf("Expected %s to verify, got %v", secret, err)
+		}
+	}
+}
+
+func TestParseRequest(t *testing.T) {
+	event := Event{
+		Version:    SchemaVersion,
+		ID:         uuid.New(),
+		Type:       "incident_created",
+		OccurredAt: time.Now().UTC(),
+		Title:      "API outage",
+		Incident:   &Incident{ID: uuid.New(), Title: "API outage", Endpoints: []Endpoint{{ID: uuid.New(), Name: "API"}}},
+	}
+	body, _ := json.Marshal(event)
+
+	r := httptest.NewRequest("POST", "/hooks/watchtower", bytes.NewReader(body))
+	r.Header.Set(SignatureHeader, Sign(body, time.Now(), "whsec_current"))
+
+	parsed, err := ParseRequest(r, "whsec_current")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if parsed.ID != event.ID || parsed.Incident == nil || parsed.Incident.Endpoints[0].Name != "API" {
+		t.Errorf("Expected the signed event, got %+v", parsed)
+	}
+
+	// Unsigned requests are rejected
+	r = httptest.NewRequest("POST", "/hooks/watchtower", bytes.NewReader(body))
+	if _, err := ParseRequest(r, "whsec_current"); !errors.Is(err, ErrMissingSignature) {
+		t.Errorf("Expected missing signature, got %v", err)
+	}
+}